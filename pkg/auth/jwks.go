@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Key set errors
+var (
+	ErrUnknownKey = errors.New("unknown signing key")
+	ErrNoKeys     = errors.New("key set contains no usable signing keys")
+)
+
+// minRefreshInterval limits how often an unknown "kid" may force a refetch
+const minRefreshInterval = 30 * time.Second
+
+// jwk is a single JSON Web Key as published in a JWKS document
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet caches public signing keys loaded from a JWKS document.
+// Keys are refreshed periodically, and on demand when a token references
+// a key ID that is not cached yet (key rotation).
+type KeySet struct {
+	fetch           func(ctx context.Context) ([]byte, error)
+	refreshInterval time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// NewRemoteKeySet creates a key set backed by a JWKS URL
+func NewRemoteKeySet(url string, refreshInterval time.Duration) *KeySet {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	return newKeySet(func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	}, refreshInterval)
+}
+
+// NewFileKeySet creates a key set backed by a JWKS file on disk
+func NewFileKeySet(path string, refreshInterval time.Duration) *KeySet {
+	return newKeySet(func(ctx context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}, refreshInterval)
+}
+
+func newKeySet(fetch func(ctx context.Context) ([]byte, error), refreshInterval time.Duration) *KeySet {
+	if refreshInterval <= 0 {
+		refreshInterval = 15 * time.Minute
+	}
+	return &KeySet{
+		fetch:           fetch,
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]crypto.PublicKey),
+	}
+}
+
+// Key returns the public key for a key ID, refreshing the cache if needed.
+// An empty kid is accepted only when the set holds exactly one key.
+func (ks *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	stale := time.Since(ks.fetchedAt) > ks.refreshInterval
+	ks.mu.RUnlock()
+
+	if stale {
+		// A failed refresh keeps serving previously cached keys
+		if err := ks.refresh(ctx); err != nil && ks.size() == 0 {
+			return nil, err
+		}
+	}
+
+	if key, ok := ks.lookup(kid); ok {
+		return key, nil
+	}
+
+	// Unknown kid: the issuer may have rotated keys since the last fetch
+	ks.mu.RLock()
+	canRefresh := time.Since(ks.fetchedAt) > minRefreshInterval
+	ks.mu.RUnlock()
+
+	if canRefresh {
+		if err := ks.refresh(ctx); err != nil {
+			return nil, err
+		}
+		if key, ok := ks.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// lookup finds a cached key
+func (ks *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if kid == "" {
+		if len(ks.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range ks.keys {
+			return key, true
+		}
+	}
+
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+func (ks *KeySet) size() int {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return len(ks.keys)
+}
+
+// refresh fetches and parses the JWKS document, replacing the cached keys
+func (ks *KeySet) refresh(ctx context.Context) error {
+	data, err := ks.fetch(ctx)
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	// Record the attempt even on failure so a broken endpoint is not hammered
+	ks.fetchedAt = time.Now()
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	ks.keys = keys
+	return nil
+}
+
+// parseJWKS parses RSA and EC signing keys from a JWKS document
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JWKS document: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = parseRSAKey(k)
+		case "EC":
+			key, err = parseECKey(k)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK %q: %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	return keys, nil
+}
+
+func parseRSAKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 {
+		return nil, errors.New("invalid RSA parameters")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+func parseECKey(k jwk) (*ecdsa.PublicKey, error) {
+	if k.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("invalid EC coordinates")
+	}
+
+	// Reject points that are not on the curve
+	point := append([]byte{0x04}, append(x, y...)...)
+	if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+		return nil, err
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
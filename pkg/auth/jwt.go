@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/pkg/config"
+)
+
+// Common validation errors
+var (
+	ErrMissingToken     = errors.New("missing bearer token")
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrTokenExpired     = errors.New("token has expired")
+	ErrTokenNotYetValid = errors.New("token is not yet valid")
+	ErrInvalidIssuer    = errors.New("invalid token issuer")
+	ErrInvalidAudience  = errors.New("invalid token audience")
+	ErrMissingSubject   = errors.New("token has no subject")
+)
+
+// header is the decoded JOSE header of a compact JWS
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// MinSecretLength is the shortest HS256 secret accepted. Shorter secrets
+// can be brute-forced offline from any token signed with them.
+const MinSecretLength = 32
+
+// Validator verifies signed JWTs and maps their claims to a Principal
+type Validator struct {
+	cfg  *config.JWTConfig
+	keys *KeySet
+	now  func() time.Time
+}
+
+// NewValidator creates a validator from configuration.
+// HS256 is enabled when a secret of at least MinSecretLength bytes is
+// configured; RS256/ES256 are enabled when a JWKS URL or file is configured.
+func NewValidator(cfg *config.JWTConfig) (*Validator, error) {
+	v := &Validator{
+		cfg: cfg,
+		now: time.Now,
+	}
+
+	switch {
+	case cfg.JWKSURL != "":
+		v.keys = NewRemoteKeySet(cfg.JWKSURL, cfg.JWKSRefreshInterval)
+	case cfg.JWKSFile != "":
+		v.keys = NewFileKeySet(cfg.JWKSFile, cfg.JWKSRefreshInterval)
+	}
+
+	if cfg.Secret == "" && v.keys == nil {
+		return nil, fmt.Errorf("auth: either JWT_SECRET or JWT_JWKS_URL/JWT_JWKS_FILE must be configured")
+	}
+
+	if cfg.Secret != "" && len(cfg.Secret) < MinSecretLength {
+		return nil, fmt.Errorf("auth: JWT_SECRET must be at least %d bytes long", MinSecretLength)
+	}
+
+	return v, nil
+}
+
+// Validate verifies the token signature and registered claims and returns the principal
+func (v *Validator) Validate(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var hdr header
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if err := v.verifySignature(ctx, hdr, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	principal := v.principalFromClaims(claims)
+	if principal.UserID == "" {
+		return nil, ErrMissingSubject
+	}
+
+	return principal, nil
+}
+
+// verifySignature checks the signature with the key matching the header algorithm.
+// The key type is always derived from the algorithm so an RSA public key can never
+// be used as an HMAC secret.
+func (v *Validator) verifySignature(ctx context.Context, hdr header, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch hdr.Alg {
+	case "HS256":
+		if v.cfg.Secret == "" {
+			return ErrUnsupportedAlg
+		}
+		mac := hmac.New(sha256.New, []byte(v.cfg.Secret))
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case "RS256":
+		key, err := v.publicKey(ctx, hdr.Kid)
+		if err != nil {
+			return err
+		}
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case "ES256":
+		key, err := v.publicKey(ctx, hdr.Kid)
+		if err != nil {
+			return err
+		}
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve.Params().BitSize != 256 || len(signature) != 64 {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, digest[:], r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+
+	return ErrUnsupportedAlg
+}
+
+// publicKey resolves a signing key from the configured key set
+func (v *Validator) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if v.keys == nil {
+		return nil, ErrUnsupportedAlg
+	}
+	return v.keys.Key(ctx, kid)
+}
+
+// validateClaims checks exp, nbf, iat, iss and aud
+func (v *Validator) validateClaims(claims map[string]interface{}) error {
+	now := v.now()
+	skew := v.cfg.ClockSkew
+
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return ErrTokenExpired
+	}
+	if now.After(exp.Add(skew)) {
+		return ErrTokenExpired
+	}
+
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(skew).Before(nbf) {
+		return ErrTokenNotYetValid
+	}
+
+	if iat, ok := numericClaim(claims, "iat"); ok && now.Add(skew).Before(iat) {
+		return ErrTokenNotYetValid
+	}
+
+	if v.cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.cfg.Issuer {
+			return ErrInvalidIssuer
+		}
+	}
+
+	if v.cfg.Audience != "" && !containsString(stringsClaim(claims, "aud"), v.cfg.Audience) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+// principalFromClaims maps claims to a principal using the configured claim names
+func (v *Validator) principalFromClaims(claims map[string]interface{}) *Principal {
+	p := &Principal{
+		UserID:   firstString(stringsClaim(claims, v.cfg.UserIDClaim)),
+		UserName: firstString(stringsClaim(claims, v.cfg.UserNameClaim)),
+		Role:     firstString(stringsClaim(claims, v.cfg.RoleClaim)),
+		TenantID: firstString(stringsClaim(claims, v.cfg.TenantIDClaim)),
+		GroupIDs: stringsClaim(claims, v.cfg.GroupIDsClaim),
+		Claims:   claims,
+	}
+
+	if p.UserName == "" {
+		p.UserName = p.UserID
+	}
+
+	return p
+}
+
+// decodeSegment decodes a base64url JSON segment into out
+func decodeSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// lookupClaim resolves a claim by name, following dotted paths into nested objects
+func lookupClaim(claims map[string]interface{}, name string) (interface{}, bool) {
+	if name == "" {
+		return nil, false
+	}
+	if value, ok := claims[name]; ok {
+		return value, true
+	}
+
+	var current interface{} = claims
+	for _, part := range strings.Split(name, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = obj[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// numericClaim reads a NumericDate claim
+func numericClaim(claims map[string]interface{}, name string) (time.Time, bool) {
+	value, ok := lookupClaim(claims, name)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
+
+// stringsClaim reads a claim that may be a single string or an array of strings
+func stringsClaim(claims map[string]interface{}, name string) []string {
+	value, ok := lookupClaim(claims, name)
+	if !ok {
+		return nil
+	}
+
+	switch val := value.(type) {
+	case string:
+		if val == "" {
+			return nil
+		}
+		return []string{val}
+	case float64:
+		return []string{fmt.Sprintf("%.0f", val)}
+	case []interface{}:
+		result := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func firstString(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
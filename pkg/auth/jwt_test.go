@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/pkg/config"
+)
+
+const testSecret = "test-secret-that-is-at-least-32-bytes"
+
+func testConfig() *config.JWTConfig {
+	return &config.JWTConfig{
+		Secret:        testSecret,
+		Issuer:        "https://auth.example.com",
+		Audience:      "cms-admin",
+		ClockSkew:     time.Minute,
+		UserIDClaim:   "sub",
+		UserNameClaim: "name",
+		RoleClaim:     "realm_access.roles",
+		TenantIDClaim: "tenantId",
+		GroupIDsClaim: "groupIds",
+	}
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	t.Helper()
+	input := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func validClaims() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"sub":          "user-1",
+		"name":         "Alice",
+		"iss":          "https://auth.example.com",
+		"aud":          []string{"cms-admin", "other"},
+		"exp":          now.Add(time.Hour).Unix(),
+		"iat":          now.Unix(),
+		"tenantId":     "tenant-1",
+		"groupIds":     []string{"g1", "g2"},
+		"realm_access": map[string]interface{}{"roles": []string{"editor"}},
+	}
+}
+
+func TestValidator_HS256(t *testing.T) {
+	v, err := NewValidator(testConfig())
+	if err != nil {
+		t.Fatalf("NewValidator: %v", err)
+	}
+
+	p, err := v.Validate(context.Background(), signHS256(t, testSecret, validClaims()))
+	if err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+
+	if p.UserID != "user-1" || p.UserName != "Alice" || p.Role != "editor" || p.TenantID != "tenant-1" {
+		t.Errorf("unexpected principal: %+v", p)
+	}
+	if len(p.GroupIDs) != 2 || p.GroupIDs[1] != "g2" {
+		t.Errorf("unexpected group IDs: %v", p.GroupIDs)
+	}
+}
+
+func TestNewValidator_RejectsWeakSecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+	}{
+		{"empty", ""},
+		{"short", "change_me"},
+		{"one byte short", testSecret[:MinSecretLength-1]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Secret = tt.secret
+			if _, err := NewValidator(cfg); err == nil {
+				t.Errorf("expected secret %q to be rejected", tt.secret)
+			}
+		})
+	}
+}
+
+func TestValidator_RejectsInvalidTokens(t *testing.T) {
+	v, _ := NewValidator(testConfig())
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-2 * time.Minute).Unix()
+
+	withinSkew := validClaims()
+	withinSkew["exp"] = time.Now().Add(-30 * time.Second).Unix()
+
+	notYetValid := validClaims()
+	notYetValid["nbf"] = time.Now().Add(5 * time.Minute).Unix()
+
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "https://evil.example.com"
+
+	wrongAudience := validClaims()
+	wrongAudience["aud"] = "someone-else"
+
+	noSubject := validClaims()
+	delete(noSubject, "sub")
+
+	unsigned := encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + "."
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"expired", signHS256(t, testSecret, expired), ErrTokenExpired},
+		{"within clock skew", signHS256(t, testSecret, withinSkew), nil},
+		{"not yet valid", signHS256(t, testSecret, notYetValid), ErrTokenNotYetValid},
+		{"wrong issuer", signHS256(t, testSecret, wrongIssuer), ErrInvalidIssuer},
+		{"wrong audience", signHS256(t, testSecret, wrongAudience), ErrInvalidAudience},
+		{"no subject", signHS256(t, testSecret, noSubject), ErrMissingSubject},
+		{"wrong secret", signHS256(t, "other-secret", validClaims()), ErrInvalidSignature},
+		{"alg none", unsigned, ErrUnsupportedAlg},
+		{"malformed", "not-a-token", ErrMalformedToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Validate(context.Background(), tt.token)
+			if err != tt.wantErr {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func writeJWKS(t *testing.T, path string, keys ...map[string]string) {
+	t.Helper()
+	data, _ := json.Marshal(map[string]interface{}{"keys": keys})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("write JWKS: %v", err)
+	}
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func signRS256(t *testing.T, kid string, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	t.Helper()
+	input := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestValidator_RS256KeyRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writeJWKS(t, path, rsaJWK("old", &oldKey.PublicKey))
+
+	cfg := testConfig()
+	cfg.Secret = ""
+	cfg.JWKSFile = path
+	v, err := NewValidator(cfg)
+	if err != nil {
+		t.Fatalf("NewValidator: %v", err)
+	}
+
+	if _, err := v.Validate(context.Background(), signRS256(t, "old", oldKey, validClaims())); err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+
+	// HS256 must not be accepted when no secret is configured
+	if _, err := v.Validate(context.Background(), signHS256(t, "", validClaims())); err != ErrUnsupportedAlg {
+		t.Errorf("expected %v, got %v", ErrUnsupportedAlg, err)
+	}
+
+	// Rotate: publish the new key and let the unknown kid trigger a refetch
+	writeJWKS(t, path, rsaJWK("old", &oldKey.PublicKey), rsaJWK("new", &newKey.PublicKey))
+	v.keys.fetchedAt = time.Now().Add(-minRefreshInterval - time.Second)
+
+	if _, err := v.Validate(context.Background(), signRS256(t, "new", newKey, validClaims())); err != nil {
+		t.Fatalf("expected rotated key to be accepted, got %v", err)
+	}
+
+	if _, err := v.Validate(context.Background(), signRS256(t, "missing", newKey, validClaims())); err != ErrUnknownKey {
+		t.Errorf("expected %v, got %v", ErrUnknownKey, err)
+	}
+}
+
+func TestValidator_ES256(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	writeJWKS(t, path, map[string]string{
+		"kty": "EC",
+		"kid": "ec-1",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	})
+
+	cfg := testConfig()
+	cfg.JWKSFile = path
+	v, _ := NewValidator(cfg)
+
+	input := encodeSegment(t, map[string]string{"alg": "ES256", "kid": "ec-1"}) + "." + encodeSegment(t, validClaims())
+	digest := sha256.Sum256([]byte(input))
+	r, s, _ := ecdsa.Sign(rand.Reader, key, digest[:])
+	sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+
+	if _, err := v.Validate(context.Background(), input+"."+base64.RawURLEncoding.EncodeToString(sig)); err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Principal is the authenticated caller extracted from a validated token
+type Principal struct {
+	UserID   string
+	UserName string
+	Role     string
+	TenantID string
+	GroupIDs []string
+	Claims   map[string]interface{}
+}
+
+// principalKey is the context key for the authenticated principal
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored by the middleware
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header
+func BearerToken(r *http.Request) (string, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", ErrMissingToken
+	}
+
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || strings.TrimSpace(parts[1]) == "" {
+		return "", ErrMalformedToken
+	}
+
+	return strings.TrimSpace(parts[1]), nil
+}
+
+// Middleware rejects requests without a valid bearer token and stores the
+// principal in the request context
+func Middleware(v *Validator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := v.Authenticate(r)
+			if err != nil {
+				Unauthorized(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// Authenticate validates the bearer token of a request
+func (v *Validator) Authenticate(r *http.Request) (*Principal, error) {
+	token, err := BearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+	return v.Validate(r.Context(), token)
+}
+
+// Unauthorized writes a 401 response with a WWW-Authenticate challenge
+func Unauthorized(w http.ResponseWriter, err error) {
+	challenge := `Bearer`
+	if err != ErrMissingToken {
+		challenge = `Bearer error="invalid_token"`
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, err.Error(), http.StatusUnauthorized)
+}
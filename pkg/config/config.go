@@ -93,3 +93,40 @@ func NewRedisConfig() *RedisConfig {
 		DB:       GetEnvInt("REDIS_DB", 0),
 	}
 }
+
+// JWTConfig holds JWT/OIDC token validation configuration
+type JWTConfig struct {
+	Secret              string        // HS256 shared secret
+	JWKSURL             string        // Remote JWKS document (RS256/ES256)
+	JWKSFile            string        // Local JWKS document (RS256/ES256)
+	JWKSRefreshInterval time.Duration // How often cached signing keys are refreshed
+	Issuer              string        // Expected "iss" claim (empty = not checked)
+	Audience            string        // Expected "aud" claim (empty = not checked)
+	ClockSkew           time.Duration // Tolerance applied to exp/nbf/iat
+
+	// Claim names used to build the authenticated principal.
+	// Dotted paths (e.g. "realm_access.roles") address nested claims.
+	UserIDClaim   string
+	UserNameClaim string
+	RoleClaim     string
+	TenantIDClaim string
+	GroupIDsClaim string
+}
+
+// NewJWTConfig creates a new JWT configuration
+func NewJWTConfig() *JWTConfig {
+	return &JWTConfig{
+		Secret:              GetEnv("JWT_SECRET", ""),
+		JWKSURL:             GetEnv("JWT_JWKS_URL", ""),
+		JWKSFile:            GetEnv("JWT_JWKS_FILE", ""),
+		JWKSRefreshInterval: GetEnvDuration("JWT_JWKS_REFRESH_INTERVAL", 15*time.Minute),
+		Issuer:              GetEnv("JWT_ISSUER", ""),
+		Audience:            GetEnv("JWT_AUDIENCE", ""),
+		ClockSkew:           GetEnvDuration("JWT_CLOCK_SKEW", time.Minute),
+		UserIDClaim:         GetEnv("JWT_CLAIM_USER_ID", "sub"),
+		UserNameClaim:       GetEnv("JWT_CLAIM_USER_NAME", "name"),
+		RoleClaim:           GetEnv("JWT_CLAIM_ROLE", "role"),
+		TenantIDClaim:       GetEnv("JWT_CLAIM_TENANT_ID", "tenantId"),
+		GroupIDsClaim:       GetEnv("JWT_CLAIM_GROUP_IDS", "groupIds"),
+	}
+}
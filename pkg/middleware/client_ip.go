@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// clientIPKey is the context key for the client address
+type clientIPKey struct{}
+
+// TrustedProxies is a set of proxy addresses whose forwarding headers are
+// believed
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses a comma-separated list of addresses and CIDR
+// ranges, e.g. "10.0.0.0/8, 192.168.1.10"
+func ParseTrustedProxies(list string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// Contains reports whether an address belongs to a trusted proxy
+func (p TrustedProxies) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIPMiddleware stores the address of the client in the request context.
+// Forwarding headers are only read from trusted proxies: the client is the
+// rightmost X-Forwarded-For entry that is not a trusted proxy, so entries a
+// client adds itself are never used.
+func ClientIPMiddleware(trusted TrustedProxies) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := trusted.clientIP(r)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip)))
+		})
+	}
+}
+
+// clientIP determines the address of the client a request was made for
+func (p TrustedProxies) clientIP(r *http.Request) string {
+	remote := remoteAddr(r)
+	addr, err := netip.ParseAddr(remote)
+	if err != nil || !p.Contains(addr) {
+		return remote
+	}
+
+	// Walk the chain back from the proxy that connected to us
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = hop.Unmap().String()
+		if !p.Contains(hop) {
+			return client
+		}
+	}
+	if len(hops) == 0 {
+		if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+			return realIP.Unmap().String()
+		}
+	}
+	return client
+}
+
+// ClientIP returns the client address stored by ClientIPMiddleware, or the
+// address of the connection if the middleware did not run
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteAddr(r)
+}
+
+// remoteAddr returns the address of the connection without its port
+func remoteAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIPMiddleware(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.10")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		realIP    string
+		want      string
+	}{
+		{name: "Direct client", remote: "203.0.113.7:5000", want: "203.0.113.7"},
+		{name: "Direct client spoofing headers", remote: "203.0.113.7:5000", forwarded: []string{"1.1.1.1"}, realIP: "2.2.2.2", want: "203.0.113.7"},
+		{name: "Through a trusted proxy", remote: "10.0.0.5:5000", forwarded: []string{"198.51.100.4"}, want: "198.51.100.4"},
+		{name: "Client prepends a spoofed hop", remote: "10.0.0.5:5000", forwarded: []string{"1.1.1.1, 198.51.100.4"}, want: "198.51.100.4"},
+		{name: "Chain of trusted proxies", remote: "10.0.0.5:5000", forwarded: []string{"198.51.100.4, 192.168.1.10", "10.1.2.3"}, want: "198.51.100.4"},
+		{name: "Real IP from a trusted proxy", remote: "192.168.1.10:5000", realIP: "198.51.100.4", want: "198.51.100.4"},
+		{name: "Malformed hop", remote: "10.0.0.5:5000", forwarded: []string{"unknown, 10.0.0.6"}, want: "10.0.0.6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			var got string
+			ClientIPMiddleware(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = ClientIP(r)
+			})).ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies_Invalid(t *testing.T) {
+	for _, list := range []string{"10.0.0.0/33", "proxy.local"} {
+		if _, err := ParseTrustedProxies(list); err == nil {
+			t.Errorf("ParseTrustedProxies(%q) error = nil, want an error", list)
+		}
+	}
+}
@@ -3,16 +3,20 @@ package main
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/vhvplatform/go-cms-service/pkg/auth"
 	"github.com/vhvplatform/go-cms-service/pkg/config"
 	"github.com/vhvplatform/go-cms-service/pkg/database"
 	"github.com/vhvplatform/go-cms-service/pkg/httpserver"
 	"github.com/vhvplatform/go-cms-service/pkg/logger"
 	pkgMiddleware "github.com/vhvplatform/go-cms-service/pkg/middleware"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/cache"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/handler"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/middleware"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/migrations"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
@@ -27,6 +31,22 @@ func main() {
 	baseURL := config.GetEnv("BASE_URL", "http://localhost:"+cfg.ServerPort)
 	uploadDir := config.GetEnv("UPLOAD_DIR", "./uploads")
 	runMigrations := config.GetEnvBool("RUN_MIGRATIONS", true)
+	previewSecret := config.GetEnv("PREVIEW_TOKEN_SECRET", "")
+	previewBaseURL := config.GetEnv("PREVIEW_BASE_URL", baseURL+"/api/v1/public/preview/")
+	previewTTL := config.GetEnvDuration("PREVIEW_TOKEN_TTL", 24*time.Hour)
+	viewDedupWindow := config.GetEnvDuration("VIEW_DEDUP_WINDOW", 30*time.Minute)
+	viewDedupCapacity := config.GetEnvInt("VIEW_DEDUP_CAPACITY", 1000000)
+	botUserAgents := strings.Split(config.GetEnv("VIEW_BOT_USER_AGENTS", ""), ",")
+	trendingConfig := service.TrendingConfig{
+		Window:   config.GetEnvDuration("TRENDING_WINDOW", 7*24*time.Hour),
+		HalfLife: config.GetEnvDuration("TRENDING_HALF_LIFE", 24*time.Hour),
+		HotTopN:  config.GetEnvInt("TRENDING_HOT_TOP_N", 0),
+	}
+	trendingInterval := config.GetEnvDuration("TRENDING_INTERVAL", 15*time.Minute)
+	snapshotInterval := config.GetEnvDuration("STATISTICS_SNAPSHOT_INTERVAL", time.Hour)
+	commentsDatabase := config.GetEnv("COMMENTS_DATABASE", "")
+	publicCacheTTL := time.Duration(config.GetEnvInt("CACHE_TTL", 300)) * time.Second
+	trustedProxies := config.GetEnv("TRUSTED_PROXIES", "")
 
 	// Initialize logger
 	log := logger.New(cfg.ServiceName, cfg.LogLevel)
@@ -36,6 +56,22 @@ func main() {
 	log.Info("Database: %s", mongoCfg.Database)
 	log.Info("Server Port: %s", cfg.ServerPort)
 	log.Info("Upload Directory: %s", uploadDir)
+	// Preview tokens are signed with their own secret, so a leaked preview
+	// secret cannot be used to mint access tokens
+	switch {
+	case previewSecret == "":
+		log.Warn("PREVIEW_TOKEN_SECRET is not set; preview links are disabled")
+	case len(previewSecret) < auth.MinSecretLength:
+		log.Fatal("PREVIEW_TOKEN_SECRET must be at least %d bytes long", auth.MinSecretLength)
+	case previewSecret == config.GetEnv("JWT_SECRET", ""):
+		log.Fatal("PREVIEW_TOKEN_SECRET must differ from JWT_SECRET")
+	}
+
+	// Forwarding headers are only believed from these proxies
+	proxies, err := pkgMiddleware.ParseTrustedProxies(trustedProxies)
+	if err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Connect to MongoDB
 	ctx := context.Background()
@@ -62,13 +98,35 @@ func main() {
 	_ = repository.NewEventStreamRepository(db) // Initialize for migrations
 	permissionRepo := repository.NewPermissionRepository(db)
 	viewStatsRepo := repository.NewViewStatsRepository(db)
+	statisticsRepo := repository.NewStatisticsRepository(db)
+	snapshotRepo := repository.NewStatisticsSnapshotRepository(db)
+	editorialRepo := repository.NewEditorialRepository(db)
+	// Comments, likes and favourites may be kept by the stats service in a
+	// database of their own
+	commentDB := db
+	if commentsDatabase != "" {
+		commentDB = mongoClient.Client.Database(commentsDatabase)
+	}
+	trendingRepo := repository.NewTrendingRepository(db, commentDB)
 	actionLogRepo := repository.NewActionLogRepository(db)
 	versionRepo := repository.NewArticleVersionRepository(db)
+	editLockRepo := repository.NewEditLockRepository(db)
+	previewLinkRepo := repository.NewPreviewLinkRepository(db)
 	rejectionNoteRepo := repository.NewRejectionNoteRepository(db)
 	commentRepo := repository.NewCommentRepository(db)
+	tenantRepo := repository.NewTenantRepository(db)
+	typeConfigRepo := repository.NewTenantArticleTypeConfigRepository(db)
+	schemaRepo := repository.NewArticleTypeSchemaRepository(db)
+	workflowRepo := repository.NewWorkflowRepository(db)
+	pollRepo := repository.NewPollRepository(db)
+	keywordRepo := repository.NewSensitiveKeywordRepository(db)
+	aiConfigRepo := repository.NewAIConfigRepository(db)
+	aiLogRepo := repository.NewAIOperationLogRepository(db)
+	aiUsageRepo := repository.NewAIUsageCounterRepository(db)
 
 	// Initialize utilities
 	imageDownloader := util.NewImageDownloader(uploadDir, baseURL)
+	viewFilter := util.NewViewFilter(viewDedupWindow, viewDedupCapacity, botUserAgents)
 
 	// Initialize view queue
 	viewQueue := worker.NewViewQueue(articleRepo, viewStatsRepo, 10000, 100, 5*time.Second)
@@ -76,19 +134,50 @@ func main() {
 	defer viewQueue.Stop()
 
 	// Initialize services
-	articleService := service.NewArticleService(articleRepo, permissionRepo, viewStatsRepo, viewQueue, actionLogRepo, versionRepo, rejectionNoteRepo, imageDownloader)
+	workflowService := service.NewWorkflowService(workflowRepo, categoryRepo)
+	keywordService := service.NewSensitiveKeywordService(keywordRepo)
+	articleService := service.NewArticleService(articleRepo, categoryRepo, typeConfigRepo, schemaRepo, workflowService, keywordService, permissionRepo, viewStatsRepo, viewQueue, viewFilter, actionLogRepo, versionRepo, rejectionNoteRepo, editLockRepo, imageDownloader)
 	categoryService := service.NewCategoryService(categoryRepo)
-	commentService := service.NewCommentService(commentRepo)
+	commentService := service.NewCommentService(commentRepo, articleRepo, keywordService)
 	rssService := service.NewRSSService(articleRepo, baseURL)
+	typeConfigService := service.NewTenantArticleTypeConfigService(typeConfigRepo)
+	schemaService := service.NewArticleTypeSchemaService(schemaRepo)
+	pollService := service.NewPollService(pollRepo, articleRepo)
+	aiService := service.NewAIService(aiConfigRepo, aiLogRepo, aiUsageRepo)
+	trendingService := service.NewTrendingService(trendingRepo, trendingConfig)
+	publicArticleService := service.NewPublicArticleService(articleRepo, newCache(log), publicCacheTTL, viewQueue, viewFilter)
+	statisticsService := service.NewStatisticsService(statisticsRepo, snapshotRepo, editorialRepo, articleRepo, categoryRepo, tenantRepo)
+	previewService := service.NewPreviewService(articleRepo, versionRepo, previewLinkRepo, previewSecret, previewBaseURL, previewTTL)
 
 	// Initialize handlers
 	articleHandler := handler.NewArticleHandler(articleService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 	commentHandler := handler.NewCommentHandler(commentService)
 	rssHandler := handler.NewRSSHandler(rssService)
+	typeConfigHandler := handler.NewTenantArticleTypeConfigHandler(typeConfigService)
+	schemaHandler := handler.NewArticleTypeSchemaHandler(schemaService)
+	workflowHandler := handler.NewWorkflowHandler(workflowService)
+	pollHandler := handler.NewPollHandler(pollService)
+	keywordHandler := handler.NewSensitiveKeywordHandler(keywordService)
+	aiHandler := handler.NewAIHandler(aiService)
+	previewHandler := handler.NewPreviewHandler(previewService)
+	statisticsHandler := handler.NewStatisticsHandler(statisticsService)
+	publicArticleHandler := handler.NewPublicArticleHandler(publicArticleService)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware()
+	tokenValidator, err := auth.NewValidator(config.NewJWTConfig())
+	if err != nil {
+		log.Fatal("Failed to initialize token validator: %v", err)
+	}
+	authMiddleware := middleware.NewAuthMiddleware(tokenValidator)
+	tenantMiddleware := middleware.NewTenantMiddleware(tenantRepo)
+	permissionMiddleware := middleware.NewPermissionMiddleware(permissionRepo)
+	requireModerator := permissionMiddleware.RequireRole(model.RoleModerator)
+
+	// Tenant-scoped routes require a valid token and a resolved tenant
+	protected := func(h http.Handler) http.Handler {
+		return authMiddleware.Authenticate(tenantMiddleware.Resolve(h))
+	}
 
 	// Setup router
 	mux := http.NewServeMux()
@@ -100,7 +189,7 @@ func main() {
 	})
 
 	// Article routes
-	mux.Handle("/api/v1/articles", authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/api/v1/articles", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
 			articleHandler.CreateArticle(w, r)
@@ -111,79 +200,301 @@ func main() {
 		}
 	})))
 
-	mux.Handle("/api/v1/articles/", authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Route based on path and method
-		if r.URL.Path == "/api/v1/articles/reorder" && r.Method == http.MethodPost {
-			articleHandler.ReorderArticles(w, r)
-			return
+	// Category routes
+	mux.Handle("/api/v1/categories", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			categoryHandler.CreateCategory(w, r)
+		case http.MethodGet:
+			categoryHandler.GetCategoryTree(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
+	})))
 
-		// Extract article ID and handle article-specific routes
+	mux.Handle("/api/v1/categories/tree", protected(http.HandlerFunc(categoryHandler.GetCategoryTree)))
+
+	mux.Handle("/api/v1/categories/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			articleHandler.GetArticle(w, r)
+			categoryHandler.GetCategory(w, r)
 		case http.MethodPatch:
-			articleHandler.UpdateArticle(w, r)
+			categoryHandler.UpdateCategory(w, r)
 		case http.MethodDelete:
-			articleHandler.DeleteArticle(w, r)
-		case http.MethodPost:
-			// Check for /publish or /view endpoint
-			if containsSegment(r.URL.Path, "publish") {
-				articleHandler.PublishArticle(w, r)
-			} else if containsSegment(r.URL.Path, "view") {
-				articleHandler.ViewArticle(w, r)
-			} else {
-				http.Error(w, "Not found", http.StatusNotFound)
-			}
+			categoryHandler.DeleteCategory(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})))
 
-	// Category routes
-	mux.Handle("/api/v1/categories", authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	// Tenant article type configuration routes
+	mux.Handle("/api/v1/article-type-config", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
+		case http.MethodGet:
+			typeConfigHandler.GetConfig(w, r)
 		case http.MethodPost:
-			categoryHandler.CreateCategory(w, r)
+			requireModerator(http.HandlerFunc(typeConfigHandler.CreateConfig)).ServeHTTP(w, r)
+		case http.MethodPut:
+			requireModerator(http.HandlerFunc(typeConfigHandler.UpdateConfig)).ServeHTTP(w, r)
+		case http.MethodDelete:
+			requireModerator(http.HandlerFunc(typeConfigHandler.DeleteConfig)).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// Article type schema routes
+	mux.Handle("/api/v1/article-schemas", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		schemaHandler.ListSchemas(w, r)
+	})))
+
+	mux.Handle("/api/v1/article-schemas/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
 		case http.MethodGet:
-			categoryHandler.GetCategoryTree(w, r)
+			schemaHandler.GetSchema(w, r)
+		case http.MethodPut:
+			requireModerator(http.HandlerFunc(schemaHandler.SaveSchema)).ServeHTTP(w, r)
+		case http.MethodDelete:
+			requireModerator(http.HandlerFunc(schemaHandler.DeleteSchema)).ServeHTTP(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})))
 
-	mux.Handle("/api/v1/categories/tree", authMiddleware.Authenticate(http.HandlerFunc(categoryHandler.GetCategoryTree)))
+	// Workflow routes
+	mux.Handle("/api/v1/workflows", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			workflowHandler.ListWorkflows(w, r)
+		case http.MethodPost:
+			requireModerator(http.HandlerFunc(workflowHandler.CreateWorkflow)).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
 
-	mux.Handle("/api/v1/categories/", authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/api/v1/workflows/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			categoryHandler.GetCategory(w, r)
-		case http.MethodPatch:
-			categoryHandler.UpdateCategory(w, r)
+			workflowHandler.GetWorkflow(w, r)
+		case http.MethodPut:
+			requireModerator(http.HandlerFunc(workflowHandler.UpdateWorkflow)).ServeHTTP(w, r)
 		case http.MethodDelete:
-			categoryHandler.DeleteCategory(w, r)
+			requireModerator(http.HandlerFunc(workflowHandler.DeleteWorkflow)).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// Sensitive keyword routes
+	mux.Handle("/api/v1/sensitive-keywords", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			keywordHandler.ListKeywords(w, r)
+		case http.MethodPost:
+			requireModerator(http.HandlerFunc(keywordHandler.CreateKeyword)).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/api/v1/sensitive-keywords/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case containsSegment(r.URL.Path, "import") && r.Method == http.MethodPost:
+			requireModerator(http.HandlerFunc(keywordHandler.ImportKeywords)).ServeHTTP(w, r)
+		case containsSegment(r.URL.Path, "scan") && r.Method == http.MethodPost:
+			keywordHandler.ScanText(w, r)
+		case r.Method == http.MethodGet:
+			keywordHandler.GetKeyword(w, r)
+		case r.Method == http.MethodPut:
+			requireModerator(http.HandlerFunc(keywordHandler.UpdateKeyword)).ServeHTTP(w, r)
+		case r.Method == http.MethodDelete:
+			requireModerator(http.HandlerFunc(keywordHandler.DeleteKeyword)).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// AI editor tool routes
+	mux.Handle("/api/v1/ai/config", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			aiHandler.GetConfig(w, r)
+		case http.MethodPut:
+			requireModerator(http.HandlerFunc(aiHandler.UpdateConfig)).ServeHTTP(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})))
 
+	mux.Handle("/api/v1/ai/usage", protected(requireModerator(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		aiHandler.GetUsage(w, r)
+	}))))
+
+	mux.Handle("/api/v1/ai/spellcheck", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		aiHandler.CheckSpelling(w, r)
+	})))
+
+	mux.Handle("/api/v1/ai/translate", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		aiHandler.Translate(w, r)
+	})))
+
+	mux.Handle("/api/v1/ai/improve", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		aiHandler.ImproveContent(w, r)
+	})))
+
+	mux.Handle("/api/v1/ai/moderate", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		aiHandler.DetectViolation(w, r)
+	})))
+
+	// Public poll routes; signed-in voters are identified by their token,
+	// anonymous voters by IP address
+	mux.Handle("/api/v1/polls/", authMiddleware.Optional(tenantMiddleware.Resolve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case containsSegment(r.URL.Path, "vote") && r.Method == http.MethodPost:
+			pollHandler.VoteOnPoll(w, r)
+		case containsSegment(r.URL.Path, "results") && r.Method == http.MethodGet:
+			pollHandler.GetPollResults(w, r)
+		case r.Method == http.MethodGet:
+			pollHandler.GetPoll(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))))
+
 	// Search route
-	mux.Handle("/api/v1/search", authMiddleware.Authenticate(http.HandlerFunc(articleHandler.SearchArticles)))
+	mux.Handle("/api/v1/search", protected(http.HandlerFunc(articleHandler.SearchArticles)))
+
+	// Statistics routes; all take format=csv or format=xlsx to export
+	mux.Handle("/api/v1/statistics/overview", protected(http.HandlerFunc(statisticsHandler.GetOverview)))
+	mux.Handle("/api/v1/statistics/trend", protected(http.HandlerFunc(statisticsHandler.GetViewTrend)))
+	mux.Handle("/api/v1/statistics/articles/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if containsSegment(r.URL.Path, "engagement") {
+			statisticsHandler.GetArticleEngagement(w, r)
+			return
+		}
+		statisticsHandler.GetArticleViews(w, r)
+	})))
+	mux.Handle("/api/v1/statistics/categories/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if containsSegment(r.URL.Path, "engagement") {
+			statisticsHandler.GetCategoryEngagement(w, r)
+			return
+		}
+		statisticsHandler.GetCategoryStatistics(w, r)
+	})))
+	mux.Handle("/api/v1/statistics/authors/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if containsSegment(r.URL.Path, "engagement") {
+			statisticsHandler.GetAuthorEngagement(w, r)
+			return
+		}
+		statisticsHandler.GetAuthorStatistics(w, r)
+	})))
+	mux.Handle("/api/v1/statistics/editorial", protected(http.HandlerFunc(statisticsHandler.GetEditorialMetrics)))
+	mux.Handle("/api/v1/statistics/editorial/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case containsSegment(r.URL.Path, "articles"):
+			statisticsHandler.GetArticleEditorialMetrics(w, r)
+		case containsSegment(r.URL.Path, "queue"):
+			statisticsHandler.GetReviewQueue(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})))
+	mux.Handle("/api/v1/statistics/snapshots", protected(http.HandlerFunc(statisticsHandler.ListSnapshots)))
+	mux.Handle("/api/v1/statistics/snapshots/", protected(http.HandlerFunc(statisticsHandler.GetSnapshot)))
+
+	// Engagement beacon route (public, tenant resolved from header or host);
+	// browsers send one beacon per article read
+	mux.Handle("/api/v1/engagement", tenantMiddleware.Resolve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		articleHandler.RecordEngagement(w, r)
+	})))
+
+	// Public article routes (tenant resolved from header or host); the
+	// frontend records a view for every article it serves
+	mux.Handle("/api/v1/public/articles", tenantMiddleware.Resolve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		publicArticleHandler.ListArticles(w, r)
+	})))
+	mux.Handle("/api/v1/public/articles/", tenantMiddleware.Resolve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if containsSegment(r.URL.Path, "view") {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			publicArticleHandler.ViewArticle(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		publicArticleHandler.GetArticle(w, r)
+	})))
+
+	// Trending articles per category (public, tenant resolved from header or host)
+	mux.Handle("/api/v1/public/categories/", tenantMiddleware.Resolve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !containsSegment(r.URL.Path, "trending") {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		publicArticleHandler.GetCategoryTrending(w, r)
+	})))
 
-	// Statistics route
-	mux.Handle("/api/v1/statistics/articles/", authMiddleware.Authenticate(http.HandlerFunc(articleHandler.GetArticleStats)))
+	// RSS route (public, tenant resolved from header or host)
+	mux.Handle("/api/v1/rss", tenantMiddleware.Resolve(http.HandlerFunc(rssHandler.GetRSSFeed)))
 
-	// RSS route (public)
-	mux.HandleFunc("/api/v1/rss", rssHandler.GetRSSFeed)
+	// Article preview route (public, the signed token identifies the tenant)
+	mux.HandleFunc("/api/v1/public/preview/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		previewHandler.GetPreview(w, r)
+	})
 
 	// Comment routes
-	mux.Handle("/api/v1/comments/pending", authMiddleware.Authenticate(http.HandlerFunc(commentHandler.GetPendingComments)))
+	mux.Handle("/api/v1/comments/pending", protected(http.HandlerFunc(commentHandler.GetPendingComments)))
 
 	// User favorites route
-	mux.Handle("/api/v1/users/favorites", authMiddleware.Authenticate(http.HandlerFunc(commentHandler.GetUserFavorites)))
+	mux.Handle("/api/v1/users/favorites", protected(http.HandlerFunc(commentHandler.GetUserFavorites)))
 
 	// Comment-specific routes with path-based routing
-	mux.Handle("/api/v1/comments/", authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/api/v1/comments/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if containsSegment(r.URL.Path, "replies") && r.Method == http.MethodGet {
 			commentHandler.GetCommentReplies(w, r)
 		} else if containsSegment(r.URL.Path, "moderate") && r.Method == http.MethodPost {
@@ -202,7 +513,7 @@ func main() {
 	})))
 
 	// Article-specific comment and favorite routes
-	mux.Handle("/api/v1/articles/", authMiddleware.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/api/v1/articles/", protected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Route based on path and method
 		if r.URL.Path == "/api/v1/articles/reorder" && r.Method == http.MethodPost {
 			articleHandler.ReorderArticles(w, r)
@@ -210,6 +521,10 @@ func main() {
 		}
 
 		// Handle /comments endpoint
+		if strings.HasSuffix(r.URL.Path, "/comments/screen") && r.Method == http.MethodPost {
+			commentHandler.ScreenComment(w, r)
+			return
+		}
 		if containsSegment(r.URL.Path, "comments") {
 			if r.Method == http.MethodPost {
 				commentHandler.CreateComment(w, r)
@@ -229,10 +544,91 @@ func main() {
 			return
 		}
 
+		// Handle /poll endpoint
+		if containsSegment(r.URL.Path, "poll") {
+			switch r.Method {
+			case http.MethodPost:
+				pollHandler.CreateArticlePoll(w, r)
+			case http.MethodGet:
+				pollHandler.GetArticlePoll(w, r)
+			case http.MethodPut:
+				pollHandler.UpdateArticlePoll(w, r)
+			case http.MethodPatch:
+				pollHandler.SetArticlePollStatus(w, r)
+			case http.MethodDelete:
+				pollHandler.DeleteArticlePoll(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+			return
+		}
+
+		// Handle /hot endpoint
+		if containsSegment(r.URL.Path, "hot") {
+			if r.Method != http.MethodPut {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			articleHandler.SetHot(w, r)
+			return
+		}
+
+		// Handle /preview-links endpoints
+		if containsSegment(r.URL.Path, "preview-links") {
+			switch r.Method {
+			case http.MethodGet:
+				previewHandler.ListLinks(w, r)
+			case http.MethodPost:
+				previewHandler.CreateLink(w, r)
+			case http.MethodDelete:
+				previewHandler.RevokeLinks(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+			return
+		}
+
+		// Handle /lock endpoints
+		if containsSegment(r.URL.Path, "take") && r.Method == http.MethodPost {
+			articleHandler.TakeEditLock(w, r)
+			return
+		}
+		if containsSegment(r.URL.Path, "lock") {
+			switch r.Method {
+			case http.MethodGet:
+				articleHandler.GetEditLock(w, r)
+			case http.MethodPost:
+				articleHandler.AcquireEditLock(w, r)
+			case http.MethodPut:
+				articleHandler.RenewEditLock(w, r)
+			case http.MethodDelete:
+				articleHandler.ReleaseEditLock(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+			return
+		}
+
+		// Handle /transitions endpoint
+		if containsSegment(r.URL.Path, "transitions") {
+			if r.Method == http.MethodPost {
+				articleHandler.TransitionArticle(w, r)
+			} else if r.Method == http.MethodGet {
+				articleHandler.GetAvailableTransitions(w, r)
+			}
+			return
+		}
+
 		// Extract article ID and handle article-specific routes
 		switch r.Method {
 		case http.MethodGet:
-			articleHandler.GetArticle(w, r)
+			if containsSegment(r.URL.Path, "diff") {
+				articleHandler.DiffArticleVersions(w, r)
+			} else if containsSegment(r.URL.Path, "versions") {
+				articleHandler.GetArticleVersions(w, r)
+			} else {
+				articleHandler.GetArticle(w, r)
+			}
 		case http.MethodPatch:
 			articleHandler.UpdateArticle(w, r)
 		case http.MethodDelete:
@@ -251,12 +647,12 @@ func main() {
 				} else {
 					articleHandler.AddRejectionNote(w, r)
 				}
+			} else if containsSegment(r.URL.Path, "restore") {
+				articleHandler.RestoreArticleVersion(w, r)
+			} else if containsSegment(r.URL.Path, "merge") {
+				articleHandler.MergeArticleVersion(w, r)
 			} else if containsSegment(r.URL.Path, "versions") {
-				if containsSegment(r.URL.Path, "restore") {
-					articleHandler.RestoreArticleVersion(w, r)
-				} else {
-					articleHandler.GetArticleVersions(w, r)
-				}
+				articleHandler.GetArticleVersions(w, r)
 			} else if containsSegment(r.URL.Path, "logs") {
 				articleHandler.GetActionLogs(w, r)
 			} else if containsSegment(r.URL.Path, "share") {
@@ -274,18 +670,31 @@ func main() {
 	go scheduler.Start(ctx)
 	defer scheduler.Stop()
 
+	// Start trending score worker
+	trendingWorker := worker.NewTrendingWorker(trendingService, trendingInterval)
+	go trendingWorker.Start(ctx)
+	defer trendingWorker.Stop()
+
+	// Start statistics snapshot worker
+	snapshotWorker := worker.NewSnapshotWorker(statisticsService, snapshotInterval)
+	go snapshotWorker.Start(ctx)
+	defer snapshotWorker.Stop()
+
 	// Wrap mux with common middleware
 	handler := pkgMiddleware.Chain(
 		pkgMiddleware.LoggingMiddleware(log),
 		pkgMiddleware.RecoveryMiddleware(log),
+		pkgMiddleware.ClientIPMiddleware(proxies),
 	)(mux)
 
 	// Start HTTP server with graceful shutdown
 	serverCfg := httpserver.DefaultConfig(cfg.ServerPort, handler)
 	server := httpserver.NewServer(serverCfg, log)
 
+	// Return rather than exit on a forced shutdown, so the deferred view
+	// queue drain still runs
 	if err := server.Start(); err != nil {
-		log.Fatal("Server error: %v", err)
+		log.Error("Server error: %v", err)
 	}
 }
 
@@ -293,3 +702,19 @@ func containsSegment(path, segment string) bool {
 	return len(path) > 0 && (path[len(path)-len(segment):] == segment ||
 		len(path) > len(segment) && path[len(path)-len(segment)-1:] == "/"+segment)
 }
+
+// newCache connects to Redis when REDIS_ADDR is set and falls back to an
+// in-memory cache otherwise
+func newCache(log *logger.Logger) cache.Cache {
+	addr := config.GetEnv("REDIS_ADDR", "")
+	if addr == "" {
+		return cache.NewMemoryCache()
+	}
+
+	redisCache, err := cache.NewRedisCache(addr, config.GetEnv("REDIS_PASSWORD", ""), config.GetEnvInt("REDIS_DB", 0))
+	if err != nil {
+		log.Warn("Redis unavailable, using in-memory cache: %v", err)
+		return cache.NewMemoryCache()
+	}
+	return redisCache
+}
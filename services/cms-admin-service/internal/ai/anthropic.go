@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	anthropicEndpoint = "https://api.anthropic.com/v1/messages"
+	anthropicModel    = "claude-3-5-haiku-latest"
+	anthropicVersion  = "2023-06-01"
+)
+
+// NewAnthropic creates a client for the Anthropic Messages API
+func NewAnthropic(cfg Config) Provider {
+	p := &chatProvider{name: "anthropic"}
+	p.complete = func(ctx context.Context, system, user string) (string, Usage, error) {
+		type message struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		}
+		maxTokens := cfg.MaxTokens
+		if maxTokens <= 0 {
+			// The Messages API requires a limit
+			maxTokens = defaultMaxTokens
+		}
+		body := struct {
+			Model       string    `json:"model"`
+			System      string    `json:"system"`
+			Messages    []message `json:"messages"`
+			MaxTokens   int       `json:"max_tokens"`
+			Temperature float64   `json:"temperature,omitempty"`
+		}{
+			Model:       orDefault(cfg.Model, anthropicModel),
+			System:      system,
+			Messages:    []message{{Role: "user", Content: user}},
+			MaxTokens:   maxTokens,
+			Temperature: cfg.Temperature,
+		}
+
+		header := http.Header{}
+		header.Set("x-api-key", cfg.APIKey)
+		header.Set("anthropic-version", anthropicVersion)
+
+		var resp struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+			Usage struct {
+				InputTokens  int `json:"input_tokens"`
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+		}
+		if err := postJSON(ctx, cfg, p.name, orDefault(cfg.Endpoint, anthropicEndpoint), header, body, &resp); err != nil {
+			return "", Usage{}, err
+		}
+
+		usage := Usage{Model: body.Model, InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens}
+		var text strings.Builder
+		for _, block := range resp.Content {
+			if block.Type == "text" {
+				text.WriteString(block.Text)
+			}
+		}
+		if text.Len() == 0 {
+			return "", usage, fmt.Errorf("anthropic returned no text")
+		}
+		return text.String(), usage, nil
+	}
+	return p
+}
@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"context"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+)
+
+const languageToolEndpoint = "https://api.languagetool.org/v2/check"
+
+// maxSuggestions limits the replacements kept per LanguageTool match
+const maxSuggestions = 5
+
+// languageTool is a client for the LanguageTool check API
+type languageTool struct {
+	cfg Config
+}
+
+// NewLanguageTool creates a LanguageTool client. Endpoint may point at a
+// self-hosted server; APIKey is sent when set.
+func NewLanguageTool(cfg Config) Provider {
+	return &languageTool{cfg: cfg}
+}
+
+func (p *languageTool) Name() string {
+	return "languagetool"
+}
+
+// CheckSpelling checks text with LanguageTool
+func (p *languageTool) CheckSpelling(ctx context.Context, text, language string) (*model.SpellCheckResult, Usage, error) {
+	form := url.Values{}
+	form.Set("text", text)
+	form.Set("language", orDefault(language, "auto"))
+	if p.cfg.APIKey != "" {
+		form.Set("apiKey", p.cfg.APIKey)
+	}
+
+	var resp struct {
+		Matches []struct {
+			Message      string `json:"message"`
+			Offset       int    `json:"offset"`
+			Length       int    `json:"length"`
+			Replacements []struct {
+				Value string `json:"value"`
+			} `json:"replacements"`
+			Rule struct {
+				IssueType string `json:"issueType"`
+			} `json:"rule"`
+		} `json:"matches"`
+	}
+	if err := postForm(ctx, p.cfg, p.Name(), orDefault(p.cfg.Endpoint, languageToolEndpoint), form, &resp); err != nil {
+		return nil, Usage{}, err
+	}
+
+	// LanguageTool counts offsets in UTF-16 code units
+	runes := []rune(text)
+	runeIndex := utf16RuneIndex(runes)
+
+	result := &model.SpellCheckResult{Original: text, Corrections: []model.SpellCorrection{}}
+	for _, match := range resp.Matches {
+		if match.Offset < 0 || match.Length < 0 || match.Offset+match.Length >= len(runeIndex) {
+			continue
+		}
+		start := runeIndex[match.Offset]
+		end := runeIndex[match.Offset+match.Length]
+
+		suggestions := []string{}
+		for i, r := range match.Replacements {
+			if i == maxSuggestions {
+				break
+			}
+			suggestions = append(suggestions, r.Value)
+		}
+
+		correctionType := "grammar"
+		if match.Rule.IssueType == "misspelling" {
+			correctionType = "spelling"
+		}
+
+		result.Corrections = append(result.Corrections, model.SpellCorrection{
+			Word:        string(runes[start:end]),
+			Suggestions: suggestions,
+			Position:    start,
+			Length:      end - start,
+			Type:        correctionType,
+			Message:     match.Message,
+		})
+	}
+	result.HasErrors = len(result.Corrections) > 0
+
+	return result, Usage{Characters: utf8.RuneCountInString(text)}, nil
+}
+
+// utf16RuneIndex maps each UTF-16 offset in runes, including the end, to a
+// rune offset
+func utf16RuneIndex(runes []rune) []int {
+	index := make([]int, 0, len(runes)+1)
+	for i, r := range runes {
+		index = append(index, i)
+		if r >= 0x10000 {
+			// Encoded as a surrogate pair
+			index = append(index, i)
+		}
+	}
+	return append(index, len(runes))
+}
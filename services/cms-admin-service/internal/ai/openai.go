@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const (
+	openAIEndpoint = "https://api.openai.com/v1/chat/completions"
+	openAIModel    = "gpt-4o-mini"
+)
+
+// NewOpenAI creates a client for the OpenAI chat completions API. Endpoint
+// may point at any OpenAI-compatible server, such as Azure OpenAI, vLLM or
+// Ollama.
+func NewOpenAI(cfg Config) Provider {
+	p := &chatProvider{name: "openai"}
+	p.complete = func(ctx context.Context, system, user string) (string, Usage, error) {
+		type message struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		}
+		body := struct {
+			Model       string    `json:"model"`
+			Messages    []message `json:"messages"`
+			MaxTokens   int       `json:"max_tokens,omitempty"`
+			Temperature float64   `json:"temperature,omitempty"`
+		}{
+			Model: orDefault(cfg.Model, openAIModel),
+			Messages: []message{
+				{Role: "system", Content: system},
+				{Role: "user", Content: user},
+			},
+			MaxTokens:   cfg.MaxTokens,
+			Temperature: cfg.Temperature,
+		}
+
+		header := http.Header{}
+		if cfg.APIKey != "" {
+			header.Set("Authorization", "Bearer "+cfg.APIKey)
+		}
+
+		var resp struct {
+			Choices []struct {
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+			} `json:"choices"`
+			Usage struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+			} `json:"usage"`
+		}
+		if err := postJSON(ctx, cfg, p.name, orDefault(cfg.Endpoint, openAIEndpoint), header, body, &resp); err != nil {
+			return "", Usage{}, err
+		}
+
+		usage := Usage{Model: body.Model, InputTokens: resp.Usage.PromptTokens, OutputTokens: resp.Usage.CompletionTokens}
+		if len(resp.Choices) == 0 {
+			return "", usage, fmt.Errorf("openai returned no choices")
+		}
+		return resp.Choices[0].Message.Content, usage, nil
+	}
+	return p
+}
@@ -0,0 +1,218 @@
+// Package ai contains clients for the AI and language services used by the
+// editor tools: spell checking, translation, content improvement and
+// moderation. Each client implements the operations its service supports.
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+)
+
+// maxResponseSize limits how much of a provider response is read
+const maxResponseSize = 4 << 20
+
+// Provider is an AI or language service client. A provider also implements
+// one or more of SpellChecker, Translator, ContentImprover and Moderator.
+type Provider interface {
+	Name() string
+}
+
+// SpellChecker finds spelling and grammar errors
+type SpellChecker interface {
+	// CheckSpelling checks text in the given language ("" to detect it)
+	CheckSpelling(ctx context.Context, text, language string) (*model.SpellCheckResult, Usage, error)
+}
+
+// Translator translates text
+type Translator interface {
+	// Translate translates text into targetLang; sourceLang may be "" to detect it
+	Translate(ctx context.Context, text, sourceLang, targetLang string) (*model.TranslationResult, Usage, error)
+}
+
+// ContentImprover suggests a better version of a text
+type ContentImprover interface {
+	ImproveContent(ctx context.Context, content string) (*model.ContentEditSuggestion, Usage, error)
+}
+
+// Moderator detects policy violations in a text
+type Moderator interface {
+	DetectViolation(ctx context.Context, content string) (*model.ViolationDetectionResult, Usage, error)
+}
+
+// Usage is what a call consumed, as reported by the provider
+type Usage struct {
+	Model        string // Model used, for providers that have models
+	InputTokens  int
+	OutputTokens int
+	Characters   int // For services billed per character, such as DeepL and Google
+}
+
+// Tokens returns the total tokens used
+func (u Usage) Tokens() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// Config holds the API settings of a provider. Endpoint is the full URL of
+// the provider's API method; empty values fall back to the provider defaults.
+type Config struct {
+	APIKey      string
+	Endpoint    string
+	Model       string
+	MaxTokens   int
+	Temperature float64
+	HTTPClient  *http.Client
+}
+
+// Factory creates a provider from its configuration
+type Factory func(cfg Config) Provider
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		"openai":       NewOpenAI,
+		"anthropic":    NewAnthropic,
+		"deepl":        NewDeepL,
+		"google":       NewGoogleTranslate,
+		"languagetool": NewLanguageTool,
+	}
+)
+
+// Register adds a provider, or replaces the one with the same name
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// New creates the named provider
+func New(name string, cfg Config) (Provider, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown AI provider: %q", name)
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	return factory(cfg), nil
+}
+
+// Names returns the registered provider names
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// APIError is an error response from a provider
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s returned %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// postJSON sends body as JSON and decodes the JSON response into out
+func postJSON(ctx context.Context, cfg Config, provider, endpoint string, header http.Header, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return do(cfg, provider, req, out)
+}
+
+// postForm sends form values and decodes the JSON response into out
+func postForm(ctx context.Context, cfg Config, provider, endpoint string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return do(cfg, provider, req, out)
+}
+
+func do(cfg Config, provider string, req *http.Request, out interface{}) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := cfg.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("%s response: %w", provider, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{Provider: provider, StatusCode: resp.StatusCode, Message: errorMessage(body)}
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%s response: %w", provider, err)
+	}
+	return nil
+}
+
+// errorMessage extracts the message from the error bodies the providers use
+func errorMessage(body []byte) string {
+	var parsed struct {
+		Message string          `json:"message"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) == nil {
+		var nested struct {
+			Message string `json:"message"`
+		}
+		var plain string
+		switch {
+		case json.Unmarshal(parsed.Error, &nested) == nil && nested.Message != "":
+			return nested.Message
+		case json.Unmarshal(parsed.Error, &plain) == nil && plain != "":
+			return plain
+		case parsed.Message != "":
+			return parsed.Message
+		}
+	}
+
+	message := strings.TrimSpace(string(body))
+	if len(message) > 200 {
+		message = message[:200]
+	}
+	return message
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,135 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+)
+
+const (
+	deepLEndpoint  = "https://api.deepl.com/v2/translate"
+	googleEndpoint = "https://translation.googleapis.com/language/translate/v2"
+)
+
+// deepL is a client for the DeepL translate API
+type deepL struct {
+	cfg Config
+}
+
+// NewDeepL creates a DeepL translation client. Free API keys need the
+// api-free.deepl.com endpoint.
+func NewDeepL(cfg Config) Provider {
+	return &deepL{cfg: cfg}
+}
+
+func (p *deepL) Name() string {
+	return "deepl"
+}
+
+// Translate translates text with DeepL, which uses upper-case language codes
+func (p *deepL) Translate(ctx context.Context, text, sourceLang, targetLang string) (*model.TranslationResult, Usage, error) {
+	body := struct {
+		Text       []string `json:"text"`
+		TargetLang string   `json:"target_lang"`
+		SourceLang string   `json:"source_lang,omitempty"`
+	}{
+		Text:       []string{text},
+		TargetLang: strings.ToUpper(targetLang),
+		SourceLang: strings.ToUpper(sourceLang),
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "DeepL-Auth-Key "+p.cfg.APIKey)
+
+	var resp struct {
+		Translations []struct {
+			DetectedSourceLanguage string `json:"detected_source_language"`
+			Text                   string `json:"text"`
+		} `json:"translations"`
+	}
+	if err := postJSON(ctx, p.cfg, p.Name(), orDefault(p.cfg.Endpoint, deepLEndpoint), header, body, &resp); err != nil {
+		return nil, Usage{}, err
+	}
+
+	usage := Usage{Characters: utf8.RuneCountInString(text)}
+	if len(resp.Translations) == 0 {
+		return nil, usage, fmt.Errorf("deepl returned no translation")
+	}
+
+	return &model.TranslationResult{
+		SourceText:     text,
+		TranslatedText: resp.Translations[0].Text,
+		SourceLang:     strings.ToLower(orDefault(sourceLang, resp.Translations[0].DetectedSourceLanguage)),
+		TargetLang:     targetLang,
+		Provider:       p.Name(),
+	}, usage, nil
+}
+
+// googleTranslate is a client for the Google Cloud Translation API (v2)
+type googleTranslate struct {
+	cfg Config
+}
+
+// NewGoogleTranslate creates a Google Cloud Translation client
+func NewGoogleTranslate(cfg Config) Provider {
+	return &googleTranslate{cfg: cfg}
+}
+
+func (p *googleTranslate) Name() string {
+	return "google"
+}
+
+// Translate translates text with Google Cloud Translation
+func (p *googleTranslate) Translate(ctx context.Context, text, sourceLang, targetLang string) (*model.TranslationResult, Usage, error) {
+	body := struct {
+		Q      []string `json:"q"`
+		Target string   `json:"target"`
+		Source string   `json:"source,omitempty"`
+		Format string   `json:"format"`
+	}{
+		Q:      []string{text},
+		Target: targetLang,
+		Source: sourceLang,
+		Format: "text",
+	}
+
+	endpoint, err := url.Parse(orDefault(p.cfg.Endpoint, googleEndpoint))
+	if err != nil {
+		return nil, Usage{}, fmt.Errorf("google endpoint: %w", err)
+	}
+	if p.cfg.APIKey != "" {
+		query := endpoint.Query()
+		query.Set("key", p.cfg.APIKey)
+		endpoint.RawQuery = query.Encode()
+	}
+
+	var resp struct {
+		Data struct {
+			Translations []struct {
+				TranslatedText         string `json:"translatedText"`
+				DetectedSourceLanguage string `json:"detectedSourceLanguage"`
+			} `json:"translations"`
+		} `json:"data"`
+	}
+	if err := postJSON(ctx, p.cfg, p.Name(), endpoint.String(), nil, body, &resp); err != nil {
+		return nil, Usage{}, err
+	}
+
+	usage := Usage{Characters: utf8.RuneCountInString(text)}
+	if len(resp.Data.Translations) == 0 {
+		return nil, usage, fmt.Errorf("google returned no translation")
+	}
+
+	return &model.TranslationResult{
+		SourceText:     text,
+		TranslatedText: resp.Data.Translations[0].TranslatedText,
+		SourceLang:     orDefault(sourceLang, resp.Data.Translations[0].DetectedSourceLanguage),
+		TargetLang:     targetLang,
+		Provider:       p.Name(),
+	}, usage, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -104,6 +105,7 @@ func (c *RedisCache) Close() error {
 
 // MemoryCache implements Cache using in-memory map (for testing/fallback)
 type MemoryCache struct {
+	mu   sync.Mutex
 	data map[string]cacheEntry
 }
 
@@ -121,6 +123,8 @@ func NewMemoryCache() *MemoryCache {
 
 // Get retrieves a value from cache
 func (c *MemoryCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	entry, exists := c.data[key]
 	if !exists || time.Now().After(entry.expiresAt) {
 		delete(c.data, key)
@@ -137,6 +141,8 @@ func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, tt
 		return err
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.data[key] = cacheEntry{
 		value:     data,
 		expiresAt: time.Now().Add(ttl),
@@ -147,6 +153,8 @@ func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, tt
 
 // Delete removes keys from cache
 func (c *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, key := range keys {
 		delete(c.data, key)
 	}
@@ -161,6 +169,8 @@ func (c *MemoryCache) DeletePattern(ctx context.Context, pattern string) error {
 		prefix = pattern[:len(pattern)-1]
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	keysToDelete := []string{}
 	for key := range c.data {
 		if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+)
+
+// AIHandler handles HTTP requests for the AI editor tools
+type AIHandler struct {
+	service *service.AIService
+}
+
+// NewAIHandler creates a new AI handler
+func NewAIHandler(service *service.AIService) *AIHandler {
+	return &AIHandler{
+		service: service,
+	}
+}
+
+// GetConfig handles GET /api/v1/ai/config
+// API keys are write-only and never returned.
+func (h *AIHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	config, err := h.service.GetConfig(r.Context(), getTenantID(r).Hex())
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, redactAIConfig(config))
+}
+
+// UpdateConfig handles PUT /api/v1/ai/config
+func (h *AIHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
+	var config model.AIConfiguration
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	config.TenantID = getTenantID(r).Hex()
+
+	if err := h.service.UpdateConfig(r.Context(), &config); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, redactAIConfig(&config))
+}
+
+// CheckSpelling handles POST /api/v1/ai/spellcheck
+func (h *AIHandler) CheckSpelling(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Text     string `json:"text"`
+		Language string `json:"language"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.CheckSpelling(r.Context(), getTenantID(r).Hex(), getUserID(r), req.Text, req.Language)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// Translate handles POST /api/v1/ai/translate
+func (h *AIHandler) Translate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Text       string `json:"text"`
+		SourceLang string `json:"sourceLang"`
+		TargetLang string `json:"targetLang"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.Translate(r.Context(), getTenantID(r).Hex(), getUserID(r), req.Text, req.SourceLang, req.TargetLang)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// ImproveContent handles POST /api/v1/ai/improve
+func (h *AIHandler) ImproveContent(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Content   string `json:"content"`
+		ArticleID string `json:"articleId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.ImproveContent(r.Context(), getTenantID(r).Hex(), getUserID(r), req.ArticleID, req.Content)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// DetectViolation handles POST /api/v1/ai/moderate
+func (h *AIHandler) DetectViolation(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.DetectViolation(r.Context(), getTenantID(r).Hex(), getUserID(r), req.Content)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// GetUsage handles GET /api/v1/ai/usage
+// Query parameters from and to (RFC 3339 or YYYY-MM-DD) default to the current
+// month; userId limits the report to one user.
+func (h *AIHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := now
+
+	query := r.URL.Query()
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseReportTime(value)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid "+param.name+" date")
+			return
+		}
+		*param.target = parsed
+	}
+
+	report, err := h.service.GetUsage(r.Context(), getTenantID(r).Hex(), query.Get("userId"), from, to)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// parseReportTime parses an RFC 3339 time or a date
+func parseReportTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// redactAIConfig copies a configuration without its API keys
+func redactAIConfig(config *model.AIConfiguration) *model.AIConfiguration {
+	redacted := *config
+	redacted.APIKey = ""
+	if config.Providers != nil {
+		redacted.Providers = make(map[string]model.AIProviderSettings, len(config.Providers))
+		for name, settings := range config.Providers {
+			settings.APIKey = ""
+			redacted.Providers[name] = settings
+		}
+	}
+	return &redacted
+}
@@ -2,9 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
@@ -13,6 +13,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// maxBeaconSize limits the body of an engagement beacon
+const maxBeaconSize = 4 << 10
+
 // ArticleHandler handles HTTP requests for articles
 type ArticleHandler struct {
 	service *service.ArticleService
@@ -43,8 +46,8 @@ func (h *ArticleHandler) CreateArticle(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context (set by auth middleware)
 	userID := getUserID(r)
 
-	if err := h.service.Create(r.Context(), &article, userID); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.Create(r.Context(), getTenantID(r), &article, userID); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -59,12 +62,13 @@ func (h *ArticleHandler) GetArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	article, err := h.service.FindByID(r.Context(), id)
+	article, err := h.service.FindByID(r.Context(), getTenantID(r), id)
 	if err != nil {
-		respondError(w, http.StatusNotFound, err.Error())
+		respondServiceError(w, http.StatusNotFound, err)
 		return
 	}
 
+	setArticleETag(w, article.CurrentVersion)
 	respondJSON(w, http.StatusOK, article)
 }
 
@@ -120,9 +124,9 @@ func (h *ArticleHandler) ListArticles(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	articles, total, err := h.service.FindAll(r.Context(), filter, page, limit, sort)
+	articles, total, err := h.service.FindAll(r.Context(), getTenantID(r), filter, page, limit, sort)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -137,6 +141,7 @@ func (h *ArticleHandler) ListArticles(w http.ResponseWriter, r *http.Request) {
 }
 
 // UpdateArticle handles PATCH /api/v1/articles/{id}
+// The edit must name the version it is based on, as If-Match or currentVersion.
 func (h *ArticleHandler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
 	id, err := getIDFromPath(r, "id")
 	if err != nil {
@@ -144,24 +149,35 @@ func (h *ArticleHandler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	article, err := h.service.FindByID(r.Context(), id)
+	article, err := h.service.FindByID(r.Context(), getTenantID(r), id)
 	if err != nil {
-		respondError(w, http.StatusNotFound, err.Error())
+		respondServiceError(w, http.StatusNotFound, err)
 		return
 	}
 
+	// The stored version must not stand in for the one the client edited,
+	// and the counters are not editable
+	article.CurrentVersion = 0
+	viewCount, trendingScore := article.ViewCount, article.TrendingScore
 	if err := json.NewDecoder(r.Body).Decode(article); err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	article.ViewCount, article.TrendingScore = viewCount, trendingScore
+	baseVersion, ok := getBaseVersion(w, r, article.CurrentVersion)
+	if !ok {
+		return
+	}
+	article.CurrentVersion = baseVersion
 
 	userID := getUserID(r)
 	userRole := getUserRole(r)
-	if err := h.service.Update(r.Context(), article, userID, userRole); err != nil {
-		respondError(w, http.StatusForbidden, err.Error())
+	if err := h.service.Update(r.Context(), getTenantID(r), article, userID, userRole); err != nil {
+		respondServiceError(w, http.StatusForbidden, err)
 		return
 	}
 
+	setArticleETag(w, article.CurrentVersion)
 	respondJSON(w, http.StatusOK, article)
 }
 
@@ -176,8 +192,8 @@ func (h *ArticleHandler) DeleteArticle(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
 	userID := getUserID(r)
 
-	if err := h.service.Delete(r.Context(), id, userID); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.Delete(r.Context(), getTenantID(r), id, userID); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -192,14 +208,35 @@ func (h *ArticleHandler) PublishArticle(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	userID := getUserID(r)
-	userRole := getUserRole(r)
-	if err := h.service.Publish(r.Context(), id, userID, userRole); err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+	var req struct {
+		BaseVersion int `json:"baseVersion"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	baseVersion, ok := getBaseVersion(w, r, req.BaseVersion)
+	if !ok {
+		return
+	}
+
+	article, err := h.service.Publish(r.Context(), getTenantID(r), id, baseVersion, getActor(r))
+	if err != nil {
+		respondServiceError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]string{"message": "Article published successfully"})
+	message := "Article published successfully"
+	if article.Status != model.ArticleStatusPublished {
+		message = "Article held for review"
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":         message,
+		"status":          article.Status,
+		"contentWarnings": article.ContentWarnings,
+	})
 }
 
 // ReorderArticles handles POST /api/v1/articles/reorder
@@ -232,49 +269,14 @@ func (h *ArticleHandler) ReorderArticles(w http.ResponseWriter, r *http.Request)
 		articles[i].Ordering = item.Ordering
 	}
 
-	if err := h.service.Reorder(r.Context(), articles); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.Reorder(r.Context(), getTenantID(r), articles); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Articles reordered successfully"})
 }
 
-// GetArticleStats handles GET /api/v1/statistics/articles/{id}
-func (h *ArticleHandler) GetArticleStats(w http.ResponseWriter, r *http.Request) {
-	id, err := getIDFromPath(r, "id")
-	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid article ID")
-		return
-	}
-
-	query := r.URL.Query()
-
-	// Parse date range (default to last 30 days)
-	endDate := time.Now()
-	startDate := endDate.AddDate(0, 0, -30)
-
-	if start := query.Get("startDate"); start != "" {
-		if t, err := time.Parse("2006-01-02", start); err == nil {
-			startDate = t
-		}
-	}
-
-	if end := query.Get("endDate"); end != "" {
-		if t, err := time.Parse("2006-01-02", end); err == nil {
-			endDate = t
-		}
-	}
-
-	stats, err := h.service.GetArticleStats(r.Context(), id, startDate, endDate)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	respondJSON(w, http.StatusOK, stats)
-}
-
 // SearchArticles handles GET /api/v1/search
 func (h *ArticleHandler) SearchArticles(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
@@ -295,9 +297,9 @@ func (h *ArticleHandler) SearchArticles(w http.ResponseWriter, r *http.Request)
 		limit = 20
 	}
 
-	articles, total, err := h.service.Search(r.Context(), q, page, limit)
+	articles, total, err := h.service.Search(r.Context(), getTenantID(r), q, page, limit)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -313,20 +315,69 @@ func (h *ArticleHandler) SearchArticles(w http.ResponseWriter, r *http.Request)
 
 // ViewArticle handles POST /api/v1/articles/{id}/view
 func (h *ArticleHandler) ViewArticle(w http.ResponseWriter, r *http.Request) {
-	id, err := getIDFromPath(r, "id")
+	id, err := getIDFromPath(r, "view")
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid article ID")
 		return
 	}
 
-	if err := h.service.IncrementViewCount(r.Context(), id); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.IncrementViewCount(r.Context(), getTenantID(r), id, getVisitor(r)); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "View recorded"})
 }
 
+// SetHot handles PUT /api/v1/articles/{id}/hot
+// {"hot": true} or {"hot": false} overrides the trending worker; {"hot": null}
+// hands the flag back to it.
+func (h *ArticleHandler) SetHot(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "hot")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var req struct {
+		Hot *bool `json:"hot"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	article, err := h.service.SetHot(r.Context(), getTenantID(r), id, req.Hot, getActor(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, article)
+}
+
+// RecordEngagement handles POST /api/v1/engagement
+// Browsers send the beacon with navigator.sendBeacon when the reader leaves the
+// article, so any content type is accepted as long as the body is JSON.
+func (h *ArticleHandler) RecordEngagement(w http.ResponseWriter, r *http.Request) {
+	var beacon model.EngagementBeacon
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBeaconSize)).Decode(&beacon); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if beacon.ArticleID.IsZero() {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	if err := h.service.RecordEngagement(r.Context(), getTenantID(r), &beacon, getVisitor(r)); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // RejectArticle handles POST /api/v1/articles/{id}/reject
 func (h *ArticleHandler) RejectArticle(w http.ResponseWriter, r *http.Request) {
 	id, err := getIDFromPath(r, "id")
@@ -336,7 +387,8 @@ func (h *ArticleHandler) RejectArticle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Note string `json:"note"`
+		Note        string `json:"note"`
+		BaseVersion int    `json:"baseVersion"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid request body")
@@ -349,19 +401,68 @@ func (h *ArticleHandler) RejectArticle(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	baseVersion, ok := getBaseVersion(w, r, req.BaseVersion)
+	if !ok {
+		return
+	}
 
-	userID := getUserID(r)
-	userName := getUserName(r)
-	userRole := getUserRole(r)
-
-	if err := h.service.RejectArticle(r.Context(), id, userID, userName, userRole, req.Note); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.RejectArticle(r.Context(), getTenantID(r), id, baseVersion, getActor(r), req.Note); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Article rejected"})
 }
 
+// TransitionArticle handles POST /api/v1/articles/{id}/transitions
+func (h *ArticleHandler) TransitionArticle(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "transitions")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var req struct {
+		Transition  string `json:"transition"`
+		Note        string `json:"note"`
+		BaseVersion int    `json:"baseVersion"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	baseVersion, ok := getBaseVersion(w, r, req.BaseVersion)
+	if !ok {
+		return
+	}
+
+	article, err := h.service.Transition(r.Context(), getTenantID(r), id, baseVersion, req.Transition, req.Note, getActor(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	setArticleETag(w, article.CurrentVersion)
+	respondJSON(w, http.StatusOK, article)
+}
+
+// GetAvailableTransitions handles GET /api/v1/articles/{id}/transitions
+func (h *ArticleHandler) GetAvailableTransitions(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "transitions")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	transitions, err := h.service.AvailableTransitions(r.Context(), getTenantID(r), id, getActor(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, transitions)
+}
+
 // AddRejectionNote handles POST /api/v1/articles/{id}/rejection-notes
 func (h *ArticleHandler) AddRejectionNote(w http.ResponseWriter, r *http.Request) {
 	id, err := getIDFromPath(r, "id")
@@ -400,8 +501,8 @@ func (h *ArticleHandler) AddRejectionNote(w http.ResponseWriter, r *http.Request
 		parentID = &objID
 	}
 
-	if err := h.service.AddRejectionNote(r.Context(), id, userID, userName, userRole, req.Note, parentID); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.AddRejectionNote(r.Context(), getTenantID(r), id, userID, userName, userRole, req.Note, parentID); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -416,9 +517,9 @@ func (h *ArticleHandler) GetRejectionNotes(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	notes, err := h.service.GetRejectionNotes(r.Context(), id)
+	notes, err := h.service.GetRejectionNotes(r.Context(), getTenantID(r), id)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -436,8 +537,8 @@ func (h *ArticleHandler) ResolveRejectionNotes(w http.ResponseWriter, r *http.Re
 	userID := getUserID(r)
 	userRole := getUserRole(r)
 
-	if err := h.service.ResolveRejectionNotes(r.Context(), id, userID, userRole); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.ResolveRejectionNotes(r.Context(), getTenantID(r), id, userID, userRole); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -446,15 +547,15 @@ func (h *ArticleHandler) ResolveRejectionNotes(w http.ResponseWriter, r *http.Re
 
 // GetArticleVersions handles GET /api/v1/articles/{id}/versions
 func (h *ArticleHandler) GetArticleVersions(w http.ResponseWriter, r *http.Request) {
-	id, err := getIDFromPath(r, "id")
+	id, err := getIDFromPath(r, "versions")
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid article ID")
 		return
 	}
 
-	versions, err := h.service.GetArticleVersions(r.Context(), id)
+	versions, err := h.service.GetArticleVersions(r.Context(), getTenantID(r), id)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -469,18 +570,87 @@ func (h *ArticleHandler) GetArticleVersion(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	version, err := h.service.GetArticleVersion(r.Context(), versionID)
+	version, err := h.service.GetArticleVersion(r.Context(), getTenantID(r), versionID)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, version)
 }
 
-// RestoreArticleVersion handles POST /api/v1/articles/{id}/versions/{versionNum}/restore
+// DiffArticleVersions handles GET /api/v1/articles/{id}/versions/diff?from=N&to=M
+// Without to, version from is compared with the current article.
+func (h *ArticleHandler) DiffArticleVersions(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "versions")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil || from < 1 {
+		respondError(w, http.StatusBadRequest, "Invalid from version")
+		return
+	}
+	to := 0
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		if to, err = strconv.Atoi(toStr); err != nil || to < 1 {
+			respondError(w, http.StatusBadRequest, "Invalid to version")
+			return
+		}
+	}
+
+	diff, err := h.service.DiffArticleVersions(r.Context(), getTenantID(r), id, from, to)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, diff)
+}
+
+// MergeArticleVersion handles POST /api/v1/articles/{id}/versions/merge
+// The body holds the edited article and the version it was based on. A merge
+// with conflicts is not saved and returns 409 with the conflicting fields.
+func (h *ArticleHandler) MergeArticleVersion(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "versions")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var req struct {
+		BaseVersion int            `json:"baseVersion"`
+		Article     *model.Article `json:"article"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Article == nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.BaseVersion < 1 {
+		respondError(w, http.StatusBadRequest, "Base version is required")
+		return
+	}
+
+	result, err := h.service.MergeArticleVersion(r.Context(), getTenantID(r), id, req.BaseVersion, req.Article, getUserID(r), getUserRole(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !result.Saved {
+		respondJSON(w, http.StatusConflict, result)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// RestoreArticleVersion handles POST /api/v1/articles/{id}/versions/restore?version=N
+// The body {"fields": [...], "baseVersion": 7} may restore only the listed
+// fields; baseVersion can also be sent as If-Match.
 func (h *ArticleHandler) RestoreArticleVersion(w http.ResponseWriter, r *http.Request) {
-	id, err := getIDFromPath(r, "id")
+	id, err := getIDFromPath(r, "versions")
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid article ID")
 		return
@@ -499,17 +669,129 @@ func (h *ArticleHandler) RestoreArticleVersion(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	var req struct {
+		Fields      []string `json:"fields"`
+		BaseVersion int      `json:"baseVersion"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	baseVersion, ok := getBaseVersion(w, r, req.BaseVersion)
+	if !ok {
+		return
+	}
+
 	userID := getUserID(r)
 	userRole := getUserRole(r)
 
-	if err := h.service.RestoreArticleVersion(r.Context(), id, versionNum, userID, userRole); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.RestoreArticleVersion(r.Context(), getTenantID(r), id, versionNum, req.Fields, baseVersion, userID, userRole); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Version restored successfully"})
 }
 
+// GetEditLock handles GET /api/v1/articles/{id}/lock
+func (h *ArticleHandler) GetEditLock(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "lock")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	lock, err := h.service.GetEditLock(r.Context(), getTenantID(r), id)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"locked": lock != nil,
+		"lock":   lock,
+	})
+}
+
+// AcquireEditLock handles POST /api/v1/articles/{id}/lock
+func (h *ArticleHandler) AcquireEditLock(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "lock")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	lock, err := h.service.AcquireEditLock(r.Context(), getTenantID(r), id, getActor(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, lock)
+}
+
+// RenewEditLock handles PUT /api/v1/articles/{id}/lock
+func (h *ArticleHandler) RenewEditLock(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "lock")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	lock, err := h.service.RenewEditLock(r.Context(), getTenantID(r), id, getActor(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, lock)
+}
+
+// ReleaseEditLock handles DELETE /api/v1/articles/{id}/lock
+func (h *ArticleHandler) ReleaseEditLock(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "lock")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	if err := h.service.ReleaseEditLock(r.Context(), getTenantID(r), id, getActor(r)); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TakeEditLock handles POST /api/v1/articles/{id}/lock/take
+func (h *ArticleHandler) TakeEditLock(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "lock")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	lock, err := h.service.TakeEditLock(r.Context(), getTenantID(r), id, getActor(r), req.Note)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, lock)
+}
+
 // GetActionLogs handles GET /api/v1/articles/{id}/logs
 func (h *ArticleHandler) GetActionLogs(w http.ResponseWriter, r *http.Request) {
 	id, err := getIDFromPath(r, "id")
@@ -528,9 +810,9 @@ func (h *ArticleHandler) GetActionLogs(w http.ResponseWriter, r *http.Request) {
 		limit = 20
 	}
 
-	logs, total, err := h.service.GetActionLogs(r.Context(), id, page, limit)
+	logs, total, err := h.service.GetActionLogs(r.Context(), getTenantID(r), id, page, limit)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -552,9 +834,9 @@ func (h *ArticleHandler) GetSocialShareURLs(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	article, err := h.service.FindByID(r.Context(), id)
+	article, err := h.service.FindByID(r.Context(), getTenantID(r), id)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
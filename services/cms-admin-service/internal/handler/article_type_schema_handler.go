@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+)
+
+// ArticleTypeSchemaHandler handles HTTP requests for article type schemas
+type ArticleTypeSchemaHandler struct {
+	service *service.ArticleTypeSchemaService
+}
+
+// NewArticleTypeSchemaHandler creates a new article type schema handler
+func NewArticleTypeSchemaHandler(service *service.ArticleTypeSchemaService) *ArticleTypeSchemaHandler {
+	return &ArticleTypeSchemaHandler{
+		service: service,
+	}
+}
+
+// ListSchemas handles GET /api/v1/article-schemas
+func (h *ArticleTypeSchemaHandler) ListSchemas(w http.ResponseWriter, r *http.Request) {
+	schemas, err := h.service.List(r.Context(), getTenantID(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, schemas)
+}
+
+// GetSchema handles GET /api/v1/article-schemas/{articleType}
+func (h *ArticleTypeSchemaHandler) GetSchema(w http.ResponseWriter, r *http.Request) {
+	schema, err := h.service.Get(r.Context(), getTenantID(r), getArticleTypeFromPath(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, schema)
+}
+
+// SaveSchema handles PUT /api/v1/article-schemas/{articleType}
+// The request body is the JSON Schema document itself.
+func (h *ArticleTypeSchemaHandler) SaveSchema(w http.ResponseWriter, r *http.Request) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	schema, err := h.service.Save(r.Context(), getTenantID(r), getArticleTypeFromPath(r), raw, getUserID(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, schema)
+}
+
+// DeleteSchema handles DELETE /api/v1/article-schemas/{articleType}
+func (h *ArticleTypeSchemaHandler) DeleteSchema(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), getTenantID(r), getArticleTypeFromPath(r)); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Article type schema deleted successfully"})
+}
+
+func getArticleTypeFromPath(r *http.Request) model.ArticleType {
+	parts := strings.Split(strings.TrimSuffix(r.URL.Path, "/"), "/")
+	return model.ArticleType(parts[len(parts)-1])
+}
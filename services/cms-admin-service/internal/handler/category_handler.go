@@ -29,8 +29,8 @@ func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 	}
 
 	userID := getUserID(r)
-	if err := h.service.Create(r.Context(), &category, userID); err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+	if err := h.service.Create(r.Context(), getTenantID(r), &category, userID); err != nil {
+		respondServiceError(w, http.StatusBadRequest, err)
 		return
 	}
 
@@ -45,9 +45,9 @@ func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	category, err := h.service.FindByID(r.Context(), id)
+	category, err := h.service.FindByID(r.Context(), getTenantID(r), id)
 	if err != nil {
-		respondError(w, http.StatusNotFound, err.Error())
+		respondServiceError(w, http.StatusNotFound, err)
 		return
 	}
 
@@ -56,9 +56,9 @@ func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
 
 // GetCategoryTree handles GET /api/v1/categories/tree
 func (h *CategoryHandler) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
-	tree, err := h.service.GetTree(r.Context())
+	tree, err := h.service.GetTree(r.Context(), getTenantID(r))
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -73,9 +73,9 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	category, err := h.service.FindByID(r.Context(), id)
+	category, err := h.service.FindByID(r.Context(), getTenantID(r), id)
 	if err != nil {
-		respondError(w, http.StatusNotFound, err.Error())
+		respondServiceError(w, http.StatusNotFound, err)
 		return
 	}
 
@@ -84,8 +84,8 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.service.Update(r.Context(), category); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.Update(r.Context(), getTenantID(r), category); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -100,8 +100,8 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.service.Delete(r.Context(), id); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.Delete(r.Context(), getTenantID(r), id); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
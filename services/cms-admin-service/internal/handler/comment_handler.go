@@ -24,7 +24,7 @@ func NewCommentHandler(service *service.CommentService) *CommentHandler {
 
 // CreateComment handles POST /api/v1/articles/{id}/comments
 func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
-	articleID, err := getIDFromPath(r, "id")
+	articleID, err := getIDFromPath(r, "comments")
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid article ID")
 		return
@@ -56,17 +56,43 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 		comment.ParentID = &parentObjID
 	}
 
-	if err := h.service.CreateComment(r.Context(), comment, userID, userName); err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+	if err := h.service.CreateComment(r.Context(), getTenantID(r), comment, userID, userName); err != nil {
+		respondServiceError(w, http.StatusBadRequest, err)
 		return
 	}
 
 	respondJSON(w, http.StatusCreated, comment)
 }
 
+// ScreenComment handles POST /api/v1/articles/{id}/comments/screen. The stats
+// service calls it on behalf of a commenter before storing a public comment.
+func (h *CommentHandler) ScreenComment(w http.ResponseWriter, r *http.Request) {
+	articleID, err := getIDFromPath(r, "comments")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	scan, err := h.service.ScreenComment(r.Context(), getTenantID(r), articleID, req.Content)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, scan)
+}
+
 // GetArticleComments handles GET /api/v1/articles/{id}/comments
 func (h *CommentHandler) GetArticleComments(w http.ResponseWriter, r *http.Request) {
-	articleID, err := getIDFromPath(r, "id")
+	articleID, err := getIDFromPath(r, "comments")
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid article ID")
 		return
@@ -2,10 +2,18 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	pkgMiddleware "github.com/vhvplatform/go-cms-service/pkg/middleware"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -21,9 +29,102 @@ func respondError(w http.ResponseWriter, status int, message string) {
 	respondJSON(w, status, map[string]string{"error": message})
 }
 
+// respondServiceError maps validation errors to 400 with per-field details,
+// permission errors to 403, repository sentinel errors to 404/409, invalid
+// preview links to 404, outdated article versions to 409 with the changes
+// since, blocked content to 422 with the keyword findings, articles locked by
+// another editor to 423 with the lock, used-up AI quotas to 429, AI provider
+// failures to 502 and everything else to the given fallback status
+func respondServiceError(w http.ResponseWriter, fallback int, err error) {
+	var validationErrs validator.ValidationErrors
+	var blocked *service.ContentBlockedError
+	var quota *service.QuotaExceededError
+	var versionConflict *service.VersionConflictError
+	var locked *service.EditLockedError
+	switch {
+	case errors.As(err, &locked):
+		respondJSON(w, http.StatusLocked, map[string]interface{}{
+			"error": err.Error(),
+			"lock":  locked.Lock,
+		})
+	case errors.As(err, &versionConflict):
+		setArticleETag(w, versionConflict.CurrentVersion)
+		respondJSON(w, http.StatusConflict, map[string]interface{}{
+			"error":          err.Error(),
+			"baseVersion":    versionConflict.BaseVersion,
+			"currentVersion": versionConflict.CurrentVersion,
+			"changes":        versionConflict.Changes,
+		})
+	case errors.As(err, &quota):
+		retryAfter := int(math.Ceil(time.Until(quota.ResetsAt).Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+		respondJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+			"error":    err.Error(),
+			"scope":    quota.Scope,
+			"limit":    quota.Limit,
+			"resetsAt": quota.ResetsAt,
+		})
+	case errors.As(err, &blocked):
+		respondJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":    err.Error(),
+			"findings": blocked.Scan.Results,
+		})
+	case errors.As(err, &validationErrs):
+		respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":  err.Error(),
+			"fields": validationErrs,
+		})
+	case errors.Is(err, service.ErrForbidden):
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, service.ErrInvalidPreviewLink):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrDuplicate), errors.Is(err, repository.ErrConflict):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrAIProvider):
+		respondError(w, http.StatusBadGateway, err.Error())
+	default:
+		respondError(w, fallback, err.Error())
+	}
+}
+
+// setArticleETag sets the ETag of an article response to its version
+func setArticleETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
 // Request helpers
 
+// getBaseVersion returns the article version a change is based on, from the
+// If-Match header or else the version sent in the body. Changes to articles
+// require one; without it the response is 428, and for a version below 1 it
+// is 400. ok is false in both cases.
+func getBaseVersion(w http.ResponseWriter, r *http.Request, bodyVersion int) (version int, ok bool) {
+	if header := r.Header.Get("If-Match"); header != "" {
+		tag := strings.TrimPrefix(strings.TrimSpace(header), "W/")
+		version, err := strconv.Atoi(strings.Trim(tag, `"`))
+		if err != nil || version < 1 {
+			respondError(w, http.StatusBadRequest, "Invalid If-Match header")
+			return 0, false
+		}
+		return version, true
+	}
+	if bodyVersion < 0 {
+		respondError(w, http.StatusBadRequest, "Invalid article version")
+		return 0, false
+	}
+	if bodyVersion > 0 {
+		return bodyVersion, true
+	}
+	respondError(w, http.StatusPreconditionRequired, "The article version is required: send If-Match or the version in the body")
+	return 0, false
+}
+
 func getIDFromPath(r *http.Request, param string) (primitive.ObjectID, error) {
+	return primitive.ObjectIDFromHex(getSegmentFromPath(r, param))
+}
+
+// getSegmentFromPath returns the path segment before param, or the last one
+func getSegmentFromPath(r *http.Request, param string) string {
 	// Extract ID from URL path
 	// This is a simplified version - in production use a proper router like chi or gorilla/mux
 	parts := strings.Split(r.URL.Path, "/")
@@ -40,7 +141,7 @@ func getIDFromPath(r *http.Request, param string) (primitive.ObjectID, error) {
 		idStr = parts[len(parts)-1]
 	}
 
-	return primitive.ObjectIDFromHex(idStr)
+	return idStr
 }
 
 func getUserID(r *http.Request) string {
@@ -50,7 +151,7 @@ func getUserID(r *http.Request) string {
 			return id
 		}
 	}
-	return "system" // Default for testing
+	return ""
 }
 
 func getUserRole(r *http.Request) model.Role {
@@ -60,7 +161,7 @@ func getUserRole(r *http.Request) model.Role {
 			return model.Role(role)
 		}
 	}
-	return model.RoleWriter // Default for testing
+	return ""
 }
 
 func getUserName(r *http.Request) string {
@@ -70,5 +171,50 @@ func getUserName(r *http.Request) string {
 			return name
 		}
 	}
-	return "System User" // Default for testing
+	return ""
+}
+
+func getUserGroups(r *http.Request) []string {
+	// Get group IDs from context (set by auth middleware)
+	if groupIDs := r.Context().Value("groupIDs"); groupIDs != nil {
+		if groups, ok := groupIDs.([]string); ok {
+			return groups
+		}
+	}
+	return nil
+}
+
+// getActor collects the identity of the authenticated user
+func getActor(r *http.Request) model.Actor {
+	return model.Actor{
+		UserID:   getUserID(r),
+		UserName: getUserName(r),
+		Role:     getUserRole(r),
+		GroupIDs: getUserGroups(r),
+	}
+}
+
+// getClientIP returns the caller's address, as resolved from the connection
+// and the forwarding headers of trusted proxies
+func getClientIP(r *http.Request) string {
+	return pkgMiddleware.ClientIP(r)
+}
+
+// getVisitor identifies the viewer of an article for view counting
+func getVisitor(r *http.Request) model.Visitor {
+	return model.Visitor{
+		UserID:    getUserID(r),
+		IP:        getClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+func getTenantID(r *http.Request) primitive.ObjectID {
+	// Get tenant ID from context (set by tenant middleware)
+	if tenantID := r.Context().Value("tenantID"); tenantID != nil {
+		if id, ok := tenantID.(primitive.ObjectID); ok {
+			return id
+		}
+	}
+	return primitive.NilObjectID
 }
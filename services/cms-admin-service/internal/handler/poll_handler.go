@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+)
+
+// PollHandler handles HTTP requests for polls
+type PollHandler struct {
+	service *service.PollService
+}
+
+// NewPollHandler creates a new poll handler
+func NewPollHandler(service *service.PollService) *PollHandler {
+	return &PollHandler{
+		service: service,
+	}
+}
+
+// CreateArticlePoll handles POST /api/v1/articles/{id}/poll
+func (h *PollHandler) CreateArticlePoll(w http.ResponseWriter, r *http.Request) {
+	articleID, err := getIDFromPath(r, "poll")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var poll model.Poll
+	if err := json.NewDecoder(r.Body).Decode(&poll); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	poll.ArticleID = articleID
+
+	if err := h.service.CreatePoll(r.Context(), getTenantID(r), &poll, getUserID(r), getUserRole(r)); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, poll)
+}
+
+// GetArticlePoll handles GET /api/v1/articles/{id}/poll
+func (h *PollHandler) GetArticlePoll(w http.ResponseWriter, r *http.Request) {
+	articleID, err := getIDFromPath(r, "poll")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	poll, err := h.service.GetArticlePoll(r.Context(), getTenantID(r), articleID)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, poll)
+}
+
+// UpdateArticlePoll handles PUT /api/v1/articles/{id}/poll
+func (h *PollHandler) UpdateArticlePoll(w http.ResponseWriter, r *http.Request) {
+	articleID, err := getIDFromPath(r, "poll")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var poll model.Poll
+	if err := json.NewDecoder(r.Body).Decode(&poll); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.UpdatePoll(r.Context(), getTenantID(r), articleID, &poll, getUserRole(r)); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, poll)
+}
+
+// SetArticlePollStatus handles PATCH /api/v1/articles/{id}/poll
+func (h *PollHandler) SetArticlePollStatus(w http.ResponseWriter, r *http.Request) {
+	articleID, err := getIDFromPath(r, "poll")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var req struct {
+		IsActive *bool `json:"isActive"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.IsActive == nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.SetPollStatus(r.Context(), getTenantID(r), articleID, *req.IsActive, getUserRole(r)); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Poll status updated successfully"})
+}
+
+// DeleteArticlePoll handles DELETE /api/v1/articles/{id}/poll
+func (h *PollHandler) DeleteArticlePoll(w http.ResponseWriter, r *http.Request) {
+	articleID, err := getIDFromPath(r, "poll")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	if err := h.service.DeletePoll(r.Context(), getTenantID(r), articleID, getUserRole(r)); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Poll deleted successfully"})
+}
+
+// GetPoll handles GET /api/v1/polls/{id}
+func (h *PollHandler) GetPoll(w http.ResponseWriter, r *http.Request) {
+	pollID, err := getIDFromPath(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid poll ID")
+		return
+	}
+
+	poll, userVote, err := h.service.GetPoll(r.Context(), getTenantID(r), pollID, getUserID(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"poll":     poll,
+		"userVote": userVote,
+	})
+}
+
+// GetPollResults handles GET /api/v1/polls/{id}/results
+func (h *PollHandler) GetPollResults(w http.ResponseWriter, r *http.Request) {
+	pollID, err := getIDFromPath(r, "results")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid poll ID")
+		return
+	}
+
+	poll, err := h.service.GetPollResults(r.Context(), getTenantID(r), pollID)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, poll)
+}
+
+// VoteOnPoll handles POST /api/v1/polls/{id}/vote
+// Signed-in users vote once per poll; anonymous visitors once per IP address.
+func (h *PollHandler) VoteOnPoll(w http.ResponseWriter, r *http.Request) {
+	pollID, err := getIDFromPath(r, "vote")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid poll ID")
+		return
+	}
+
+	var req struct {
+		OptionIDs []string `json:"optionIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.VoteOnPoll(r.Context(), getTenantID(r), pollID, getUserID(r), req.OptionIDs, getClientIP(r)); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Vote recorded"})
+}
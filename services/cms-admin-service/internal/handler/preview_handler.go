@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+)
+
+// PreviewHandler handles HTTP requests for article preview links
+type PreviewHandler struct {
+	service *service.PreviewService
+}
+
+// NewPreviewHandler creates a new preview handler
+func NewPreviewHandler(service *service.PreviewService) *PreviewHandler {
+	return &PreviewHandler{
+		service: service,
+	}
+}
+
+// CreateLink handles POST /api/v1/articles/{id}/preview-links
+// The optional body selects the version ({"version": 3}) and how long the link
+// is valid ({"expiresIn": "48h"}).
+func (h *PreviewHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "preview-links")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	var req struct {
+		Version   int    `json:"version"`
+		ExpiresIn string `json:"expiresIn"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	var ttl time.Duration
+	if req.ExpiresIn != "" {
+		ttl, err = time.ParseDuration(req.ExpiresIn)
+		if err != nil || ttl <= 0 {
+			respondError(w, http.StatusBadRequest, "Invalid expiresIn")
+			return
+		}
+	}
+
+	link, err := h.service.CreateLink(r.Context(), getTenantID(r), id, req.Version, ttl, getUserID(r))
+	if err != nil {
+		respondServiceError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, link)
+}
+
+// ListLinks handles GET /api/v1/articles/{id}/preview-links
+func (h *PreviewHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "preview-links")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	links, err := h.service.ListLinks(r.Context(), getTenantID(r), id)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, links)
+}
+
+// RevokeLinks handles DELETE /api/v1/articles/{id}/preview-links
+func (h *PreviewHandler) RevokeLinks(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "preview-links")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+
+	revoked, err := h.service.RevokeLinks(r.Context(), getTenantID(r), id)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]int64{"revoked": revoked})
+}
+
+// GetPreview handles GET /api/v1/public/preview/{token}
+// The token identifies the tenant, so no tenant header is needed. Previews
+// are never cached and ask search engines not to index them.
+func (h *PreviewHandler) GetPreview(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, "/api/v1/public/preview/")
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+
+	article, err := h.service.GetPreview(r.Context(), token)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, article)
+}
@@ -1,21 +1,31 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
-	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// PublicArticleReader interface for dependency injection; implemented by
+// service.PublicArticleService
+type PublicArticleReader interface {
+	GetArticleByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.Article, error)
+	GetArticleBySlug(ctx context.Context, tenantID primitive.ObjectID, slug string) (*model.Article, error)
+	ListPublicArticles(ctx context.Context, tenantID primitive.ObjectID, filter map[string]interface{}, page, limit int, sort map[string]int) ([]*model.Article, int64, error)
+	GetTrendingArticles(ctx context.Context, tenantID, categoryID primitive.ObjectID, limit int) ([]*model.Article, error)
+	IncrementViewCount(ctx context.Context, tenantID, id primitive.ObjectID, visitor model.Visitor) error
+}
+
 // PublicArticleHandler handles HTTP requests for public article APIs (user-facing)
 type PublicArticleHandler struct {
-	service *service.PublicArticleService
+	service PublicArticleReader
 }
 
 // NewPublicArticleHandler creates a new public article handler
-func NewPublicArticleHandler(service *service.PublicArticleService) *PublicArticleHandler {
+func NewPublicArticleHandler(service PublicArticleReader) *PublicArticleHandler {
 	return &PublicArticleHandler{
 		service: service,
 	}
@@ -29,9 +39,9 @@ func (h *PublicArticleHandler) GetArticle(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	article, err := h.service.GetArticleByID(r.Context(), id)
+	article, err := h.service.GetArticleByID(r.Context(), getTenantID(r), id)
 	if err != nil {
-		respondError(w, http.StatusNotFound, err.Error())
+		respondServiceError(w, http.StatusNotFound, err)
 		return
 	}
 
@@ -47,9 +57,9 @@ func (h *PublicArticleHandler) GetArticleBySlug(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	article, err := h.service.GetArticleBySlug(r.Context(), slug)
+	article, err := h.service.GetArticleBySlug(r.Context(), getTenantID(r), slug)
 	if err != nil {
-		respondError(w, http.StatusNotFound, err.Error())
+		respondServiceError(w, http.StatusNotFound, err)
 		return
 	}
 
@@ -103,9 +113,11 @@ func (h *PublicArticleHandler) ListArticles(w http.ResponseWriter, r *http.Reque
 		filter["tags"] = tags
 	}
 
-	// Parse sort
+	// Parse sort; "trending" orders by trending score, highest first
 	sort := make(map[string]int)
-	if sortBy := query.Get("sort"); sortBy != "" {
+	if sortBy := query.Get("sort"); sortBy == "trending" {
+		sort["trendingScore"] = -1
+	} else if sortBy != "" {
 		if sortBy[0] == '-' {
 			sort[sortBy[1:]] = -1
 		} else {
@@ -113,9 +125,9 @@ func (h *PublicArticleHandler) ListArticles(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	articles, total, err := h.service.ListPublicArticles(r.Context(), filter, page, limit, sort)
+	articles, total, err := h.service.ListPublicArticles(r.Context(), getTenantID(r), filter, page, limit, sort)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondServiceError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -131,16 +143,38 @@ func (h *PublicArticleHandler) ListArticles(w http.ResponseWriter, r *http.Reque
 
 // ViewArticle handles POST /api/v1/public/articles/{id}/view
 func (h *PublicArticleHandler) ViewArticle(w http.ResponseWriter, r *http.Request) {
-	id, err := getIDFromPath(r, "id")
+	id, err := getIDFromPath(r, "view")
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid article ID")
 		return
 	}
 
-	if err := h.service.IncrementViewCount(r.Context(), id); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.IncrementViewCount(r.Context(), getTenantID(r), id, getVisitor(r)); err != nil {
+		respondServiceError(w, http.StatusNotFound, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "View recorded"})
 }
+
+// GetCategoryTrending handles GET /api/v1/public/categories/{id}/trending
+func (h *PublicArticleHandler) GetCategoryTrending(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := getIDFromPath(r, "trending")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	articles, err := h.service.GetTrendingArticles(r.Context(), getTenantID(r), categoryID, limit)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{"data": articles})
+}
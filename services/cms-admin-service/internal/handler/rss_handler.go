@@ -35,7 +35,7 @@ func (h *RSSHandler) GetRSSFeed(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate RSS feed
-	rss, err := h.service.GenerateFeed(r.Context(), limit, categoryPtr)
+	rss, err := h.service.GenerateFeed(r.Context(), getTenantID(r), limit, categoryPtr)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -0,0 +1,294 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
+)
+
+// maxKeywordImportSize limits the body of a bulk keyword import
+const maxKeywordImportSize = 10 << 20
+
+// SensitiveKeywordHandler handles HTTP requests for sensitive keywords
+type SensitiveKeywordHandler struct {
+	service *service.SensitiveKeywordService
+}
+
+// NewSensitiveKeywordHandler creates a new sensitive keyword handler
+func NewSensitiveKeywordHandler(service *service.SensitiveKeywordService) *SensitiveKeywordHandler {
+	return &SensitiveKeywordHandler{
+		service: service,
+	}
+}
+
+// keywordRequest is the request body for a keyword; isActive defaults to true
+type keywordRequest struct {
+	Keyword          string `json:"keyword"`
+	IsRegex          bool   `json:"isRegex"`
+	Severity         string `json:"severity"`
+	Action           string `json:"action"`
+	Description      string `json:"description"`
+	Category         string `json:"category"`
+	WholeWord        bool   `json:"wholeWord"`
+	IgnoreDiacritics bool   `json:"ignoreDiacritics"`
+	IsActive         *bool  `json:"isActive"`
+}
+
+func (req keywordRequest) apply(keyword *model.SensitiveKeyword) {
+	keyword.Keyword = req.Keyword
+	keyword.IsRegex = req.IsRegex
+	keyword.Severity = req.Severity
+	keyword.Action = req.Action
+	keyword.Description = req.Description
+	keyword.Category = req.Category
+	keyword.WholeWord = req.WholeWord
+	keyword.IgnoreDiacritics = req.IgnoreDiacritics
+	keyword.IsActive = req.IsActive == nil || *req.IsActive
+}
+
+// CreateKeyword handles POST /api/v1/sensitive-keywords
+func (h *SensitiveKeywordHandler) CreateKeyword(w http.ResponseWriter, r *http.Request) {
+	var req keywordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	keyword := &model.SensitiveKeyword{
+		TenantID:  getTenantID(r).Hex(),
+		CreatedBy: getUserID(r),
+	}
+	req.apply(keyword)
+
+	if err := h.service.Create(r.Context(), keyword); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, keyword)
+}
+
+// ListKeywords handles GET /api/v1/sensitive-keywords
+func (h *SensitiveKeywordHandler) ListKeywords(w http.ResponseWriter, r *http.Request) {
+	activeOnly := r.URL.Query().Get("activeOnly") == "true"
+
+	keywords, err := h.service.GetByTenant(r.Context(), getTenantID(r).Hex(), activeOnly)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if keywords == nil {
+		keywords = []*model.SensitiveKeyword{}
+	}
+
+	respondJSON(w, http.StatusOK, keywords)
+}
+
+// GetKeyword handles GET /api/v1/sensitive-keywords/{id}
+func (h *SensitiveKeywordHandler) GetKeyword(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid keyword ID")
+		return
+	}
+
+	keyword, err := h.service.GetByID(r.Context(), getTenantID(r).Hex(), id)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, keyword)
+}
+
+// UpdateKeyword handles PUT /api/v1/sensitive-keywords/{id}
+func (h *SensitiveKeywordHandler) UpdateKeyword(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid keyword ID")
+		return
+	}
+
+	keyword, err := h.service.GetByID(r.Context(), getTenantID(r).Hex(), id)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	var req keywordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.apply(keyword)
+
+	if err := h.service.Update(r.Context(), keyword); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, keyword)
+}
+
+// DeleteKeyword handles DELETE /api/v1/sensitive-keywords/{id}
+func (h *SensitiveKeywordHandler) DeleteKeyword(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid keyword ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), getTenantID(r).Hex(), id); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Keyword deleted successfully"})
+}
+
+// ImportKeywords handles POST /api/v1/sensitive-keywords/import
+// The body is a JSON array of keywords, or CSV (Content-Type text/csv) with a
+// header row naming the columns keyword, isRegex, severity, action, category,
+// description, wholeWord, ignoreDiacritics and isActive. Only keyword is
+// required.
+func (h *SensitiveKeywordHandler) ImportKeywords(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, maxKeywordImportSize)
+
+	var requests []keywordRequest
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		requests, err = parseKeywordCSV(body)
+	} else {
+		err = json.NewDecoder(body).Decode(&requests)
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid import: %v", err))
+		return
+	}
+
+	tenantID := getTenantID(r).Hex()
+	userID := getUserID(r)
+	keywords := make([]*model.SensitiveKeyword, len(requests))
+	for i, req := range requests {
+		keywords[i] = &model.SensitiveKeyword{TenantID: tenantID, CreatedBy: userID}
+		req.apply(keywords[i])
+	}
+
+	if err := h.service.BulkImport(r.Context(), keywords); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"message":  "Keywords imported successfully",
+		"imported": len(keywords),
+	})
+}
+
+// ScanText handles POST /api/v1/sensitive-keywords/scan
+// It reports matches without saving anything, e.g. to check a draft.
+func (h *SensitiveKeywordHandler) ScanText(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.service.ScanContent(r.Context(), getTenantID(r).Hex(), req.Text)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// parseKeywordCSV reads keywords from CSV with a header row
+func parseKeywordCSV(r io.Reader) ([]keywordRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("missing header row: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["keyword"]; !ok {
+		return nil, fmt.Errorf("header row has no keyword column")
+	}
+
+	var requests []keywordRequest
+	var errs validator.ValidationErrors
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		req := keywordRequest{
+			Keyword:     get("keyword"),
+			Severity:    get("severity"),
+			Action:      get("action"),
+			Category:    get("category"),
+			Description: get("description"),
+		}
+		parseBool := func(column string) *bool {
+			value := get(column)
+			if value == "" {
+				return nil
+			}
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, validator.ValidationError{Field: fmt.Sprintf("line %d: %s", line, column), Message: "must be true or false"})
+				return nil
+			}
+			return &parsed
+		}
+
+		flags := []struct {
+			column string
+			target *bool
+		}{
+			{"isRegex", &req.IsRegex},
+			{"wholeWord", &req.WholeWord},
+			{"ignoreDiacritics", &req.IgnoreDiacritics},
+		}
+		for _, flag := range flags {
+			if value := parseBool(flag.column); value != nil {
+				*flag.target = *value
+			}
+		}
+		req.IsActive = parseBool("isActive")
+		requests = append(requests, req)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return requests, nil
+}
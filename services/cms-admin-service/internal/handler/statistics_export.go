@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+)
+
+// getExportFormat reads the format query parameter: empty for JSON, or an
+// export format. It writes an error response and reports false if the format
+// is unknown.
+func getExportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		return "", true
+	case util.ExportCSV, util.ExportXLSX:
+		return format, true
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid format, must be json, csv or xlsx")
+		return "", false
+	}
+}
+
+// exportWriter writes a statistics export as a file download. The response is
+// only started by the first table, so errors found before then can still be
+// answered normally. After a write fails, further writes are skipped and err
+// holds the failure.
+type exportWriter struct {
+	w        http.ResponseWriter
+	format   string
+	filename string
+	tables   util.TableWriter
+	err      error
+}
+
+// newExportWriter prepares an export named after its content and period
+func newExportWriter(w http.ResponseWriter, format, name string, startDate, endDate time.Time) *exportWriter {
+	return &exportWriter{
+		w:        w,
+		format:   format,
+		filename: fmt.Sprintf("%s-%s-%s.%s", name, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), format),
+	}
+}
+
+func (e *exportWriter) started() bool {
+	return e.tables != nil || e.err != nil
+}
+
+func (e *exportWriter) table(name string, header ...string) {
+	if e.err != nil {
+		return
+	}
+	if e.tables == nil {
+		e.w.Header().Set("Content-Type", util.ExportContentType(e.format))
+		e.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.filename))
+		e.w.WriteHeader(http.StatusOK)
+		if e.tables, e.err = util.NewTableWriter(e.w, e.format); e.err != nil {
+			return
+		}
+	}
+	e.err = e.tables.Table(name, header...)
+}
+
+func (e *exportWriter) row(values ...interface{}) {
+	if e.err == nil {
+		e.err = e.tables.Row(values...)
+	}
+}
+
+func (e *exportWriter) close() {
+	if e.err == nil && e.tables != nil {
+		e.err = e.tables.Close()
+	}
+}
+
+// formatDate formats the local day of a time
+func formatDate(t time.Time) string {
+	return t.Local().Format("2006-01-02")
+}
+
+// writeCounts writes a table of counts by key, in key order
+func writeCounts(e *exportWriter, name, keyHeader string, counts map[string]int64) {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	e.table(name, keyHeader, "Count")
+	for _, key := range keys {
+		e.row(key, counts[key])
+	}
+}
+
+func writeOverview(e *exportWriter, stats *model.ArticleStatistics) {
+	e.table("Summary", "Metric", "Value")
+	e.row("Start date", formatDate(stats.Period.StartDate))
+	e.row("End date", formatDate(stats.Period.EndDate))
+	e.row("Total articles", stats.TotalArticles)
+	e.row("Published articles", stats.PublishedArticles)
+	e.row("Draft articles", stats.DraftArticles)
+	e.row("Pending articles", stats.PendingArticles)
+	e.row("Archived articles", stats.ArchivedArticles)
+	e.row("Views", stats.TotalViews)
+	e.row("Unique views", stats.TotalUniqueViews)
+
+	writeCounts(e, "Articles by type", "Type", stats.ArticlesByType)
+	writeCounts(e, "Articles by category", "Category ID", stats.ArticlesByCategory)
+	writeCounts(e, "Articles by author", "Author", stats.ArticlesByAuthor)
+
+	e.table("Top viewed articles", "Article ID", "Title", "Slug", "Type", "Category ID", "Views", "Unique views")
+	for _, article := range stats.TopViewedArticles {
+		e.row(article.ArticleID.Hex(), article.Title, article.Slug, string(article.ArticleType), article.CategoryID.Hex(), article.ViewCount, article.UniqueViews)
+	}
+}
+
+func writeViewTrendHeader(e *exportWriter) {
+	e.table("Daily views", "Date", "Views", "Unique views", "Articles viewed")
+}
+
+func writeViewTrendRow(e *exportWriter, day *model.ViewTrendData) {
+	e.row(formatDate(day.Date), day.ViewCount, day.UniqueViews, day.Articles)
+}
+
+func writeArticleViewHeader(e *exportWriter) {
+	e.table("Daily views", "Date", "Views", "Unique views", "Reads", "Active seconds", "Completed reads")
+}
+
+func writeArticleViewRow(e *exportWriter, view *model.ArticleView) {
+	e.row(formatDate(view.Date), view.Views, view.UniqueViews, view.Engagement.Reads, view.Engagement.ActiveSeconds, view.Engagement.Depth100)
+}
+
+func writeCategoryStatistics(e *exportWriter, stats *model.CategoryStatistics) {
+	e.table("Category", "Metric", "Value")
+	e.row("Category ID", stats.CategoryID.Hex())
+	e.row("Name", stats.CategoryName)
+	e.row("Total articles", stats.TotalArticles)
+	e.row("Published articles", stats.PublishedCount)
+	e.row("Views", stats.TotalViews)
+	e.row("Unique views", stats.UniqueViews)
+	e.row("Average views per article", stats.AverageViews)
+}
+
+func writeAuthorStatistics(e *exportWriter, stats *model.AuthorStatistics) {
+	e.table("Author", "Metric", "Value")
+	e.row("Author ID", stats.AuthorID)
+	e.row("Name", stats.AuthorName)
+	e.row("Total articles", stats.TotalArticles)
+	e.row("Published articles", stats.PublishedCount)
+	e.row("Draft articles", stats.DraftCount)
+	e.row("Views", stats.TotalViews)
+	e.row("Average views per article", stats.AverageViews)
+}
+
+func writeEngagement(e *exportWriter, stats *model.EngagementStatistics) {
+	e.table("Summary", "Metric", "Value")
+	e.row("Scope", stats.Scope)
+	e.row("ID", stats.ID)
+	e.row("Start date", formatDate(stats.Period.StartDate))
+	e.row("End date", formatDate(stats.Period.EndDate))
+	e.row("Views", stats.Views)
+	e.row("Unique views", stats.UniqueViews)
+	e.row("Reads", stats.Reads)
+	e.row("Average read time (seconds)", stats.AverageReadTime)
+	e.row("Completion rate", stats.CompletionRate)
+
+	e.table("Scroll depth", "Depth (%)", "Reads")
+	for _, depth := range []string{"0", "25", "50", "75", "100"} {
+		e.row(depth, stats.ScrollDepth[depth])
+	}
+	writeCounts(e, "Devices", "Device", stats.Devices)
+
+	for _, top := range []struct {
+		name    string
+		sources []*model.SourceCount
+	}{
+		{"Referrers", stats.TopReferrers},
+		{"UTM sources", stats.TopUTMSources},
+		{"UTM mediums", stats.TopUTMMediums},
+		{"UTM campaigns", stats.TopUTMCampaigns},
+	} {
+		e.table(top.name, "Value", "Reads")
+		for _, source := range top.sources {
+			e.row(source.Value, source.Count)
+		}
+	}
+}
+
+func writeSnapshotList(e *exportWriter, snapshots []*model.StatisticsSnapshot) {
+	e.table("Snapshots", "Period", "Start date", "End date", "Total articles", "Published articles", "Draft articles", "Pending articles", "Archived articles", "Views", "Unique views")
+	for _, snapshot := range snapshots {
+		stats := snapshot.Statistics
+		if stats == nil {
+			stats = &model.ArticleStatistics{}
+		}
+		e.row(snapshot.Period.Type, formatDate(snapshot.Period.StartDate), formatDate(snapshot.Period.EndDate),
+			stats.TotalArticles, stats.PublishedArticles, stats.DraftArticles, stats.PendingArticles, stats.ArchivedArticles,
+			stats.TotalViews, stats.TotalUniqueViews)
+	}
+}
+
+// editorialStatuses are the statuses time in state is exported for, in
+// workflow order
+var editorialStatuses = []model.ArticleStatus{
+	model.ArticleStatusDraft,
+	model.ArticleStatusPendingReview,
+	model.ArticleStatusPublished,
+	model.ArticleStatusArchived,
+}
+
+func writeDurationRow(e *exportWriter, label string, stats *model.DurationStats) {
+	if stats == nil {
+		stats = &model.DurationStats{}
+	}
+	e.row(label, stats.Count, stats.Average, stats.Median, stats.P90, stats.Max)
+}
+
+func writeEditorialGroups(e *exportWriter, name, idHeader string, groups []*model.EditorialMetrics) {
+	header := []string{idHeader, "Name", "Articles", "Reviewed", "Rejected", "Rejection rate"}
+	for _, status := range editorialStatuses {
+		header = append(header, string(status)+" median (hours)", string(status)+" p90 (hours)")
+	}
+	e.table(name, header...)
+	for _, group := range groups {
+		values := []interface{}{group.ID, group.Name, group.Articles, group.Reviewed, group.Rejected, group.RejectionRate}
+		for _, status := range editorialStatuses {
+			stats := group.TimeInState[status]
+			if stats == nil {
+				stats = &model.DurationStats{}
+			}
+			values = append(values, stats.Median, stats.P90)
+		}
+		e.row(values...)
+	}
+}
+
+func writeEditorialMetrics(e *exportWriter, metrics *model.PerformanceMetrics) {
+	e.table("Summary", "Metric", "Value")
+	e.row("Start date", formatDate(metrics.Period.StartDate))
+	e.row("End date", formatDate(metrics.Period.EndDate))
+	e.row("Articles per day", metrics.ArticlesPerDay)
+	e.row("Views per day", metrics.ViewsPerDay)
+	e.row("Reviewed", metrics.Reviewed)
+	e.row("Rejected", metrics.Rejected)
+	e.row("Rejection rate", metrics.RejectionRate)
+
+	e.table("Time in state", "State", "Count", "Average (hours)", "Median (hours)", "P90 (hours)", "Max (hours)")
+	writeDurationRow(e, "Creation to publication", &metrics.PublishTime)
+	for _, status := range editorialStatuses {
+		writeDurationRow(e, string(status), metrics.TimeInState[status])
+	}
+
+	writeEditorialGroups(e, "By author", "Author ID", metrics.ByAuthor)
+	writeEditorialGroups(e, "By editor", "Editor ID", metrics.ByEditor)
+	writeEditorialGroups(e, "By category", "Category ID", metrics.ByCategory)
+}
+
+func writeArticleEditorialMetrics(e *exportWriter, articles []*model.ArticleEditorialMetrics) {
+	header := []string{"Article ID", "Title", "Author ID", "Author", "Category ID", "Review rounds", "Rejections"}
+	for _, status := range editorialStatuses {
+		header = append(header, string(status)+" (hours)")
+	}
+	e.table("Articles", header...)
+	for _, article := range articles {
+		values := []interface{}{article.ArticleID.Hex(), article.Title, article.AuthorID, article.AuthorName, article.CategoryID.Hex(), article.ReviewRounds, article.Rejections}
+		for _, status := range editorialStatuses {
+			values = append(values, article.TimeInState[status])
+		}
+		e.row(values...)
+	}
+}
+
+func writeReviewQueue(e *exportWriter, queue *model.ReviewQueue) {
+	e.table("Summary", "Metric", "Value")
+	e.row("Generated at", queue.GeneratedAt.Local().Format(time.RFC3339))
+	e.row("Articles", queue.Count)
+	e.row("Average age (hours)", queue.Age.Average)
+	e.row("Median age (hours)", queue.Age.Median)
+	e.row("P90 age (hours)", queue.Age.P90)
+	e.row("Max age (hours)", queue.Age.Max)
+
+	e.table("Age", "Age", "Articles")
+	for _, bucket := range queue.Buckets {
+		e.row(bucket.Label, bucket.Count)
+	}
+
+	e.table("Queue", "Article ID", "Title", "Author ID", "Author", "Category ID", "Submitted at", "Age (hours)")
+	for _, article := range queue.Articles {
+		e.row(article.ArticleID.Hex(), article.Title, article.AuthorID, article.AuthorName, article.CategoryID.Hex(), article.SubmittedAt.Local().Format(time.RFC3339), article.AgeHours)
+	}
+}
@@ -0,0 +1,490 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+)
+
+// StatisticsHandler handles HTTP requests for statistics. Every endpoint
+// answers in JSON, or with format=csv or format=xlsx as a file download.
+type StatisticsHandler struct {
+	service *service.StatisticsService
+}
+
+// NewStatisticsHandler creates a new statistics handler
+func NewStatisticsHandler(service *service.StatisticsService) *StatisticsHandler {
+	return &StatisticsHandler{
+		service: service,
+	}
+}
+
+// GetOverview handles GET /api/v1/statistics/overview
+func (h *StatisticsHandler) GetOverview(w http.ResponseWriter, r *http.Request) {
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := getStatisticsPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetOverview(r.Context(), getTenantID(r), startDate, endDate)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if format == "" {
+		respondJSON(w, http.StatusOK, stats)
+		return
+	}
+	export := newExportWriter(w, format, "overview", startDate, endDate)
+	writeOverview(export, stats)
+	export.close()
+}
+
+// GetViewTrend handles GET /api/v1/statistics/trend
+func (h *StatisticsHandler) GetViewTrend(w http.ResponseWriter, r *http.Request) {
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := getStatisticsPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	if format == "" {
+		trend := []*model.ViewTrendData{}
+		err := h.service.EachViewTrend(r.Context(), getTenantID(r), startDate, endDate, func(day *model.ViewTrendData) error {
+			trend = append(trend, day)
+			return nil
+		})
+		if err != nil {
+			respondServiceError(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, trend)
+		return
+	}
+
+	// Days are written as they are read
+	export := newExportWriter(w, format, "view-trend", startDate, endDate)
+	err := h.service.EachViewTrend(r.Context(), getTenantID(r), startDate, endDate, func(day *model.ViewTrendData) error {
+		if !export.started() {
+			writeViewTrendHeader(export)
+		}
+		writeViewTrendRow(export, day)
+		return export.err
+	})
+	if err != nil && !export.started() {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !export.started() {
+		writeViewTrendHeader(export)
+	}
+	export.close()
+}
+
+// GetArticleViews handles GET /api/v1/statistics/articles/{id}
+func (h *StatisticsHandler) GetArticleViews(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := getStatisticsPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	if format == "" {
+		views := []*model.ArticleView{}
+		err := h.service.EachArticleView(r.Context(), getTenantID(r), id, startDate, endDate, func(view *model.ArticleView) error {
+			views = append(views, view)
+			return nil
+		})
+		if err != nil {
+			respondServiceError(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, views)
+		return
+	}
+
+	// Days are written as they are read
+	export := newExportWriter(w, format, "article-"+id.Hex(), startDate, endDate)
+	err = h.service.EachArticleView(r.Context(), getTenantID(r), id, startDate, endDate, func(view *model.ArticleView) error {
+		if !export.started() {
+			writeArticleViewHeader(export)
+		}
+		writeArticleViewRow(export, view)
+		return export.err
+	})
+	if err != nil && !export.started() {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !export.started() {
+		writeArticleViewHeader(export)
+	}
+	export.close()
+}
+
+// GetCategoryStatistics handles GET /api/v1/statistics/categories/{id}
+func (h *StatisticsHandler) GetCategoryStatistics(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := getStatisticsPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetCategoryStatistics(r.Context(), getTenantID(r), id, startDate, endDate)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if format == "" {
+		respondJSON(w, http.StatusOK, stats)
+		return
+	}
+	export := newExportWriter(w, format, "category-"+id.Hex(), startDate, endDate)
+	writeCategoryStatistics(export, stats)
+	export.close()
+}
+
+// GetAuthorStatistics handles GET /api/v1/statistics/authors/{authorId}
+func (h *StatisticsHandler) GetAuthorStatistics(w http.ResponseWriter, r *http.Request) {
+	authorID := getSegmentFromPath(r, "id")
+	if authorID == "" || authorID == "authors" {
+		respondError(w, http.StatusBadRequest, "Invalid author ID")
+		return
+	}
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := getStatisticsPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetAuthorStatistics(r.Context(), getTenantID(r), authorID, startDate, endDate)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if format == "" {
+		respondJSON(w, http.StatusOK, stats)
+		return
+	}
+	export := newExportWriter(w, format, "author-"+authorID, startDate, endDate)
+	writeAuthorStatistics(export, stats)
+	export.close()
+}
+
+// GetArticleEngagement handles GET /api/v1/statistics/articles/{id}/engagement
+func (h *StatisticsHandler) GetArticleEngagement(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "engagement")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid article ID")
+		return
+	}
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := getStatisticsPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetArticleEngagement(r.Context(), getTenantID(r), id, startDate, endDate)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondEngagement(w, format, stats)
+}
+
+// GetCategoryEngagement handles GET /api/v1/statistics/categories/{id}/engagement
+func (h *StatisticsHandler) GetCategoryEngagement(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "engagement")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := getStatisticsPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetCategoryEngagement(r.Context(), getTenantID(r), id, startDate, endDate)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondEngagement(w, format, stats)
+}
+
+// GetAuthorEngagement handles GET /api/v1/statistics/authors/{authorId}/engagement
+func (h *StatisticsHandler) GetAuthorEngagement(w http.ResponseWriter, r *http.Request) {
+	authorID := getSegmentFromPath(r, "engagement")
+	if authorID == "" || authorID == "authors" {
+		respondError(w, http.StatusBadRequest, "Invalid author ID")
+		return
+	}
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := getStatisticsPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetAuthorEngagement(r.Context(), getTenantID(r), authorID, startDate, endDate)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondEngagement(w, format, stats)
+}
+
+// ListSnapshots handles GET /api/v1/statistics/snapshots
+func (h *StatisticsHandler) ListSnapshots(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	periodType := query.Get("period")
+	switch periodType {
+	case "":
+		periodType = model.PeriodMonthly
+	case model.PeriodDaily, model.PeriodWeekly, model.PeriodMonthly:
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid period, must be daily, weekly or monthly")
+		return
+	}
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+	endDate := time.Now()
+	startDate, endDate, ok := getPeriod(w, r, endDate.AddDate(-1, 0, 0), endDate)
+	if !ok {
+		return
+	}
+
+	// Pagination; exports list every snapshot
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if format != "" {
+		page, limit = 1, 0
+	}
+
+	snapshots, total, err := h.service.ListSnapshots(r.Context(), getTenantID(r), periodType, startDate, endDate, page, limit)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if format == "" {
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"snapshots": snapshots,
+			"total":     total,
+			"page":      page,
+			"limit":     limit,
+		})
+		return
+	}
+	export := newExportWriter(w, format, periodType+"-snapshots", startDate, endDate)
+	writeSnapshotList(export, snapshots)
+	export.close()
+}
+
+// GetSnapshot handles GET /api/v1/statistics/snapshots/{id}
+func (h *StatisticsHandler) GetSnapshot(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid snapshot ID")
+		return
+	}
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	snapshot, err := h.service.GetSnapshot(r.Context(), getTenantID(r), id)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if format == "" {
+		respondJSON(w, http.StatusOK, snapshot)
+		return
+	}
+	period := snapshot.Period
+	export := newExportWriter(w, format, period.Type+"-snapshot", period.StartDate.Local(), period.EndDate.Local())
+	writeOverview(export, snapshot.Statistics)
+	writeViewTrendHeader(export)
+	for _, day := range snapshot.ViewTrend {
+		writeViewTrendRow(export, day)
+	}
+	export.close()
+}
+
+// GetEditorialMetrics handles GET /api/v1/statistics/editorial
+func (h *StatisticsHandler) GetEditorialMetrics(w http.ResponseWriter, r *http.Request) {
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := getStatisticsPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	metrics, err := h.service.GetEditorialMetrics(r.Context(), getTenantID(r), startDate, endDate)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if format == "" {
+		respondJSON(w, http.StatusOK, metrics)
+		return
+	}
+	export := newExportWriter(w, format, "editorial", startDate, endDate)
+	writeEditorialMetrics(export, metrics)
+	export.close()
+}
+
+// GetArticleEditorialMetrics handles GET /api/v1/statistics/editorial/articles
+func (h *StatisticsHandler) GetArticleEditorialMetrics(w http.ResponseWriter, r *http.Request) {
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+	startDate, endDate, ok := getStatisticsPeriod(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	articles, err := h.service.GetArticleEditorialMetrics(r.Context(), getTenantID(r), startDate, endDate, limit)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if format == "" {
+		respondJSON(w, http.StatusOK, articles)
+		return
+	}
+	export := newExportWriter(w, format, "editorial-articles", startDate, endDate)
+	writeArticleEditorialMetrics(export, articles)
+	export.close()
+}
+
+// GetReviewQueue handles GET /api/v1/statistics/editorial/queue
+func (h *StatisticsHandler) GetReviewQueue(w http.ResponseWriter, r *http.Request) {
+	format, ok := getExportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	queue, err := h.service.GetReviewQueue(r.Context(), getTenantID(r), now)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if format == "" {
+		respondJSON(w, http.StatusOK, queue)
+		return
+	}
+	export := newExportWriter(w, format, "review-queue", now, now)
+	writeReviewQueue(export, queue)
+	export.close()
+}
+
+// respondEngagement writes engagement statistics as JSON or as an export
+func respondEngagement(w http.ResponseWriter, format string, stats *model.EngagementStatistics) {
+	if format == "" {
+		respondJSON(w, http.StatusOK, stats)
+		return
+	}
+	export := newExportWriter(w, format, stats.Scope+"-"+stats.ID+"-engagement", stats.Period.StartDate, stats.Period.EndDate)
+	writeEngagement(export, stats)
+	export.close()
+}
+
+// getStatisticsPeriod reads the startDate and endDate query parameters
+// (YYYY-MM-DD, both days included), defaulting to the last 30 days. It writes
+// an error response and reports false if either is invalid.
+func getStatisticsPeriod(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	endDate := time.Now()
+	return getPeriod(w, r, endDate.AddDate(0, 0, -30), endDate)
+}
+
+// getPeriod reads the startDate and endDate query parameters with the given
+// defaults. A given endDate stands for the end of that day.
+func getPeriod(w http.ResponseWriter, r *http.Request, startDate, endDate time.Time) (time.Time, time.Time, bool) {
+	query := r.URL.Query()
+	for _, param := range []struct {
+		name   string
+		target *time.Time
+	}{{"startDate", &startDate}, {"endDate", &endDate}} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid "+param.name)
+			return time.Time{}, time.Time{}, false
+		}
+		if param.name == "endDate" {
+			parsed = parsed.AddDate(0, 0, 1).Add(-time.Millisecond)
+		}
+		*param.target = parsed
+	}
+
+	return startDate, endDate, true
+}
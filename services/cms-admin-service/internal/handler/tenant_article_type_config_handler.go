@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+)
+
+// TenantArticleTypeConfigHandler handles HTTP requests for tenant article type configuration
+type TenantArticleTypeConfigHandler struct {
+	service *service.TenantArticleTypeConfigService
+}
+
+// NewTenantArticleTypeConfigHandler creates a new tenant article type config handler
+func NewTenantArticleTypeConfigHandler(service *service.TenantArticleTypeConfigService) *TenantArticleTypeConfigHandler {
+	return &TenantArticleTypeConfigHandler{
+		service: service,
+	}
+}
+
+// CreateConfig handles POST /api/v1/article-type-config
+func (h *TenantArticleTypeConfigHandler) CreateConfig(w http.ResponseWriter, r *http.Request) {
+	var config model.TenantArticleTypeConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID := getUserID(r)
+	if err := h.service.Create(r.Context(), getTenantID(r), &config, userID); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, config)
+}
+
+// GetConfig handles GET /api/v1/article-type-config
+func (h *TenantArticleTypeConfigHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	config, err := h.service.Get(r.Context(), getTenantID(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, config)
+}
+
+// UpdateConfig handles PUT /api/v1/article-type-config
+func (h *TenantArticleTypeConfigHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
+	config, err := h.service.Get(r.Context(), getTenantID(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Replace the configurable fields while keeping identity and audit fields
+	var req struct {
+		AllowedTypes      []model.ArticleType      `json:"allowedTypes"`
+		DisallowedTypes   []model.ArticleType      `json:"disallowedTypes"`
+		AllowAllTypes     bool                     `json:"allowAllTypes"`
+		CustomTypeConfigs []model.CustomTypeConfig `json:"customTypeConfigs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	config.AllowedTypes = req.AllowedTypes
+	config.DisallowedTypes = req.DisallowedTypes
+	config.AllowAllTypes = req.AllowAllTypes
+	config.CustomTypeConfigs = req.CustomTypeConfigs
+
+	if err := h.service.Update(r.Context(), getTenantID(r), config); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, config)
+}
+
+// DeleteConfig handles DELETE /api/v1/article-type-config
+func (h *TenantArticleTypeConfigHandler) DeleteConfig(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), getTenantID(r)); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Article type config deleted successfully"})
+}
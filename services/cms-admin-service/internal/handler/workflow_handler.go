@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+)
+
+// WorkflowHandler handles HTTP requests for editorial workflows
+type WorkflowHandler struct {
+	service *service.WorkflowService
+}
+
+// NewWorkflowHandler creates a new workflow handler
+func NewWorkflowHandler(service *service.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{
+		service: service,
+	}
+}
+
+// CreateWorkflow handles POST /api/v1/workflows
+func (h *WorkflowHandler) CreateWorkflow(w http.ResponseWriter, r *http.Request) {
+	var workflow model.Workflow
+	if err := json.NewDecoder(r.Body).Decode(&workflow); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.Create(r.Context(), getTenantID(r), &workflow, getUserID(r)); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, workflow)
+}
+
+// ListWorkflows handles GET /api/v1/workflows
+func (h *WorkflowHandler) ListWorkflows(w http.ResponseWriter, r *http.Request) {
+	workflows, err := h.service.FindAll(r.Context(), getTenantID(r))
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, workflows)
+}
+
+// GetWorkflow handles GET /api/v1/workflows/{id}
+func (h *WorkflowHandler) GetWorkflow(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
+	workflow, err := h.service.FindByID(r.Context(), getTenantID(r), id)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, workflow)
+}
+
+// UpdateWorkflow handles PUT /api/v1/workflows/{id}
+func (h *WorkflowHandler) UpdateWorkflow(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
+	workflow, err := h.service.FindByID(r.Context(), getTenantID(r), id)
+	if err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Replace the definition while keeping identity and audit fields
+	var req struct {
+		Name         string                     `json:"name"`
+		Description  string                     `json:"description"`
+		InitialState model.ArticleStatus        `json:"initialState"`
+		States       []model.WorkflowState      `json:"states"`
+		Transitions  []model.WorkflowTransition `json:"transitions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	workflow.Name = req.Name
+	workflow.Description = req.Description
+	workflow.InitialState = req.InitialState
+	workflow.States = req.States
+	workflow.Transitions = req.Transitions
+
+	if err := h.service.Update(r.Context(), getTenantID(r), workflow); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, workflow)
+}
+
+// DeleteWorkflow handles DELETE /api/v1/workflows/{id}
+func (h *WorkflowHandler) DeleteWorkflow(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r, "id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid workflow ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), getTenantID(r), id); err != nil {
+		respondServiceError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Workflow deleted successfully"})
+}
@@ -3,75 +3,61 @@ package middleware
 import (
 	"context"
 	"net/http"
-	"strings"
+
+	"github.com/vhvplatform/go-cms-service/pkg/auth"
 )
 
 // AuthMiddleware provides authentication middleware
 type AuthMiddleware struct {
-	// In production, this would integrate with the go-framework auth system
+	validator *auth.Validator
 }
 
 // NewAuthMiddleware creates a new auth middleware
-func NewAuthMiddleware() *AuthMiddleware {
-	return &AuthMiddleware{}
+func NewAuthMiddleware(validator *auth.Validator) *AuthMiddleware {
+	return &AuthMiddleware{
+		validator: validator,
+	}
 }
 
-// Authenticate validates the request and extracts user information
+// Authenticate validates the bearer token and extracts user information.
+// Requests without a valid token are rejected with 401.
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Extract token from Authorization header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			// For development/testing, allow requests without auth
-			ctx := context.WithValue(r.Context(), "userID", "guest")
-			ctx = context.WithValue(ctx, "userRole", "writer")
-			next.ServeHTTP(w, r.WithContext(ctx))
-			return
-		}
-
-		// Parse Bearer token
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Invalid authorization header", http.StatusUnauthorized)
-			return
-		}
-
-		token := parts[1]
-
-		// In production, validate token with JWT or framework auth
-		// For now, simple mock validation
-		userID, role := m.validateToken(token)
-		if userID == "" {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+		principal, err := m.validator.Authenticate(r)
+		if err != nil {
+			auth.Unauthorized(w, err)
 			return
 		}
 
 		// Add user info to context
-		ctx := context.WithValue(r.Context(), "userID", userID)
-		ctx = context.WithValue(ctx, "userRole", role)
+		ctx := auth.WithPrincipal(r.Context(), principal)
+		ctx = context.WithValue(ctx, "userID", principal.UserID)
+		ctx = context.WithValue(ctx, "userName", principal.UserName)
+		ctx = context.WithValue(ctx, "userRole", principal.Role)
+		ctx = context.WithValue(ctx, "groupIDs", principal.GroupIDs)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-// validateToken validates a token and returns user information
-// This is a mock implementation - in production, integrate with JWT/OAuth
-func (m *AuthMiddleware) validateToken(token string) (string, string) {
-	// Mock validation
-	if token == "test-token" {
-		return "user-123", "editor"
-	}
-	if token == "admin-token" {
-		return "admin-456", "moderator"
-	}
-	return "", ""
+// Optional authenticates requests that carry a bearer token and lets
+// anonymous requests through. A token that is present but invalid is still
+// rejected with 401.
+func (m *AuthMiddleware) Optional(next http.Handler) http.Handler {
+	authenticated := m.Authenticate(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
 }
 
 // RequireAuth is a convenience middleware that requires authentication
 func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		userID := r.Context().Value("userID")
-		if userID == nil || userID == "" {
+		if _, ok := auth.PrincipalFromContext(r.Context()); !ok {
 			http.Error(w, "Authentication required", http.StatusUnauthorized)
 			return
 		}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/vhvplatform/go-cms-service/pkg/auth"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TenantHeader is the request header used to select a tenant explicitly
+const TenantHeader = "X-Tenant-ID"
+
+// TenantMiddleware resolves the tenant a request operates on
+type TenantMiddleware struct {
+	tenantRepo *repository.TenantRepository
+}
+
+// NewTenantMiddleware creates a new tenant middleware
+func NewTenantMiddleware(tenantRepo *repository.TenantRepository) *TenantMiddleware {
+	return &TenantMiddleware{
+		tenantRepo: tenantRepo,
+	}
+}
+
+// Resolve determines the tenant from the authenticated principal, the
+// X-Tenant-ID header or the request host, in that order, and stores its ID
+// in the request context. A header that contradicts the principal's tenant
+// is rejected with 403, as are principals without a tenant unless they are
+// platform admins; requests that resolve to no tenant are rejected with 400.
+func (m *TenantMiddleware) Resolve(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		header := strings.TrimSpace(r.Header.Get(TenantHeader))
+
+		var tenant *model.Tenant
+		var err error
+
+		principal, authenticated := auth.PrincipalFromContext(ctx)
+		if authenticated && principal.TenantID == "" && model.Role(principal.Role) != model.RolePlatformAdmin {
+			http.Error(w, "Token is not bound to a tenant", http.StatusForbidden)
+			return
+		}
+
+		if authenticated && principal.TenantID != "" {
+			tenant, err = m.lookup(ctx, principal.TenantID)
+			if err == nil && header != "" && header != tenant.ID.Hex() && header != tenant.Code {
+				http.Error(w, "Tenant does not match token", http.StatusForbidden)
+				return
+			}
+		} else if header != "" {
+			tenant, err = m.lookup(ctx, header)
+		} else {
+			tenant, err = m.tenantRepo.FindByDomain(ctx, hostname(r.Host))
+		}
+
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				http.Error(w, "Unknown tenant", http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "Failed to resolve tenant", http.StatusInternalServerError)
+			return
+		}
+
+		if !tenant.IsActive {
+			http.Error(w, "Tenant is inactive", http.StatusForbidden)
+			return
+		}
+
+		ctx = context.WithValue(ctx, "tenantID", tenant.ID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// lookup finds a tenant by ObjectID hex or by code
+func (m *TenantMiddleware) lookup(ctx context.Context, ref string) (*model.Tenant, error) {
+	if id, err := primitive.ObjectIDFromHex(ref); err == nil {
+		return m.tenantRepo.FindByID(ctx, id)
+	}
+	return m.tenantRepo.FindByCode(ctx, ref)
+}
+
+// hostname strips the port from a request host
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ArticleVersionMigration starts articles saved before versioning at version
+// 1, so changes to them can name the version they are based on
+type ArticleVersionMigration struct{}
+
+// Up applies the migration
+func (m *ArticleVersionMigration) Up(ctx context.Context, db *mongo.Database) error {
+	log.Println("Running article version migration...")
+
+	unversioned := bson.M{"$or": []bson.M{
+		{"currentVersion": bson.M{"$exists": false}},
+		{"currentVersion": nil},
+		{"currentVersion": 0},
+	}}
+	result, err := db.Collection("articles").UpdateMany(ctx, unversioned, bson.M{"$set": bson.M{"currentVersion": 1}})
+	if err != nil {
+		return err
+	}
+	log.Printf("✓ Started %d articles at version 1", result.ModifiedCount)
+
+	log.Println("Article version migration completed successfully")
+	return nil
+}
+
+// Down reverts the migration
+func (m *ArticleVersionMigration) Down(ctx context.Context, db *mongo.Database) error {
+	// Version numbers handed out since cannot be told apart from the backfill
+	return nil
+}
@@ -0,0 +1,116 @@
+package migrations
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DailyStatsMigration merges daily view and traffic source documents that
+// were written twice for the same key before the keys were unique. It runs
+// before InitialMigration, whose unique indexes cannot be built over them.
+type DailyStatsMigration struct{}
+
+// dailyStatsCounters are the counters of an article_views document
+var dailyStatsCounters = []string{
+	"views", "uniqueViews",
+	"engagement.reads", "engagement.activeSeconds",
+	"engagement.depth0", "engagement.depth25", "engagement.depth50", "engagement.depth75", "engagement.depth100",
+	"engagement.mobile", "engagement.tablet", "engagement.desktop",
+}
+
+// Up applies the migration
+func (m *DailyStatsMigration) Up(ctx context.Context, db *mongo.Database) error {
+	log.Println("Running daily stats migration...")
+
+	merged, err := mergeDuplicates(ctx, db.Collection("article_views"), []string{"articleId", "date"}, dailyStatsCounters)
+	if err != nil {
+		return err
+	}
+	log.Printf("✓ Merged %d duplicate article_views documents", merged)
+
+	merged, err = mergeDuplicates(ctx, db.Collection("article_traffic_sources"), []string{"articleId", "date", "type", "value"}, []string{"count"})
+	if err != nil {
+		return err
+	}
+	log.Printf("✓ Merged %d duplicate article_traffic_sources documents", merged)
+
+	log.Println("Daily stats migration completed successfully")
+	return nil
+}
+
+// Down reverts the migration
+func (m *DailyStatsMigration) Down(ctx context.Context, db *mongo.Database) error {
+	// Merged documents cannot be split again
+	return nil
+}
+
+// mergeDuplicates folds the documents of coll that share the key fields into
+// the oldest of them, adding up their counters and keeping the batch IDs they
+// counted. It returns how many documents were merged away.
+func mergeDuplicates(ctx context.Context, coll *mongo.Collection, key, counters []string) (int, error) {
+	groupKey := bson.M{}
+	for _, field := range key {
+		groupKey[field] = "$" + field
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		{{Key: "$group", Value: bson.M{"_id": groupKey, "ids": bson.M{"$push": "$_id"}, "n": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"n": bson.M{"$gt": 1}}}},
+	}
+	cursor, err := coll.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	merged := 0
+	for cursor.Next(ctx) {
+		var group struct {
+			IDs []interface{} `bson:"ids"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return merged, err
+		}
+
+		kept := group.IDs[0]
+		for _, id := range group.IDs[1:] {
+			// The duplicate is removed before its counts are added, so a
+			// rerun after a failure in between may miss them but never
+			// counts them twice
+			var duplicate bson.Raw
+			if err := coll.FindOneAndDelete(ctx, bson.M{"_id": id}).Decode(&duplicate); err != nil {
+				return merged, err
+			}
+
+			inc := bson.M{}
+			for _, field := range counters {
+				if value, ok := duplicate.Lookup(strings.Split(field, ".")...).AsInt64OK(); ok && value != 0 {
+					inc[field] = value
+				}
+			}
+			update := bson.M{}
+			if len(inc) > 0 {
+				update["$inc"] = inc
+			}
+			if batchIDs, ok := duplicate.Lookup("batchIds").ArrayOK(); ok {
+				values, err := batchIDs.Values()
+				if err != nil {
+					return merged, err
+				}
+				update["$addToSet"] = bson.M{"batchIds": bson.M{"$each": values}}
+			}
+			if len(update) > 0 {
+				if _, err := coll.UpdateOne(ctx, bson.M{"_id": kept}, update); err != nil {
+					return merged, err
+				}
+			}
+			merged++
+		}
+	}
+	return merged, cursor.Err()
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -57,8 +58,95 @@ func (m *InitialMigration) Up(ctx context.Context, db *mongo.Database) error {
 	}
 	log.Println("✓ Created view stats indexes")
 
+	// Create indexes for tenants
+	tenantRepo := repository.NewTenantRepository(db)
+	if err := tenantRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	log.Println("✓ Created tenant indexes")
+
+	// Create indexes for tenant article type configs
+	typeConfigRepo := repository.NewTenantArticleTypeConfigRepository(db)
+	if err := typeConfigRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	log.Println("✓ Created tenant article type config indexes")
+
+	// Create indexes for article type schemas
+	schemaRepo := repository.NewArticleTypeSchemaRepository(db)
+	if err := schemaRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	log.Println("✓ Created article type schema indexes")
+
+	// Create indexes for workflows
+	workflowRepo := repository.NewWorkflowRepository(db)
+	if err := workflowRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	log.Println("✓ Created workflow indexes")
+
+	// Create indexes for polls and votes
+	pollRepo := repository.NewPollRepository(db)
+	if err := pollRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	log.Println("✓ Created poll indexes")
+
+	// Create indexes for sensitive keywords
+	keywordRepo := repository.NewSensitiveKeywordRepository(db)
+	if err := keywordRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	log.Println("✓ Created sensitive keyword indexes")
+
+	// Create indexes for AI operation logs and quota counters
+	aiLogRepo := repository.NewAIOperationLogRepository(db)
+	if err := aiLogRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	aiUsageRepo := repository.NewAIUsageCounterRepository(db)
+	if err := aiUsageRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	log.Println("✓ Created AI usage indexes")
+
+	// Create indexes for article edit locks
+	editLockRepo := repository.NewEditLockRepository(db)
+	if err := editLockRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	log.Println("✓ Created edit lock indexes")
+
+	// Create indexes for article preview links
+	previewLinkRepo := repository.NewPreviewLinkRepository(db)
+	if err := previewLinkRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	log.Println("✓ Created preview link indexes")
+
+	// Create indexes for the action log
+	actionLogRepo := repository.NewActionLogRepository(db)
+	if err := actionLogRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	log.Println("✓ Created action log indexes")
+
+	// Create indexes for statistics snapshots
+	snapshotRepo := repository.NewStatisticsSnapshotRepository(db)
+	if err := snapshotRepo.CreateIndexes(ctx); err != nil {
+		return err
+	}
+	log.Println("✓ Created statistics snapshot indexes")
+
+	// Ensure the default tenant exists
+	tenant, err := ensureDefaultTenant(ctx, tenantRepo)
+	if err != nil {
+		return err
+	}
+
 	// Seed sample categories
-	if err := m.seedCategories(ctx, categoryRepo); err != nil {
+	if err := m.seedCategories(ctx, categoryRepo, tenant.ID); err != nil {
 		return err
 	}
 	log.Println("✓ Seeded sample categories")
@@ -72,7 +160,7 @@ func (m *InitialMigration) Down(ctx context.Context, db *mongo.Database) error {
 	log.Println("Reverting initial migration...")
 
 	// Drop collections
-	collections := []string{"articles", "categories", "event_lines", "permissions", "article_views"}
+	collections := []string{"articles", "categories", "event_lines", "permissions", "article_views", "article_traffic_sources", "statistics_snapshots"}
 	for _, coll := range collections {
 		if err := db.Collection(coll).Drop(ctx); err != nil {
 			log.Printf("Warning: Failed to drop collection %s: %v", coll, err)
@@ -84,7 +172,7 @@ func (m *InitialMigration) Down(ctx context.Context, db *mongo.Database) error {
 }
 
 // seedCategories creates sample categories
-func (m *InitialMigration) seedCategories(ctx context.Context, repo *repository.CategoryRepository) error {
+func (m *InitialMigration) seedCategories(ctx context.Context, repo *repository.CategoryRepository, tenantID primitive.ObjectID) error {
 	categories := []*model.Category{
 		{
 			Name:         "News",
@@ -135,12 +223,13 @@ func (m *InitialMigration) seedCategories(ctx context.Context, repo *repository.
 
 	for _, category := range categories {
 		// Check if category already exists
-		existing, _ := repo.FindBySlug(ctx, category.Slug)
+		existing, _ := repo.FindBySlug(ctx, tenantID, category.Slug)
 		if existing != nil {
 			log.Printf("Category '%s' already exists, skipping", category.Name)
 			continue
 		}
 
+		category.TenantID = tenantID
 		if err := repo.Create(ctx, category); err != nil {
 			return err
 		}
@@ -153,7 +242,10 @@ func (m *InitialMigration) seedCategories(ctx context.Context, repo *repository.
 // RunMigrations runs all migrations
 func RunMigrations(ctx context.Context, db *mongo.Database) error {
 	migrations := []Migration{
+		&DailyStatsMigration{},
 		&InitialMigration{},
+		&TenantIsolationMigration{},
+		&ArticleVersionMigration{},
 	}
 
 	for i, migration := range migrations {
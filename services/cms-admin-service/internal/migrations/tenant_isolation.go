@@ -0,0 +1,91 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DefaultTenantCode is the code of the tenant that owns pre-tenancy data
+const DefaultTenantCode = "default"
+
+// TenantIsolationMigration assigns legacy articles and categories to the
+// default tenant and replaces global slug uniqueness with per-tenant uniqueness
+type TenantIsolationMigration struct{}
+
+// Up applies the migration
+func (m *TenantIsolationMigration) Up(ctx context.Context, db *mongo.Database) error {
+	log.Println("Running tenant isolation migration...")
+
+	tenant, err := ensureDefaultTenant(ctx, repository.NewTenantRepository(db))
+	if err != nil {
+		return err
+	}
+
+	// Documents without an owner belong to the default tenant
+	missingTenant := bson.M{"$or": []bson.M{
+		{"tenantId": bson.M{"$exists": false}},
+		{"tenantId": nil},
+		{"tenantId": primitive.NilObjectID},
+	}}
+	for _, coll := range []string{"articles", "categories"} {
+		result, err := db.Collection(coll).UpdateMany(ctx, missingTenant, bson.M{"$set": bson.M{"tenantId": tenant.ID}})
+		if err != nil {
+			return err
+		}
+		log.Printf("✓ Assigned %d %s to tenant '%s'", result.ModifiedCount, coll, tenant.Code)
+
+		// Slugs are now unique per tenant, see the repository indexes
+		if _, err := db.Collection(coll).Indexes().DropOne(ctx, "slug_1"); err != nil && !isIndexNotFound(err) {
+			return err
+		}
+	}
+
+	log.Println("Tenant isolation migration completed successfully")
+	return nil
+}
+
+// Down reverts the migration
+func (m *TenantIsolationMigration) Down(ctx context.Context, db *mongo.Database) error {
+	// Tenant ownership is kept; a global slug index cannot be restored safely
+	// once tenants share slugs.
+	return nil
+}
+
+// ensureDefaultTenant returns the default tenant, creating it if necessary
+func ensureDefaultTenant(ctx context.Context, repo *repository.TenantRepository) (*model.Tenant, error) {
+	tenant, err := repo.FindByCode(ctx, DefaultTenantCode)
+	if err == nil {
+		return tenant, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	tenant = &model.Tenant{
+		Name:        "Default",
+		Code:        DefaultTenantCode,
+		Description: "Default tenant",
+		Domains:     []string{"localhost"},
+		IsActive:    true,
+		CreatedBy:   "system",
+	}
+	if err := repo.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+	log.Printf("Created tenant: %s", tenant.Code)
+
+	return tenant, nil
+}
+
+// isIndexNotFound reports whether err is MongoDB's IndexNotFound error
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && (cmdErr.Code == 27 || cmdErr.Name == "IndexNotFound")
+}
@@ -10,15 +10,17 @@ import (
 type ActionType string
 
 const (
-	ActionTypeCreate    ActionType = "create"
-	ActionTypeUpdate    ActionType = "update"
-	ActionTypePublish   ActionType = "publish"
-	ActionTypeApprove   ActionType = "approve"
-	ActionTypeReject    ActionType = "reject"
-	ActionTypeDelete    ActionType = "delete"
-	ActionTypeRestore   ActionType = "restore"
-	ActionTypeArchive   ActionType = "archive"
-	ActionTypeUnarchive ActionType = "unarchive"
+	ActionTypeCreate     ActionType = "create"
+	ActionTypeUpdate     ActionType = "update"
+	ActionTypePublish    ActionType = "publish"
+	ActionTypeApprove    ActionType = "approve"
+	ActionTypeReject     ActionType = "reject"
+	ActionTypeDelete     ActionType = "delete"
+	ActionTypeRestore    ActionType = "restore"
+	ActionTypeArchive    ActionType = "archive"
+	ActionTypeUnarchive  ActionType = "unarchive"
+	ActionTypeTransition ActionType = "transition"
+	ActionTypeTakeLock   ActionType = "take_lock"
 )
 
 // ActionLog represents a log entry for actions performed on articles
@@ -36,5 +38,6 @@ type ActionLog struct {
 	UserAgent  string                 `json:"userAgent,omitempty" bson:"userAgent,omitempty"`
 	OldStatus  ArticleStatus          `json:"oldStatus,omitempty" bson:"oldStatus,omitempty"`
 	NewStatus  ArticleStatus          `json:"newStatus,omitempty" bson:"newStatus,omitempty"`
+	Transition string                 `json:"transition,omitempty" bson:"transition,omitempty"` // Workflow transition name
 	VersionID  *primitive.ObjectID    `json:"versionId,omitempty" bson:"versionId,omitempty"`
 }
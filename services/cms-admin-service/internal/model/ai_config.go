@@ -27,19 +27,57 @@ type AIConfiguration struct {
 	APIEndpoint string `bson:"api_endpoint,omitempty" json:"apiEndpoint,omitempty"`
 	Model       string `bson:"model,omitempty" json:"model,omitempty"` // e.g., gpt-4, claude-3
 
+	// Providers overrides the API configuration above for individual
+	// providers, keyed by provider name, e.g. when spell checking uses
+	// LanguageTool and translation uses DeepL
+	Providers map[string]AIProviderSettings `bson:"providers,omitempty" json:"providers,omitempty"`
+
 	// Advanced settings
 	MaxTokens          int      `bson:"max_tokens,omitempty" json:"maxTokens,omitempty"`
 	Temperature        float64  `bson:"temperature,omitempty" json:"temperature,omitempty"`
 	SupportedLanguages []string `bson:"supported_languages,omitempty" json:"supportedLanguages,omitempty"`
 
-	// Usage limits
-	DailyLimit   int `bson:"daily_limit,omitempty" json:"dailyLimit,omitempty"`
-	MonthlyLimit int `bson:"monthly_limit,omitempty" json:"monthlyLimit,omitempty"`
+	// Usage limits, in calls per UTC day or month; 0 means unlimited. The user
+	// limits apply to each user separately.
+	DailyLimit       int `bson:"daily_limit,omitempty" json:"dailyLimit,omitempty"`
+	MonthlyLimit     int `bson:"monthly_limit,omitempty" json:"monthlyLimit,omitempty"`
+	UserDailyLimit   int `bson:"user_daily_limit,omitempty" json:"userDailyLimit,omitempty"`
+	UserMonthlyLimit int `bson:"user_monthly_limit,omitempty" json:"userMonthlyLimit,omitempty"`
+
+	// Prices is the price table used to compute the cost of each call, keyed
+	// by model name, or by provider name for providers without models
+	Prices map[string]AIPrice `bson:"prices,omitempty" json:"prices,omitempty"`
 
 	CreatedAt time.Time `bson:"created_at" json:"createdAt"`
 	UpdatedAt time.Time `bson:"updated_at" json:"updatedAt"`
 }
 
+// AIProviderSettings holds the API configuration of one provider
+type AIProviderSettings struct {
+	APIKey      string `bson:"api_key,omitempty" json:"apiKey,omitempty"`
+	APIEndpoint string `bson:"api_endpoint,omitempty" json:"apiEndpoint,omitempty"`
+	Model       string `bson:"model,omitempty" json:"model,omitempty"`
+}
+
+// AIPrice is the price of a model or provider, per million units
+type AIPrice struct {
+	InputTokens  float64 `bson:"input_tokens,omitempty" json:"inputTokens,omitempty"`
+	OutputTokens float64 `bson:"output_tokens,omitempty" json:"outputTokens,omitempty"`
+	Characters   float64 `bson:"characters,omitempty" json:"characters,omitempty"`
+}
+
+// AIUsageCounter counts the AI calls of a tenant, or of one of its users,
+// in a day or month
+type AIUsageCounter struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID    string             `bson:"tenant_id" json:"tenantId"`
+	UserID      string             `bson:"user_id" json:"userId"` // Empty for tenant-wide counters
+	Period      string             `bson:"period" json:"period"`  // day, month
+	PeriodStart time.Time          `bson:"period_start" json:"periodStart"`
+	Count       int                `bson:"count" json:"count"`
+	ExpiresAt   time.Time          `bson:"expires_at" json:"expiresAt"`
+}
+
 // AIOperationLog represents a log of AI operations
 type AIOperationLog struct {
 	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
@@ -48,6 +86,7 @@ type AIOperationLog struct {
 	ArticleID  string             `bson:"article_id,omitempty" json:"articleId,omitempty"`
 	Operation  string             `bson:"operation" json:"operation"` // spell_check, translate, edit, detect_violation
 	Provider   string             `bson:"provider" json:"provider"`
+	Model      string             `bson:"model,omitempty" json:"model,omitempty"`
 	InputText  string             `bson:"input_text" json:"inputText"`
 	OutputText string             `bson:"output_text,omitempty" json:"outputText,omitempty"`
 	SourceLang string             `bson:"source_lang,omitempty" json:"sourceLang,omitempty"`
@@ -71,9 +110,10 @@ type SpellCheckResult struct {
 type SpellCorrection struct {
 	Word        string   `json:"word"`
 	Suggestions []string `json:"suggestions"`
-	Position    int      `json:"position"`
-	Length      int      `json:"length"`
-	Type        string   `json:"type"` // spelling, grammar
+	Position    int      `json:"position"` // Rune offset in the checked text
+	Length      int      `json:"length"`   // Length in runes
+	Type        string   `json:"type"`     // spelling, grammar
+	Message     string   `json:"message,omitempty"`
 }
 
 // TranslationResult represents translation result
@@ -101,3 +141,33 @@ type ViolationDetectionResult struct {
 	Explanation   string   `json:"explanation"`
 	Suggestions   []string `json:"suggestions"` // How to fix
 }
+
+// AIUsageSummary sums the AI calls in a usage report group
+type AIUsageSummary struct {
+	Key           string  `bson:"_id" json:"key,omitempty"` // Operation, provider or user ID
+	Calls         int     `bson:"calls" json:"calls"`
+	Failed        int     `bson:"failed" json:"failed"`
+	TokensUsed    int     `bson:"tokens_used" json:"tokensUsed"`
+	Cost          float64 `bson:"cost" json:"cost"`
+	AvgDurationMs float64 `bson:"avg_duration_ms" json:"avgDurationMs"`
+}
+
+// AIQuotaUsage reports the use of a quota in the current period
+type AIQuotaUsage struct {
+	Scope    string    `json:"scope"` // tenant or user, daily or monthly
+	Limit    int       `json:"limit"`
+	Used     int       `json:"used"`
+	ResetsAt time.Time `json:"resetsAt"`
+}
+
+// AIUsageReport summarises the AI calls of a tenant over a period
+type AIUsageReport struct {
+	From        time.Time        `json:"from"`
+	To          time.Time        `json:"to"`
+	UserID      string           `json:"userId,omitempty"`
+	Totals      AIUsageSummary   `json:"totals"`
+	ByOperation []AIUsageSummary `json:"byOperation"`
+	ByProvider  []AIUsageSummary `json:"byProvider"`
+	ByUser      []AIUsageSummary `json:"byUser"`
+	Quotas      []AIQuotaUsage   `json:"quotas"`
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -130,8 +132,10 @@ type Article struct {
 	Attachments   []Attachment           `json:"attachments" bson:"attachments"`
 	Featured      bool                   `json:"featured" bson:"featured"`
 	Hot           bool                   `json:"hot" bson:"hot"`
+	HotLocked     bool                   `json:"hotLocked" bson:"hotLocked"` // Hot was set by an editor; the trending worker leaves it alone
 	Ordering      int                    `json:"ordering" bson:"ordering"`
 	PublishAt     time.Time              `json:"publishAt" bson:"publishAt"`
+	Schedule      *PublishSchedule       `json:"schedule,omitempty" bson:"schedule"` // Set when publication was approved ahead of PublishAt
 	CreatedAt     time.Time              `json:"createdAt" bson:"createdAt"`
 	UpdatedAt     time.Time              `json:"updatedAt" bson:"updatedAt"`
 	PublishedBy   string                 `json:"publishedBy" bson:"publishedBy"`
@@ -143,6 +147,7 @@ type Article struct {
 	CharCount     int                    `json:"charCount" bson:"charCount"`
 	ImageCount    int                    `json:"imageCount" bson:"imageCount"`
 	ViewCount     int                    `json:"viewCount" bson:"viewCount"`
+	TrendingScore float64                `json:"trendingScore" bson:"trendingScore"` // Time-decayed views, comments, likes and favourites
 	ReadingStats  *ReadingStats          `json:"readingStats,omitempty" bson:"readingStats,omitempty"`
 	AccessControl AccessControl          `json:"accessControl" bson:"accessControl"` // Access control settings
 
@@ -156,6 +161,9 @@ type Article struct {
 	HasPoll bool                `json:"hasPoll" bson:"hasPoll"`                   // Indicates if article has a poll
 	PollID  *primitive.ObjectID `json:"pollId,omitempty" bson:"pollId,omitempty"` // Reference to poll
 
+	// Sensitive keyword matches from the last save, returned to the caller only
+	ContentWarnings []KeywordDetectionResult `json:"contentWarnings,omitempty" bson:"-"`
+
 	// Type-specific fields
 	// Video
 	VideoURL  string `json:"videoUrl,omitempty" bson:"videoUrl,omitempty"`
@@ -188,12 +196,49 @@ type Article struct {
 	Organizer  string     `json:"organizer,omitempty" bson:"organizer,omitempty"`
 }
 
-// ArticleView represents daily view statistics
+// PublishSchedule records the approval of an article's publication ahead of
+// its PublishAt. The scheduler only publishes approved articles, and any
+// status change withdraws the approval.
+type PublishSchedule struct {
+	Transition string    `json:"transition" bson:"transition"` // The publishing transition that was approved
+	ApprovedBy string    `json:"approvedBy" bson:"approvedBy"`
+	ApprovedAt time.Time `json:"approvedAt" bson:"approvedAt"`
+}
+
+// ArticleView represents daily view statistics. Views counts every view by a
+// person; UniqueViews leaves out repeat views by the same visitor within the
+// deduplication window. Crawlers are not counted.
 type ArticleView struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	ArticleID primitive.ObjectID `json:"articleId" bson:"articleId"`
-	Date      time.Time          `json:"date" bson:"date"`
-	Views     int                `json:"views" bson:"views"`
-	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
-	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ArticleID   primitive.ObjectID `json:"articleId" bson:"articleId"`
+	Date        time.Time          `json:"date" bson:"date"`
+	Views       int                `json:"views" bson:"views"`
+	UniqueViews int                `json:"uniqueViews" bson:"uniqueViews"`
+	Engagement  EngagementCounts   `json:"engagement" bson:"engagement"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// ViewEvent is a view of an article waiting to be counted
+type ViewEvent struct {
+	ArticleID primitive.ObjectID
+	Timestamp time.Time
+	Unique    bool // First view by the visitor within the deduplication window
+}
+
+// Visitor identifies who viewed an article
+type Visitor struct {
+	UserID    string
+	IP        string
+	UserAgent string
+}
+
+// Fingerprint identifies the visitor by user ID, or for anonymous visitors
+// by a hash of the IP address and User-Agent
+func (v Visitor) Fingerprint() string {
+	if v.UserID != "" {
+		return "user:" + v.UserID
+	}
+	sum := sha256.Sum256([]byte(v.IP + "\n" + v.UserAgent))
+	return "anon:" + hex.EncodeToString(sum[:16])
 }
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ArticleTypeSchema is a tenant-registered JSON Schema that articles of a type must satisfy.
+// The schema is applied to the article's JSON representation, so it can constrain
+// customFields as well as type-specific fields such as lawNumber or eventStart.
+type ArticleTypeSchema struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TenantID    primitive.ObjectID `json:"tenantId" bson:"tenantId"`
+	ArticleType ArticleType        `json:"articleType" bson:"articleType"`
+	Schema      json.RawMessage    `json:"schema" bson:"schema"` // Raw JSON Schema document, stored verbatim
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
+	CreatedBy   string             `json:"createdBy" bson:"createdBy"`
+	UpdatedBy   string             `json:"updatedBy" bson:"updatedBy"`
+}
@@ -32,3 +32,67 @@ type ArticleVersion struct {
 	ChangeNote   string `json:"changeNote,omitempty" bson:"changeNote,omitempty"`
 	RestoredFrom *int   `json:"restoredFrom,omitempty" bson:"restoredFrom,omitempty"` // Version number this was restored from
 }
+
+// ChangeType describes how a field differs between two versions
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// VersionDiff lists the fields that differ between two versions of an article
+type VersionDiff struct {
+	ArticleID   primitive.ObjectID `json:"articleId"`
+	FromVersion int                `json:"fromVersion"`
+	ToVersion   int                `json:"toVersion"`
+	Changes     []FieldChange      `json:"changes"`
+}
+
+// FieldChange describes the change of one field. Field is a dotted path such
+// as "title", "seo.description" or "customFields.price".
+type FieldChange struct {
+	Field    string        `json:"field"`
+	Type     ChangeType    `json:"type"`
+	Old      interface{}   `json:"old,omitempty"`
+	New      interface{}   `json:"new,omitempty"`
+	Added    []string      `json:"added,omitempty"`    // Tags and keywords
+	Removed  []string      `json:"removed,omitempty"`  // Tags and keywords
+	Segments []TextSegment `json:"segments,omitempty"` // Word-level diff of text
+	Blocks   []BlockChange `json:"blocks,omitempty"`   // Content block changes
+}
+
+// TextSegment is a run of text that is unchanged, inserted or deleted
+type TextSegment struct {
+	Op   string `json:"op"` // equal, insert, delete
+	Text string `json:"text"`
+}
+
+// BlockChange describes an added, removed or modified content block. The
+// indexes refer to the block lists of the old and new version.
+type BlockChange struct {
+	Type     ChangeType    `json:"type"`
+	OldIndex *int          `json:"oldIndex,omitempty"`
+	NewIndex *int          `json:"newIndex,omitempty"`
+	Old      *ContentBlock `json:"old,omitempty"`
+	New      *ContentBlock `json:"new,omitempty"`
+	Segments []TextSegment `json:"segments,omitempty"`
+}
+
+// FieldConflict is a field that the editor and someone else both changed
+type FieldConflict struct {
+	Field   string      `json:"field"`
+	Base    interface{} `json:"base"`
+	Current interface{} `json:"current"`
+	Edited  interface{} `json:"edited"`
+}
+
+// MergeResult is the outcome of merging an edited copy of an article with
+// the changes saved since the version it was based on
+type MergeResult struct {
+	Article     *Article        `json:"article"`
+	BaseVersion int             `json:"baseVersion"`
+	Conflicts   []FieldConflict `json:"conflicts,omitempty"`
+	Saved       bool            `json:"saved"`
+}
@@ -17,6 +17,7 @@ const (
 // Category represents a category in the tree structure
 type Category struct {
 	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	TenantID     primitive.ObjectID  `json:"tenantId" bson:"tenantId"` // Tenant ownership
 	Name         string              `json:"name" bson:"name"`
 	Slug         string              `json:"slug" bson:"slug"`
 	Description  string              `json:"description" bson:"description"`
@@ -35,6 +35,9 @@ type Comment struct {
 	ModeratedBy    string              `json:"moderatedBy,omitempty" bson:"moderatedBy,omitempty"`
 	ModeratedAt    *time.Time          `json:"moderatedAt,omitempty" bson:"moderatedAt,omitempty"`
 	ModerationNote string              `json:"moderationNote,omitempty" bson:"moderationNote,omitempty"`
+
+	// Sensitive keyword matches, returned to the author only
+	ContentWarnings []KeywordDetectionResult `json:"contentWarnings,omitempty" bson:"-"`
 }
 
 // CommentLike represents a user's like on a comment
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EditLock is an advisory lease on editing an article. The holder keeps it by
+// renewing it before it expires; while it is active only the holder can save
+// the article.
+type EditLock struct {
+	ArticleID  primitive.ObjectID `json:"articleId" bson:"_id"`
+	TenantID   primitive.ObjectID `json:"tenantId" bson:"tenantId"`
+	UserID     string             `json:"userId" bson:"userId"`
+	UserName   string             `json:"userName" bson:"userName"`
+	UserRole   Role               `json:"userRole" bson:"userRole"`
+	AcquiredAt time.Time          `json:"acquiredAt" bson:"acquiredAt"`
+	ExpiresAt  time.Time          `json:"expiresAt" bson:"expiresAt"`
+}
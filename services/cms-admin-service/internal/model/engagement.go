@@ -0,0 +1,148 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeviceClass is the kind of device an article was read on
+type DeviceClass string
+
+const (
+	DeviceMobile  DeviceClass = "mobile"
+	DeviceTablet  DeviceClass = "tablet"
+	DeviceDesktop DeviceClass = "desktop"
+)
+
+// EngagementBeacon is what a reader's browser reports once per page view,
+// when the reader leaves the article
+type EngagementBeacon struct {
+	ArticleID     primitive.ObjectID `json:"articleId"`
+	ScrollDepth   int                `json:"scrollDepth"`   // Furthest point reached, in percent
+	ActiveSeconds int                `json:"activeSeconds"` // Time the page was visible and in use
+	Referrer      string             `json:"referrer"`      // document.referrer
+	UTMSource     string             `json:"utmSource"`
+	UTMMedium     string             `json:"utmMedium"`
+	UTMCampaign   string             `json:"utmCampaign"`
+	DeviceClass   DeviceClass        `json:"deviceClass"` // Optional, detected from the user agent otherwise
+}
+
+// EngagementEvent is a normalized beacon waiting to be counted
+type EngagementEvent struct {
+	ArticleID     primitive.ObjectID
+	Timestamp     time.Time
+	ScrollDepth   int // Bucket: 0, 25, 50, 75 or 100 percent
+	ActiveSeconds int
+	Device        DeviceClass
+	Referrer      string // Referring host, or DirectTraffic
+	UTMSource     string
+	UTMMedium     string
+	UTMCampaign   string
+}
+
+// Sources lists the referrer and the UTM values the event came from
+func (e EngagementEvent) Sources() map[TrafficSourceType]string {
+	sources := make(map[TrafficSourceType]string, 4)
+	for sourceType, value := range map[TrafficSourceType]string{
+		TrafficReferrer:    e.Referrer,
+		TrafficUTMSource:   e.UTMSource,
+		TrafficUTMMedium:   e.UTMMedium,
+		TrafficUTMCampaign: e.UTMCampaign,
+	} {
+		if value != "" {
+			sources[sourceType] = value
+		}
+	}
+	return sources
+}
+
+// EngagementCounts holds the engagement totals of an article on one day
+type EngagementCounts struct {
+	Reads         int `json:"reads" bson:"reads"` // Beacons received
+	ActiveSeconds int `json:"activeSeconds" bson:"activeSeconds"`
+	Depth0        int `json:"depth0" bson:"depth0"`
+	Depth25       int `json:"depth25" bson:"depth25"`
+	Depth50       int `json:"depth50" bson:"depth50"`
+	Depth75       int `json:"depth75" bson:"depth75"`
+	Depth100      int `json:"depth100" bson:"depth100"` // Completed reads
+	Mobile        int `json:"mobile" bson:"mobile"`
+	Tablet        int `json:"tablet" bson:"tablet"`
+	Desktop       int `json:"desktop" bson:"desktop"`
+}
+
+// Add counts one engagement event
+func (c *EngagementCounts) Add(event EngagementEvent) {
+	c.Reads++
+	c.ActiveSeconds += event.ActiveSeconds
+	switch event.ScrollDepth {
+	case 100:
+		c.Depth100++
+	case 75:
+		c.Depth75++
+	case 50:
+		c.Depth50++
+	case 25:
+		c.Depth25++
+	default:
+		c.Depth0++
+	}
+	switch event.Device {
+	case DeviceMobile:
+		c.Mobile++
+	case DeviceTablet:
+		c.Tablet++
+	default:
+		c.Desktop++
+	}
+}
+
+// TrafficSourceType is the kind of a traffic source
+type TrafficSourceType string
+
+const (
+	TrafficReferrer    TrafficSourceType = "referrer"
+	TrafficUTMSource   TrafficSourceType = "utm_source"
+	TrafficUTMMedium   TrafficSourceType = "utm_medium"
+	TrafficUTMCampaign TrafficSourceType = "utm_campaign"
+)
+
+// DirectTraffic is the referrer recorded for reads without one
+const DirectTraffic = "(direct)"
+
+// TrafficSource counts the reads of an article on one day that came from one
+// referrer or UTM value
+type TrafficSource struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ArticleID primitive.ObjectID `json:"articleId" bson:"articleId"`
+	Date      time.Time          `json:"date" bson:"date"`
+	Type      TrafficSourceType  `json:"type" bson:"type"`
+	Value     string             `json:"value" bson:"value"`
+	Count     int                `json:"count" bson:"count"`
+}
+
+// SourceCount is a traffic source and its number of reads
+type SourceCount struct {
+	Value string `json:"value" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+// EngagementStatistics summarizes how an article, or the articles of a
+// category or author, were read over a period
+type EngagementStatistics struct {
+	Scope           string           `json:"scope"` // article, category, author
+	ID              string           `json:"id"`
+	Views           int64            `json:"views"`
+	UniqueViews     int64            `json:"uniqueViews"`
+	Reads           int64            `json:"reads"`
+	AverageReadTime float64          `json:"averageReadTime"` // Seconds of active time per read
+	CompletionRate  float64          `json:"completionRate"`  // Share of reads that reached the end
+	ScrollDepth     map[string]int64 `json:"scrollDepth"`     // Reads per furthest bucket reached
+	Devices         map[string]int64 `json:"devices"`
+	TopReferrers    []*SourceCount   `json:"topReferrers"`
+	TopUTMSources   []*SourceCount   `json:"topUtmSources"`
+	TopUTMMediums   []*SourceCount   `json:"topUtmMediums"`
+	TopUTMCampaigns []*SourceCount   `json:"topUtmCampaigns"`
+	Period          StatisticsPeriod `json:"period"`
+	GeneratedAt     time.Time        `json:"generatedAt"`
+}
@@ -13,6 +13,10 @@ const (
 	RoleWriter    Role = "writer"
 	RoleEditor    Role = "editor"
 	RoleModerator Role = "moderator"
+
+	// RolePlatformAdmin operates the platform itself; its tokens carry no
+	// tenant and select the tenant to act on with the X-Tenant-ID header
+	RolePlatformAdmin Role = "platform_admin"
 )
 
 // ResourceType represents the type of resource for permissions
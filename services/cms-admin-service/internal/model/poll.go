@@ -9,6 +9,7 @@ import (
 // Poll represents a poll/survey within an article
 type Poll struct {
 	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TenantID      primitive.ObjectID `json:"tenantId" bson:"tenantId"`
 	ArticleID     primitive.ObjectID `json:"articleId" bson:"articleId"`
 	Question      string             `json:"question" bson:"question"`
 	Options       []PollOption       `json:"options" bson:"options"`
@@ -21,6 +22,15 @@ type Poll struct {
 	CreatedAt     time.Time          `json:"createdAt" bson:"createdAt"`
 	UpdatedAt     time.Time          `json:"updatedAt" bson:"updatedAt"`
 	CreatedBy     string             `json:"createdBy" bson:"createdBy"`
+	IsOpen        bool               `json:"isOpen" bson:"-"` // Calculated field, whether votes are accepted now
+}
+
+// AcceptsVotes reports whether the poll is active and within its voting period
+func (p *Poll) AcceptsVotes(now time.Time) bool {
+	if !p.IsActive || now.Before(p.StartDate) {
+		return false
+	}
+	return p.EndDate == nil || now.Before(*p.EndDate)
 }
 
 // PollOption represents an option in a poll
@@ -35,8 +45,8 @@ type PollOption struct {
 type PollVote struct {
 	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	PollID    primitive.ObjectID `json:"pollId" bson:"pollId"`
-	UserID    string             `json:"userId" bson:"userId"`
+	UserID    string             `json:"userId" bson:"userId"`       // Empty for anonymous votes
 	OptionIDs []string           `json:"optionIds" bson:"optionIds"` // Support multiple selections
 	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
-	IPAddress string             `json:"ipAddress,omitempty" bson:"ipAddress,omitempty"` // For tracking
+	IPAddress string             `json:"ipAddress,omitempty" bson:"ipAddress,omitempty"` // Limits anonymous votes to one per address
 }
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PreviewLink records a preview token minted for one version of an article.
+// The token itself is only returned when the link is created; deleting the
+// record revokes it.
+type PreviewLink struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TenantID  primitive.ObjectID `json:"tenantId" bson:"tenantId"`
+	ArticleID primitive.ObjectID `json:"articleId" bson:"articleId"`
+	Version   int                `json:"version" bson:"version"`
+	CreatedBy string             `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	ExpiresAt time.Time          `json:"expiresAt" bson:"expiresAt"`
+
+	Token string `json:"token,omitempty" bson:"-"`
+	URL   string `json:"url,omitempty" bson:"-"`
+}
@@ -6,6 +6,21 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Keyword actions, from least to most strict
+const (
+	KeywordActionWarn   = "warn"   // Report matches to the caller
+	KeywordActionReview = "review" // Hold the content for moderation
+	KeywordActionBlock  = "block"  // Refuse the content
+)
+
+// Keyword severities, from lowest to highest
+const (
+	KeywordSeverityLow      = "low"
+	KeywordSeverityMedium   = "medium"
+	KeywordSeverityHigh     = "high"
+	KeywordSeverityCritical = "critical"
+)
+
 // SensitiveKeyword represents a sensitive keyword or pattern to detect in content
 type SensitiveKeyword struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
@@ -16,17 +31,23 @@ type SensitiveKeyword struct {
 	Action      string             `bson:"action" json:"action"`     // warn, block, review
 	Description string             `bson:"description,omitempty" json:"description,omitempty"`
 	Category    string             `bson:"category,omitempty" json:"category,omitempty"` // e.g., profanity, violence, political
-	IsActive    bool               `bson:"is_active" json:"isActive"`
-	CreatedBy   string             `bson:"created_by" json:"createdBy"`
-	CreatedAt   time.Time          `bson:"created_at" json:"createdAt"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updatedAt"`
+	// WholeWord only matches when the keyword is not part of a longer word
+	WholeWord bool `bson:"whole_word" json:"wholeWord"`
+	// IgnoreDiacritics matches with or without Vietnamese diacritics, so
+	// "ma tuy" also matches "ma túy"
+	IgnoreDiacritics bool      `bson:"ignore_diacritics" json:"ignoreDiacritics"`
+	IsActive         bool      `bson:"is_active" json:"isActive"`
+	CreatedBy        string    `bson:"created_by" json:"createdBy"`
+	CreatedAt        time.Time `bson:"created_at" json:"createdAt"`
+	UpdatedAt        time.Time `bson:"updated_at" json:"updatedAt"`
 }
 
 // KeywordDetectionResult represents the result of keyword detection in content
 type KeywordDetectionResult struct {
+	Field       string   `json:"field,omitempty"` // Scanned field, e.g. "title" or "contentBlocks[2].content"
 	Keyword     string   `json:"keyword"`
 	Matches     []string `json:"matches"`   // Actual matched text
-	Positions   []int    `json:"positions"` // Position in content
+	Positions   []int    `json:"positions"` // Rune offset of each match in the field
 	Severity    string   `json:"severity"`
 	Action      string   `json:"action"`
 	Category    string   `json:"category"`
@@ -38,6 +59,6 @@ type ContentScanResult struct {
 	HasViolations     bool                     `json:"hasViolations"`
 	Results           []KeywordDetectionResult `json:"results"`
 	HighestSeverity   string                   `json:"highestSeverity"`
-	RecommendedAction string                   `json:"recommendedAction"` // warn, block, review
+	RecommendedAction string                   `json:"recommendedAction"` // Strictest action matched: warn, review or block
 	ScannedAt         time.Time                `json:"scannedAt"`
 }
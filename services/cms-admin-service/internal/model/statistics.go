@@ -8,66 +8,91 @@ import (
 
 // ArticleStatistics represents aggregated statistics for articles
 type ArticleStatistics struct {
-	TotalArticles      int64                 `json:"totalArticles"`
-	PublishedArticles  int64                 `json:"publishedArticles"`
-	DraftArticles      int64                 `json:"draftArticles"`
-	PendingArticles    int64                 `json:"pendingArticles"`
-	ArchivedArticles   int64                 `json:"archivedArticles"`
-	TotalViews         int64                 `json:"totalViews"`
-	ArticlesByType     map[string]int64      `json:"articlesByType"`
-	ArticlesByCategory map[string]int64      `json:"articlesByCategory"`
-	ArticlesByAuthor   map[string]int64      `json:"articlesByAuthor"`
-	RecentArticles     []*Article            `json:"recentArticles,omitempty"`
-	TopViewedArticles  []*ArticleViewSummary `json:"topViewedArticles"`
-	Period             StatisticsPeriod      `json:"period"`
-	GeneratedAt        time.Time             `json:"generatedAt"`
+	TotalArticles      int64                 `json:"totalArticles" bson:"totalArticles"`
+	PublishedArticles  int64                 `json:"publishedArticles" bson:"publishedArticles"`
+	DraftArticles      int64                 `json:"draftArticles" bson:"draftArticles"`
+	PendingArticles    int64                 `json:"pendingArticles" bson:"pendingArticles"`
+	ArchivedArticles   int64                 `json:"archivedArticles" bson:"archivedArticles"`
+	TotalViews         int64                 `json:"totalViews" bson:"totalViews"`
+	TotalUniqueViews   int64                 `json:"totalUniqueViews" bson:"totalUniqueViews"`
+	ArticlesByType     map[string]int64      `json:"articlesByType" bson:"articlesByType"`
+	ArticlesByCategory map[string]int64      `json:"articlesByCategory" bson:"articlesByCategory"`
+	ArticlesByAuthor   map[string]int64      `json:"articlesByAuthor" bson:"articlesByAuthor"`
+	RecentArticles     []*Article            `json:"recentArticles,omitempty" bson:"-"`
+	TopViewedArticles  []*ArticleViewSummary `json:"topViewedArticles" bson:"topViewedArticles"`
+	Period             StatisticsPeriod      `json:"period" bson:"period"`
+	GeneratedAt        time.Time             `json:"generatedAt" bson:"generatedAt"`
 }
 
 // ArticleViewSummary represents a summary of article views
 type ArticleViewSummary struct {
-	ArticleID   primitive.ObjectID `json:"articleId"`
-	Title       string             `json:"title"`
-	Slug        string             `json:"slug"`
-	ArticleType ArticleType        `json:"articleType"`
-	ViewCount   int64              `json:"viewCount"`
-	CategoryID  primitive.ObjectID `json:"categoryId"`
+	ArticleID   primitive.ObjectID `json:"articleId" bson:"articleId"`
+	Title       string             `json:"title" bson:"title"`
+	Slug        string             `json:"slug" bson:"slug"`
+	ArticleType ArticleType        `json:"articleType" bson:"articleType"`
+	ViewCount   int64              `json:"viewCount" bson:"viewCount"`
+	UniqueViews int64              `json:"uniqueViews" bson:"uniqueViews"`
+	CategoryID  primitive.ObjectID `json:"categoryId" bson:"categoryId"`
 }
 
 // CategoryStatistics represents statistics for a category
 type CategoryStatistics struct {
-	CategoryID     primitive.ObjectID `json:"categoryId"`
-	CategoryName   string             `json:"categoryName"`
-	TotalArticles  int64              `json:"totalArticles"`
-	PublishedCount int64              `json:"publishedCount"`
-	TotalViews     int64              `json:"totalViews"`
-	AverageViews   float64            `json:"averageViews"`
-	LastUpdated    time.Time          `json:"lastUpdated"`
+	CategoryID     primitive.ObjectID `json:"categoryId" bson:"categoryId"`
+	CategoryName   string             `json:"categoryName" bson:"categoryName"`
+	TotalArticles  int64              `json:"totalArticles" bson:"totalArticles"`
+	PublishedCount int64              `json:"publishedCount" bson:"publishedCount"`
+	TotalViews     int64              `json:"totalViews" bson:"totalViews"`
+	UniqueViews    int64              `json:"uniqueViews" bson:"uniqueViews"`
+	AverageViews   float64            `json:"averageViews" bson:"averageViews"`
+	LastUpdated    time.Time          `json:"lastUpdated" bson:"lastUpdated"`
 }
 
 // AuthorStatistics represents statistics for an author
 type AuthorStatistics struct {
-	AuthorID       string    `json:"authorId"`
-	AuthorName     string    `json:"authorName"`
-	TotalArticles  int64     `json:"totalArticles"`
-	PublishedCount int64     `json:"publishedCount"`
-	DraftCount     int64     `json:"draftCount"`
-	TotalViews     int64     `json:"totalViews"`
-	AverageViews   float64   `json:"averageViews"`
-	LastPublished  time.Time `json:"lastPublished"`
+	AuthorID       string    `json:"authorId" bson:"authorId"`
+	AuthorName     string    `json:"authorName" bson:"authorName"`
+	TotalArticles  int64     `json:"totalArticles" bson:"totalArticles"`
+	PublishedCount int64     `json:"publishedCount" bson:"publishedCount"`
+	DraftCount     int64     `json:"draftCount" bson:"draftCount"`
+	TotalViews     int64     `json:"totalViews" bson:"totalViews"`
+	AverageViews   float64   `json:"averageViews" bson:"averageViews"`
+	LastPublished  time.Time `json:"lastPublished" bson:"lastPublished"`
 }
 
 // ViewTrendData represents view trends over time
 type ViewTrendData struct {
-	Date      time.Time `json:"date"`
-	ViewCount int64     `json:"viewCount"`
-	Articles  int64     `json:"articles"`
+	Date        time.Time `json:"date" bson:"date"`
+	ViewCount   int64     `json:"viewCount" bson:"viewCount"`
+	UniqueViews int64     `json:"uniqueViews" bson:"uniqueViews"`
+	Articles    int64     `json:"articles" bson:"articles"`
 }
 
 // StatisticsPeriod represents a time period for statistics
 type StatisticsPeriod struct {
-	StartDate time.Time `json:"startDate"`
-	EndDate   time.Time `json:"endDate"`
-	Type      string    `json:"type"` // daily, weekly, monthly, yearly, custom
+	StartDate time.Time `json:"startDate" bson:"startDate"`
+	EndDate   time.Time `json:"endDate" bson:"endDate"`
+	Type      string    `json:"type" bson:"type"` // daily, weekly, monthly, yearly, custom
+}
+
+// Statistics period types
+const (
+	PeriodDaily   = "daily"
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+	PeriodYearly  = "yearly"
+	PeriodCustom  = "custom"
+)
+
+// StatisticsSnapshot holds a tenant's statistics for one closed day, week or
+// month. Snapshots are written once, so reports on past periods do not change
+// when articles are edited or deleted later.
+type StatisticsSnapshot struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TenantID   primitive.ObjectID `json:"tenantId" bson:"tenantId"`
+	Period     StatisticsPeriod   `json:"period" bson:"period"`
+	Statistics *ArticleStatistics `json:"statistics" bson:"statistics"`
+	ViewTrend  []*ViewTrendData   `json:"viewTrend" bson:"viewTrend"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
 }
 
 // TenantStatistics represents statistics for a tenant
@@ -83,11 +108,103 @@ type TenantStatistics struct {
 	TopCategories     []CategoryStatistics `json:"topCategories"`
 }
 
-// PerformanceMetrics represents performance metrics
+// PerformanceMetrics represents performance metrics. Times in state count the
+// stays in a status that ended during the period.
 type PerformanceMetrics struct {
-	AveragePublishTime float64          `json:"averagePublishTime"` // Average time from draft to published (hours)
-	AverageReviewTime  float64          `json:"averageReviewTime"`  // Average time in review (hours)
-	ArticlesPerDay     float64          `json:"articlesPerDay"`
-	ViewsPerDay        float64          `json:"viewsPerDay"`
-	Period             StatisticsPeriod `json:"period"`
+	AveragePublishTime float64                          `json:"averagePublishTime"` // Average time from draft to published (hours)
+	AverageReviewTime  float64                          `json:"averageReviewTime"`  // Average time in review (hours)
+	ArticlesPerDay     float64                          `json:"articlesPerDay"`
+	ViewsPerDay        float64                          `json:"viewsPerDay"`
+	PublishTime        DurationStats                    `json:"publishTime"` // Creation to first publication, for articles first published in the period
+	ReviewTime         DurationStats                    `json:"reviewTime"`  // Stays in pending_review
+	TimeInState        map[ArticleStatus]*DurationStats `json:"timeInState"`
+	Reviewed           int                              `json:"reviewed"` // Stays in pending_review that ended in a decision
+	Rejected           int                              `json:"rejected"`
+	RejectionRate      float64                          `json:"rejectionRate"`
+	ByAuthor           []*EditorialMetrics              `json:"byAuthor"`
+	ByEditor           []*EditorialMetrics              `json:"byEditor"` // Reviews, by the editor who decided them
+	ByCategory         []*EditorialMetrics              `json:"byCategory"`
+	Period             StatisticsPeriod                 `json:"period"`
+}
+
+// DurationStats summarizes a set of durations, in hours
+type DurationStats struct {
+	Count   int     `json:"count"`
+	Average float64 `json:"average"`
+	Median  float64 `json:"median"`
+	P90     float64 `json:"p90"`
+	Max     float64 `json:"max"`
+}
+
+// EditorialMetrics is how long the articles of an author or category stayed
+// in each status and how their reviews ended, or the same for the reviews an
+// editor decided
+type EditorialMetrics struct {
+	ID            string                           `json:"id"`
+	Name          string                           `json:"name,omitempty"`
+	Articles      int                              `json:"articles"`
+	TimeInState   map[ArticleStatus]*DurationStats `json:"timeInState"`
+	Reviewed      int                              `json:"reviewed"`
+	Rejected      int                              `json:"rejected"`
+	RejectionRate float64                          `json:"rejectionRate"`
+}
+
+// ArticleEditorialMetrics is how long an article stayed in each status
+type ArticleEditorialMetrics struct {
+	ArticleID    primitive.ObjectID        `json:"articleId"`
+	Title        string                    `json:"title"`
+	AuthorID     string                    `json:"authorId"`
+	AuthorName   string                    `json:"authorName,omitempty"`
+	CategoryID   primitive.ObjectID        `json:"categoryId"`
+	TimeInState  map[ArticleStatus]float64 `json:"timeInState"`  // Hours, summed over the stays
+	ReviewRounds int                       `json:"reviewRounds"` // Stays in pending_review
+	Rejections   int                       `json:"rejections"`
+}
+
+// StateStay is a time an article spent in one status, read from the action
+// log: from the change into the status until the next change
+type StateStay struct {
+	ArticleID  primitive.ObjectID `json:"articleId" bson:"articleId"`
+	Title      string             `json:"title" bson:"title"`
+	AuthorID   string             `json:"authorId" bson:"authorId"`
+	AuthorName string             `json:"authorName" bson:"authorName"`
+	CategoryID primitive.ObjectID `json:"categoryId" bson:"categoryId"`
+	Status     ArticleStatus      `json:"status" bson:"status"`
+	EnteredAt  time.Time          `json:"enteredAt" bson:"enteredAt"`
+	LeftAt     time.Time          `json:"leftAt" bson:"leftAt"`
+	NextStatus ArticleStatus      `json:"nextStatus" bson:"nextStatus"`
+	LeftBy     string             `json:"leftBy" bson:"leftBy"` // User who changed the status
+	LeftByName string             `json:"leftByName" bson:"leftByName"`
+	LeftAction ActionType         `json:"leftAction" bson:"leftAction"`
+}
+
+// Hours returns the length of the stay in hours
+func (s *StateStay) Hours() float64 {
+	return s.LeftAt.Sub(s.EnteredAt).Hours()
+}
+
+// ReviewQueue lists the articles waiting in pending_review, oldest first
+type ReviewQueue struct {
+	Count       int              `json:"count"`
+	Age         DurationStats    `json:"age"` // Hours since submission
+	Buckets     []*AgeBucket     `json:"buckets"`
+	Articles    []*QueuedArticle `json:"articles"`
+	GeneratedAt time.Time        `json:"generatedAt"`
+}
+
+// AgeBucket counts the queued articles of an age range
+type AgeBucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// QueuedArticle is an article waiting for review
+type QueuedArticle struct {
+	ArticleID   primitive.ObjectID `json:"articleId" bson:"articleId"`
+	Title       string             `json:"title" bson:"title"`
+	AuthorID    string             `json:"authorId" bson:"authorId"`
+	AuthorName  string             `json:"authorName,omitempty" bson:"authorName"`
+	CategoryID  primitive.ObjectID `json:"categoryId" bson:"categoryId"`
+	SubmittedAt time.Time          `json:"submittedAt" bson:"submittedAt"` // Last change into pending_review
+	AgeHours    float64            `json:"ageHours" bson:"-"`
 }
@@ -12,6 +12,7 @@ type Tenant struct {
 	Name        string             `json:"name" bson:"name"`
 	Code        string             `json:"code" bson:"code"` // Unique tenant code
 	Description string             `json:"description" bson:"description"`
+	Domains     []string           `json:"domains" bson:"domains"` // Host names served by this tenant
 	IsActive    bool               `json:"isActive" bson:"isActive"`
 	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
 	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Workflow defines the editorial states an article moves through and who may
+// move it. A workflow applies to one category of a tenant, or to the whole
+// tenant when CategoryID is nil.
+type Workflow struct {
+	ID           primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	TenantID     primitive.ObjectID   `json:"tenantId" bson:"tenantId"`
+	CategoryID   *primitive.ObjectID  `json:"categoryId,omitempty" bson:"categoryId"` // nil = tenant default
+	Name         string               `json:"name" bson:"name"`
+	Description  string               `json:"description" bson:"description"`
+	InitialState ArticleStatus        `json:"initialState" bson:"initialState"` // State new articles start in
+	States       []WorkflowState      `json:"states" bson:"states"`
+	Transitions  []WorkflowTransition `json:"transitions" bson:"transitions"`
+	CreatedAt    time.Time            `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time            `json:"updatedAt" bson:"updatedAt"`
+	CreatedBy    string               `json:"createdBy" bson:"createdBy"`
+}
+
+// WorkflowState is a status an article can be in
+type WorkflowState struct {
+	Name       ArticleStatus `json:"name" bson:"name"` // Stored as the article status, e.g. "copy_edit"
+	Label      string        `json:"label" bson:"label"`
+	EditableBy []Role        `json:"editableBy,omitempty" bson:"editableBy,omitempty"` // Roles that may edit content in this state (empty = anyone)
+}
+
+// WorkflowTransition moves an article from one of several states to another
+type WorkflowTransition struct {
+	Name          string          `json:"name" bson:"name"` // Unique within the workflow, e.g. "approve_legal"
+	Label         string          `json:"label" bson:"label"`
+	From          []ArticleStatus `json:"from" bson:"from"`
+	To            ArticleStatus   `json:"to" bson:"to"`
+	AllowedRoles  []Role          `json:"allowedRoles" bson:"allowedRoles"`
+	AllowedGroups []string        `json:"allowedGroups,omitempty" bson:"allowedGroups,omitempty"` // Group IDs carried in the user's token
+	AllowAuthor   bool            `json:"allowAuthor" bson:"allowAuthor"`                         // The article's creator may always perform it
+	IsRejection   bool            `json:"isRejection" bson:"isRejection"`                         // Records a rejection note
+	RequireNote   bool            `json:"requireNote" bson:"requireNote"`                         // A note must accompany the transition
+}
+
+// Actor identifies the user performing an editorial action
+type Actor struct {
+	UserID   string   `json:"userId"`
+	UserName string   `json:"userName"`
+	Role     Role     `json:"role"`
+	GroupIDs []string `json:"groupIds,omitempty"`
+}
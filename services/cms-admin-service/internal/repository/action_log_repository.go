@@ -154,3 +154,21 @@ func (r *ActionLogRepository) FindAll(ctx context.Context, filter map[string]int
 
 	return logs, total, nil
 }
+
+// CreateIndexes creates the indexes for action logs
+func (r *ActionLogRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "articleId", Value: 1}, {Key: "timestamp", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "timestamp", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "timestamp", Value: 1}},
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
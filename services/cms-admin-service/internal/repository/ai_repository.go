@@ -100,23 +100,42 @@ func (r *AIOperationLogRepository) GetByTenant(ctx context.Context, tenantID str
 	return logs, nil
 }
 
-// GetUsageStats retrieves usage statistics for a tenant
-func (r *AIOperationLogRepository) GetUsageStats(ctx context.Context, tenantID string, startDate, endDate time.Time) (map[string]interface{}, error) {
+// GetUsageStats summarises a tenant's AI calls between two times, in total
+// and broken down by operation, provider and user. A non-empty userID limits
+// the report to that user.
+func (r *AIOperationLogRepository) GetUsageStats(ctx context.Context, tenantID, userID string, startDate, endDate time.Time) (*model.AIUsageReport, error) {
+	match := bson.M{
+		"tenant_id": tenantID,
+		"created_at": bson.M{
+			"$gte": startDate,
+			"$lte": endDate,
+		},
+	}
+	if userID != "" {
+		match["user_id"] = userID
+	}
+
+	group := func(key interface{}) []bson.M {
+		return []bson.M{
+			{"$group": bson.M{
+				"_id":             key,
+				"calls":           bson.M{"$sum": 1},
+				"failed":          bson.M{"$sum": bson.M{"$cond": bson.A{"$success", 0, 1}}},
+				"tokens_used":     bson.M{"$sum": "$tokens_used"},
+				"cost":            bson.M{"$sum": "$cost"},
+				"avg_duration_ms": bson.M{"$avg": "$duration_ms"},
+			}},
+			{"$sort": bson.M{"calls": -1}},
+		}
+	}
+
 	pipeline := []bson.M{
-		{"$match": bson.M{
-			"tenant_id": tenantID,
-			"created_at": bson.M{
-				"$gte": startDate,
-				"$lte": endDate,
-			},
-			"success": true,
-		}},
-		{"$group": bson.M{
-			"_id":          "$operation",
-			"count":        bson.M{"$sum": 1},
-			"total_tokens": bson.M{"$sum": "$tokens_used"},
-			"total_cost":   bson.M{"$sum": "$cost"},
-			"avg_duration": bson.M{"$avg": "$duration_ms"},
+		{"$match": match},
+		{"$facet": bson.M{
+			"totals":       group(nil),
+			"by_operation": group("$operation"),
+			"by_provider":  group("$provider"),
+			"by_user":      group("$user_id"),
 		}},
 	}
 
@@ -126,13 +145,133 @@ func (r *AIOperationLogRepository) GetUsageStats(ctx context.Context, tenantID s
 	}
 	defer cursor.Close(ctx)
 
-	var results []bson.M
-	if err := cursor.All(ctx, &results); err != nil {
+	var facets []struct {
+		Totals      []model.AIUsageSummary `bson:"totals"`
+		ByOperation []model.AIUsageSummary `bson:"by_operation"`
+		ByProvider  []model.AIUsageSummary `bson:"by_provider"`
+		ByUser      []model.AIUsageSummary `bson:"by_user"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
 		return nil, err
 	}
 
-	stats := make(map[string]interface{})
-	stats["operations"] = results
+	report := &model.AIUsageReport{
+		From:        startDate,
+		To:          endDate,
+		UserID:      userID,
+		ByOperation: []model.AIUsageSummary{},
+		ByProvider:  []model.AIUsageSummary{},
+		ByUser:      []model.AIUsageSummary{},
+	}
+	if len(facets) > 0 {
+		if len(facets[0].Totals) > 0 {
+			report.Totals = facets[0].Totals[0]
+		}
+		report.ByOperation = append(report.ByOperation, facets[0].ByOperation...)
+		report.ByProvider = append(report.ByProvider, facets[0].ByProvider...)
+		report.ByUser = append(report.ByUser, facets[0].ByUser...)
+	}
+	return report, nil
+}
+
+// CreateIndexes creates necessary indexes for operation logs
+func (r *AIOperationLogRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// AIUsageCounterRepository keeps the call counters that enforce AI quotas
+type AIUsageCounterRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAIUsageCounterRepository(db *mongo.Database) *AIUsageCounterRepository {
+	return &AIUsageCounterRepository{
+		collection: db.Collection("ai_usage_counters"),
+	}
+}
+
+// Increment counts a call unless the counter has reached limit, and reports
+// whether it did. The check and the increment are one atomic update: once the
+// limit is reached the filter no longer matches and the upsert fails on the
+// unique index.
+func (r *AIUsageCounterRepository) Increment(ctx context.Context, counter *model.AIUsageCounter, limit int) (bool, error) {
+	filter := bson.M{
+		"tenant_id":    counter.TenantID,
+		"user_id":      counter.UserID,
+		"period":       counter.Period,
+		"period_start": counter.PeriodStart,
+		"count":        bson.M{"$lt": limit},
+	}
+	update := bson.M{
+		"$inc":         bson.M{"count": 1},
+		"$setOnInsert": bson.M{"expires_at": counter.ExpiresAt},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(counter)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Decrement takes back a counted call
+func (r *AIUsageCounterRepository) Decrement(ctx context.Context, counter *model.AIUsageCounter) error {
+	filter := bson.M{
+		"tenant_id":    counter.TenantID,
+		"user_id":      counter.UserID,
+		"period":       counter.Period,
+		"period_start": counter.PeriodStart,
+		"count":        bson.M{"$gt": 0},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"count": -1}})
+	return err
+}
+
+// Count returns the calls counted in a period
+func (r *AIUsageCounterRepository) Count(ctx context.Context, tenantID, userID, period string, periodStart time.Time) (int, error) {
+	var counter model.AIUsageCounter
+	err := r.collection.FindOne(ctx, bson.M{
+		"tenant_id":    tenantID,
+		"user_id":      userID,
+		"period":       period,
+		"period_start": periodStart,
+	}).Decode(&counter)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return counter.Count, nil
+}
 
-	return stats, nil
+// CreateIndexes creates the unique counter index and removes counters of
+// past periods once they expire
+func (r *AIUsageCounterRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "user_id", Value: 1},
+				{Key: "period", Value: 1},
+				{Key: "period_start", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
 }
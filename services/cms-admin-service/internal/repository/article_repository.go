@@ -34,49 +34,122 @@ func (r *ArticleRepository) Create(ctx context.Context, article *model.Article)
 	article.UpdatedAt = time.Now()
 
 	_, err := r.collection.InsertOne(ctx, article)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("article slug %w", ErrDuplicate)
+	}
 	return err
 }
 
-// FindByID finds an article by ID
-func (r *ArticleRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Article, error) {
+// FindByID finds an article by ID within a tenant
+func (r *ArticleRepository) FindByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.Article, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "tenantId": tenantID})
+}
+
+// FindBySlug finds an article by slug within a tenant
+func (r *ArticleRepository) FindBySlug(ctx context.Context, tenantID primitive.ObjectID, slug string) (*model.Article, error) {
+	return r.findOne(ctx, bson.M{"slug": slug, "tenantId": tenantID})
+}
+
+func (r *ArticleRepository) findOne(ctx context.Context, filter bson.M) (*model.Article, error) {
 	var article model.Article
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&article)
+	err := r.collection.FindOne(ctx, filter).Decode(&article)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("article not found")
+			return nil, fmt.Errorf("article %w", ErrNotFound)
 		}
 		return nil, err
 	}
 	return &article, nil
 }
 
-// FindBySlug finds an article by slug
-func (r *ArticleRepository) FindBySlug(ctx context.Context, slug string) (*model.Article, error) {
-	var article model.Article
-	err := r.collection.FindOne(ctx, bson.M{"slug": slug}).Decode(&article)
+// counterFields are kept by increments and workers rather than by edits, so
+// saving an article never writes them back
+var counterFields = []string{"viewCount", "viewBatchIds", "trendingScore"}
+
+// Update updates an article. The article's tenant is part of the filter so a
+// document can never be moved to, or written through, another tenant. The
+// counters are left as they are in the database.
+func (r *ArticleRepository) Update(ctx context.Context, article *model.Article) error {
+	article.UpdatedAt = time.Now()
+	set, err := withoutCounters(article)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("article not found")
-		}
-		return nil, err
+		return err
 	}
-	return &article, nil
+
+	filter := bson.M{"_id": article.ID, "tenantId": article.TenantID}
+	update := bson.M{"$set": set}
+
+	return r.updateOne(ctx, filter, update)
 }
 
-// Update updates an article
-func (r *ArticleRepository) Update(ctx context.Context, article *model.Article) error {
+// UpdateIfUnchanged updates an article only if it is still as read, the copy
+// the change is based on: at the same version, in the same status and with
+// the same scheduled publication. Transitions and schedule approvals do not
+// change the version, so an update that carries the status and schedule it
+// read can otherwise undo a publication made in the meantime. It returns
+// ErrConflict when the article has moved on. The counters are left as they
+// are in the database.
+func (r *ArticleRepository) UpdateIfUnchanged(ctx context.Context, article, read *model.Article) error {
+	filter := bson.M{
+		"_id":            article.ID,
+		"tenantId":       article.TenantID,
+		"currentVersion": versionFilter(read.CurrentVersion),
+		"status":         read.Status,
+	}
+	if read.Schedule != nil {
+		filter["schedule.approvedAt"] = read.Schedule.ApprovedAt
+	} else {
+		filter["schedule"] = nil
+	}
+
 	article.UpdatedAt = time.Now()
+	set, err := withoutCounters(article)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("article slug %w", ErrDuplicate)
+		}
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("article version %w", ErrConflict)
+	}
+	return nil
+}
 
-	filter := bson.M{"_id": article.ID}
-	update := bson.M{"$set": article}
+// withoutCounters returns the fields of an article to save, without the
+// counters
+func withoutCounters(article *model.Article) (bson.M, error) {
+	data, err := bson.Marshal(article)
+	if err != nil {
+		return nil, err
+	}
+	var set bson.M
+	if err := bson.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	for _, field := range counterFields {
+		delete(set, field)
+	}
+	return set, nil
+}
 
-	_, err := r.collection.UpdateOne(ctx, filter, update)
-	return err
+// versionFilter matches a version number; articles saved before versioning
+// have no currentVersion and read as version 0
+func versionFilter(version int) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
 }
 
 // Delete soft deletes an article
-func (r *ArticleRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	filter := bson.M{"_id": id}
+func (r *ArticleRepository) Delete(ctx context.Context, tenantID, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "tenantId": tenantID}
 	update := bson.M{
 		"$set": bson.M{
 			"status":    model.ArticleStatusDeleted,
@@ -84,12 +157,26 @@ func (r *ArticleRepository) Delete(ctx context.Context, id primitive.ObjectID) e
 		},
 	}
 
-	_, err := r.collection.UpdateOne(ctx, filter, update)
-	return err
+	return r.updateOne(ctx, filter, update)
+}
+
+// updateOne applies an update and reports ErrNotFound when no document matched
+func (r *ArticleRepository) updateOne(ctx context.Context, filter, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("article slug %w", ErrDuplicate)
+		}
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("article %w", ErrNotFound)
+	}
+	return nil
 }
 
-// FindAll finds articles with filters and pagination
-func (r *ArticleRepository) FindAll(ctx context.Context, filter map[string]interface{}, page, limit int, sort map[string]int) ([]*model.Article, int64, error) {
+// FindAll finds articles of a tenant with filters and pagination
+func (r *ArticleRepository) FindAll(ctx context.Context, tenantID primitive.ObjectID, filter map[string]interface{}, page, limit int, sort map[string]int) ([]*model.Article, int64, error) {
 	// Build filter
 	bsonFilter := bson.M{}
 	for k, v := range filter {
@@ -100,6 +187,7 @@ func (r *ArticleRepository) FindAll(ctx context.Context, filter map[string]inter
 			bsonFilter[k] = v
 		}
 	}
+	bsonFilter["tenantId"] = tenantID
 
 	// Count total
 	total, err := r.collection.CountDocuments(ctx, bsonFilter)
@@ -136,8 +224,8 @@ func (r *ArticleRepository) FindAll(ctx context.Context, filter map[string]inter
 }
 
 // UpdateStatus updates article status
-func (r *ArticleRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status model.ArticleStatus, publishedBy string) error {
-	filter := bson.M{"_id": id}
+func (r *ArticleRepository) UpdateStatus(ctx context.Context, tenantID, id primitive.ObjectID, status model.ArticleStatus, publishedBy string) error {
+	filter := bson.M{"_id": id, "tenantId": tenantID}
 	update := bson.M{
 		"$set": bson.M{
 			"status":      status,
@@ -146,13 +234,55 @@ func (r *ArticleRepository) UpdateStatus(ctx context.Context, id primitive.Objec
 		},
 	}
 
-	_, err := r.collection.UpdateOne(ctx, filter, update)
-	return err
+	return r.updateOne(ctx, filter, update)
+}
+
+// TransitionStatus moves an article from one status to another. The update
+// only applies if the article is still in the expected status and version, so
+// two concurrent transitions cannot both succeed and an article edited in the
+// meantime is not published unseen. It withdraws any approval of a scheduled
+// publication.
+func (r *ArticleRepository) TransitionStatus(ctx context.Context, tenantID, id primitive.ObjectID, version int, from, to model.ArticleStatus, userID string) error {
+	filter := bson.M{"_id": id, "tenantId": tenantID, "currentVersion": versionFilter(version), "status": from}
+	set := bson.M{
+		"status":    to,
+		"schedule":  nil,
+		"updatedAt": time.Now(),
+	}
+	if to == model.ArticleStatusPublished {
+		set["publishedBy"] = userID
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("article status %w", ErrConflict)
+	}
+	return nil
+}
+
+// SchedulePublish records the approval of an article's scheduled
+// publication, unless the article's version or status has changed
+func (r *ArticleRepository) SchedulePublish(ctx context.Context, tenantID, id primitive.ObjectID, version int, status model.ArticleStatus, schedule *model.PublishSchedule) error {
+	filter := bson.M{"_id": id, "tenantId": tenantID, "currentVersion": versionFilter(version), "status": status}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{
+		"schedule":  schedule,
+		"updatedAt": time.Now(),
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("article status %w", ErrConflict)
+	}
+	return nil
 }
 
 // UpdateOrdering updates article ordering
-func (r *ArticleRepository) UpdateOrdering(ctx context.Context, id primitive.ObjectID, ordering int) error {
-	filter := bson.M{"_id": id}
+func (r *ArticleRepository) UpdateOrdering(ctx context.Context, tenantID, id primitive.ObjectID, ordering int) error {
+	filter := bson.M{"_id": id, "tenantId": tenantID}
 	update := bson.M{
 		"$set": bson.M{
 			"ordering":  ordering,
@@ -160,8 +290,7 @@ func (r *ArticleRepository) UpdateOrdering(ctx context.Context, id primitive.Obj
 		},
 	}
 
-	_, err := r.collection.UpdateOne(ctx, filter, update)
-	return err
+	return r.updateOne(ctx, filter, update)
 }
 
 // IncrementViewCount increments the view count for an article
@@ -176,11 +305,38 @@ func (r *ArticleRepository) IncrementViewCount(ctx context.Context, id primitive
 	return err
 }
 
-// FindArticlesToPublish finds articles that should be published
+// AddViewCounts adds the view counts of one flushed batch to the articles in
+// a single bulk write. Each article remembers the IDs of its last
+// recentViewBatches batches and skips a batch it has already counted, so the
+// batch can be retried after a partial failure without counting views twice.
+func (r *ArticleRepository) AddViewCounts(ctx context.Context, batchID primitive.ObjectID, counts map[primitive.ObjectID]int) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(counts))
+	for id, count := range counts {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id, "viewBatchIds": bson.M{"$ne": batchID}}).
+			SetUpdate(bson.M{
+				"$inc":  bson.M{"viewCount": count},
+				"$set":  bson.M{"updatedAt": now},
+				"$push": bson.M{"viewBatchIds": bson.M{"$each": bson.A{batchID}, "$slice": -recentViewBatches}},
+			}))
+	}
+
+	_, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// FindArticlesToPublish finds articles approved for scheduled publication
+// whose publication date has been reached
 func (r *ArticleRepository) FindArticlesToPublish(ctx context.Context) ([]*model.Article, error) {
 	now := time.Now()
 	filter := bson.M{
-		"status":    model.ArticleStatusPendingReview,
+		"status":    bson.M{"$nin": bson.A{model.ArticleStatusPublished, model.ArticleStatusDeleted}},
+		"schedule":  bson.M{"$ne": nil},
 		"publishAt": bson.M{"$lte": now},
 	}
 
@@ -245,9 +401,20 @@ func (r *ArticleRepository) CalculateImageCount(blocks []model.ContentBlock) int
 func (r *ArticleRepository) CreateIndexes(ctx context.Context) error {
 	indexes := []mongo.IndexModel{
 		{
-			Keys:    bson.D{{Key: "slug", Value: 1}},
+			// Slugs are unique per tenant
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "slug", Value: 1},
+			},
 			Options: options.Index().SetUnique(true),
 		},
+		{
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "publishAt", Value: -1},
+			},
+		},
 		{
 			Keys: bson.D{
 				{Key: "title", Value: "text"},
@@ -279,17 +446,31 @@ func (r *ArticleRepository) CreateIndexes(ctx context.Context) error {
 				{Key: "publishAt", Value: -1},
 			},
 		},
+		{
+			// Articles approved for scheduled publication, for the scheduler
+			Keys:    bson.D{{Key: "publishAt", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"schedule": bson.M{"$type": "object"}}),
+		},
+		{
+			// Trending lists and the trending worker's hot ranking
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "trendingScore", Value: -1},
+			},
+		},
 	}
 
 	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
 	return err
 }
 
-// FindByTag finds articles with a specific tag
-func (r *ArticleRepository) FindByTag(ctx context.Context, tag string, page, limit int) ([]*model.Article, int64, error) {
+// FindByTag finds articles of a tenant with a specific tag
+func (r *ArticleRepository) FindByTag(ctx context.Context, tenantID primitive.ObjectID, tag string, page, limit int) ([]*model.Article, int64, error) {
 	filter := bson.M{
-		"tags":   tag,
-		"status": model.ArticleStatusPublished,
+		"tenantId": tenantID,
+		"tags":     tag,
+		"status":   model.ArticleStatusPublished,
 	}
 
 	// Count total
@@ -318,9 +499,10 @@ func (r *ArticleRepository) FindByTag(ctx context.Context, tag string, page, lim
 	return articles, total, nil
 }
 
-// FindByAuthor finds articles by author ID
-func (r *ArticleRepository) FindByAuthor(ctx context.Context, authorID string, page, limit int) ([]*model.Article, int64, error) {
+// FindByAuthor finds articles of a tenant by author ID
+func (r *ArticleRepository) FindByAuthor(ctx context.Context, tenantID primitive.ObjectID, authorID string, page, limit int) ([]*model.Article, int64, error) {
 	filter := bson.M{
+		"tenantId":  tenantID,
 		"author.id": authorID,
 		"status":    model.ArticleStatusPublished,
 	}
@@ -351,15 +533,16 @@ func (r *ArticleRepository) FindByAuthor(ctx context.Context, authorID string, p
 	return articles, total, nil
 }
 
-// FindRelatedArticles finds related articles by IDs
-func (r *ArticleRepository) FindRelatedArticles(ctx context.Context, articleIDs []primitive.ObjectID) ([]*model.Article, error) {
+// FindRelatedArticles finds related articles of a tenant by IDs
+func (r *ArticleRepository) FindRelatedArticles(ctx context.Context, tenantID primitive.ObjectID, articleIDs []primitive.ObjectID) ([]*model.Article, error) {
 	if len(articleIDs) == 0 {
 		return []*model.Article{}, nil
 	}
 
 	filter := bson.M{
-		"_id":    bson.M{"$in": articleIDs},
-		"status": model.ArticleStatusPublished,
+		"_id":      bson.M{"$in": articleIDs},
+		"tenantId": tenantID,
+		"status":   model.ArticleStatusPublished,
 	}
 
 	cursor, err := r.collection.Find(ctx, filter)
@@ -377,7 +560,7 @@ func (r *ArticleRepository) FindRelatedArticles(ctx context.Context, articleIDs
 }
 
 // UpdateRelatedArticles updates related articles for an article
-func (r *ArticleRepository) UpdateRelatedArticles(ctx context.Context, id primitive.ObjectID, relatedIDs []primitive.ObjectID) error {
+func (r *ArticleRepository) UpdateRelatedArticles(ctx context.Context, tenantID, id primitive.ObjectID, relatedIDs []primitive.ObjectID) error {
 	update := bson.M{
 		"$set": bson.M{
 			"relatedArticles": relatedIDs,
@@ -385,20 +568,53 @@ func (r *ArticleRepository) UpdateRelatedArticles(ctx context.Context, id primit
 		},
 	}
 
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
-	return err
+	return r.updateOne(ctx, bson.M{"_id": id, "tenantId": tenantID}, update)
+}
+
+// SetPoll links a poll to an article, or unlinks it when pollID is nil
+func (r *ArticleRepository) SetPoll(ctx context.Context, tenantID, id primitive.ObjectID, pollID *primitive.ObjectID) error {
+	set := bson.M{
+		"hasPoll":   pollID != nil,
+		"updatedAt": time.Now(),
+	}
+	update := bson.M{"$set": set}
+	if pollID != nil {
+		set["pollId"] = pollID
+	} else {
+		update["$unset"] = bson.M{"pollId": ""}
+	}
+
+	return r.updateOne(ctx, bson.M{"_id": id, "tenantId": tenantID}, update)
+}
+
+// CountByIDs counts how many of the given article IDs belong to a tenant
+func (r *ArticleRepository) CountByIDs(ctx context.Context, tenantID primitive.ObjectID, ids []primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"_id":      bson.M{"$in": ids},
+		"tenantId": tenantID,
+	})
+}
+
+// CountByType counts a tenant's non-deleted articles of a given type
+func (r *ArticleRepository) CountByType(ctx context.Context, tenantID primitive.ObjectID, articleType model.ArticleType) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"tenantId":    tenantID,
+		"articleType": articleType,
+		"status":      bson.M{"$ne": model.ArticleStatusDeleted},
+	})
 }
 
-// FindSimilarArticlesByTags finds similar articles based on shared tags
-func (r *ArticleRepository) FindSimilarArticlesByTags(ctx context.Context, articleID primitive.ObjectID, tags []string, limit int) ([]*model.Article, error) {
+// FindSimilarArticlesByTags finds similar articles of a tenant based on shared tags
+func (r *ArticleRepository) FindSimilarArticlesByTags(ctx context.Context, tenantID, articleID primitive.ObjectID, tags []string, limit int) ([]*model.Article, error) {
 	if len(tags) == 0 {
 		return []*model.Article{}, nil
 	}
 
 	filter := bson.M{
-		"_id":    bson.M{"$ne": articleID},
-		"tags":   bson.M{"$in": tags},
-		"status": model.ArticleStatusPublished,
+		"_id":      bson.M{"$ne": articleID},
+		"tenantId": tenantID,
+		"tags":     bson.M{"$in": tags},
+		"status":   model.ArticleStatusPublished,
 	}
 
 	opts := options.Find().
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ArticleTypeSchemaRepository handles article type schema data operations
+type ArticleTypeSchemaRepository struct {
+	collection *mongo.Collection
+}
+
+// NewArticleTypeSchemaRepository creates a new article type schema repository
+func NewArticleTypeSchemaRepository(db *mongo.Database) *ArticleTypeSchemaRepository {
+	return &ArticleTypeSchemaRepository{
+		collection: db.Collection("article_type_schemas"),
+	}
+}
+
+// Save creates or replaces the schema of an article type for a tenant
+func (r *ArticleTypeSchemaRepository) Save(ctx context.Context, schema *model.ArticleTypeSchema) error {
+	now := time.Now()
+	filter := bson.M{"tenantId": schema.TenantID, "articleType": schema.ArticleType}
+	update := bson.M{
+		"$set": bson.M{
+			"schema":    []byte(schema.Schema),
+			"updatedAt": now,
+			"updatedBy": schema.UpdatedBy,
+		},
+		"$setOnInsert": bson.M{
+			"_id":       primitive.NewObjectID(),
+			"createdAt": now,
+			"createdBy": schema.UpdatedBy,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	return r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(schema)
+}
+
+// FindByType finds the schema of an article type for a tenant
+func (r *ArticleTypeSchemaRepository) FindByType(ctx context.Context, tenantID primitive.ObjectID, articleType model.ArticleType) (*model.ArticleTypeSchema, error) {
+	var schema model.ArticleTypeSchema
+	err := r.collection.FindOne(ctx, bson.M{"tenantId": tenantID, "articleType": articleType}).Decode(&schema)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("article type schema %w", ErrNotFound)
+		}
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// FindAll finds all schemas registered by a tenant
+func (r *ArticleTypeSchemaRepository) FindAll(ctx context.Context, tenantID primitive.ObjectID) ([]*model.ArticleTypeSchema, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "articleType", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"tenantId": tenantID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	schemas := []*model.ArticleTypeSchema{}
+	if err := cursor.All(ctx, &schemas); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+// Delete deletes the schema of an article type for a tenant
+func (r *ArticleTypeSchemaRepository) Delete(ctx context.Context, tenantID primitive.ObjectID, articleType model.ArticleType) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"tenantId": tenantID, "articleType": articleType})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("article type schema %w", ErrNotFound)
+	}
+	return nil
+}
+
+// CreateIndexes creates necessary indexes for article type schemas
+func (r *ArticleTypeSchemaRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			// One schema per article type and tenant
+			Keys:    bson.D{{Key: "tenantId", Value: 1}, {Key: "articleType", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -31,29 +31,28 @@ func (r *CategoryRepository) Create(ctx context.Context, category *model.Categor
 	category.UpdatedAt = time.Now()
 
 	_, err := r.collection.InsertOne(ctx, category)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("category slug %w", ErrDuplicate)
+	}
 	return err
 }
 
-// FindByID finds a category by ID
-func (r *CategoryRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Category, error) {
-	var category model.Category
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&category)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("category not found")
-		}
-		return nil, err
-	}
-	return &category, nil
+// FindByID finds a category by ID within a tenant
+func (r *CategoryRepository) FindByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.Category, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "tenantId": tenantID})
+}
+
+// FindBySlug finds a category by slug within a tenant
+func (r *CategoryRepository) FindBySlug(ctx context.Context, tenantID primitive.ObjectID, slug string) (*model.Category, error) {
+	return r.findOne(ctx, bson.M{"slug": slug, "tenantId": tenantID})
 }
 
-// FindBySlug finds a category by slug
-func (r *CategoryRepository) FindBySlug(ctx context.Context, slug string) (*model.Category, error) {
+func (r *CategoryRepository) findOne(ctx context.Context, filter bson.M) (*model.Category, error) {
 	var category model.Category
-	err := r.collection.FindOne(ctx, bson.M{"slug": slug}).Decode(&category)
+	err := r.collection.FindOne(ctx, filter).Decode(&category)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("category not found")
+			return nil, fmt.Errorf("category %w", ErrNotFound)
 		}
 		return nil, err
 	}
@@ -64,24 +63,39 @@ func (r *CategoryRepository) FindBySlug(ctx context.Context, slug string) (*mode
 func (r *CategoryRepository) Update(ctx context.Context, category *model.Category) error {
 	category.UpdatedAt = time.Now()
 
-	filter := bson.M{"_id": category.ID}
+	filter := bson.M{"_id": category.ID, "tenantId": category.TenantID}
 	update := bson.M{"$set": category}
 
-	_, err := r.collection.UpdateOne(ctx, filter, update)
-	return err
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("category slug %w", ErrDuplicate)
+		}
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("category %w", ErrNotFound)
+	}
+	return nil
 }
 
 // Delete deletes a category
-func (r *CategoryRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	filter := bson.M{"_id": id}
-	_, err := r.collection.DeleteOne(ctx, filter)
-	return err
+func (r *CategoryRepository) Delete(ctx context.Context, tenantID, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id, "tenantId": tenantID}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("category %w", ErrNotFound)
+	}
+	return nil
 }
 
-// FindAll finds all categories
-func (r *CategoryRepository) FindAll(ctx context.Context) ([]*model.Category, error) {
-	opts := options.Find().SetSort(bson.M{"ordering": 1, "name": 1})
-	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+// FindAll finds all categories of a tenant
+func (r *CategoryRepository) FindAll(ctx context.Context, tenantID primitive.ObjectID) ([]*model.Category, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "ordering", Value: 1}, {Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"tenantId": tenantID}, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -95,16 +109,16 @@ func (r *CategoryRepository) FindAll(ctx context.Context) ([]*model.Category, er
 	return categories, nil
 }
 
-// FindByParentID finds categories by parent ID
-func (r *CategoryRepository) FindByParentID(ctx context.Context, parentID *primitive.ObjectID) ([]*model.Category, error) {
-	filter := bson.M{}
+// FindByParentID finds categories of a tenant by parent ID
+func (r *CategoryRepository) FindByParentID(ctx context.Context, tenantID primitive.ObjectID, parentID *primitive.ObjectID) ([]*model.Category, error) {
+	filter := bson.M{"tenantId": tenantID}
 	if parentID == nil {
 		filter["parentId"] = nil
 	} else {
 		filter["parentId"] = *parentID
 	}
 
-	opts := options.Find().SetSort(bson.M{"ordering": 1, "name": 1})
+	opts := options.Find().SetSort(bson.D{{Key: "ordering", Value: 1}, {Key: "name", Value: 1}})
 	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
@@ -123,11 +137,18 @@ func (r *CategoryRepository) FindByParentID(ctx context.Context, parentID *primi
 func (r *CategoryRepository) CreateIndexes(ctx context.Context) error {
 	indexes := []mongo.IndexModel{
 		{
-			Keys:    bson.D{{Key: "slug", Value: 1}},
+			// Slugs are unique per tenant
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "slug", Value: 1},
+			},
 			Options: options.Index().SetUnique(true),
 		},
 		{
-			Keys: bson.D{{Key: "parentId", Value: 1}},
+			Keys: bson.D{
+				{Key: "tenantId", Value: 1},
+				{Key: "parentId", Value: 1},
+			},
 		},
 		{
 			Keys: bson.D{{Key: "categoryType", Value: 1}},
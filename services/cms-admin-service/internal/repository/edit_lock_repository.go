@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EditLockRepository stores article edit locks, one document per locked
+// article keyed by the article ID
+type EditLockRepository struct {
+	collection *mongo.Collection
+}
+
+// NewEditLockRepository creates a new edit lock repository
+func NewEditLockRepository(db *mongo.Database) *EditLockRepository {
+	return &EditLockRepository{
+		collection: db.Collection("article_locks"),
+	}
+}
+
+// Acquire takes the lock for lock.UserID, or extends it if they already hold
+// it, and reports whether it did. The update only matches a lock held by the
+// same user or one that has expired; if another user holds it the upsert
+// fails on the _id and the lock is left alone.
+func (r *EditLockRepository) Acquire(ctx context.Context, lock *model.EditLock) (bool, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id": lock.ArticleID,
+		"$or": bson.A{
+			bson.M{"userId": lock.UserID},
+			bson.M{"expiresAt": bson.M{"$lte": now}},
+		},
+	}
+	// A lock taken over from an expired holder starts anew; the holder's own
+	// renewals keep the original acquiredAt
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"tenantId": lock.TenantID,
+		"userName": lock.UserName,
+		"userRole": lock.UserRole,
+		"acquiredAt": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$userId", lock.UserID}}, "$acquiredAt", now,
+		}},
+		"userId":    lock.UserID,
+		"expiresAt": lock.ExpiresAt,
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(lock)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Renew extends a lock the user still holds. It returns ErrNotFound if the
+// lock has expired or belongs to someone else.
+func (r *EditLockRepository) Renew(ctx context.Context, articleID primitive.ObjectID, userID string, expiresAt time.Time) (*model.EditLock, error) {
+	filter := bson.M{
+		"_id":       articleID,
+		"userId":    userID,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+	update := bson.M{"$set": bson.M{"expiresAt": expiresAt}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var lock model.EditLock
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&lock)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("edit lock %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// Replace gives the lock to lock.UserID whoever holds it, and returns the
+// previous lock if one was active
+func (r *EditLockRepository) Replace(ctx context.Context, lock *model.EditLock) (*model.EditLock, error) {
+	lock.AcquiredAt = time.Now()
+	opts := options.FindOneAndReplace().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var previous model.EditLock
+	err := r.collection.FindOneAndReplace(ctx, bson.M{"_id": lock.ArticleID}, lock, opts).Decode(&previous)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !previous.ExpiresAt.After(lock.AcquiredAt) {
+		return nil, nil
+	}
+	return &previous, nil
+}
+
+// FindActive returns the unexpired lock on an article, or ErrNotFound.
+// Expired locks are removed by the TTL index, which runs about once a minute,
+// so the expiry is checked here as well.
+func (r *EditLockRepository) FindActive(ctx context.Context, articleID primitive.ObjectID) (*model.EditLock, error) {
+	var lock model.EditLock
+	err := r.collection.FindOne(ctx, bson.M{
+		"_id":       articleID,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}).Decode(&lock)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("edit lock %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// Release removes the lock if the user holds it
+func (r *EditLockRepository) Release(ctx context.Context, articleID primitive.ObjectID, userID string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": articleID, "userId": userID})
+	return err
+}
+
+// CreateIndexes removes locks once they expire
+func (r *EditLockRepository) CreateIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EditorialRepository reads editorial workflow metrics from the action log.
+// Log entries carry no tenant, so entries are joined with their article.
+type EditorialRepository struct {
+	articleCollection   *mongo.Collection
+	actionLogCollection *mongo.Collection
+}
+
+// NewEditorialRepository creates a new editorial repository
+func NewEditorialRepository(db *mongo.Database) *EditorialRepository {
+	return &EditorialRepository{
+		articleCollection:   db.Collection("articles"),
+		actionLogCollection: db.Collection("action_logs"),
+	}
+}
+
+// statusChange matches the log entries that moved an article to another
+// status; creations have no old status and count as well
+var statusChange = bson.M{
+	"newStatus": bson.M{"$exists": true, "$ne": ""},
+	"$expr":     bson.M{"$ne": bson.A{"$oldStatus", "$newStatus"}},
+}
+
+// FindStateStays returns the stays of a tenant's articles in a status that
+// ended between startDate and endDate. Each status change is paired with the
+// next change of the same article, which ends the stay.
+func (r *EditorialRepository) FindStateStays(ctx context.Context, tenantID primitive.ObjectID, startDate, endDate time.Time) ([]*model.StateStay, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"$and": bson.A{statusChange, bson.M{"timestamp": bson.M{"$lte": endDate}}}}},
+		{"$setWindowFields": bson.M{
+			"partitionBy": "$articleId",
+			"sortBy":      bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}},
+			"output": bson.M{
+				"leftAt":     bson.M{"$shift": bson.M{"output": "$timestamp", "by": 1}},
+				"nextStatus": bson.M{"$shift": bson.M{"output": "$newStatus", "by": 1}},
+				"leftBy":     bson.M{"$shift": bson.M{"output": "$userId", "by": 1}},
+				"leftByName": bson.M{"$shift": bson.M{"output": "$userName", "by": 1}},
+				"leftAction": bson.M{"$shift": bson.M{"output": "$actionType", "by": 1}},
+			},
+		}},
+		{"$match": bson.M{"leftAt": bson.M{"$gte": startDate, "$lte": endDate}}},
+	}
+	pipeline = append(pipeline, logArticleLookup(tenantID)...)
+	pipeline = append(pipeline, bson.M{"$project": bson.M{
+		"_id":        0,
+		"articleId":  1,
+		"title":      "$article.title",
+		"authorId":   "$article.createdBy",
+		"authorName": "$article.author.name",
+		"categoryId": "$article.categoryId",
+		"status":     "$newStatus",
+		"enteredAt":  "$timestamp",
+		"leftAt":     1,
+		"nextStatus": 1,
+		"leftBy":     1,
+		"leftByName": 1,
+		"leftAction": 1,
+	}})
+
+	cursor, err := r.actionLogCollection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	stays := []*model.StateStay{}
+	if err := cursor.All(ctx, &stays); err != nil {
+		return nil, err
+	}
+	return stays, nil
+}
+
+// FindPublishTimes returns the hours from creation to first publication of a
+// tenant's articles first published between startDate and endDate
+func (r *EditorialRepository) FindPublishTimes(ctx context.Context, tenantID primitive.ObjectID, startDate, endDate time.Time) ([]float64, error) {
+	firstAt := func(field string, value interface{}) bson.M {
+		return bson.M{"$min": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$" + field, value}}, "$timestamp", nil}}}
+	}
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"timestamp": bson.M{"$lte": endDate},
+			"$or": bson.A{
+				bson.M{"actionType": model.ActionTypeCreate},
+				bson.M{"newStatus": model.ArticleStatusPublished},
+			},
+		}},
+		{"$group": bson.M{
+			"_id":         "$articleId",
+			"createdAt":   firstAt("actionType", model.ActionTypeCreate),
+			"publishedAt": firstAt("newStatus", model.ArticleStatusPublished),
+		}},
+		{"$match": bson.M{
+			"createdAt":   bson.M{"$ne": nil},
+			"publishedAt": bson.M{"$gte": startDate, "$lte": endDate},
+		}},
+		{"$set": bson.M{"articleId": "$_id"}},
+	}
+	pipeline = append(pipeline, logArticleLookup(tenantID)...)
+	pipeline = append(pipeline, bson.M{"$project": bson.M{
+		"hours": bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{"$publishedAt", "$createdAt"}}, time.Hour.Milliseconds()}},
+	}})
+
+	cursor, err := r.actionLogCollection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	hours := []float64{}
+	for cursor.Next(ctx) {
+		var result struct {
+			Hours float64 `bson:"hours"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		hours = append(hours, result.Hours)
+	}
+	return hours, cursor.Err()
+}
+
+// FindReviewQueue lists a tenant's articles in pending_review, oldest
+// submission first. An article was submitted when it last changed to
+// pending_review, or when it was last updated if the log has no such change.
+func (r *EditorialRepository) FindReviewQueue(ctx context.Context, tenantID primitive.ObjectID) ([]*model.QueuedArticle, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"tenantId": tenantID, "status": model.ArticleStatusPendingReview}},
+		{"$lookup": bson.M{
+			"from":         r.actionLogCollection.Name(),
+			"localField":   "_id",
+			"foreignField": "articleId",
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$and": bson.A{statusChange, bson.M{"newStatus": model.ArticleStatusPendingReview}}}},
+				bson.M{"$sort": bson.M{"timestamp": -1}},
+				bson.M{"$limit": 1},
+				bson.M{"$project": bson.M{"timestamp": 1}},
+			},
+			"as": "submission",
+		}},
+		{"$project": bson.M{
+			"_id":         0,
+			"articleId":   "$_id",
+			"title":       1,
+			"authorId":    "$createdBy",
+			"authorName":  "$author.name",
+			"categoryId":  1,
+			"submittedAt": bson.M{"$ifNull": bson.A{bson.M{"$first": "$submission.timestamp"}, "$updatedAt"}},
+		}},
+		{"$sort": bson.D{{Key: "submittedAt", Value: 1}, {Key: "articleId", Value: 1}}},
+	}
+
+	cursor, err := r.articleCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	articles := []*model.QueuedArticle{}
+	if err := cursor.All(ctx, &articles); err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// logArticleLookup returns the stages that join log entries with the fields
+// of their article the metrics use, keeping the entries of a tenant's
+// articles
+func logArticleLookup(tenantID primitive.ObjectID) []bson.M {
+	return []bson.M{
+		{"$lookup": bson.M{
+			"from":         "articles",
+			"localField":   "articleId",
+			"foreignField": "_id",
+			"pipeline": bson.A{bson.M{"$project": bson.M{
+				"tenantId":    1,
+				"title":       1,
+				"createdBy":   1,
+				"author.name": 1,
+				"categoryId":  1,
+			}}},
+			"as": "article",
+		}},
+		{"$unwind": "$article"},
+		{"$match": bson.M{"article.tenantId": tenantID}},
+	}
+}
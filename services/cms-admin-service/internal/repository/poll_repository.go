@@ -9,6 +9,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // PollRepository handles poll data operations
@@ -38,39 +39,35 @@ func (r *PollRepository) Create(ctx context.Context, poll *model.Poll) error {
 	}
 
 	_, err := r.collection.InsertOne(ctx, poll)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("poll for this article %w", ErrDuplicate)
+	}
 	return err
 }
 
-// FindByID finds a poll by ID
-func (r *PollRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Poll, error) {
-	var poll model.Poll
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&poll)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, ErrNotFound
-		}
-		return nil, err
-	}
-
-	// Calculate percentages
-	r.calculatePercentages(&poll)
-
-	return &poll, nil
+// FindByID finds a poll of a tenant by ID
+func (r *PollRepository) FindByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.Poll, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "tenantId": tenantID})
 }
 
 // FindByArticleID finds the poll for an article
-func (r *PollRepository) FindByArticleID(ctx context.Context, articleID primitive.ObjectID) (*model.Poll, error) {
+func (r *PollRepository) FindByArticleID(ctx context.Context, tenantID, articleID primitive.ObjectID) (*model.Poll, error) {
+	return r.findOne(ctx, bson.M{"articleId": articleID, "tenantId": tenantID})
+}
+
+func (r *PollRepository) findOne(ctx context.Context, filter bson.M) (*model.Poll, error) {
 	var poll model.Poll
-	err := r.collection.FindOne(ctx, bson.M{"articleId": articleID}).Decode(&poll)
+	err := r.collection.FindOne(ctx, filter).Decode(&poll)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, ErrNotFound
+			return nil, fmt.Errorf("poll %w", ErrNotFound)
 		}
 		return nil, err
 	}
 
 	// Calculate percentages
 	r.calculatePercentages(&poll)
+	poll.IsOpen = poll.AcceptsVotes(time.Now())
 
 	return &poll, nil
 }
@@ -79,86 +76,66 @@ func (r *PollRepository) FindByArticleID(ctx context.Context, articleID primitiv
 func (r *PollRepository) Update(ctx context.Context, poll *model.Poll) error {
 	poll.UpdatedAt = time.Now()
 
-	filter := bson.M{"_id": poll.ID}
+	filter := bson.M{"_id": poll.ID, "tenantId": poll.TenantID}
 	update := bson.M{"$set": poll}
 
-	_, err := r.collection.UpdateOne(ctx, filter, update)
-	return err
-}
-
-// Vote records a vote on a poll
-func (r *PollRepository) Vote(ctx context.Context, vote *model.PollVote) error {
-	// Check if user already voted
-	existing := r.voteCollection.FindOne(ctx, bson.M{
-		"pollId": vote.PollID,
-		"userId": vote.UserID,
-	})
-	if existing.Err() == nil {
-		return fmt.Errorf("user has already voted on this poll")
-	}
-
-	// Get poll to validate options and check if active
-	poll, err := r.FindByID(ctx, vote.PollID)
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
-
-	if !poll.IsActive {
-		return fmt.Errorf("poll is not active")
-	}
-
-	// Check if poll has ended
-	if poll.EndDate != nil && time.Now().After(*poll.EndDate) {
-		return fmt.Errorf("poll has ended")
-	}
-
-	// Validate option IDs
-	validOptions := make(map[string]bool)
-	for _, opt := range poll.Options {
-		validOptions[opt.ID] = true
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("poll %w", ErrNotFound)
 	}
+	return nil
+}
 
-	for _, optID := range vote.OptionIDs {
-		if !validOptions[optID] {
-			return fmt.Errorf("invalid option ID: %s", optID)
-		}
+// Delete deletes a poll and its votes
+func (r *PollRepository) Delete(ctx context.Context, tenantID, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "tenantId": tenantID})
+	if err != nil {
+		return err
 	}
-
-	// Check multiple selection rules
-	if !poll.IsMultiple && len(vote.OptionIDs) > 1 {
-		return fmt.Errorf("poll does not allow multiple selections")
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("poll %w", ErrNotFound)
 	}
 
-	if poll.IsMultiple && poll.MaxSelections > 0 && len(vote.OptionIDs) > poll.MaxSelections {
-		return fmt.Errorf("exceeded maximum selections: %d", poll.MaxSelections)
-	}
+	_, err = r.voteCollection.DeleteMany(ctx, bson.M{"pollId": id})
+	return err
+}
 
-	// Create vote
+// Vote records a vote on a poll and increments the selected options. The
+// vote indexes allow one vote per user, and one anonymous vote per IP address.
+func (r *PollRepository) Vote(ctx context.Context, poll *model.Poll, vote *model.PollVote) error {
 	vote.ID = primitive.NewObjectID()
+	vote.PollID = poll.ID
 	vote.CreatedAt = time.Now()
 
 	if _, err := r.voteCollection.InsertOne(ctx, vote); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("vote %w", ErrDuplicate)
+		}
 		return err
 	}
 
 	// Update poll vote counts
-	update := bson.M{
-		"$inc": bson.M{"totalVotes": 1},
-		"$set": bson.M{"updatedAt": time.Now()},
-	}
+	inc := bson.M{"totalVotes": 1}
 
 	// Increment vote count for each selected option
 	for _, optID := range vote.OptionIDs {
 		for i, opt := range poll.Options {
 			if opt.ID == optID {
-				fieldName := fmt.Sprintf("options.%d.voteCount", i)
-				update["$inc"].(bson.M)[fieldName] = 1
+				inc[fmt.Sprintf("options.%d.voteCount", i)] = 1
 				break
 			}
 		}
 	}
 
-	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": vote.PollID}, update)
+	update := bson.M{
+		"$inc": inc,
+		"$set": bson.M{"updatedAt": time.Now()},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": poll.ID}, update)
 	return err
 }
 
@@ -171,6 +148,16 @@ func (r *PollRepository) HasUserVoted(ctx context.Context, pollID primitive.Obje
 	return count > 0, err
 }
 
+// HasIPVoted checks if an anonymous vote was already cast from an IP address
+func (r *PollRepository) HasIPVoted(ctx context.Context, pollID primitive.ObjectID, ipAddress string) (bool, error) {
+	count, err := r.voteCollection.CountDocuments(ctx, bson.M{
+		"pollId":    pollID,
+		"userId":    "",
+		"ipAddress": ipAddress,
+	})
+	return count > 0, err
+}
+
 // GetUserVote gets a user's vote on a poll
 func (r *PollRepository) GetUserVote(ctx context.Context, pollID primitive.ObjectID, userID string) (*model.PollVote, error) {
 	var vote model.PollVote
@@ -188,12 +175,12 @@ func (r *PollRepository) GetUserVote(ctx context.Context, pollID primitive.Objec
 }
 
 // GetPollResults gets detailed results of a poll
-func (r *PollRepository) GetPollResults(ctx context.Context, pollID primitive.ObjectID) (*model.Poll, error) {
-	return r.FindByID(ctx, pollID)
+func (r *PollRepository) GetPollResults(ctx context.Context, tenantID, pollID primitive.ObjectID) (*model.Poll, error) {
+	return r.FindByID(ctx, tenantID, pollID)
 }
 
 // SetPollStatus activates or deactivates a poll
-func (r *PollRepository) SetPollStatus(ctx context.Context, pollID primitive.ObjectID, isActive bool) error {
+func (r *PollRepository) SetPollStatus(ctx context.Context, tenantID, pollID primitive.ObjectID, isActive bool) error {
 	update := bson.M{
 		"$set": bson.M{
 			"isActive":  isActive,
@@ -201,8 +188,14 @@ func (r *PollRepository) SetPollStatus(ctx context.Context, pollID primitive.Obj
 		},
 	}
 
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": pollID}, update)
-	return err
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": pollID, "tenantId": tenantID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("poll %w", ErrNotFound)
+	}
+	return nil
 }
 
 // calculatePercentages calculates vote percentages for poll options
@@ -224,3 +217,34 @@ func (r *PollRepository) DeleteByArticleID(ctx context.Context, articleID primit
 	_, err := r.collection.DeleteMany(ctx, bson.M{"articleId": articleID})
 	return err
 }
+
+// CreateIndexes creates necessary indexes for polls and votes
+func (r *PollRepository) CreateIndexes(ctx context.Context) error {
+	pollIndexes := []mongo.IndexModel{
+		{
+			// One poll per article
+			Keys:    bson.D{{Key: "tenantId", Value: 1}, {Key: "articleId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	if _, err := r.collection.Indexes().CreateMany(ctx, pollIndexes); err != nil {
+		return err
+	}
+
+	voteIndexes := []mongo.IndexModel{
+		{
+			// One vote per signed-in user
+			Keys: bson.D{{Key: "pollId", Value: 1}, {Key: "userId", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"userId": bson.M{"$gt": ""}}),
+		},
+		{
+			// One anonymous vote per IP address
+			Keys: bson.D{{Key: "pollId", Value: 1}, {Key: "ipAddress", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"userId": ""}),
+		},
+	}
+	_, err := r.voteCollection.Indexes().CreateMany(ctx, voteIndexes)
+	return err
+}
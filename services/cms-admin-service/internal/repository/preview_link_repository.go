@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PreviewLinkRepository handles preview link data operations
+type PreviewLinkRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPreviewLinkRepository creates a new preview link repository
+func NewPreviewLinkRepository(db *mongo.Database) *PreviewLinkRepository {
+	return &PreviewLinkRepository{
+		collection: db.Collection("preview_links"),
+	}
+}
+
+// Create creates a new preview link
+func (r *PreviewLinkRepository) Create(ctx context.Context, link *model.PreviewLink) error {
+	link.ID = primitive.NewObjectID()
+	link.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, link)
+	return err
+}
+
+// FindActive finds an unexpired preview link of an article
+func (r *PreviewLinkRepository) FindActive(ctx context.Context, tenantID, articleID, id primitive.ObjectID) (*model.PreviewLink, error) {
+	var link model.PreviewLink
+	err := r.collection.FindOne(ctx, bson.M{
+		"_id":       id,
+		"tenantId":  tenantID,
+		"articleId": articleID,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}).Decode(&link)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// FindActiveByArticleID lists the unexpired preview links of an article,
+// newest first
+func (r *PreviewLinkRepository) FindActiveByArticleID(ctx context.Context, tenantID, articleID primitive.ObjectID) ([]*model.PreviewLink, error) {
+	filter := bson.M{
+		"tenantId":  tenantID,
+		"articleId": articleID,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	links := []*model.PreviewLink{}
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// DeleteByArticleID deletes all preview links of an article and returns how
+// many there were
+func (r *PreviewLinkRepository) DeleteByArticleID(ctx context.Context, tenantID, articleID primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"tenantId": tenantID, "articleId": articleID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// CreateIndexes creates the indexes for preview links and removes links once
+// they expire
+func (r *PreviewLinkRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "articleId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
@@ -11,10 +12,12 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// SensitiveKeywordRepository handles sensitive keyword data operations
 type SensitiveKeywordRepository struct {
 	collection *mongo.Collection
 }
 
+// NewSensitiveKeywordRepository creates a new sensitive keyword repository
 func NewSensitiveKeywordRepository(db *mongo.Database) *SensitiveKeywordRepository {
 	return &SensitiveKeywordRepository{
 		collection: db.Collection("sensitive_keywords"),
@@ -34,11 +37,14 @@ func (r *SensitiveKeywordRepository) Create(ctx context.Context, keyword *model.
 	return nil
 }
 
-// GetByID retrieves a keyword by ID
-func (r *SensitiveKeywordRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*model.SensitiveKeyword, error) {
+// GetByID retrieves a keyword of a tenant by ID
+func (r *SensitiveKeywordRepository) GetByID(ctx context.Context, tenantID string, id primitive.ObjectID) (*model.SensitiveKeyword, error) {
 	var keyword model.SensitiveKeyword
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&keyword)
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "tenant_id": tenantID}).Decode(&keyword)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("sensitive keyword %w", ErrNotFound)
+		}
 		return nil, err
 	}
 	return &keyword, nil
@@ -93,14 +99,26 @@ func (r *SensitiveKeywordRepository) Update(ctx context.Context, keyword *model.
 		"$set": keyword,
 	}
 
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": keyword.ID}, update)
-	return err
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": keyword.ID, "tenant_id": keyword.TenantID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("sensitive keyword %w", ErrNotFound)
+	}
+	return nil
 }
 
-// Delete deletes a keyword
-func (r *SensitiveKeywordRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
-	return err
+// Delete deletes a keyword of a tenant
+func (r *SensitiveKeywordRepository) Delete(ctx context.Context, tenantID string, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "tenant_id": tenantID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("sensitive keyword %w", ErrNotFound)
+	}
+	return nil
 }
 
 // BulkCreate creates multiple keywords at once
@@ -117,6 +135,25 @@ func (r *SensitiveKeywordRepository) BulkCreate(ctx context.Context, keywords []
 		docs[i] = k
 	}
 
-	_, err := r.collection.InsertMany(ctx, docs)
+	result, err := r.collection.InsertMany(ctx, docs)
+	if err != nil {
+		return err
+	}
+	for i, id := range result.InsertedIDs {
+		keywords[i].ID = id.(primitive.ObjectID)
+	}
+	return nil
+}
+
+// CreateIndexes creates necessary indexes for sensitive keywords
+func (r *SensitiveKeywordRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			// Scans load a tenant's active keywords
+			Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "is_active", Value: 1}},
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
 	return err
 }
@@ -8,6 +8,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // StatisticsRepository handles statistics operations
@@ -15,6 +16,7 @@ type StatisticsRepository struct {
 	db                *mongo.Database
 	articleCollection *mongo.Collection
 	viewCollection    *mongo.Collection
+	sourceCollection  *mongo.Collection
 }
 
 // NewStatisticsRepository creates a new statistics repository
@@ -23,6 +25,7 @@ func NewStatisticsRepository(db *mongo.Database) *StatisticsRepository {
 		db:                db,
 		articleCollection: db.Collection("articles"),
 		viewCollection:    db.Collection("article_views"),
+		sourceCollection:  db.Collection("article_traffic_sources"),
 	}
 }
 
@@ -175,11 +178,15 @@ func (r *StatisticsRepository) GetArticleStatistics(ctx context.Context, startDa
 				"$lte": endDate,
 			},
 		}},
-		{"$group": bson.M{
-			"_id":        nil,
-			"totalViews": bson.M{"$sum": "$views"},
-		}},
 	}
+	if tenantID != nil {
+		viewPipeline = append(viewPipeline, articleLookup(bson.M{"tenantId": *tenantID})...)
+	}
+	viewPipeline = append(viewPipeline, bson.M{"$group": bson.M{
+		"_id":         nil,
+		"totalViews":  bson.M{"$sum": "$views"},
+		"uniqueViews": bson.M{"$sum": "$uniqueViews"},
+	}})
 	cursor, err = r.viewCollection.Aggregate(ctx, viewPipeline)
 	if err != nil {
 		return nil, err
@@ -188,10 +195,12 @@ func (r *StatisticsRepository) GetArticleStatistics(ctx context.Context, startDa
 
 	if cursor.Next(ctx) {
 		var result struct {
-			TotalViews int64 `bson:"totalViews"`
+			TotalViews  int64 `bson:"totalViews"`
+			UniqueViews int64 `bson:"uniqueViews"`
 		}
 		if err := cursor.Decode(&result); err == nil {
 			stats.TotalViews = result.TotalViews
+			stats.TotalUniqueViews = result.UniqueViews
 		}
 	}
 
@@ -207,28 +216,26 @@ func (r *StatisticsRepository) GetTopViewedArticles(ctx context.Context, startDa
 		},
 	}
 
-	pipeline := []bson.M{
-		{"$match": matchStage},
-		{"$group": bson.M{
-			"_id":       "$articleId",
-			"viewCount": bson.M{"$sum": "$views"},
+	pipeline := []bson.M{{"$match": matchStage}}
+	if tenantID != nil {
+		pipeline = append(pipeline, articleLookup(bson.M{"tenantId": *tenantID})...)
+	}
+	pipeline = append(pipeline,
+		bson.M{"$group": bson.M{
+			"_id":         "$articleId",
+			"viewCount":   bson.M{"$sum": "$views"},
+			"uniqueViews": bson.M{"$sum": "$uniqueViews"},
 		}},
-		{"$sort": bson.M{"viewCount": -1}},
-		{"$limit": limit},
-		{"$lookup": bson.M{
+		bson.M{"$sort": bson.D{{Key: "viewCount", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": limit},
+		bson.M{"$lookup": bson.M{
 			"from":         "articles",
 			"localField":   "_id",
 			"foreignField": "_id",
 			"as":           "article",
 		}},
-		{"$unwind": "$article"},
-	}
-
-	if tenantID != nil {
-		pipeline = append(pipeline, bson.M{
-			"$match": bson.M{"article.tenantId": *tenantID},
-		})
-	}
+		bson.M{"$unwind": "$article"},
+	)
 
 	cursor, err := r.viewCollection.Aggregate(ctx, pipeline)
 	if err != nil {
@@ -236,11 +243,12 @@ func (r *StatisticsRepository) GetTopViewedArticles(ctx context.Context, startDa
 	}
 	defer cursor.Close(ctx)
 
-	var results []*model.ArticleViewSummary
+	results := []*model.ArticleViewSummary{}
 	for cursor.Next(ctx) {
 		var result struct {
-			ViewCount int64 `bson:"viewCount"`
-			Article   struct {
+			ViewCount   int64 `bson:"viewCount"`
+			UniqueViews int64 `bson:"uniqueViews"`
+			Article     struct {
 				ID          primitive.ObjectID `bson:"_id"`
 				Title       string             `bson:"title"`
 				Slug        string             `bson:"slug"`
@@ -258,6 +266,7 @@ func (r *StatisticsRepository) GetTopViewedArticles(ctx context.Context, startDa
 			Slug:        result.Article.Slug,
 			ArticleType: result.Article.ArticleType,
 			ViewCount:   result.ViewCount,
+			UniqueViews: result.UniqueViews,
 			CategoryID:  result.Article.CategoryID,
 		}
 		results = append(results, summary)
@@ -266,14 +275,15 @@ func (r *StatisticsRepository) GetTopViewedArticles(ctx context.Context, startDa
 	return results, nil
 }
 
-// GetCategoryStatistics gets statistics for categories
-func (r *StatisticsRepository) GetCategoryStatistics(ctx context.Context, categoryID primitive.ObjectID, startDate, endDate time.Time) (*model.CategoryStatistics, error) {
+// GetCategoryStatistics gets statistics for a tenant's category
+func (r *StatisticsRepository) GetCategoryStatistics(ctx context.Context, tenantID, categoryID primitive.ObjectID, startDate, endDate time.Time) (*model.CategoryStatistics, error) {
 	stats := &model.CategoryStatistics{
 		CategoryID: categoryID,
 	}
 
 	// Count articles
 	filter := bson.M{
+		"tenantId":   tenantID,
 		"categoryId": categoryID,
 		"createdAt": bson.M{
 			"$gte": startDate,
@@ -299,7 +309,7 @@ func (r *StatisticsRepository) GetCategoryStatistics(ctx context.Context, catego
 	var category struct {
 		Name string `bson:"name"`
 	}
-	err = r.db.Collection("categories").FindOne(ctx, bson.M{"_id": categoryID}).Decode(&category)
+	err = r.db.Collection("categories").FindOne(ctx, bson.M{"_id": categoryID, "tenantId": tenantID}).Decode(&category)
 	if err == nil {
 		stats.CategoryName = category.Name
 	}
@@ -312,19 +322,13 @@ func (r *StatisticsRepository) GetCategoryStatistics(ctx context.Context, catego
 				"$lte": endDate,
 			},
 		}},
-		{"$lookup": bson.M{
-			"from":         "articles",
-			"localField":   "articleId",
-			"foreignField": "_id",
-			"as":           "article",
-		}},
-		{"$unwind": "$article"},
-		{"$match": bson.M{"article.categoryId": categoryID}},
-		{"$group": bson.M{
-			"_id":        nil,
-			"totalViews": bson.M{"$sum": "$views"},
-		}},
 	}
+	viewPipeline = append(viewPipeline, articleLookup(bson.M{"tenantId": tenantID, "categoryId": categoryID})...)
+	viewPipeline = append(viewPipeline, bson.M{"$group": bson.M{
+		"_id":         nil,
+		"totalViews":  bson.M{"$sum": "$views"},
+		"uniqueViews": bson.M{"$sum": "$uniqueViews"},
+	}})
 
 	cursor, err := r.viewCollection.Aggregate(ctx, viewPipeline)
 	if err != nil {
@@ -334,10 +338,12 @@ func (r *StatisticsRepository) GetCategoryStatistics(ctx context.Context, catego
 
 	if cursor.Next(ctx) {
 		var result struct {
-			TotalViews int64 `bson:"totalViews"`
+			TotalViews  int64 `bson:"totalViews"`
+			UniqueViews int64 `bson:"uniqueViews"`
 		}
 		if err := cursor.Decode(&result); err == nil {
 			stats.TotalViews = result.TotalViews
+			stats.UniqueViews = result.UniqueViews
 			if stats.TotalArticles > 0 {
 				stats.AverageViews = float64(stats.TotalViews) / float64(stats.TotalArticles)
 			}
@@ -348,13 +354,14 @@ func (r *StatisticsRepository) GetCategoryStatistics(ctx context.Context, catego
 	return stats, nil
 }
 
-// GetAuthorStatistics gets statistics for an author
-func (r *StatisticsRepository) GetAuthorStatistics(ctx context.Context, authorID string, startDate, endDate time.Time) (*model.AuthorStatistics, error) {
+// GetAuthorStatistics gets statistics for an author in a tenant
+func (r *StatisticsRepository) GetAuthorStatistics(ctx context.Context, tenantID primitive.ObjectID, authorID string, startDate, endDate time.Time) (*model.AuthorStatistics, error) {
 	stats := &model.AuthorStatistics{
 		AuthorID: authorID,
 	}
 
 	filter := bson.M{
+		"tenantId":  tenantID,
 		"createdBy": authorID,
 		"createdAt": bson.M{
 			"$gte": startDate,
@@ -391,20 +398,26 @@ func (r *StatisticsRepository) GetAuthorStatistics(ctx context.Context, authorID
 			Name string `bson:"name"`
 		} `bson:"author"`
 	}
-	err = r.articleCollection.FindOne(ctx, bson.M{"createdBy": authorID}).Decode(&article)
+	err = r.articleCollection.FindOne(ctx, bson.M{"tenantId": tenantID, "createdBy": authorID}).Decode(&article)
 	if err == nil {
 		stats.AuthorName = article.Author.Name
 	}
 
-	// Total views
+	// Views in the period
 	pipeline := []bson.M{
-		{"$match": bson.M{"createdBy": authorID}},
-		{"$group": bson.M{
-			"_id":        nil,
-			"totalViews": bson.M{"$sum": "$viewCount"},
+		{"$match": bson.M{
+			"date": bson.M{
+				"$gte": startDate,
+				"$lte": endDate,
+			},
 		}},
 	}
-	cursor, err := r.articleCollection.Aggregate(ctx, pipeline)
+	pipeline = append(pipeline, articleLookup(bson.M{"tenantId": tenantID, "createdBy": authorID})...)
+	pipeline = append(pipeline, bson.M{"$group": bson.M{
+		"_id":        nil,
+		"totalViews": bson.M{"$sum": "$views"},
+	}})
+	cursor, err := r.viewCollection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -427,6 +440,20 @@ func (r *StatisticsRepository) GetAuthorStatistics(ctx context.Context, authorID
 
 // GetViewTrend gets view trends over time
 func (r *StatisticsRepository) GetViewTrend(ctx context.Context, startDate, endDate time.Time, tenantID *primitive.ObjectID) ([]*model.ViewTrendData, error) {
+	trends := []*model.ViewTrendData{}
+	err := r.EachViewTrend(ctx, startDate, endDate, tenantID, func(trend *model.ViewTrendData) error {
+		trends = append(trends, trend)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trends, nil
+}
+
+// EachViewTrend calls fn with the view totals of each day, oldest first, as
+// they are read, and stops at the first error fn returns
+func (r *StatisticsRepository) EachViewTrend(ctx context.Context, startDate, endDate time.Time, tenantID *primitive.ObjectID, fn func(*model.ViewTrendData) error) error {
 	matchStage := bson.M{
 		"date": bson.M{
 			"$gte": startDate,
@@ -434,35 +461,239 @@ func (r *StatisticsRepository) GetViewTrend(ctx context.Context, startDate, endD
 		},
 	}
 
-	pipeline := []bson.M{
-		{"$match": matchStage},
-		{"$group": bson.M{
-			"_id":       "$date",
-			"viewCount": bson.M{"$sum": "$views"},
-			"articles":  bson.M{"$addToSet": "$articleId"},
+	pipeline := []bson.M{{"$match": matchStage}}
+	if tenantID != nil {
+		pipeline = append(pipeline, articleLookup(bson.M{"tenantId": *tenantID})...)
+	}
+	pipeline = append(pipeline,
+		bson.M{"$group": bson.M{
+			"_id":         "$date",
+			"viewCount":   bson.M{"$sum": "$views"},
+			"uniqueViews": bson.M{"$sum": "$uniqueViews"},
+			"articles":    bson.M{"$addToSet": "$articleId"},
 		}},
-		{"$project": bson.M{
-			"date":      "$_id",
-			"viewCount": 1,
-			"articles":  bson.M{"$size": "$articles"},
+		bson.M{"$project": bson.M{
+			"date":        "$_id",
+			"viewCount":   1,
+			"uniqueViews": 1,
+			"articles":    bson.M{"$size": "$articles"},
 		}},
-		{"$sort": bson.M{"date": 1}},
-	}
+		bson.M{"$sort": bson.M{"date": 1}},
+	)
 
 	cursor, err := r.viewCollection.Aggregate(ctx, pipeline)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer cursor.Close(ctx)
 
-	var trends []*model.ViewTrendData
 	for cursor.Next(ctx) {
 		var trend model.ViewTrendData
 		if err := cursor.Decode(&trend); err != nil {
-			continue
+			return err
+		}
+		if err := fn(&trend); err != nil {
+			return err
 		}
-		trends = append(trends, &trend)
 	}
+	return cursor.Err()
+}
 
-	return trends, nil
+// EachArticleView calls fn with the view and engagement counts of an article
+// on each day, oldest first, as they are read, and stops at the first error
+// fn returns
+func (r *StatisticsRepository) EachArticleView(ctx context.Context, articleID primitive.ObjectID, startDate, endDate time.Time, fn func(*model.ArticleView) error) error {
+	filter := bson.M{
+		"articleId": articleID,
+		"date": bson.M{
+			"$gte": startDate,
+			"$lte": endDate,
+		},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: 1}})
+
+	cursor, err := r.viewCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var view model.ArticleView
+		if err := cursor.Decode(&view); err != nil {
+			return err
+		}
+		if err := fn(&view); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// articleLookup returns the stages that join daily view documents with their
+// article and keep those whose article matches a filter on article fields.
+// Only the filtered fields are joined, not the whole article.
+func articleLookup(filter bson.M) []bson.M {
+	match := bson.M{}
+	fields := bson.M{}
+	for field, value := range filter {
+		match["article."+field] = value
+		fields[field] = 1
+	}
+	return []bson.M{
+		{"$lookup": bson.M{
+			"from":         "articles",
+			"localField":   "articleId",
+			"foreignField": "_id",
+			"pipeline":     bson.A{bson.M{"$project": fields}},
+			"as":           "article",
+		}},
+		{"$unwind": "$article"},
+		{"$match": match},
+	}
+}
+
+// FindArticleIDsByCategory returns the IDs of a tenant's articles in a category
+func (r *StatisticsRepository) FindArticleIDsByCategory(ctx context.Context, tenantID, categoryID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return r.findArticleIDs(ctx, bson.M{"tenantId": tenantID, "categoryId": categoryID})
+}
+
+// FindArticleIDsByAuthor returns the IDs of a tenant's articles by an author
+func (r *StatisticsRepository) FindArticleIDsByAuthor(ctx context.Context, tenantID primitive.ObjectID, authorID string) ([]primitive.ObjectID, error) {
+	return r.findArticleIDs(ctx, bson.M{"tenantId": tenantID, "author.id": authorID})
+}
+
+func (r *StatisticsRepository) findArticleIDs(ctx context.Context, filter bson.M) ([]primitive.ObjectID, error) {
+	values, err := r.articleCollection.Distinct(ctx, "_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// GetEngagementStatistics sums the daily view and engagement counts of the
+// given articles between startDate and endDate
+func (r *StatisticsRepository) GetEngagementStatistics(ctx context.Context, articleIDs []primitive.ObjectID, startDate, endDate time.Time) (*model.EngagementStatistics, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"articleId": bson.M{"$in": articleIDs},
+			"date": bson.M{
+				"$gte": startDate,
+				"$lte": endDate,
+			},
+		}},
+		{"$group": bson.M{
+			"_id":           nil,
+			"views":         bson.M{"$sum": "$views"},
+			"uniqueViews":   bson.M{"$sum": "$uniqueViews"},
+			"reads":         bson.M{"$sum": "$engagement.reads"},
+			"activeSeconds": bson.M{"$sum": "$engagement.activeSeconds"},
+			"depth0":        bson.M{"$sum": "$engagement.depth0"},
+			"depth25":       bson.M{"$sum": "$engagement.depth25"},
+			"depth50":       bson.M{"$sum": "$engagement.depth50"},
+			"depth75":       bson.M{"$sum": "$engagement.depth75"},
+			"depth100":      bson.M{"$sum": "$engagement.depth100"},
+			"mobile":        bson.M{"$sum": "$engagement.mobile"},
+			"tablet":        bson.M{"$sum": "$engagement.tablet"},
+			"desktop":       bson.M{"$sum": "$engagement.desktop"},
+		}},
+	}
+
+	cursor, err := r.viewCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Views         int64 `bson:"views"`
+		UniqueViews   int64 `bson:"uniqueViews"`
+		Reads         int64 `bson:"reads"`
+		ActiveSeconds int64 `bson:"activeSeconds"`
+		Depth0        int64 `bson:"depth0"`
+		Depth25       int64 `bson:"depth25"`
+		Depth50       int64 `bson:"depth50"`
+		Depth75       int64 `bson:"depth75"`
+		Depth100      int64 `bson:"depth100"`
+		Mobile        int64 `bson:"mobile"`
+		Tablet        int64 `bson:"tablet"`
+		Desktop       int64 `bson:"desktop"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := &model.EngagementStatistics{
+		Views:       result.Views,
+		UniqueViews: result.UniqueViews,
+		Reads:       result.Reads,
+		ScrollDepth: map[string]int64{
+			"0":   result.Depth0,
+			"25":  result.Depth25,
+			"50":  result.Depth50,
+			"75":  result.Depth75,
+			"100": result.Depth100,
+		},
+		Devices: map[string]int64{
+			string(model.DeviceMobile):  result.Mobile,
+			string(model.DeviceTablet):  result.Tablet,
+			string(model.DeviceDesktop): result.Desktop,
+		},
+		Period: model.StatisticsPeriod{
+			StartDate: startDate,
+			EndDate:   endDate,
+			Type:      "custom",
+		},
+		GeneratedAt: time.Now(),
+	}
+	if result.Reads > 0 {
+		stats.AverageReadTime = float64(result.ActiveSeconds) / float64(result.Reads)
+		stats.CompletionRate = float64(result.Depth100) / float64(result.Reads)
+	}
+	return stats, nil
+}
+
+// GetTopTrafficSources returns the referrers or UTM values that brought the
+// most reads of the given articles between startDate and endDate
+func (r *StatisticsRepository) GetTopTrafficSources(ctx context.Context, articleIDs []primitive.ObjectID, sourceType model.TrafficSourceType, startDate, endDate time.Time, limit int) ([]*model.SourceCount, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"articleId": bson.M{"$in": articleIDs},
+			"type":      sourceType,
+			"date": bson.M{
+				"$gte": startDate,
+				"$lte": endDate,
+			},
+		}},
+		{"$group": bson.M{
+			"_id":   "$value",
+			"count": bson.M{"$sum": "$count"},
+		}},
+		{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		{"$limit": limit},
+	}
+
+	cursor, err := r.sourceCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sources := []*model.SourceCount{}
+	if err := cursor.All(ctx, &sources); err != nil {
+		return nil, err
+	}
+	return sources, nil
 }
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StatisticsSnapshotRepository handles statistics snapshot data operations
+type StatisticsSnapshotRepository struct {
+	collection *mongo.Collection
+}
+
+// NewStatisticsSnapshotRepository creates a new statistics snapshot repository
+func NewStatisticsSnapshotRepository(db *mongo.Database) *StatisticsSnapshotRepository {
+	return &StatisticsSnapshotRepository{
+		collection: db.Collection("statistics_snapshots"),
+	}
+}
+
+// Create stores a snapshot. A tenant has one snapshot per period, so creating
+// a second one fails with ErrDuplicate.
+func (r *StatisticsSnapshotRepository) Create(ctx context.Context, snapshot *model.StatisticsSnapshot) error {
+	snapshot.ID = primitive.NewObjectID()
+	snapshot.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, snapshot)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("statistics snapshot %w", ErrDuplicate)
+	}
+	return err
+}
+
+// Exists reports whether a tenant has a snapshot of the period of a type
+// starting at startDate
+func (r *StatisticsSnapshotRepository) Exists(ctx context.Context, tenantID primitive.ObjectID, periodType string, startDate time.Time) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"tenantId":         tenantID,
+		"period.type":      periodType,
+		"period.startDate": startDate,
+	}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// FindByID finds a tenant's snapshot by ID
+func (r *StatisticsSnapshotRepository) FindByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.StatisticsSnapshot, error) {
+	var snapshot model.StatisticsSnapshot
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "tenantId": tenantID}).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// FindAll lists a tenant's snapshots of a period type that start between
+// startDate and endDate, oldest first, without their view trend. A limit of 0
+// lists them all.
+func (r *StatisticsSnapshotRepository) FindAll(ctx context.Context, tenantID primitive.ObjectID, periodType string, startDate, endDate time.Time, page, limit int) ([]*model.StatisticsSnapshot, int64, error) {
+	filter := bson.M{
+		"tenantId":    tenantID,
+		"period.type": periodType,
+		"period.startDate": bson.M{
+			"$gte": startDate,
+			"$lte": endDate,
+		},
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "period.startDate", Value: 1}}).
+		SetProjection(bson.M{"viewTrend": 0}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	snapshots := []*model.StatisticsSnapshot{}
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		return nil, 0, err
+	}
+	return snapshots, total, nil
+}
+
+// CreateIndexes creates the indexes for statistics snapshots
+func (r *StatisticsSnapshotRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenantId", Value: 1}, {Key: "period.type", Value: 1}, {Key: "period.startDate", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
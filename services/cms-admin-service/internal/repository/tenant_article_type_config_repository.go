@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TenantArticleTypeConfigRepository handles tenant article type configuration data operations
+type TenantArticleTypeConfigRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTenantArticleTypeConfigRepository creates a new tenant article type config repository
+func NewTenantArticleTypeConfigRepository(db *mongo.Database) *TenantArticleTypeConfigRepository {
+	return &TenantArticleTypeConfigRepository{
+		collection: db.Collection("tenant_article_type_configs"),
+	}
+}
+
+// Create creates the article type configuration of a tenant
+func (r *TenantArticleTypeConfigRepository) Create(ctx context.Context, config *model.TenantArticleTypeConfig) error {
+	config.ID = primitive.NewObjectID()
+	config.CreatedAt = time.Now()
+	config.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, config)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("article type config %w", ErrDuplicate)
+	}
+	return err
+}
+
+// FindByTenantID finds the article type configuration of a tenant
+func (r *TenantArticleTypeConfigRepository) FindByTenantID(ctx context.Context, tenantID primitive.ObjectID) (*model.TenantArticleTypeConfig, error) {
+	var config model.TenantArticleTypeConfig
+	err := r.collection.FindOne(ctx, bson.M{"tenantId": tenantID}).Decode(&config)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("article type config %w", ErrNotFound)
+		}
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Update updates the article type configuration of a tenant
+func (r *TenantArticleTypeConfigRepository) Update(ctx context.Context, config *model.TenantArticleTypeConfig) error {
+	config.UpdatedAt = time.Now()
+
+	filter := bson.M{"_id": config.ID, "tenantId": config.TenantID}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": config})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("article type config %w", ErrNotFound)
+	}
+	return nil
+}
+
+// Delete deletes the article type configuration of a tenant
+func (r *TenantArticleTypeConfigRepository) Delete(ctx context.Context, tenantID primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"tenantId": tenantID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("article type config %w", ErrNotFound)
+	}
+	return nil
+}
+
+// CreateIndexes creates necessary indexes for tenant article type configs
+func (r *TenantArticleTypeConfigRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			// One configuration per tenant
+			Keys:    bson.D{{Key: "tenantId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TenantRepository handles tenant data operations
+type TenantRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTenantRepository creates a new tenant repository
+func NewTenantRepository(db *mongo.Database) *TenantRepository {
+	return &TenantRepository{
+		collection: db.Collection("tenants"),
+	}
+}
+
+// Create creates a new tenant
+func (r *TenantRepository) Create(ctx context.Context, tenant *model.Tenant) error {
+	tenant.ID = primitive.NewObjectID()
+	tenant.CreatedAt = time.Now()
+	tenant.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, tenant)
+	return err
+}
+
+// FindByID finds a tenant by ID
+func (r *TenantRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Tenant, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// FindByCode finds a tenant by its unique code
+func (r *TenantRepository) FindByCode(ctx context.Context, code string) (*model.Tenant, error) {
+	return r.findOne(ctx, bson.M{"code": code})
+}
+
+// FindByDomain finds the tenant serving a host name
+func (r *TenantRepository) FindByDomain(ctx context.Context, host string) (*model.Tenant, error) {
+	return r.findOne(ctx, bson.M{"domains": strings.ToLower(host)})
+}
+
+// FindActive lists the active tenants
+func (r *TenantRepository) FindActive(ctx context.Context) ([]*model.Tenant, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"isActive": true})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tenants := []*model.Tenant{}
+	if err := cursor.All(ctx, &tenants); err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+func (r *TenantRepository) findOne(ctx context.Context, filter bson.M) (*model.Tenant, error) {
+	var tenant model.Tenant
+	err := r.collection.FindOne(ctx, filter).Decode(&tenant)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("tenant %w", ErrNotFound)
+		}
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// CreateIndexes creates necessary indexes for the tenants collection
+func (r *TenantRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "code", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// A host name may only map to one tenant; tenants without domains are skipped
+			Keys: bson.D{{Key: "domains", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"domains": bson.M{"$type": "string"}}),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
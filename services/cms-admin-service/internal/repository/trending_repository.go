@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TrendingRepository reads the engagement signals the trending score is
+// computed from and stores the scores on the articles. Comments, comment likes
+// and favourites may live in another database, shared with the stats service.
+type TrendingRepository struct {
+	articleCollection  *mongo.Collection
+	viewCollection     *mongo.Collection
+	commentCollection  *mongo.Collection
+	likeCollection     *mongo.Collection
+	favoriteCollection *mongo.Collection
+}
+
+// NewTrendingRepository creates a new trending repository
+func NewTrendingRepository(db, commentDB *mongo.Database) *TrendingRepository {
+	return &TrendingRepository{
+		articleCollection:  db.Collection("articles"),
+		viewCollection:     db.Collection("article_views"),
+		commentCollection:  commentDB.Collection("comments"),
+		likeCollection:     commentDB.Collection("comment_likes"),
+		favoriteCollection: commentDB.Collection("favorite_articles"),
+	}
+}
+
+// DecayedViews sums the daily views of each article since a time, each day
+// weighted by decay. A day's views are dated at its midday.
+func (r *TrendingRepository) DecayedViews(ctx context.Context, since, now time.Time, halfLife time.Duration) (map[primitive.ObjectID]float64, error) {
+	return r.decayedSums(ctx, r.viewCollection, []bson.M{
+		{"$match": bson.M{"date": bson.M{"$gte": since}}},
+		{"$project": bson.M{
+			"articleId": 1,
+			"value":     "$views",
+			"at":        bson.M{"$add": bson.A{"$date", (12 * time.Hour).Milliseconds()}},
+		}},
+	}, now, halfLife)
+}
+
+// DecayedComments counts the approved comments on each article since a time,
+// each weighted by decay
+func (r *TrendingRepository) DecayedComments(ctx context.Context, since, now time.Time, halfLife time.Duration) (map[primitive.ObjectID]float64, error) {
+	return r.decayedSums(ctx, r.commentCollection, []bson.M{
+		{"$match": bson.M{
+			"status":    model.CommentStatusApproved,
+			"createdAt": bson.M{"$gte": since},
+		}},
+		{"$project": bson.M{"articleId": 1, "value": bson.M{"$literal": 1}, "at": "$createdAt"}},
+	}, now, halfLife)
+}
+
+// DecayedCommentLikes counts the likes given since a time to the comments on
+// each article, each weighted by decay
+func (r *TrendingRepository) DecayedCommentLikes(ctx context.Context, since, now time.Time, halfLife time.Duration) (map[primitive.ObjectID]float64, error) {
+	return r.decayedSums(ctx, r.likeCollection, []bson.M{
+		{"$match": bson.M{"createdAt": bson.M{"$gte": since}}},
+		{"$lookup": bson.M{
+			"from":         r.commentCollection.Name(),
+			"localField":   "commentId",
+			"foreignField": "_id",
+			"as":           "comment",
+		}},
+		{"$unwind": "$comment"},
+		{"$project": bson.M{"articleId": "$comment.articleId", "value": bson.M{"$literal": 1}, "at": "$createdAt"}},
+	}, now, halfLife)
+}
+
+// DecayedFavorites counts the times each article was saved as a favourite
+// since a time, each weighted by decay
+func (r *TrendingRepository) DecayedFavorites(ctx context.Context, since, now time.Time, halfLife time.Duration) (map[primitive.ObjectID]float64, error) {
+	return r.decayedSums(ctx, r.favoriteCollection, []bson.M{
+		{"$match": bson.M{"createdAt": bson.M{"$gte": since}}},
+		{"$project": bson.M{"articleId": 1, "value": bson.M{"$literal": 1}, "at": "$createdAt"}},
+	}, now, halfLife)
+}
+
+// decayedSums runs stages that project articleId, value and at, and sums per
+// article value * 0.5^(age / halfLife). Signals dated after now count fully.
+func (r *TrendingRepository) decayedSums(ctx context.Context, collection *mongo.Collection, stages []bson.M, now time.Time, halfLife time.Duration) (map[primitive.ObjectID]float64, error) {
+	age := bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{now, "$at"}}}}
+	pipeline := append(stages, bson.M{"$group": bson.M{
+		"_id": "$articleId",
+		"score": bson.M{"$sum": bson.M{"$multiply": bson.A{
+			"$value",
+			bson.M{"$pow": bson.A{0.5, bson.M{"$divide": bson.A{age, halfLife.Milliseconds()}}}},
+		}}},
+	}})
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sums := make(map[primitive.ObjectID]float64)
+	for cursor.Next(ctx) {
+		var result struct {
+			ArticleID primitive.ObjectID `bson:"_id"`
+			Score     float64            `bson:"score"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		sums[result.ArticleID] = result.Score
+	}
+	return sums, cursor.Err()
+}
+
+// SaveScores stores the trending scores and resets the score of every other
+// article to zero
+func (r *TrendingRepository) SaveScores(ctx context.Context, scores map[primitive.ObjectID]float64) error {
+	stale, err := r.articleCollection.Distinct(ctx, "_id", bson.M{"trendingScore": bson.M{"$gt": 0}})
+	if err != nil {
+		return err
+	}
+
+	models := make([]mongo.WriteModel, 0, len(scores)+len(stale))
+	for id, score := range scores {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetUpdate(bson.M{"$set": bson.M{"trendingScore": score}}))
+	}
+	for _, value := range stale {
+		if id, ok := value.(primitive.ObjectID); ok {
+			if _, scored := scores[id]; !scored {
+				models = append(models, mongo.NewUpdateOneModel().
+					SetFilter(bson.M{"_id": id}).
+					SetUpdate(bson.M{"$set": bson.M{"trendingScore": 0}}))
+			}
+		}
+	}
+	if len(models) == 0 {
+		return nil
+	}
+
+	_, err = r.articleCollection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// UpdateHot marks the topN published articles of each tenant with the highest
+// trending score as hot and clears the flag on the others. Articles whose hot
+// flag was set by an editor are neither counted nor changed.
+func (r *TrendingRepository) UpdateHot(ctx context.Context, topN int) error {
+	notLocked := bson.M{"$ne": true}
+	cursor, err := r.articleCollection.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{
+			"status":        model.ArticleStatusPublished,
+			"hotLocked":     notLocked,
+			"trendingScore": bson.M{"$gt": 0},
+		}},
+		{"$setWindowFields": bson.M{
+			"partitionBy": "$tenantId",
+			"sortBy":      bson.M{"trendingScore": -1},
+			"output":      bson.M{"rank": bson.M{"$documentNumber": bson.M{}}},
+		}},
+		{"$match": bson.M{"rank": bson.M{"$lte": topN}}},
+		{"$project": bson.M{"_id": 1}},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	top := []primitive.ObjectID{}
+	for cursor.Next(ctx) {
+		var result struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return err
+		}
+		top = append(top, result.ID)
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if _, err := r.articleCollection.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": top}, "hot": bson.M{"$ne": true}, "hotLocked": notLocked},
+		bson.M{"$set": bson.M{"hot": true}},
+	); err != nil {
+		return err
+	}
+	_, err = r.articleCollection.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$nin": top}, "hot": true, "hotLocked": notLocked},
+		bson.M{"$set": bson.M{"hot": false}},
+	)
+	return err
+}
@@ -11,20 +11,29 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// ViewStatsRepository handles view statistics operations
+// recentViewBatches is how many flushed view batch IDs a document keeps to
+// recognise a retried batch
+const recentViewBatches = 50
+
+// ViewStatsRepository handles view statistics operations. Daily view and
+// engagement counts share one document per article and day; the reads per
+// referrer and UTM value are kept in their own collection.
 type ViewStatsRepository struct {
-	collection *mongo.Collection
+	collection       *mongo.Collection
+	sourceCollection *mongo.Collection
 }
 
 // NewViewStatsRepository creates a new view stats repository
 func NewViewStatsRepository(db *mongo.Database) *ViewStatsRepository {
 	return &ViewStatsRepository{
-		collection: db.Collection("article_views"),
+		collection:       db.Collection("article_views"),
+		sourceCollection: db.Collection("article_traffic_sources"),
 	}
 }
 
-// RecordView records a view for an article on a specific date
-func (r *ViewStatsRepository) RecordView(ctx context.Context, articleID primitive.ObjectID, date time.Time) error {
+// RecordView records a view for an article on a specific date; unique tells
+// whether it also counts as a unique view
+func (r *ViewStatsRepository) RecordView(ctx context.Context, articleID primitive.ObjectID, date time.Time, unique bool) error {
 	// Normalize date to start of day
 	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 
@@ -33,8 +42,12 @@ func (r *ViewStatsRepository) RecordView(ctx context.Context, articleID primitiv
 		"date":      date,
 	}
 
+	uniqueViews := 0
+	if unique {
+		uniqueViews = 1
+	}
 	update := bson.M{
-		"$inc": bson.M{"views": 1},
+		"$inc": bson.M{"views": 1, "uniqueViews": uniqueViews},
 		"$set": bson.M{"updatedAt": time.Now()},
 		"$setOnInsert": bson.M{
 			"articleId": articleID,
@@ -48,6 +61,118 @@ func (r *ViewStatsRepository) RecordView(ctx context.Context, articleID primitiv
 	return err
 }
 
+// AddViews adds the daily view and engagement counts of one flushed batch. The daily
+// documents are created first, so the increments can skip documents that
+// already counted the batch without racing other writers on the upsert; a
+// retried batch is therefore counted once.
+func (r *ViewStatsRepository) AddViews(ctx context.Context, batchID primitive.ObjectID, views []*model.ArticleView) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	upserts := make([]mongo.WriteModel, 0, len(views))
+	increments := make([]mongo.WriteModel, 0, len(views))
+	for _, view := range views {
+		key := bson.M{"articleId": view.ArticleID, "date": view.Date}
+		upserts = append(upserts, mongo.NewUpdateOneModel().
+			SetFilter(key).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{
+				"articleId":   view.ArticleID,
+				"date":        view.Date,
+				"views":       0,
+				"uniqueViews": 0,
+				"createdAt":   now,
+			}}).
+			SetUpsert(true))
+		increments = append(increments, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"articleId": view.ArticleID, "date": view.Date, "batchIds": bson.M{"$ne": batchID}}).
+			SetUpdate(bson.M{
+				"$inc":  dailyIncrements(view),
+				"$set":  bson.M{"updatedAt": now},
+				"$push": bson.M{"batchIds": bson.M{"$each": bson.A{batchID}, "$slice": -recentViewBatches}},
+			}))
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+	// A duplicate key only means another writer created the document first
+	if _, err := r.collection.BulkWrite(ctx, upserts, opts); err != nil && !onlyDuplicateKeys(err) {
+		return err
+	}
+	_, err := r.collection.BulkWrite(ctx, increments, opts)
+	return err
+}
+
+// dailyIncrements lists the counters of a daily document to increment
+func dailyIncrements(view *model.ArticleView) bson.M {
+	e := view.Engagement
+	inc := bson.M{"views": view.Views, "uniqueViews": view.UniqueViews}
+	if e.Reads == 0 {
+		return inc
+	}
+	for field, value := range map[string]int{
+		"reads":         e.Reads,
+		"activeSeconds": e.ActiveSeconds,
+		"depth0":        e.Depth0,
+		"depth25":       e.Depth25,
+		"depth50":       e.Depth50,
+		"depth75":       e.Depth75,
+		"depth100":      e.Depth100,
+		"mobile":        e.Mobile,
+		"tablet":        e.Tablet,
+		"desktop":       e.Desktop,
+	} {
+		inc["engagement."+field] = value
+	}
+	return inc
+}
+
+// AddTrafficSources adds the reads per referrer and UTM value of one flushed
+// batch, skipping documents that already counted it like AddViews
+func (r *ViewStatsRepository) AddTrafficSources(ctx context.Context, batchID primitive.ObjectID, sources []*model.TrafficSource) error {
+	if len(sources) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	upserts := make([]mongo.WriteModel, 0, len(sources))
+	increments := make([]mongo.WriteModel, 0, len(sources))
+	for _, source := range sources {
+		key := bson.M{"articleId": source.ArticleID, "date": source.Date, "type": source.Type, "value": source.Value}
+		upserts = append(upserts, mongo.NewUpdateOneModel().
+			SetFilter(key).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{
+				"articleId": source.ArticleID,
+				"date":      source.Date,
+				"type":      source.Type,
+				"value":     source.Value,
+				"count":     0,
+				"createdAt": now,
+			}}).
+			SetUpsert(true))
+		increments = append(increments, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{
+				"articleId": source.ArticleID,
+				"date":      source.Date,
+				"type":      source.Type,
+				"value":     source.Value,
+				"batchIds":  bson.M{"$ne": batchID},
+			}).
+			SetUpdate(bson.M{
+				"$inc":  bson.M{"count": source.Count},
+				"$set":  bson.M{"updatedAt": now},
+				"$push": bson.M{"batchIds": bson.M{"$each": bson.A{batchID}, "$slice": -recentViewBatches}},
+			}))
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+	if _, err := r.sourceCollection.BulkWrite(ctx, upserts, opts); err != nil && !onlyDuplicateKeys(err) {
+		return err
+	}
+	_, err := r.sourceCollection.BulkWrite(ctx, increments, opts)
+	return err
+}
+
 // GetArticleStats gets view statistics for a specific article
 func (r *ViewStatsRepository) GetArticleStats(ctx context.Context, articleID primitive.ObjectID, startDate, endDate time.Time) ([]*model.ArticleView, error) {
 	filter := bson.M{
@@ -113,7 +238,8 @@ func (r *ViewStatsRepository) GetCategoryStats(ctx context.Context, categoryID p
 	return 0, nil
 }
 
-// CreateIndexes creates necessary indexes for the article_views collection
+// CreateIndexes creates necessary indexes for the article_views and
+// article_traffic_sources collections
 func (r *ViewStatsRepository) CreateIndexes(ctx context.Context) error {
 	indexes := []mongo.IndexModel{
 		{
@@ -125,8 +251,27 @@ func (r *ViewStatsRepository) CreateIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "date", Value: -1}},
 		},
+		{
+			// One document per article and day, which AddViews relies on
+			Keys:    bson.D{{Key: "articleId", Value: 1}, {Key: "date", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
 	}
 
-	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	if _, err := r.collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		return err
+	}
+
+	// One document per article, day and source, which AddTrafficSources
+	// relies on; it also serves the top sources of an article
+	_, err := r.sourceCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "articleId", Value: 1},
+			{Key: "date", Value: 1},
+			{Key: "type", Value: 1},
+			{Key: "value", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
 	return err
 }
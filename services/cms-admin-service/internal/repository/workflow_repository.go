@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WorkflowRepository handles workflow definition data operations
+type WorkflowRepository struct {
+	collection *mongo.Collection
+}
+
+// NewWorkflowRepository creates a new workflow repository
+func NewWorkflowRepository(db *mongo.Database) *WorkflowRepository {
+	return &WorkflowRepository{
+		collection: db.Collection("workflows"),
+	}
+}
+
+// Create creates a new workflow
+func (r *WorkflowRepository) Create(ctx context.Context, workflow *model.Workflow) error {
+	workflow.ID = primitive.NewObjectID()
+	workflow.CreatedAt = time.Now()
+	workflow.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, workflow)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("workflow for this scope %w", ErrDuplicate)
+	}
+	return err
+}
+
+// FindByID finds a workflow of a tenant by ID
+func (r *WorkflowRepository) FindByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.Workflow, error) {
+	return r.findOne(ctx, bson.M{"_id": id, "tenantId": tenantID})
+}
+
+// FindByCategory finds the workflow attached to a category; a nil category
+// finds the tenant default
+func (r *WorkflowRepository) FindByCategory(ctx context.Context, tenantID primitive.ObjectID, categoryID *primitive.ObjectID) (*model.Workflow, error) {
+	return r.findOne(ctx, bson.M{"tenantId": tenantID, "categoryId": categoryID})
+}
+
+func (r *WorkflowRepository) findOne(ctx context.Context, filter bson.M) (*model.Workflow, error) {
+	var workflow model.Workflow
+	err := r.collection.FindOne(ctx, filter).Decode(&workflow)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("workflow %w", ErrNotFound)
+		}
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// FindAll finds all workflows of a tenant
+func (r *WorkflowRepository) FindAll(ctx context.Context, tenantID primitive.ObjectID) ([]*model.Workflow, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"tenantId": tenantID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	workflows := []*model.Workflow{}
+	if err := cursor.All(ctx, &workflows); err != nil {
+		return nil, err
+	}
+	return workflows, nil
+}
+
+// Update updates a workflow
+func (r *WorkflowRepository) Update(ctx context.Context, workflow *model.Workflow) error {
+	workflow.UpdatedAt = time.Now()
+
+	filter := bson.M{"_id": workflow.ID, "tenantId": workflow.TenantID}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": workflow})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("workflow for this scope %w", ErrDuplicate)
+		}
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("workflow %w", ErrNotFound)
+	}
+	return nil
+}
+
+// Delete deletes a workflow
+func (r *WorkflowRepository) Delete(ctx context.Context, tenantID, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "tenantId": tenantID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("workflow %w", ErrNotFound)
+	}
+	return nil
+}
+
+// CreateIndexes creates necessary indexes for workflows
+func (r *WorkflowRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			// One workflow per category, plus one tenant default (null category)
+			Keys:    bson.D{{Key: "tenantId", Value: 1}, {Key: "categoryId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -1,361 +1,554 @@
 package service
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
+	"log"
 	"net/http"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/ai"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
 )
 
+// maxAITextLength limits the text sent to a provider, in characters
+const maxAITextLength = 50000
+
+// AIService runs the AI editor tools with the providers configured per tenant
 type AIService struct {
 	configRepo *repository.AIConfigRepository
 	logRepo    *repository.AIOperationLogRepository
+	usageRepo  *repository.AIUsageCounterRepository
 	httpClient *http.Client
 }
 
-func NewAIService(configRepo *repository.AIConfigRepository, logRepo *repository.AIOperationLogRepository) *AIService {
+func NewAIService(configRepo *repository.AIConfigRepository, logRepo *repository.AIOperationLogRepository, usageRepo *repository.AIUsageCounterRepository) *AIService {
 	return &AIService{
 		configRepo: configRepo,
 		logRepo:    logRepo,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		usageRepo:  usageRepo,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
 	}
 }
 
-// CheckSpelling checks spelling and grammar
-func (s *AIService) CheckSpelling(ctx context.Context, tenantID, userID, text string) (*model.SpellCheckResult, error) {
+// aiFeature describes one AI operation: the tenant setting that enables it,
+// the setting that picks its provider and the capability the provider needs
+type aiFeature struct {
+	operation string // Operation name in the log
+	label     string
+	field     string // Configuration field naming the provider
+	enabled   func(config *model.AIConfiguration) bool
+	provider  func(config *model.AIConfiguration) string
+	supports  func(provider ai.Provider) bool
+}
+
+var (
+	spellCheckFeature = aiFeature{
+		operation: "spell_check",
+		label:     "spell check",
+		field:     "spellCheckProvider",
+		enabled:   func(c *model.AIConfiguration) bool { return c.SpellCheckEnabled },
+		provider:  func(c *model.AIConfiguration) string { return c.SpellCheckProvider },
+		supports:  func(p ai.Provider) bool { _, ok := p.(ai.SpellChecker); return ok },
+	}
+	translationFeature = aiFeature{
+		operation: "translate",
+		label:     "translation",
+		field:     "translationProvider",
+		enabled:   func(c *model.AIConfiguration) bool { return c.TranslationEnabled },
+		provider:  func(c *model.AIConfiguration) string { return c.TranslationProvider },
+		supports:  func(p ai.Provider) bool { _, ok := p.(ai.Translator); return ok },
+	}
+	contentEditFeature = aiFeature{
+		operation: "improve_content",
+		label:     "content editing",
+		field:     "aiProvider",
+		enabled:   func(c *model.AIConfiguration) bool { return c.ContentEditEnabled },
+		provider:  func(c *model.AIConfiguration) string { return c.AIProvider },
+		supports:  func(p ai.Provider) bool { _, ok := p.(ai.ContentImprover); return ok },
+	}
+	violationFeature = aiFeature{
+		operation: "detect_violation",
+		label:     "violation detection",
+		field:     "aiProvider",
+		enabled:   func(c *model.AIConfiguration) bool { return c.ViolationDetection },
+		provider:  func(c *model.AIConfiguration) string { return c.AIProvider },
+		supports:  func(p ai.Provider) bool { _, ok := p.(ai.Moderator); return ok },
+	}
+
+	aiFeatures = []aiFeature{spellCheckFeature, translationFeature, contentEditFeature, violationFeature}
+)
+
+// GetConfig returns the AI configuration of a tenant; tenants without one get
+// an empty configuration with every feature disabled
+func (s *AIService) GetConfig(ctx context.Context, tenantID string) (*model.AIConfiguration, error) {
 	config, err := s.configRepo.GetByTenant(ctx, tenantID)
-	if err != nil || config == nil || !config.SpellCheckEnabled {
-		return nil, fmt.Errorf("spell check not enabled for tenant")
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = &model.AIConfiguration{TenantID: tenantID}
+	}
+	return config, nil
+}
+
+// UpdateConfig validates and saves the AI configuration of a tenant. API keys
+// left empty keep their stored value, so clients never need to read keys back.
+func (s *AIService) UpdateConfig(ctx context.Context, config *model.AIConfiguration) error {
+	existing, err := s.configRepo.GetByTenant(ctx, config.TenantID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		config.ID = existing.ID
+		config.CreatedAt = existing.CreatedAt
+		if err := keepStoredKeys(config, existing); err != nil {
+			return err
+		}
 	}
 
-	startTime := time.Now()
+	if err := s.validateConfig(config); err != nil {
+		return err
+	}
+	return s.configRepo.Upsert(ctx, config)
+}
 
-	var result *model.SpellCheckResult
-	var opErr error
+// keepStoredKeys fills in the API keys left empty with their stored values. A
+// stored key is only kept while it goes to the same endpoint; otherwise anyone
+// able to change the endpoint could have the key sent to a server of theirs.
+func keepStoredKeys(config, existing *model.AIConfiguration) error {
+	var errs validator.ValidationErrors
+	tenantKeyEntered := config.APIKey != ""
+	if !tenantKeyEntered {
+		if existing.APIKey != "" && config.APIEndpoint != existing.APIEndpoint {
+			errs = append(errs, validator.ValidationError{Field: "apiKey", Message: "apiKey has to be entered again when apiEndpoint changes"})
+		}
+		config.APIKey = existing.APIKey
+	}
 
-	switch config.SpellCheckProvider {
-	case "openai":
-		result, opErr = s.spellCheckOpenAI(ctx, config, text)
-	case "languagetool":
-		result, opErr = s.spellCheckLanguageTool(ctx, config, text)
-	default:
-		opErr = fmt.Errorf("unsupported spell check provider: %s", config.SpellCheckProvider)
+	for name, settings := range config.Providers {
+		if settings.APIKey != "" {
+			continue
+		}
+		stored := existing.Providers[name]
+		if providerConfig(config, name, nil).Endpoint == providerConfig(existing, name, nil).Endpoint {
+			settings.APIKey = stored.APIKey
+			config.Providers[name] = settings
+			continue
+		}
+		// Without a key of its own the provider falls back to the tenant-wide key
+		if stored.APIKey != "" || (!tenantKeyEntered && config.APIKey != "") {
+			field := "providers." + name + ".apiKey"
+			errs = append(errs, validator.ValidationError{Field: field, Message: field + " has to be entered again when the provider's endpoint changes"})
+		}
 	}
 
-	duration := time.Since(startTime).Milliseconds()
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
 
-	// Log operation
-	log := &model.AIOperationLog{
-		TenantID:  tenantID,
-		UserID:    userID,
-		Operation: "spell_check",
-		Provider:  config.SpellCheckProvider,
-		InputText: text,
-		Success:   opErr == nil,
-		Duration:  duration,
-		CreatedAt: time.Now(),
+// validateConfig checks that every enabled feature has a provider that
+// supports it, and the model settings
+func (s *AIService) validateConfig(config *model.AIConfiguration) error {
+	var errs validator.ValidationErrors
+
+	for _, feature := range aiFeatures {
+		if !feature.enabled(config) {
+			continue
+		}
+		name := feature.provider(config)
+		if name == "" {
+			errs = append(errs, validator.ValidationError{Field: feature.field, Message: fmt.Sprintf("a provider is required when %s is enabled", feature.label)})
+			continue
+		}
+		provider, err := ai.New(name, ai.Config{})
+		if err != nil {
+			errs = append(errs, validator.ValidationError{Field: feature.field, Message: fmt.Sprintf("unknown provider %q; available: %s", name, strings.Join(ai.Names(), ", "))})
+			continue
+		}
+		if !feature.supports(provider) {
+			errs = append(errs, validator.ValidationError{Field: feature.field, Message: fmt.Sprintf("%s does not support %s", name, feature.label)})
+		}
 	}
-	if opErr != nil {
-		log.Error = opErr.Error()
+
+	if config.MaxTokens < 0 {
+		errs = append(errs, validator.ValidationError{Field: "maxTokens", Message: "maxTokens cannot be negative"})
+	}
+	if config.Temperature < 0 || config.Temperature > 2 {
+		errs = append(errs, validator.ValidationError{Field: "temperature", Message: "temperature must be between 0 and 2"})
 	}
-	s.logRepo.Create(ctx, log)
 
-	return result, opErr
+	limits := []struct {
+		field string
+		value int
+	}{
+		{"dailyLimit", config.DailyLimit},
+		{"monthlyLimit", config.MonthlyLimit},
+		{"userDailyLimit", config.UserDailyLimit},
+		{"userMonthlyLimit", config.UserMonthlyLimit},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 {
+			errs = append(errs, validator.ValidationError{Field: limit.field, Message: limit.field + " cannot be negative"})
+		}
+	}
+
+	for name, price := range config.Prices {
+		if price.InputTokens < 0 || price.OutputTokens < 0 || price.Characters < 0 {
+			errs = append(errs, validator.ValidationError{Field: "prices." + name, Message: "prices cannot be negative"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
 
-// Translate translates text to target language
-func (s *AIService) Translate(ctx context.Context, tenantID, userID, text, targetLang string) (*model.TranslationResult, error) {
-	config, err := s.configRepo.GetByTenant(ctx, tenantID)
-	if err != nil || config == nil || !config.TranslationEnabled {
-		return nil, fmt.Errorf("translation not enabled for tenant")
+// CheckSpelling checks spelling and grammar
+func (s *AIService) CheckSpelling(ctx context.Context, tenantID, userID, text, language string) (*model.SpellCheckResult, error) {
+	if err := validateAIText("text", text); err != nil {
+		return nil, err
 	}
 
-	startTime := time.Now()
+	call, err := s.begin(ctx, tenantID, userID, spellCheckFeature)
+	if err != nil {
+		return nil, err
+	}
+	result, usage, opErr := call.provider.(ai.SpellChecker).CheckSpelling(ctx, text, language)
 
-	var result *model.TranslationResult
-	var opErr error
+	return result, s.finish(ctx, call, &model.AIOperationLog{
+		TenantID:   tenantID,
+		UserID:     userID,
+		Operation:  spellCheckFeature.operation,
+		InputText:  text,
+		SourceLang: language,
+	}, usage, opErr)
+}
 
-	switch config.TranslationProvider {
-	case "openai":
-		result, opErr = s.translateOpenAI(ctx, config, text, targetLang)
-	case "google":
-		result, opErr = s.translateGoogle(ctx, config, text, targetLang)
-	case "deepl":
-		result, opErr = s.translateDeepL(ctx, config, text, targetLang)
-	default:
-		opErr = fmt.Errorf("unsupported translation provider: %s", config.TranslationProvider)
+// Translate translates text to the target language; sourceLang may be empty
+// to detect it
+func (s *AIService) Translate(ctx context.Context, tenantID, userID, text, sourceLang, targetLang string) (*model.TranslationResult, error) {
+	if err := validateAIText("text", text); err != nil {
+		return nil, err
+	}
+	if targetLang == "" {
+		return nil, validator.ValidationErrors{{Field: "targetLang", Message: "targetLang is required"}}
 	}
 
-	duration := time.Since(startTime).Milliseconds()
+	call, err := s.begin(ctx, tenantID, userID, translationFeature)
+	if err != nil {
+		return nil, err
+	}
+	result, usage, opErr := call.provider.(ai.Translator).Translate(ctx, text, sourceLang, targetLang)
 
-	// Log operation
-	log := &model.AIOperationLog{
+	entry := &model.AIOperationLog{
 		TenantID:   tenantID,
 		UserID:     userID,
-		Operation:  "translate",
-		Provider:   config.TranslationProvider,
+		Operation:  translationFeature.operation,
 		InputText:  text,
+		SourceLang: sourceLang,
 		TargetLang: targetLang,
-		Success:    opErr == nil,
-		Duration:   duration,
-		CreatedAt:  time.Now(),
 	}
 	if result != nil {
-		log.OutputText = result.TranslatedText
-		log.SourceLang = result.SourceLang
-	}
-	if opErr != nil {
-		log.Error = opErr.Error()
+		entry.OutputText = result.TranslatedText
+		entry.SourceLang = result.SourceLang
 	}
-	s.logRepo.Create(ctx, log)
-
-	return result, opErr
+	return result, s.finish(ctx, call, entry, usage, opErr)
 }
 
 // ImproveContent suggests content improvements
 func (s *AIService) ImproveContent(ctx context.Context, tenantID, userID, articleID, content string) (*model.ContentEditSuggestion, error) {
-	config, err := s.configRepo.GetByTenant(ctx, tenantID)
-	if err != nil || config == nil || !config.ContentEditEnabled {
-		return nil, fmt.Errorf("content editing not enabled for tenant")
+	if err := validateAIText("content", content); err != nil {
+		return nil, err
 	}
 
-	startTime := time.Now()
-
-	var result *model.ContentEditSuggestion
-	var opErr error
-
-	switch config.AIProvider {
-	case "openai":
-		result, opErr = s.improveContentOpenAI(ctx, config, content)
-	case "anthropic":
-		result, opErr = s.improveContentAnthropic(ctx, config, content)
-	default:
-		opErr = fmt.Errorf("unsupported AI provider: %s", config.AIProvider)
+	call, err := s.begin(ctx, tenantID, userID, contentEditFeature)
+	if err != nil {
+		return nil, err
 	}
+	result, usage, opErr := call.provider.(ai.ContentImprover).ImproveContent(ctx, content)
 
-	duration := time.Since(startTime).Milliseconds()
-
-	// Log operation
-	log := &model.AIOperationLog{
+	entry := &model.AIOperationLog{
 		TenantID:  tenantID,
 		UserID:    userID,
 		ArticleID: articleID,
-		Operation: "improve_content",
-		Provider:  config.AIProvider,
+		Operation: contentEditFeature.operation,
 		InputText: content,
-		Success:   opErr == nil,
-		Duration:  duration,
-		CreatedAt: time.Now(),
 	}
 	if result != nil {
-		log.OutputText = result.Improved
-	}
-	if opErr != nil {
-		log.Error = opErr.Error()
+		entry.OutputText = result.Improved
 	}
-	s.logRepo.Create(ctx, log)
-
-	return result, opErr
+	return result, s.finish(ctx, call, entry, usage, opErr)
 }
 
 // DetectViolation detects content violations using AI
 func (s *AIService) DetectViolation(ctx context.Context, tenantID, userID, content string) (*model.ViolationDetectionResult, error) {
-	config, err := s.configRepo.GetByTenant(ctx, tenantID)
-	if err != nil || config == nil || !config.ViolationDetection {
-		return nil, fmt.Errorf("violation detection not enabled for tenant")
+	if err := validateAIText("content", content); err != nil {
+		return nil, err
 	}
 
-	startTime := time.Now()
-
-	var result *model.ViolationDetectionResult
-	var opErr error
-
-	switch config.AIProvider {
-	case "openai":
-		result, opErr = s.detectViolationOpenAI(ctx, config, content)
-	case "anthropic":
-		result, opErr = s.detectViolationAnthropic(ctx, config, content)
-	default:
-		opErr = fmt.Errorf("unsupported AI provider: %s", config.AIProvider)
+	call, err := s.begin(ctx, tenantID, userID, violationFeature)
+	if err != nil {
+		return nil, err
 	}
+	result, usage, opErr := call.provider.(ai.Moderator).DetectViolation(ctx, content)
 
-	duration := time.Since(startTime).Milliseconds()
-
-	// Log operation
-	log := &model.AIOperationLog{
+	return result, s.finish(ctx, call, &model.AIOperationLog{
 		TenantID:  tenantID,
 		UserID:    userID,
-		Operation: "detect_violation",
-		Provider:  config.AIProvider,
+		Operation: violationFeature.operation,
 		InputText: content,
-		Success:   opErr == nil,
-		Duration:  duration,
-		CreatedAt: time.Now(),
-	}
-	if opErr != nil {
-		log.Error = opErr.Error()
-	}
-	s.logRepo.Create(ctx, log)
-
-	return result, opErr
+	}, usage, opErr)
 }
 
-// Provider-specific implementations (simplified - would need actual API integration)
-
-func (s *AIService) spellCheckOpenAI(ctx context.Context, config *model.AIConfiguration, text string) (*model.SpellCheckResult, error) {
-	// Sanitize input to prevent prompt injection
-	sanitizedText := strings.ReplaceAll(text, "\n", " ")
-	sanitizedText = strings.ReplaceAll(sanitizedText, "\"", "'")
-	if len(sanitizedText) > 5000 {
-		sanitizedText = sanitizedText[:5000] // Limit length
+// GetUsage reports a tenant's AI calls between two times, broken down by
+// operation, provider and user, with the use of its quotas in the current
+// periods. A non-empty userID limits the report to that user.
+func (s *AIService) GetUsage(ctx context.Context, tenantID, userID string, from, to time.Time) (*model.AIUsageReport, error) {
+	if !to.After(from) {
+		return nil, validator.ValidationErrors{{Field: "to", Message: "to must be after from"}}
 	}
 
-	_ = "Check the following text for spelling and grammar errors. Return a JSON array of corrections."
+	report, err := s.logRepo.GetUsageStats(ctx, tenantID, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
 
-	// Mock response for now - in production, integrate with actual OpenAI API
-	return &model.SpellCheckResult{
-		Original:    text,
-		Corrections: []model.SpellCorrection{},
-		HasErrors:   false,
-	}, nil
+	config, err := s.GetConfig(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	report.Quotas = []model.AIQuotaUsage{}
+	for _, q := range aiQuotas(config, tenantID, userID, time.Now()) {
+		used, err := s.usageRepo.Count(ctx, tenantID, q.counter.UserID, q.counter.Period, q.counter.PeriodStart)
+		if err != nil {
+			return nil, err
+		}
+		report.Quotas = append(report.Quotas, model.AIQuotaUsage{
+			Scope:    q.scope,
+			Limit:    q.limit,
+			Used:     used,
+			ResetsAt: q.resetsAt,
+		})
+	}
+	return report, nil
 }
 
-func (s *AIService) spellCheckLanguageTool(ctx context.Context, config *model.AIConfiguration, text string) (*model.SpellCheckResult, error) {
-	// Integration with LanguageTool API
-	return &model.SpellCheckResult{
-		Original:    text,
-		Corrections: []model.SpellCorrection{},
-		HasErrors:   false,
-	}, nil
+// aiCall is an AI operation in progress
+type aiCall struct {
+	config   *model.AIConfiguration
+	provider ai.Provider
+	name     string
+	release  func() // Takes back the quota the call counted against
+	start    time.Time
 }
 
-func (s *AIService) translateOpenAI(ctx context.Context, config *model.AIConfiguration, text, targetLang string) (*model.TranslationResult, error) {
-	type openAIRequest struct {
-		Model    string `json:"model"`
-		Messages []struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		} `json:"messages"`
-		MaxTokens   int     `json:"max_tokens,omitempty"`
-		Temperature float64 `json:"temperature,omitempty"`
-	}
-
-	req := openAIRequest{
-		Model: config.Model,
-		Messages: []struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		}{
-			{Role: "system", Content: fmt.Sprintf("You are a translator. Translate the following text to %s. Return only the translation without any explanation.", targetLang)},
-			{Role: "user", Content: text},
-		},
-		MaxTokens:   config.MaxTokens,
-		Temperature: config.Temperature,
+// begin loads the tenant configuration, creates the provider of a feature,
+// which is known to support it, and counts the call against the quotas
+func (s *AIService) begin(ctx context.Context, tenantID, userID string, feature aiFeature) (*aiCall, error) {
+	config, err := s.configRepo.GetByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil || !feature.enabled(config) {
+		return nil, fmt.Errorf("%w: %s is not enabled for this tenant", ErrForbidden, feature.label)
 	}
 
-	jsonData, _ := json.Marshal(req)
-	httpReq, _ := http.NewRequestWithContext(ctx, "POST", config.APIEndpoint, bytes.NewBuffer(jsonData))
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+config.APIKey)
+	name := feature.provider(config)
+	provider, err := ai.New(name, providerConfig(config, name, s.httpClient))
+	if err != nil {
+		return nil, err
+	}
+	if !feature.supports(provider) {
+		return nil, fmt.Errorf("AI provider %s does not support %s", name, feature.label)
+	}
 
-	resp, err := s.httpClient.Do(httpReq)
+	release, err := s.reserve(ctx, aiQuotas(config, tenantID, userID, time.Now()))
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
+	return &aiCall{config: config, provider: provider, name: name, release: release, start: time.Now()}, nil
+}
 
-	// Parse OpenAI response
-	var openAIResp struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
+// finish completes and saves the operation log, computing the cost from the
+// tenant's price table. Failed calls do not count against the quotas.
+func (s *AIService) finish(ctx context.Context, call *aiCall, entry *model.AIOperationLog, usage ai.Usage, opErr error) error {
+	if opErr != nil {
+		call.release()
 	}
 
-	if err := json.Unmarshal(body, &openAIResp); err != nil {
-		return nil, err
-	}
+	entry.Duration = time.Since(call.start).Milliseconds()
+	entry.Provider = call.name
+	entry.Model = usage.Model
+	entry.Cost = aiCost(call.config.Prices, call.name, usage)
+	s.logOperation(ctx, entry, usage, opErr)
 
-	if len(openAIResp.Choices) == 0 {
-		return nil, fmt.Errorf("no translation returned")
+	return providerError(opErr)
+}
+
+// aiQuota is one limit on the calls of a tenant or user in a period
+type aiQuota struct {
+	scope    string
+	limit    int
+	resetsAt time.Time
+	counter  model.AIUsageCounter
+}
+
+// aiQuotas lists the limits that apply to a call by userID at now. Periods are
+// UTC days and months; counters are kept for a day after their period ends.
+func aiQuotas(config *model.AIConfiguration, tenantID, userID string, now time.Time) []aiQuota {
+	now = now.UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	type period struct {
+		name          string
+		start, resets time.Time
+	}
+	day := period{"day", dayStart, dayStart.AddDate(0, 0, 1)}
+	month := period{"month", monthStart, monthStart.AddDate(0, 1, 0)}
+
+	candidates := []struct {
+		scope   string
+		perUser bool
+		period  period
+		limit   int
+	}{
+		{"tenant daily", false, day, config.DailyLimit},
+		{"tenant monthly", false, month, config.MonthlyLimit},
+		{"user daily", true, day, config.UserDailyLimit},
+		{"user monthly", true, month, config.UserMonthlyLimit},
 	}
 
-	return &model.TranslationResult{
-		SourceText:     text,
-		TranslatedText: openAIResp.Choices[0].Message.Content,
-		TargetLang:     targetLang,
-		Provider:       "openai",
-	}, nil
+	var quotas []aiQuota
+	for _, c := range candidates {
+		if c.limit <= 0 || (c.perUser && userID == "") {
+			continue
+		}
+		counter := model.AIUsageCounter{
+			TenantID:    tenantID,
+			Period:      c.period.name,
+			PeriodStart: c.period.start,
+			ExpiresAt:   c.period.resets.AddDate(0, 0, 1),
+		}
+		if c.perUser {
+			counter.UserID = userID
+		}
+		quotas = append(quotas, aiQuota{
+			scope:    c.scope,
+			limit:    c.limit,
+			resetsAt: c.period.resets,
+			counter:  counter,
+		})
+	}
+	return quotas
 }
 
-func (s *AIService) translateGoogle(ctx context.Context, config *model.AIConfiguration, text, targetLang string) (*model.TranslationResult, error) {
-	// Google Translate API integration
-	return &model.TranslationResult{
-		SourceText:     text,
-		TranslatedText: text, // Placeholder
-		TargetLang:     targetLang,
-		Provider:       "google",
-	}, nil
+// reserve counts a call against every quota, or against none if one of them
+// is used up. The returned function takes the call back.
+func (s *AIService) reserve(ctx context.Context, quotas []aiQuota) (func(), error) {
+	var counted []*model.AIUsageCounter
+	release := func() {
+		// The call may outlive the request, so releasing ignores cancellation
+		ctx := context.WithoutCancel(ctx)
+		for _, counter := range counted {
+			if err := s.usageRepo.Decrement(ctx, counter); err != nil {
+				log.Printf("Failed to release AI quota: %v", err)
+			}
+		}
+	}
+
+	for i := range quotas {
+		q := &quotas[i]
+		ok, err := s.usageRepo.Increment(ctx, &q.counter, q.limit)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if !ok {
+			release()
+			return nil, &QuotaExceededError{Scope: q.scope, Limit: q.limit, ResetsAt: q.resetsAt}
+		}
+		counted = append(counted, &q.counter)
+	}
+	return release, nil
 }
 
-func (s *AIService) translateDeepL(ctx context.Context, config *model.AIConfiguration, text, targetLang string) (*model.TranslationResult, error) {
-	// DeepL API integration
-	return &model.TranslationResult{
-		SourceText:     text,
-		TranslatedText: text, // Placeholder
-		TargetLang:     targetLang,
-		Provider:       "deepl",
-	}, nil
+// aiCost prices a call with the price of its model, or of its provider for
+// providers without models. Calls without a price cost nothing.
+func aiCost(prices map[string]model.AIPrice, provider string, usage ai.Usage) float64 {
+	price, ok := prices[usage.Model]
+	if !ok || usage.Model == "" {
+		if price, ok = prices[provider]; !ok {
+			return 0
+		}
+	}
+	return (float64(usage.InputTokens)*price.InputTokens +
+		float64(usage.OutputTokens)*price.OutputTokens +
+		float64(usage.Characters)*price.Characters) / 1e6
 }
 
-func (s *AIService) improveContentOpenAI(ctx context.Context, config *model.AIConfiguration, content string) (*model.ContentEditSuggestion, error) {
-	// OpenAI content improvement
-	return &model.ContentEditSuggestion{
-		Original:   content,
-		Improved:   content,
-		Changes:    []string{},
-		Confidence: 0.8,
-	}, nil
+// providerConfig builds the API settings of a provider, applying the
+// provider's overrides to the tenant-wide settings
+func providerConfig(config *model.AIConfiguration, name string, client *http.Client) ai.Config {
+	cfg := ai.Config{
+		APIKey:      config.APIKey,
+		Endpoint:    config.APIEndpoint,
+		Model:       config.Model,
+		MaxTokens:   config.MaxTokens,
+		Temperature: config.Temperature,
+		HTTPClient:  client,
+	}
+	if settings, ok := config.Providers[name]; ok {
+		if settings.APIKey != "" {
+			cfg.APIKey = settings.APIKey
+		}
+		if settings.APIEndpoint != "" {
+			cfg.Endpoint = settings.APIEndpoint
+		}
+		if settings.Model != "" {
+			cfg.Model = settings.Model
+		}
+	}
+	return cfg
 }
 
-func (s *AIService) improveContentAnthropic(ctx context.Context, config *model.AIConfiguration, content string) (*model.ContentEditSuggestion, error) {
-	// Anthropic content improvement
-	return &model.ContentEditSuggestion{
-		Original:   content,
-		Improved:   content,
-		Changes:    []string{},
-		Confidence: 0.8,
-	}, nil
+// logOperation completes and saves an operation log. Logging failures do not
+// fail the operation.
+func (s *AIService) logOperation(ctx context.Context, entry *model.AIOperationLog, usage ai.Usage, opErr error) {
+	entry.TokensUsed = usage.Tokens()
+	entry.Success = opErr == nil
+	if opErr != nil {
+		entry.Error = opErr.Error()
+	}
+	if err := s.logRepo.Create(ctx, entry); err != nil {
+		log.Printf("Failed to log AI operation: %v", err)
+	}
 }
 
-func (s *AIService) detectViolationOpenAI(ctx context.Context, config *model.AIConfiguration, content string) (*model.ViolationDetectionResult, error) {
-	// OpenAI violation detection
-	return &model.ViolationDetectionResult{
-		HasViolation:  false,
-		ViolationType: "",
-		Confidence:    0.0,
-		Explanation:   "No violations detected",
-		Suggestions:   []string{},
-	}, nil
+// providerError marks provider failures so they are reported as such
+func providerError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ErrAIProvider, err)
 }
 
-func (s *AIService) detectViolationAnthropic(ctx context.Context, config *model.AIConfiguration, content string) (*model.ViolationDetectionResult, error) {
-	// Anthropic violation detection
-	return &model.ViolationDetectionResult{
-		HasViolation:  false,
-		ViolationType: "",
-		Confidence:    0.0,
-		Explanation:   "No violations detected",
-		Suggestions:   []string{},
-	}, nil
+// validateAIText checks the text sent to a provider
+func validateAIText(field, text string) error {
+	if strings.TrimSpace(text) == "" {
+		return validator.ValidationErrors{{Field: field, Message: field + " is required"}}
+	}
+	if utf8.RuneCountInString(text) > maxAITextLength {
+		return validator.ValidationErrors{{Field: field, Message: fmt.Sprintf("%s cannot be longer than %d characters", field, maxAITextLength)}}
+	}
+	return nil
 }
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -10,51 +11,115 @@ import (
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// heldForReviewNote is logged when sensitive keywords send an article to review
+const heldForReviewNote = "Held for review: content matches sensitive keywords"
+
+// editLockTTL is how long an edit lock lasts unless its holder renews it
+const editLockTTL = 2 * time.Minute
+
 // ViewQueue interface for dependency injection
 type ViewQueue interface {
-	Enqueue(articleID primitive.ObjectID) error
+	Enqueue(event model.ViewEvent) error
+	EnqueueEngagement(event model.EngagementEvent) error
 }
 
 // ArticleService handles article business logic
 type ArticleService struct {
 	repo              *repository.ArticleRepository
+	categoryRepo      *repository.CategoryRepository
+	typeConfigRepo    *repository.TenantArticleTypeConfigRepository
+	schemaRepo        *repository.ArticleTypeSchemaRepository
+	workflowService   *WorkflowService
+	keywordService    *SensitiveKeywordService
 	permissionRepo    *repository.PermissionRepository
 	viewStatsRepo     *repository.ViewStatsRepository
 	viewQueue         ViewQueue
+	viewFilter        *util.ViewFilter
 	actionLogRepo     *repository.ActionLogRepository
 	versionRepo       *repository.ArticleVersionRepository
 	rejectionNoteRepo *repository.RejectionNoteRepository
+	editLockRepo      *repository.EditLockRepository
 	imageDownloader   *util.ImageDownloader
 }
 
 // NewArticleService creates a new article service
 func NewArticleService(
 	repo *repository.ArticleRepository,
+	categoryRepo *repository.CategoryRepository,
+	typeConfigRepo *repository.TenantArticleTypeConfigRepository,
+	schemaRepo *repository.ArticleTypeSchemaRepository,
+	workflowService *WorkflowService,
+	keywordService *SensitiveKeywordService,
 	permissionRepo *repository.PermissionRepository,
 	viewStatsRepo *repository.ViewStatsRepository,
 	viewQueue ViewQueue,
+	viewFilter *util.ViewFilter,
 	actionLogRepo *repository.ActionLogRepository,
 	versionRepo *repository.ArticleVersionRepository,
 	rejectionNoteRepo *repository.RejectionNoteRepository,
+	editLockRepo *repository.EditLockRepository,
 	imageDownloader *util.ImageDownloader,
 ) *ArticleService {
 	return &ArticleService{
 		repo:              repo,
+		categoryRepo:      categoryRepo,
+		typeConfigRepo:    typeConfigRepo,
+		schemaRepo:        schemaRepo,
+		workflowService:   workflowService,
+		keywordService:    keywordService,
 		permissionRepo:    permissionRepo,
 		viewStatsRepo:     viewStatsRepo,
 		viewQueue:         viewQueue,
+		viewFilter:        viewFilter,
 		actionLogRepo:     actionLogRepo,
 		versionRepo:       versionRepo,
 		rejectionNoteRepo: rejectionNoteRepo,
+		editLockRepo:      editLockRepo,
 		imageDownloader:   imageDownloader,
 	}
 }
 
-// Create creates a new article
-func (s *ArticleService) Create(ctx context.Context, article *model.Article, userID string) error {
+// Create creates a new article owned by a tenant
+func (s *ArticleService) Create(ctx context.Context, tenantID primitive.ObjectID, article *model.Article, userID string) error {
+	article.TenantID = tenantID
+	if err := s.checkReferences(ctx, article); err != nil {
+		return err
+	}
+	if err := s.checkTypeRules(ctx, article, true); err != nil {
+		return err
+	}
+	if err := s.checkSchema(ctx, article); err != nil {
+		return err
+	}
+
+	// New articles always start in the initial state of their workflow
+	workflow, err := s.resolveWorkflow(ctx, tenantID, article.CategoryID)
+	if err != nil {
+		return err
+	}
+	if article.Status != "" && article.Status != workflow.InitialState {
+		return validator.ValidationErrors{{
+			Field:   "status",
+			Message: fmt.Sprintf("new articles start as %s; use workflow transitions to change status", workflow.InitialState),
+		}}
+	}
+	article.Status = workflow.InitialState
+
+	// Blocked keywords refuse the article; review keywords hold it for review
+	scan, err := s.scanArticle(ctx, article)
+	if err != nil {
+		return err
+	}
+	var logNote string
+	if needsReview(scan) {
+		article.Status = model.ArticleStatusPendingReview
+		logNote = heldForReviewNote
+	}
+
 	// Generate slug if not provided
 	if article.Slug == "" {
 		article.Slug = s.generateSlug(article.Title)
@@ -72,10 +137,14 @@ func (s *ArticleService) Create(ctx context.Context, article *model.Article, use
 	article.CharCount = s.repo.CalculateCharCount(article.Content)
 	article.ImageCount = s.repo.CalculateImageCount(article.ContentBlocks)
 
-	// Set defaults
-	if article.Status == "" {
-		article.Status = model.ArticleStatusDraft
-	}
+	// Set defaults; polls are attached through the poll endpoints,
+	// publication is scheduled through workflow transitions and the counters
+	// start from zero
+	article.HasPoll = false
+	article.PollID = nil
+	article.Schedule = nil
+	article.ViewCount = 0
+	article.TrendingScore = 0
 	article.CreatedBy = userID
 	article.CurrentVersion = 1
 
@@ -90,6 +159,7 @@ func (s *ArticleService) Create(ctx context.Context, article *model.Article, use
 			ArticleID:  article.ID,
 			ActionType: model.ActionTypeCreate,
 			UserID:     userID,
+			Note:       logNote,
 			NewStatus:  article.Status,
 		})
 	}
@@ -103,38 +173,99 @@ func (s *ArticleService) Create(ctx context.Context, article *model.Article, use
 }
 
 // FindByID finds an article by ID
-func (s *ArticleService) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Article, error) {
-	return s.repo.FindByID(ctx, id)
+func (s *ArticleService) FindByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.Article, error) {
+	return s.repo.FindByID(ctx, tenantID, id)
 }
 
 // FindBySlug finds an article by slug
-func (s *ArticleService) FindBySlug(ctx context.Context, slug string) (*model.Article, error) {
-	return s.repo.FindBySlug(ctx, slug)
+func (s *ArticleService) FindBySlug(ctx context.Context, tenantID primitive.ObjectID, slug string) (*model.Article, error) {
+	return s.repo.FindBySlug(ctx, tenantID, slug)
 }
 
 // Update updates an article
-func (s *ArticleService) Update(ctx context.Context, article *model.Article, userID string, userRole model.Role) error {
+// article.CurrentVersion is the version the edit is based on; if the article
+// has changed since, a VersionConflictError is returned.
+func (s *ArticleService) Update(ctx context.Context, tenantID primitive.ObjectID, article *model.Article, userID string, userRole model.Role) error {
+	return s.update(ctx, tenantID, article, article.CurrentVersion, userID, userRole, &model.ActionLog{ActionType: model.ActionTypeUpdate}, "Article updated")
+}
+
+// update validates and saves an edited article based on baseVersion, logging
+// entry and creating a version with the given note
+func (s *ArticleService) update(ctx context.Context, tenantID primitive.ObjectID, article *model.Article, baseVersion int, userID string, userRole model.Role, entry *model.ActionLog, versionNote string) error {
 	// Get existing article to check status
-	existing, err := s.repo.FindByID(ctx, article.ID)
+	existing, err := s.repo.FindByID(ctx, tenantID, article.ID)
 	if err != nil {
 		return err
 	}
+	if err := s.checkBaseVersion(ctx, existing, baseVersion); err != nil {
+		return err
+	}
+	if err := s.checkEditLock(ctx, existing.ID, userID); err != nil {
+		return err
+	}
 
-	// Check if article has been reviewed (moved beyond draft/pending_review)
-	if existing.Status == model.ArticleStatusPublished || existing.Status == model.ArticleStatusArchived {
-		// Only editors and moderators can edit reviewed articles
-		if userRole != model.RoleEditor && userRole != model.RoleModerator {
-			return fmt.Errorf("cannot edit article: article has been reviewed and published, only editors and moderators can make changes")
-		}
+	// The tenant is never taken from the request body
+	article.TenantID = tenantID
+	if err := s.checkReferences(ctx, article); err != nil {
+		return err
+	}
+	if err := s.checkTypeRules(ctx, article, article.ArticleType != existing.ArticleType); err != nil {
+		return err
+	}
+	if err := s.checkSchema(ctx, article); err != nil {
+		return err
+	}
+
+	// Status only changes through workflow transitions
+	if article.Status != "" && article.Status != existing.Status {
+		return validator.ValidationErrors{{
+			Field:   "status",
+			Message: "status cannot be changed by an update; use workflow transitions",
+		}}
+	}
+	article.Status = existing.Status
+
+	// The poll link is managed by the poll endpoints
+	article.HasPoll = existing.HasPoll
+	article.PollID = existing.PollID
+
+	// The trending score belongs to the trending worker; changing the hot flag
+	// by hand keeps the worker from changing it back
+	article.TrendingScore = existing.TrendingScore
+	article.HotLocked = existing.HotLocked || article.Hot != existing.Hot
+
+	// The workflow decides who may edit content in the current state
+	workflow, err := s.resolveWorkflow(ctx, tenantID, existing.CategoryID)
+	if err != nil {
+		return err
+	}
+	if !CanEdit(workflow, existing.Status, userRole) {
+		return fmt.Errorf("%w: cannot edit article in status %s", ErrForbidden, existing.Status)
 	}
 
-	// If article is published or archived, and user is only a writer, block the update
-	if existing.CreatedBy == userID && userRole == model.RoleWriter {
-		if existing.Status == model.ArticleStatusPublished || existing.Status == model.ArticleStatusArchived {
-			return fmt.Errorf("cannot edit article: article has been reviewed, contact an editor or moderator for changes")
+	// A scheduled publication stays approved only through edits by someone
+	// who could have approved it, and only while it is still ahead
+	article.Schedule = existing.Schedule
+	if existing.Schedule != nil {
+		transition := FindTransition(workflow, existing.Schedule.Transition)
+		actor := model.Actor{UserID: userID, Role: userRole}
+		if transition == nil || !isAllowed(transition, existing, actor) || !article.PublishAt.After(time.Now()) {
+			article.Schedule = nil
 		}
 	}
 
+	// Blocked keywords refuse the update; review keywords send it back to review
+	scan, err := s.scanArticle(ctx, article)
+	if err != nil {
+		return err
+	}
+	var logNote string
+	if needsReview(scan) && article.Status != model.ArticleStatusPendingReview {
+		article.Status = model.ArticleStatusPendingReview
+		article.Schedule = nil
+		logNote = heldForReviewNote
+	}
+
 	// Process and download external images if image downloader is available
 	if s.imageDownloader != nil && article.Content != "" {
 		processedContent, _, err := s.imageDownloader.ProcessHTMLImages(article.Content)
@@ -150,41 +281,45 @@ func (s *ArticleService) Update(ctx context.Context, article *model.Article, use
 	// Increment version
 	article.CurrentVersion = existing.CurrentVersion + 1
 
-	// Update article
-	if err := s.repo.Update(ctx, article); err != nil {
-		return err
+	// Update article unless someone else saved it since it was read
+	if err := s.repo.UpdateIfUnchanged(ctx, article, existing); err != nil {
+		return s.reloadConflict(ctx, tenantID, article.ID, existing.CurrentVersion, err)
 	}
 
 	// Log action
 	if s.actionLogRepo != nil {
-		s.logAction(ctx, &model.ActionLog{
-			ArticleID:  article.ID,
-			ActionType: model.ActionTypeUpdate,
-			UserID:     userID,
-			OldStatus:  existing.Status,
-			NewStatus:  article.Status,
-		})
+		entry.ArticleID = article.ID
+		entry.UserID = userID
+		entry.OldStatus = existing.Status
+		entry.NewStatus = article.Status
+		switch {
+		case logNote != "" && entry.Note != "":
+			entry.Note += "; " + logNote
+		case logNote != "":
+			entry.Note = logNote
+		}
+		s.logAction(ctx, entry)
 	}
 
 	// Create version snapshot
 	if s.versionRepo != nil {
-		s.createVersion(ctx, article, userID, "Article updated")
+		s.createVersion(ctx, article, userID, versionNote)
 	}
 
 	return nil
 }
 
 // Delete soft deletes an article
-func (s *ArticleService) Delete(ctx context.Context, id primitive.ObjectID, userID string) error {
+func (s *ArticleService) Delete(ctx context.Context, tenantID, id primitive.ObjectID, userID string) error {
 	// Get article for logging
-	article, err := s.repo.FindByID(ctx, id)
+	article, err := s.repo.FindByID(ctx, tenantID, id)
 	if err != nil {
 		return err
 	}
 
 	oldStatus := article.Status
 
-	if err := s.repo.Delete(ctx, id); err != nil {
+	if err := s.repo.Delete(ctx, tenantID, id); err != nil {
 		return err
 	}
 
@@ -203,92 +338,347 @@ func (s *ArticleService) Delete(ctx context.Context, id primitive.ObjectID, user
 }
 
 // FindAll finds articles with filters and pagination
-func (s *ArticleService) FindAll(ctx context.Context, filter map[string]interface{}, page, limit int, sort map[string]int) ([]*model.Article, int64, error) {
-	return s.repo.FindAll(ctx, filter, page, limit, sort)
+func (s *ArticleService) FindAll(ctx context.Context, tenantID primitive.ObjectID, filter map[string]interface{}, page, limit int, sort map[string]int) ([]*model.Article, int64, error) {
+	return s.repo.FindAll(ctx, tenantID, filter, page, limit, sort)
 }
 
-// Publish publishes an article
-func (s *ArticleService) Publish(ctx context.Context, id primitive.ObjectID, userID string, userRole model.Role) error {
-	// Only editors and moderators can publish
-	if userRole != model.RoleEditor && userRole != model.RoleModerator {
-		return fmt.Errorf("insufficient permissions: only editors and moderators can publish articles")
+// Publish publishes an article through the first publishing transition of
+// its workflow the actor may perform. Articles matching review keywords that
+// have not been reviewed yet are moved to pending review instead.
+func (s *ArticleService) Publish(ctx context.Context, tenantID, id primitive.ObjectID, baseVersion int, actor model.Actor) (*model.Article, error) {
+	return s.transitionTo(ctx, tenantID, id, baseVersion, "", actor, func(t *model.WorkflowTransition) bool {
+		return t.To == model.ArticleStatusPublished
+	})
+}
+
+// UpdateStatus moves an article to a status through a workflow transition
+// leading there
+func (s *ArticleService) UpdateStatus(ctx context.Context, tenantID, id primitive.ObjectID, baseVersion int, status model.ArticleStatus, actor model.Actor) error {
+	_, err := s.transitionTo(ctx, tenantID, id, baseVersion, "", actor, func(t *model.WorkflowTransition) bool {
+		return t.To == status
+	})
+	return err
+}
+
+// Transition performs a named workflow transition on an article. Like the
+// other status changes it fails with a VersionConflictError if the article
+// has changed since baseVersion.
+func (s *ArticleService) Transition(ctx context.Context, tenantID, id primitive.ObjectID, baseVersion int, name, note string, actor model.Actor) (*model.Article, error) {
+	article, err := s.repo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBaseVersion(ctx, article, baseVersion); err != nil {
+		return nil, err
 	}
 
-	article, err := s.repo.FindByID(ctx, id)
+	workflow, err := s.resolveWorkflow(ctx, tenantID, article.CategoryID)
 	if err != nil {
+		return nil, err
+	}
+
+	transition := FindTransition(workflow, name)
+	if transition == nil {
+		return nil, validator.ValidationErrors{{
+			Field:   "transition",
+			Message: fmt.Sprintf("unknown transition %q", name),
+		}}
+	}
+
+	if err := s.applyTransition(ctx, article, transition, note, actor); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// AvailableTransitions lists the transitions the actor may perform on an article
+func (s *ArticleService) AvailableTransitions(ctx context.Context, tenantID, id primitive.ObjectID, actor model.Actor) ([]model.WorkflowTransition, error) {
+	article, err := s.repo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow, err := s.resolveWorkflow(ctx, tenantID, article.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return AvailableTransitions(workflow, article, actor), nil
+}
+
+// ApplyScheduledStatus changes the status of an article on behalf of the
+// scheduler, if the status is unchanged. Only articles whose publication was
+// approved through a publishing transition of their workflow, which still
+// applies, are published.
+func (s *ArticleService) ApplyScheduledStatus(ctx context.Context, article *model.Article, status model.ArticleStatus) error {
+	if status == model.ArticleStatusPublished {
+		if err := s.checkSchedule(ctx, article); err != nil {
+			return err
+		}
+		// Keywords added since the article was scheduled still apply: flagged
+		// content is held for review instead of being published
+		scan, err := s.scanArticle(ctx, article)
+		var blocked *ContentBlockedError
+		if errors.As(err, &blocked) || (err == nil && needsReview(scan)) {
+			return s.holdScheduled(ctx, article)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.TransitionStatus(ctx, article.TenantID, article.ID, article.CurrentVersion, article.Status, status, "scheduler"); err != nil {
 		return err
 	}
 
-	oldStatus := article.Status
+	actionType := model.ActionTypeTransition
+	switch status {
+	case model.ArticleStatusPublished:
+		actionType = model.ActionTypePublish
+	case model.ArticleStatusArchived:
+		actionType = model.ActionTypeArchive
+	}
 
-	// Check if article can be published
-	if article.Status == model.ArticleStatusPublished {
-		return fmt.Errorf("article is already published")
+	s.logAction(ctx, &model.ActionLog{
+		ArticleID:  article.ID,
+		ActionType: actionType,
+		UserID:     "scheduler",
+		Note:       "Scheduled status change",
+		OldStatus:  article.Status,
+		NewStatus:  status,
+	})
+	return nil
+}
+
+// holdScheduled withdraws the scheduled publication of a flagged article and
+// moves it to review, so that it is only published by a reviewer
+func (s *ArticleService) holdScheduled(ctx context.Context, article *model.Article) error {
+	var err error
+	if article.Status == model.ArticleStatusPendingReview {
+		err = s.repo.SchedulePublish(ctx, article.TenantID, article.ID, article.CurrentVersion, article.Status, nil)
+	} else {
+		err = s.repo.TransitionStatus(ctx, article.TenantID, article.ID, article.CurrentVersion, article.Status, model.ArticleStatusPendingReview, "scheduler")
+	}
+	if err != nil {
+		return err
 	}
 
-	// Check publish date
-	now := time.Now()
-	if article.PublishAt.After(now) {
-		return fmt.Errorf("article is scheduled for future publication")
+	s.logAction(ctx, &model.ActionLog{
+		ArticleID:  article.ID,
+		ActionType: model.ActionTypeTransition,
+		UserID:     "scheduler",
+		Note:       heldForReviewNote,
+		OldStatus:  article.Status,
+		NewStatus:  model.ArticleStatusPendingReview,
+	})
+	return ErrHeldForReview
+}
+
+// checkSchedule checks that an article's publication was approved, is due,
+// and that the approved transition still publishes articles in its status
+func (s *ArticleService) checkSchedule(ctx context.Context, article *model.Article) error {
+	if article.Schedule == nil || article.PublishAt.IsZero() || article.PublishAt.After(time.Now()) {
+		return fmt.Errorf("article is not due for scheduled publication: %w", repository.ErrConflict)
 	}
 
-	if err := s.repo.UpdateStatus(ctx, id, model.ArticleStatusPublished, userID); err != nil {
+	workflow, err := s.resolveWorkflow(ctx, article.TenantID, article.CategoryID)
+	if err != nil {
 		return err
 	}
+	transition := FindTransition(workflow, article.Schedule.Transition)
+	if transition == nil || transition.To != model.ArticleStatusPublished || !containsStatus(transition.From, article.Status) {
+		return fmt.Errorf("scheduled %s no longer applies to an article in status %s: %w", article.Schedule.Transition, article.Status, repository.ErrConflict)
+	}
+	return nil
+}
+
+// transitionTo performs the first transition matching the predicate that the
+// actor may take from the article's current state. It backs the status
+// endpoints that predate named transitions.
+func (s *ArticleService) transitionTo(ctx context.Context, tenantID, id primitive.ObjectID, baseVersion int, note string, actor model.Actor, match func(*model.WorkflowTransition) bool) (*model.Article, error) {
+	article, err := s.repo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBaseVersion(ctx, article, baseVersion); err != nil {
+		return nil, err
+	}
+
+	workflow, err := s.resolveWorkflow(ctx, tenantID, article.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	var denied *model.WorkflowTransition
+	for i := range workflow.Transitions {
+		transition := &workflow.Transitions[i]
+		if !match(transition) || !containsStatus(transition.From, article.Status) {
+			continue
+		}
+		if isAllowed(transition, article, actor) {
+			if err := s.applyTransition(ctx, article, transition, note, actor); err != nil {
+				return nil, err
+			}
+			return article, nil
+		}
+		denied = transition
+	}
+
+	if denied != nil {
+		// Reports why the actor may not perform it
+		return nil, s.applyTransition(ctx, article, denied, note, actor)
+	}
+	return nil, fmt.Errorf("no workflow transition applies to an article in status %s: %w", article.Status, repository.ErrConflict)
+}
+
+// applyTransition checks and performs a transition, records the rejection
+// note if it is a rejection and logs it under the transition's name
+func (s *ArticleService) applyTransition(ctx context.Context, article *model.Article, transition *model.WorkflowTransition, note string, actor model.Actor) error {
+	if !containsStatus(transition.From, article.Status) {
+		return fmt.Errorf("cannot %s an article in status %s: %w", transition.Name, article.Status, repository.ErrConflict)
+	}
+	if !isAllowed(transition, article, actor) {
+		return fmt.Errorf("%w: not allowed to %s this article", ErrForbidden, transition.Name)
+	}
+	if transition.RequireNote && strings.TrimSpace(note) == "" {
+		return validator.ValidationErrors{{
+			Field:   "note",
+			Message: fmt.Sprintf("a note is required to %s an article", transition.Name),
+		}}
+	}
+	oldStatus := article.Status
+	to := transition.To
+	actionType := transitionActionType(transition)
+	logNote := note
+	if to == model.ArticleStatusPublished {
+		scan, err := s.scanArticle(ctx, article)
+		if err != nil {
+			return err
+		}
+		// Flagged content has to pass through review before it is published
+		if needsReview(scan) && oldStatus != model.ArticleStatusPendingReview {
+			to = model.ArticleStatusPendingReview
+			actionType = model.ActionTypeTransition
+			logNote = heldForReviewNote
+		}
+
+		// Publishing ahead of the publication date approves the article for
+		// the scheduler, which publishes it once the date is reached. The
+		// scheduler never publishes flagged content, so a reviewer has to
+		// publish it when it is due.
+		if to == model.ArticleStatusPublished && article.PublishAt.After(time.Now()) {
+			if needsReview(scan) {
+				return validator.ValidationErrors{{
+					Field:   "publishAt",
+					Message: "content matching review keywords cannot be scheduled; publish it once its publication date is reached",
+				}}
+			}
+			return s.schedulePublish(ctx, article, transition, note, actor)
+		}
+	}
+
+	if err := s.repo.TransitionStatus(ctx, article.TenantID, article.ID, article.CurrentVersion, oldStatus, to, actor.UserID); err != nil {
+		return s.reloadConflict(ctx, article.TenantID, article.ID, article.CurrentVersion, err)
+	}
+	article.Status = to
+	article.Schedule = nil
+
+	// Create rejection note in separate table
+	if transition.IsRejection && s.rejectionNoteRepo != nil {
+		rejectionNote := &model.RejectionNote{
+			ArticleID:  article.ID,
+			UserID:     actor.UserID,
+			UserName:   actor.UserName,
+			UserRole:   actor.Role,
+			Note:       note,
+			IsResolved: false,
+		}
+		if err := s.rejectionNoteRepo.Create(ctx, rejectionNote); err != nil {
+			return err
+		}
+	}
 
 	// Log action
 	if s.actionLogRepo != nil {
 		s.logAction(ctx, &model.ActionLog{
-			ArticleID:  id,
-			ActionType: model.ActionTypePublish,
-			UserID:     userID,
-			UserRole:   userRole,
+			ArticleID:  article.ID,
+			ActionType: actionType,
+			Transition: transition.Name,
+			UserID:     actor.UserID,
+			UserName:   actor.UserName,
+			UserRole:   actor.Role,
+			Note:       logNote,
 			OldStatus:  oldStatus,
-			NewStatus:  model.ArticleStatusPublished,
+			NewStatus:  to,
 		})
 	}
 
 	return nil
 }
 
-// UpdateStatus updates article status
-func (s *ArticleService) UpdateStatus(ctx context.Context, id primitive.ObjectID, status model.ArticleStatus, userID string, userRole model.Role) error {
-	// Get existing article
-	existing, err := s.repo.FindByID(ctx, id)
-	if err != nil {
-		return err
+// schedulePublish records that a publishing transition was approved for an
+// article whose publication date is still ahead
+func (s *ArticleService) schedulePublish(ctx context.Context, article *model.Article, transition *model.WorkflowTransition, note string, actor model.Actor) error {
+	schedule := &model.PublishSchedule{
+		Transition: transition.Name,
+		ApprovedBy: actor.UserID,
+		ApprovedAt: time.Now(),
 	}
-
-	// Check permissions based on status transition
-	if existing.Status == model.ArticleStatusPublished || existing.Status == model.ArticleStatusArchived {
-		// Only editors and moderators can change status of published/archived articles
-		if userRole != model.RoleEditor && userRole != model.RoleModerator {
-			return fmt.Errorf("cannot change article status: article has been reviewed, only editors and moderators can change status")
-		}
+	if err := s.repo.SchedulePublish(ctx, article.TenantID, article.ID, article.CurrentVersion, article.Status, schedule); err != nil {
+		return s.reloadConflict(ctx, article.TenantID, article.ID, article.CurrentVersion, err)
 	}
+	article.Schedule = schedule
 
-	// Writers can only change status of their own draft/pending_review articles
-	if userRole == model.RoleWriter {
-		if existing.CreatedBy != userID {
-			return fmt.Errorf("cannot change article status: you can only change status of your own articles")
+	if s.actionLogRepo != nil {
+		logNote := "Scheduled for publication at " + article.PublishAt.Format(time.RFC3339)
+		if note != "" {
+			logNote = note + "; " + logNote
 		}
+		s.logAction(ctx, &model.ActionLog{
+			ArticleID:  article.ID,
+			ActionType: model.ActionTypeTransition,
+			Transition: transition.Name,
+			UserID:     actor.UserID,
+			UserName:   actor.UserName,
+			UserRole:   actor.Role,
+			Note:       logNote,
+			OldStatus:  article.Status,
+			NewStatus:  article.Status,
+		})
+	}
+	return nil
+}
 
-		// Writers cannot publish articles, only submit for review
-		if status == model.ArticleStatusPublished {
-			return fmt.Errorf("cannot publish article: only editors and moderators can publish articles")
-		}
+// transitionActionType maps a transition onto the closest action log type so
+// existing log consumers keep working
+func transitionActionType(transition *model.WorkflowTransition) model.ActionType {
+	switch {
+	case transition.IsRejection:
+		return model.ActionTypeReject
+	case transition.To == model.ArticleStatusPublished:
+		return model.ActionTypePublish
+	case transition.To == model.ArticleStatusArchived:
+		return model.ActionTypeArchive
+	default:
+		return model.ActionTypeTransition
 	}
+}
 
-	return s.repo.UpdateStatus(ctx, id, status, userID)
+// resolveWorkflow returns the workflow governing articles of a category
+func (s *ArticleService) resolveWorkflow(ctx context.Context, tenantID, categoryID primitive.ObjectID) (*model.Workflow, error) {
+	if s.workflowService == nil {
+		return DefaultWorkflow(), nil
+	}
+	return s.workflowService.Resolve(ctx, tenantID, categoryID)
 }
 
 // Reorder updates article ordering
-func (s *ArticleService) Reorder(ctx context.Context, articles []struct {
+func (s *ArticleService) Reorder(ctx context.Context, tenantID primitive.ObjectID, articles []struct {
 	ID       primitive.ObjectID
 	Ordering int
 }) error {
 	for _, item := range articles {
-		if err := s.repo.UpdateOrdering(ctx, item.ID, item.Ordering); err != nil {
+		if err := s.repo.UpdateOrdering(ctx, tenantID, item.ID, item.Ordering); err != nil {
 			return err
 		}
 	}
@@ -296,54 +686,118 @@ func (s *ArticleService) Reorder(ctx context.Context, articles []struct {
 }
 
 // SetFeatured sets the featured flag for an article
-func (s *ArticleService) SetFeatured(ctx context.Context, id primitive.ObjectID, featured bool) error {
-	article, err := s.repo.FindByID(ctx, id)
+func (s *ArticleService) SetFeatured(ctx context.Context, tenantID, id primitive.ObjectID, featured bool) error {
+	article, err := s.repo.FindByID(ctx, tenantID, id)
 	if err != nil {
 		return err
 	}
 
 	article.Featured = featured
-	return s.repo.Update(ctx, article)
+	return s.repo.UpdateIfUnchanged(ctx, article, article)
 }
 
-// SetHot sets the hot flag for an article
-func (s *ArticleService) SetHot(ctx context.Context, id primitive.ObjectID, hot bool) error {
-	article, err := s.repo.FindByID(ctx, id)
+// SetHot lets an editor or moderator set the hot flag of an article, which
+// the trending worker then leaves alone. A nil hot hands the flag back to the
+// worker.
+func (s *ArticleService) SetHot(ctx context.Context, tenantID, id primitive.ObjectID, hot *bool, actor model.Actor) (*model.Article, error) {
+	if actor.Role != model.RoleEditor && actor.Role != model.RoleModerator {
+		return nil, fmt.Errorf("%w: only editors and moderators can set the hot flag", ErrForbidden)
+	}
+	article, err := s.repo.FindByID(ctx, tenantID, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	article.Hot = hot
-	return s.repo.Update(ctx, article)
+	article.HotLocked = hot != nil
+	if hot != nil {
+		article.Hot = *hot
+	}
+	if err := s.repo.UpdateIfUnchanged(ctx, article, article); err != nil {
+		return nil, err
+	}
+	return article, nil
 }
 
-// IncrementViewCount increments the view count for an article using queue
-func (s *ArticleService) IncrementViewCount(ctx context.Context, id primitive.ObjectID) error {
+// IncrementViewCount increments the view count for an article using queue.
+// Views by crawlers are ignored, and repeat views by the same visitor within
+// the deduplication window do not count as unique views.
+func (s *ArticleService) IncrementViewCount(ctx context.Context, tenantID, id primitive.ObjectID, visitor model.Visitor) error {
+	if err := s.checkArticle(ctx, tenantID, id); err != nil {
+		return err
+	}
+	event, counted := newViewEvent(s.viewFilter, id, visitor)
+	if !counted {
+		return nil
+	}
+
 	// Enqueue view event for asynchronous processing
 	if s.viewQueue != nil {
-		return s.viewQueue.Enqueue(id)
+		return s.viewQueue.Enqueue(event)
 	}
 
 	// Fallback to synchronous processing if queue not available
 	if err := s.repo.IncrementViewCount(ctx, id); err != nil {
 		return err
 	}
-	return s.viewStatsRepo.RecordView(ctx, id, time.Now())
+	return s.viewStatsRepo.RecordView(ctx, id, event.Timestamp, event.Unique)
 }
 
-// GetArticleStats gets view statistics for an article
-func (s *ArticleService) GetArticleStats(ctx context.Context, articleID primitive.ObjectID, startDate, endDate time.Time) ([]*model.ArticleView, error) {
-	return s.viewStatsRepo.GetArticleStats(ctx, articleID, startDate, endDate)
+// newViewEvent creates the view event for a visitor, or reports false for a
+// crawler. Without a filter every view counts as unique.
+func newViewEvent(filter *util.ViewFilter, articleID primitive.ObjectID, visitor model.Visitor) (model.ViewEvent, bool) {
+	event := model.ViewEvent{ArticleID: articleID, Timestamp: time.Now(), Unique: true}
+	if filter == nil {
+		return event, true
+	}
+	if filter.IsBot(visitor.UserAgent) {
+		return event, false
+	}
+	event.Unique = filter.IsFirstView(articleID.Hex(), visitor.Fingerprint(), event.Timestamp)
+	return event, true
+}
+
+// RecordEngagement counts an engagement beacon for an article using the view
+// queue. Beacons sent by crawlers are ignored.
+func (s *ArticleService) RecordEngagement(ctx context.Context, tenantID primitive.ObjectID, beacon *model.EngagementBeacon, visitor model.Visitor) error {
+	if err := s.checkArticle(ctx, tenantID, beacon.ArticleID); err != nil {
+		return err
+	}
+	event, counted := newEngagementEvent(s.viewFilter, beacon, visitor)
+	if !counted {
+		return nil
+	}
+
+	if s.viewQueue != nil {
+		return s.viewQueue.EnqueueEngagement(event)
+	}
+
+	// Fallback to synchronous processing if queue not available
+	ts := event.Timestamp
+	view := &model.ArticleView{
+		ArticleID: event.ArticleID,
+		Date:      time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, ts.Location()),
+	}
+	view.Engagement.Add(event)
+	var sources []*model.TrafficSource
+	for sourceType, value := range event.Sources() {
+		sources = append(sources, &model.TrafficSource{ArticleID: view.ArticleID, Date: view.Date, Type: sourceType, Value: value, Count: 1})
+	}
+
+	batchID := primitive.NewObjectID()
+	if err := s.viewStatsRepo.AddViews(ctx, batchID, []*model.ArticleView{view}); err != nil {
+		return err
+	}
+	return s.viewStatsRepo.AddTrafficSources(ctx, batchID, sources)
 }
 
 // Search performs full-text search on articles
-func (s *ArticleService) Search(ctx context.Context, query string, page, limit int) ([]*model.Article, int64, error) {
+func (s *ArticleService) Search(ctx context.Context, tenantID primitive.ObjectID, query string, page, limit int) ([]*model.Article, int64, error) {
 	filter := map[string]interface{}{
 		"q":      query,
 		"status": model.ArticleStatusPublished,
 	}
 
-	return s.repo.FindAll(ctx, filter, page, limit, nil)
+	return s.repo.FindAll(ctx, tenantID, filter, page, limit, nil)
 }
 
 // GetPublishableArticles gets articles ready to be published
@@ -387,75 +841,113 @@ func (s *ArticleService) generateSlug(title string) string {
 	return slug
 }
 
-// RejectArticle rejects an article with a note
-func (s *ArticleService) RejectArticle(ctx context.Context, id primitive.ObjectID, userID string, userName string, userRole model.Role, note string) error {
-	// Only editors and moderators can reject
-	if userRole != model.RoleEditor && userRole != model.RoleModerator {
-		return fmt.Errorf("insufficient permissions: only editors and moderators can reject articles")
+// RejectArticle rejects an article with a note through the rejection
+// transition of its workflow
+func (s *ArticleService) RejectArticle(ctx context.Context, tenantID, id primitive.ObjectID, baseVersion int, actor model.Actor, note string) error {
+	_, err := s.transitionTo(ctx, tenantID, id, baseVersion, note, actor, func(t *model.WorkflowTransition) bool {
+		return t.IsRejection
+	})
+	return err
+}
+
+// GetArticleVersions gets all versions of an article
+func (s *ArticleService) GetArticleVersions(ctx context.Context, tenantID, articleID primitive.ObjectID) ([]*model.ArticleVersion, error) {
+	if s.versionRepo == nil {
+		return nil, fmt.Errorf("version repository not initialized")
 	}
+	if err := s.checkArticle(ctx, tenantID, articleID); err != nil {
+		return nil, err
+	}
+	return s.versionRepo.FindByArticleID(ctx, articleID)
+}
 
-	article, err := s.repo.FindByID(ctx, id)
+// GetArticleVersion gets a specific version of an article
+func (s *ArticleService) GetArticleVersion(ctx context.Context, tenantID, versionID primitive.ObjectID) (*model.ArticleVersion, error) {
+	if s.versionRepo == nil {
+		return nil, fmt.Errorf("version repository not initialized")
+	}
+	version, err := s.versionRepo.FindByID(ctx, versionID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if err := s.checkArticle(ctx, tenantID, version.ArticleID); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
 
-	oldStatus := article.Status
-	article.Status = model.ArticleStatusDraft
-
-	if err := s.repo.Update(ctx, article); err != nil {
-		return err
+// DiffArticleVersions compares two versions of an article field by field.
+// A toVersion of 0 compares with the article as it is now.
+func (s *ArticleService) DiffArticleVersions(ctx context.Context, tenantID, articleID primitive.ObjectID, fromVersion, toVersion int) (*model.VersionDiff, error) {
+	if s.versionRepo == nil {
+		return nil, fmt.Errorf("version repository not initialized")
 	}
 
-	// Create rejection note in separate table
-	if s.rejectionNoteRepo != nil {
-		rejectionNote := &model.RejectionNote{
-			ArticleID:  article.ID,
-			UserID:     userID,
-			UserName:   userName,
-			UserRole:   userRole,
-			Note:       note,
-			IsResolved: false,
-		}
-		if err := s.rejectionNoteRepo.Create(ctx, rejectionNote); err != nil {
-			return err
-		}
+	article, err := s.repo.FindByID(ctx, tenantID, articleID)
+	if err != nil {
+		return nil, err
+	}
+	from, err := s.versionRepo.FindByVersionNumber(ctx, articleID, fromVersion)
+	if err != nil {
+		return nil, err
 	}
 
-	// Log action
-	if s.actionLogRepo != nil {
-		s.logAction(ctx, &model.ActionLog{
-			ArticleID:  article.ID,
-			ActionType: model.ActionTypeReject,
-			UserID:     userID,
-			UserName:   userName,
-			UserRole:   userRole,
-			Note:       note,
-			OldStatus:  oldStatus,
-			NewStatus:  article.Status,
-		})
+	to := article
+	if toVersion != 0 {
+		version, err := s.versionRepo.FindByVersionNumber(ctx, articleID, toVersion)
+		if err != nil {
+			return nil, err
+		}
+		to = versionSnapshot(version)
+	} else {
+		toVersion = article.CurrentVersion
 	}
 
-	return nil
+	return &model.VersionDiff{
+		ArticleID:   articleID,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Changes:     DiffArticles(versionSnapshot(from), to),
+	}, nil
 }
 
-// GetArticleVersions gets all versions of an article
-func (s *ArticleService) GetArticleVersions(ctx context.Context, articleID primitive.ObjectID) ([]*model.ArticleVersion, error) {
+// MergeArticleVersion saves an editor's copy of an article that was based on
+// an older version, merged with the changes saved since. When the editor and
+// someone else changed the same field the merge is not saved and the result
+// lists the conflicts.
+func (s *ArticleService) MergeArticleVersion(ctx context.Context, tenantID, articleID primitive.ObjectID, baseVersion int, edited *model.Article, userID string, userRole model.Role) (*model.MergeResult, error) {
 	if s.versionRepo == nil {
 		return nil, fmt.Errorf("version repository not initialized")
 	}
-	return s.versionRepo.FindByArticleID(ctx, articleID)
-}
 
-// GetArticleVersion gets a specific version of an article
-func (s *ArticleService) GetArticleVersion(ctx context.Context, versionID primitive.ObjectID) (*model.ArticleVersion, error) {
-	if s.versionRepo == nil {
-		return nil, fmt.Errorf("version repository not initialized")
+	current, err := s.repo.FindByID(ctx, tenantID, articleID)
+	if err != nil {
+		return nil, err
+	}
+	base, err := s.versionRepo.FindByVersionNumber(ctx, articleID, baseVersion)
+	if err != nil {
+		return nil, err
 	}
-	return s.versionRepo.FindByID(ctx, versionID)
+
+	merged, conflicts := MergeArticles(versionSnapshot(base), current, edited)
+	result := &model.MergeResult{Article: merged, BaseVersion: baseVersion, Conflicts: conflicts}
+	if len(conflicts) > 0 {
+		return result, nil
+	}
+
+	note := fmt.Sprintf("Merged edits based on version %d", baseVersion)
+	if err := s.update(ctx, tenantID, merged, current.CurrentVersion, userID, userRole, &model.ActionLog{ActionType: model.ActionTypeUpdate, UserRole: userRole, Note: note}, note); err != nil {
+		return nil, err
+	}
+	result.Saved = true
+	return result, nil
 }
 
-// RestoreArticleVersion restores an article to a specific version
-func (s *ArticleService) RestoreArticleVersion(ctx context.Context, articleID primitive.ObjectID, versionNum int, userID string, userRole model.Role) error {
+// RestoreArticleVersion restores the content of an article to a specific
+// version. When fields are given only those fields are taken from the version.
+// The result is validated and saved like any other update. baseVersion is the
+// version the caller last saw.
+func (s *ArticleService) RestoreArticleVersion(ctx context.Context, tenantID, articleID primitive.ObjectID, versionNum int, fields []string, baseVersion int, userID string, userRole model.Role) error {
 	// Only editors and moderators can restore versions
 	if userRole != model.RoleEditor && userRole != model.RoleModerator {
 		return fmt.Errorf("insufficient permissions: only editors and moderators can restore versions")
@@ -465,73 +957,195 @@ func (s *ArticleService) RestoreArticleVersion(ctx context.Context, articleID pr
 		return fmt.Errorf("version repository not initialized")
 	}
 
+	// Get current article
+	article, err := s.repo.FindByID(ctx, tenantID, articleID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkBaseVersion(ctx, article, baseVersion); err != nil {
+		return err
+	}
+	if err := s.checkEditLock(ctx, article.ID, userID); err != nil {
+		return err
+	}
+
 	// Get the version to restore
 	version, err := s.versionRepo.FindByVersionNumber(ctx, articleID, versionNum)
 	if err != nil {
 		return err
 	}
 
-	// Get current article
-	article, err := s.repo.FindByID(ctx, articleID)
+	// Without a list of fields the content of the whole version is restored;
+	// the status, poll, counters and trending state stay as they are
+	note := fmt.Sprintf("Restored to version %d", versionNum)
+	if len(fields) > 0 {
+		if err := checkVersionFields(fields); err != nil {
+			return err
+		}
+		note = fmt.Sprintf("Restored %s from version %d", strings.Join(fields, ", "), versionNum)
+	} else {
+		fields = restoreFields(version)
+	}
+
+	restored := *article
+	copyVersionFields(&restored, versionSnapshot(version), fields)
+	return s.update(ctx, tenantID, &restored, article.CurrentVersion, userID, userRole, &model.ActionLog{
+		ActionType: model.ActionTypeRestore,
+		UserRole:   userRole,
+		Note:       note,
+		VersionID:  &version.ID,
+	}, note)
+}
+
+// GetEditLock returns the active edit lock on an article, or nil if nobody
+// is editing it
+func (s *ArticleService) GetEditLock(ctx context.Context, tenantID, articleID primitive.ObjectID) (*model.EditLock, error) {
+	if s.editLockRepo == nil {
+		return nil, fmt.Errorf("edit lock repository not initialized")
+	}
+	if err := s.checkArticle(ctx, tenantID, articleID); err != nil {
+		return nil, err
+	}
+
+	lock, err := s.editLockRepo.FindActive(ctx, articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return lock, err
+}
+
+// AcquireEditLock takes the edit lock on an article for the actor, or extends
+// it if they already hold it. If someone else holds it, an EditLockedError
+// says who.
+func (s *ArticleService) AcquireEditLock(ctx context.Context, tenantID, articleID primitive.ObjectID, actor model.Actor) (*model.EditLock, error) {
+	if s.editLockRepo == nil {
+		return nil, fmt.Errorf("edit lock repository not initialized")
+	}
+	if err := s.checkArticle(ctx, tenantID, articleID); err != nil {
+		return nil, err
+	}
+
+	lock := newEditLock(tenantID, articleID, actor)
+	acquired, err := s.editLockRepo.Acquire(ctx, lock)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if !acquired {
+		holder, err := s.editLockRepo.FindActive(ctx, articleID)
+		if err != nil {
+			// Released between the two calls
+			return nil, fmt.Errorf("edit lock %w", repository.ErrConflict)
+		}
+		return nil, &EditLockedError{Lock: holder}
 	}
+	return lock, nil
+}
 
-	oldStatus := article.Status
+// RenewEditLock extends the actor's edit lock. It fails with an
+// EditLockedError if someone has taken the lock over and with ErrNotFound if
+// it has expired.
+func (s *ArticleService) RenewEditLock(ctx context.Context, tenantID, articleID primitive.ObjectID, actor model.Actor) (*model.EditLock, error) {
+	if s.editLockRepo == nil {
+		return nil, fmt.Errorf("edit lock repository not initialized")
+	}
+	if err := s.checkArticle(ctx, tenantID, articleID); err != nil {
+		return nil, err
+	}
 
-	// Restore from full snapshot if available, otherwise from version fields
-	if version.FullSnapshot != nil {
-		// Preserve ID and creation info
-		version.FullSnapshot.ID = article.ID
-		version.FullSnapshot.CreatedAt = article.CreatedAt
-		version.FullSnapshot.CreatedBy = article.CreatedBy
-		version.FullSnapshot.CurrentVersion = article.CurrentVersion + 1
-		article = version.FullSnapshot
-	} else {
-		// Restore basic fields
-		article.Title = version.Title
-		article.Subtitle = version.Subtitle
-		article.Slug = version.Slug
-		article.ArticleType = version.ArticleType
-		article.CategoryID = version.CategoryID
-		article.Summary = version.Summary
-		article.Content = version.Content
-		article.Author = version.Author
-		article.Tags = version.Tags
-		article.SEO = version.SEO
-		article.CurrentVersion++
+	lock, err := s.editLockRepo.Renew(ctx, articleID, actor.UserID, time.Now().Add(editLockTTL))
+	if errors.Is(err, repository.ErrNotFound) {
+		if holder, findErr := s.editLockRepo.FindActive(ctx, articleID); findErr == nil {
+			return nil, &EditLockedError{Lock: holder}
+		}
 	}
+	return lock, err
+}
 
-	// Update article
-	if err := s.repo.Update(ctx, article); err != nil {
+// ReleaseEditLock gives up the actor's edit lock on an article
+func (s *ArticleService) ReleaseEditLock(ctx context.Context, tenantID, articleID primitive.ObjectID, actor model.Actor) error {
+	if s.editLockRepo == nil {
+		return fmt.Errorf("edit lock repository not initialized")
+	}
+	if err := s.checkArticle(ctx, tenantID, articleID); err != nil {
 		return err
 	}
+	return s.editLockRepo.Release(ctx, articleID, actor.UserID)
+}
 
-	// Log action
-	if s.actionLogRepo != nil {
+// TakeEditLock gives the edit lock to an editor or moderator even if someone
+// else holds it. Taking a lock from another user is recorded in the action log.
+func (s *ArticleService) TakeEditLock(ctx context.Context, tenantID, articleID primitive.ObjectID, actor model.Actor, note string) (*model.EditLock, error) {
+	if actor.Role != model.RoleEditor && actor.Role != model.RoleModerator {
+		return nil, fmt.Errorf("%w: only editors and moderators can take over an edit lock", ErrForbidden)
+	}
+	if s.editLockRepo == nil {
+		return nil, fmt.Errorf("edit lock repository not initialized")
+	}
+	if err := s.checkArticle(ctx, tenantID, articleID); err != nil {
+		return nil, err
+	}
+
+	lock := newEditLock(tenantID, articleID, actor)
+	previous, err := s.editLockRepo.Replace(ctx, lock)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != nil && previous.UserID != actor.UserID {
+		logNote := fmt.Sprintf("Took the edit lock from %s", lockHolderName(previous))
+		if note != "" {
+			logNote += ": " + note
+		}
 		s.logAction(ctx, &model.ActionLog{
-			ArticleID:  article.ID,
-			ActionType: model.ActionTypeRestore,
-			UserID:     userID,
-			UserRole:   userRole,
-			Note:       fmt.Sprintf("Restored to version %d", versionNum),
-			OldStatus:  oldStatus,
-			NewStatus:  article.Status,
-			VersionID:  &version.ID,
+			ArticleID:  articleID,
+			ActionType: model.ActionTypeTakeLock,
+			UserID:     actor.UserID,
+			UserName:   actor.UserName,
+			UserRole:   actor.Role,
+			Note:       logNote,
 		})
 	}
+	return lock, nil
+}
 
-	// Create a new version entry for the restore
-	s.createVersion(ctx, article, userID, fmt.Sprintf("Restored from version %d", versionNum))
-
+// checkEditLock fails with an EditLockedError while someone other than userID
+// holds the edit lock on an article
+func (s *ArticleService) checkEditLock(ctx context.Context, articleID primitive.ObjectID, userID string) error {
+	if s.editLockRepo == nil {
+		return nil
+	}
+	lock, err := s.editLockRepo.FindActive(ctx, articleID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if lock.UserID != userID {
+		return &EditLockedError{Lock: lock}
+	}
 	return nil
 }
 
+func newEditLock(tenantID, articleID primitive.ObjectID, actor model.Actor) *model.EditLock {
+	return &model.EditLock{
+		ArticleID: articleID,
+		TenantID:  tenantID,
+		UserID:    actor.UserID,
+		UserName:  actor.UserName,
+		UserRole:  actor.Role,
+		ExpiresAt: time.Now().Add(editLockTTL),
+	}
+}
+
 // GetActionLogs gets action logs for an article
-func (s *ArticleService) GetActionLogs(ctx context.Context, articleID primitive.ObjectID, page, limit int) ([]*model.ActionLog, int64, error) {
+func (s *ArticleService) GetActionLogs(ctx context.Context, tenantID, articleID primitive.ObjectID, page, limit int) ([]*model.ActionLog, int64, error) {
 	if s.actionLogRepo == nil {
 		return nil, 0, fmt.Errorf("action log repository not initialized")
 	}
+	if err := s.checkArticle(ctx, tenantID, articleID); err != nil {
+		return nil, 0, err
+	}
 	return s.actionLogRepo.FindByArticleID(ctx, articleID, page, limit)
 }
 
@@ -543,6 +1157,143 @@ func (s *ArticleService) GetUserActionLogs(ctx context.Context, userID string, p
 	return s.actionLogRepo.FindByUserID(ctx, userID, page, limit)
 }
 
+// checkArticle verifies that an article exists within the tenant
+func (s *ArticleService) checkArticle(ctx context.Context, tenantID, articleID primitive.ObjectID) error {
+	_, err := s.repo.FindByID(ctx, tenantID, articleID)
+	return err
+}
+
+// checkReferences verifies that the category and related articles referenced
+// by an article belong to the article's tenant
+func (s *ArticleService) checkReferences(ctx context.Context, article *model.Article) error {
+	if s.categoryRepo != nil && !article.CategoryID.IsZero() {
+		if _, err := s.categoryRepo.FindByID(ctx, article.TenantID, article.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	return s.checkRelatedArticles(ctx, article.TenantID, article.RelatedArticles)
+}
+
+// checkTypeRules applies the tenant's article type configuration. The
+// per-type quota is only checked when the article enters a type, i.e. on
+// create or when its type changes.
+func (s *ArticleService) checkTypeRules(ctx context.Context, article *model.Article, entersType bool) error {
+	if s.typeConfigRepo == nil {
+		return nil
+	}
+
+	config, err := s.typeConfigRepo.FindByTenantID(ctx, article.TenantID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// Tenants without a configuration may use every type
+			return nil
+		}
+		return err
+	}
+
+	if err := validator.NewArticleValidator().ValidateTenantRules(article, config); err != nil {
+		return err
+	}
+
+	typeConfig := validator.FindTypeConfig(config, article.ArticleType)
+	if !entersType || typeConfig == nil || typeConfig.MaxArticles == 0 {
+		return nil
+	}
+
+	count, err := s.repo.CountByType(ctx, article.TenantID, article.ArticleType)
+	if err != nil {
+		return err
+	}
+	if count >= int64(typeConfig.MaxArticles) {
+		return validator.ValidationErrors{{
+			Field:   "articleType",
+			Message: fmt.Sprintf("tenant limit of %d %s articles reached", typeConfig.MaxArticles, article.ArticleType),
+		}}
+	}
+	return nil
+}
+
+// checkSchema validates an article against the JSON Schema its tenant
+// registered for the article type, if any
+func (s *ArticleService) checkSchema(ctx context.Context, article *model.Article) error {
+	if s.schemaRepo == nil {
+		return nil
+	}
+
+	typeSchema, err := s.schemaRepo.FindByType(ctx, article.TenantID, article.ArticleType)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	schema, err := validator.CompileSchema(typeSchema.Schema)
+	if err != nil {
+		return fmt.Errorf("stored schema for %s articles is invalid: %v", article.ArticleType, err)
+	}
+
+	return validator.NewArticleValidator().ValidateSchema(article, schema)
+}
+
+// scanArticle runs the tenant's sensitive keyword scan over the text of an
+// article. Matches of blocked keywords are returned as a ContentBlockedError;
+// other matches are attached to the article as warnings for the caller.
+func (s *ArticleService) scanArticle(ctx context.Context, article *model.Article) (*model.ContentScanResult, error) {
+	article.ContentWarnings = nil
+	if s.keywordService == nil {
+		return nil, nil
+	}
+
+	fields := []ScanField{
+		{Name: "title", Text: article.Title},
+		{Name: "summary", Text: article.Summary},
+		{Name: "content", Text: article.Content},
+	}
+	for i, block := range article.ContentBlocks {
+		fields = append(fields, ScanField{Name: fmt.Sprintf("contentBlocks[%d].content", i), Text: block.Content})
+	}
+
+	scan, err := s.keywordService.ScanFields(ctx, article.TenantID.Hex(), fields)
+	if err != nil {
+		return nil, err
+	}
+	if scan.RecommendedAction == model.KeywordActionBlock {
+		return nil, &ContentBlockedError{Scan: scan}
+	}
+	if scan.HasViolations {
+		article.ContentWarnings = scan.Results
+	}
+	return scan, nil
+}
+
+// needsReview reports whether a scan matched keywords that require review
+func needsReview(scan *model.ContentScanResult) bool {
+	return scan != nil && scan.RecommendedAction == model.KeywordActionReview
+}
+
+// checkRelatedArticles verifies that all related article IDs belong to the tenant
+func (s *ArticleService) checkRelatedArticles(ctx context.Context, tenantID primitive.ObjectID, ids []primitive.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	unique := make(map[primitive.ObjectID]struct{}, len(ids))
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+
+	count, err := s.repo.CountByIDs(ctx, tenantID, ids)
+	if err != nil {
+		return err
+	}
+	if count != int64(len(unique)) {
+		return fmt.Errorf("related article %w", repository.ErrNotFound)
+	}
+	return nil
+}
+
 // logAction logs an action to the action log
 func (s *ArticleService) logAction(ctx context.Context, log *model.ActionLog) error {
 	if s.actionLogRepo == nil {
@@ -551,6 +1302,41 @@ func (s *ArticleService) logAction(ctx context.Context, log *model.ActionLog) er
 	return s.actionLogRepo.Create(ctx, log)
 }
 
+// checkBaseVersion returns a VersionConflictError if the article has changed
+// since baseVersion, the version the caller based its change on
+func (s *ArticleService) checkBaseVersion(ctx context.Context, article *model.Article, baseVersion int) error {
+	if baseVersion == article.CurrentVersion {
+		return nil
+	}
+	return s.versionConflict(ctx, article, baseVersion)
+}
+
+// reloadConflict turns a conditional write that failed because the article
+// moved past baseVersion into a VersionConflictError against the article as
+// it is now. Other errors are returned unchanged.
+func (s *ArticleService) reloadConflict(ctx context.Context, tenantID, id primitive.ObjectID, baseVersion int, err error) error {
+	if !errors.Is(err, repository.ErrConflict) {
+		return err
+	}
+	latest, findErr := s.repo.FindByID(ctx, tenantID, id)
+	if findErr != nil || latest.CurrentVersion == baseVersion {
+		return err
+	}
+	return s.versionConflict(ctx, latest, baseVersion)
+}
+
+// versionConflict builds the conflict error for a change based on
+// baseVersion, with the field changes saved since then
+func (s *ArticleService) versionConflict(ctx context.Context, current *model.Article, baseVersion int) error {
+	conflict := &VersionConflictError{BaseVersion: baseVersion, CurrentVersion: current.CurrentVersion}
+	if s.versionRepo != nil {
+		if base, err := s.versionRepo.FindByVersionNumber(ctx, current.ID, baseVersion); err == nil {
+			conflict.Changes = DiffArticles(versionSnapshot(base), current)
+		}
+	}
+	return conflict
+}
+
 // createVersion creates a version snapshot of an article
 func (s *ArticleService) createVersion(ctx context.Context, article *model.Article, userID string, note string) error {
 	if s.versionRepo == nil {
@@ -586,10 +1372,13 @@ func (s *ArticleService) createVersion(ctx context.Context, article *model.Artic
 }
 
 // AddRejectionNote adds a rejection note to an article (for conversation thread)
-func (s *ArticleService) AddRejectionNote(ctx context.Context, articleID primitive.ObjectID, userID string, userName string, userRole model.Role, note string, parentID *primitive.ObjectID) error {
+func (s *ArticleService) AddRejectionNote(ctx context.Context, tenantID, articleID primitive.ObjectID, userID string, userName string, userRole model.Role, note string, parentID *primitive.ObjectID) error {
 	if s.rejectionNoteRepo == nil {
 		return fmt.Errorf("rejection note repository not initialized")
 	}
+	if err := s.checkArticle(ctx, tenantID, articleID); err != nil {
+		return err
+	}
 
 	rejectionNote := &model.RejectionNote{
 		ArticleID:  articleID,
@@ -605,15 +1394,18 @@ func (s *ArticleService) AddRejectionNote(ctx context.Context, articleID primiti
 }
 
 // GetRejectionNotes gets all rejection notes for an article
-func (s *ArticleService) GetRejectionNotes(ctx context.Context, articleID primitive.ObjectID) ([]*model.RejectionNote, error) {
+func (s *ArticleService) GetRejectionNotes(ctx context.Context, tenantID, articleID primitive.ObjectID) ([]*model.RejectionNote, error) {
 	if s.rejectionNoteRepo == nil {
 		return nil, fmt.Errorf("rejection note repository not initialized")
 	}
+	if err := s.checkArticle(ctx, tenantID, articleID); err != nil {
+		return nil, err
+	}
 	return s.rejectionNoteRepo.FindByArticleID(ctx, articleID)
 }
 
 // ResolveRejectionNotes marks all rejection notes for an article as resolved
-func (s *ArticleService) ResolveRejectionNotes(ctx context.Context, articleID primitive.ObjectID, userID string, userRole model.Role) error {
+func (s *ArticleService) ResolveRejectionNotes(ctx context.Context, tenantID, articleID primitive.ObjectID, userID string, userRole model.Role) error {
 	// Only editors and moderators can resolve rejection notes
 	if userRole != model.RoleEditor && userRole != model.RoleModerator {
 		return fmt.Errorf("insufficient permissions: only editors and moderators can resolve rejection notes")
@@ -622,58 +1414,61 @@ func (s *ArticleService) ResolveRejectionNotes(ctx context.Context, articleID pr
 	if s.rejectionNoteRepo == nil {
 		return fmt.Errorf("rejection note repository not initialized")
 	}
+	if err := s.checkArticle(ctx, tenantID, articleID); err != nil {
+		return err
+	}
 
 	return s.rejectionNoteRepo.MarkAsResolved(ctx, articleID)
 }
 
 // GetUnresolvedRejectionCount gets the count of unresolved rejection notes for an article
-func (s *ArticleService) GetUnresolvedRejectionCount(ctx context.Context, articleID primitive.ObjectID) (int64, error) {
+func (s *ArticleService) GetUnresolvedRejectionCount(ctx context.Context, tenantID, articleID primitive.ObjectID) (int64, error) {
 	if s.rejectionNoteRepo == nil {
 		return 0, nil
 	}
+	if err := s.checkArticle(ctx, tenantID, articleID); err != nil {
+		return 0, err
+	}
 	return s.rejectionNoteRepo.CountUnresolvedByArticleID(ctx, articleID)
 }
 
 // FindByTag finds articles with a specific tag
-func (s *ArticleService) FindByTag(ctx context.Context, tag string, page, limit int) ([]*model.Article, int64, error) {
-	return s.repo.FindByTag(ctx, tag, page, limit)
+func (s *ArticleService) FindByTag(ctx context.Context, tenantID primitive.ObjectID, tag string, page, limit int) ([]*model.Article, int64, error) {
+	return s.repo.FindByTag(ctx, tenantID, tag, page, limit)
 }
 
 // FindByAuthor finds articles by author ID
-func (s *ArticleService) FindByAuthor(ctx context.Context, authorID string, page, limit int) ([]*model.Article, int64, error) {
-	return s.repo.FindByAuthor(ctx, authorID, page, limit)
+func (s *ArticleService) FindByAuthor(ctx context.Context, tenantID primitive.ObjectID, authorID string, page, limit int) ([]*model.Article, int64, error) {
+	return s.repo.FindByAuthor(ctx, tenantID, authorID, page, limit)
 }
 
 // GetRelatedArticles gets related articles for an article
-func (s *ArticleService) GetRelatedArticles(ctx context.Context, articleID primitive.ObjectID) ([]*model.Article, error) {
-	article, err := s.repo.FindByID(ctx, articleID)
+func (s *ArticleService) GetRelatedArticles(ctx context.Context, tenantID, articleID primitive.ObjectID) ([]*model.Article, error) {
+	article, err := s.repo.FindByID(ctx, tenantID, articleID)
 	if err != nil {
 		return nil, err
 	}
 
 	// If manually assigned related articles exist, return them
 	if len(article.RelatedArticles) > 0 {
-		return s.repo.FindRelatedArticles(ctx, article.RelatedArticles)
+		return s.repo.FindRelatedArticles(ctx, tenantID, article.RelatedArticles)
 	}
 
 	// Otherwise, find similar articles by tags
-	return s.repo.FindSimilarArticlesByTags(ctx, articleID, article.Tags, 5)
+	return s.repo.FindSimilarArticlesByTags(ctx, tenantID, articleID, article.Tags, 5)
 }
 
 // UpdateRelatedArticles updates related articles for an article
-func (s *ArticleService) UpdateRelatedArticles(ctx context.Context, articleID primitive.ObjectID, relatedIDs []primitive.ObjectID, userID string, userRole model.Role) error {
+func (s *ArticleService) UpdateRelatedArticles(ctx context.Context, tenantID, articleID primitive.ObjectID, relatedIDs []primitive.ObjectID, userID string, userRole model.Role) error {
 	// Only editors and moderators can update related articles
 	if userRole != model.RoleEditor && userRole != model.RoleModerator {
 		return fmt.Errorf("insufficient permissions: only editors and moderators can update related articles")
 	}
 
-	// Validate that related articles exist
-	if len(relatedIDs) > 0 {
-		_, err := s.repo.FindRelatedArticles(ctx, relatedIDs)
-		if err != nil {
-			return fmt.Errorf("failed to validate related articles: %w", err)
-		}
+	// Validate that related articles exist within the tenant
+	if err := s.checkRelatedArticles(ctx, tenantID, relatedIDs); err != nil {
+		return err
 	}
 
-	return s.repo.UpdateRelatedArticles(ctx, articleID, relatedIDs)
+	return s.repo.UpdateRelatedArticles(ctx, tenantID, articleID, relatedIDs)
 }
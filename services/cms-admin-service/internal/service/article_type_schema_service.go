@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ArticleTypeSchemaService handles article type schema business logic
+type ArticleTypeSchemaService struct {
+	repo *repository.ArticleTypeSchemaRepository
+}
+
+// NewArticleTypeSchemaService creates a new article type schema service
+func NewArticleTypeSchemaService(repo *repository.ArticleTypeSchemaRepository) *ArticleTypeSchemaService {
+	return &ArticleTypeSchemaService{
+		repo: repo,
+	}
+}
+
+// Save registers or replaces the schema of an article type for a tenant
+func (s *ArticleTypeSchemaService) Save(ctx context.Context, tenantID primitive.ObjectID, articleType model.ArticleType, raw json.RawMessage, userID string) (*model.ArticleTypeSchema, error) {
+	if err := validator.NewArticleValidator().ValidateSchemaDocument(articleType, raw); err != nil {
+		return nil, err
+	}
+
+	schema := &model.ArticleTypeSchema{
+		TenantID:    tenantID,
+		ArticleType: articleType,
+		Schema:      raw,
+		UpdatedBy:   userID,
+	}
+	if err := s.repo.Save(ctx, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// Get gets the schema of an article type for a tenant
+func (s *ArticleTypeSchemaService) Get(ctx context.Context, tenantID primitive.ObjectID, articleType model.ArticleType) (*model.ArticleTypeSchema, error) {
+	return s.repo.FindByType(ctx, tenantID, articleType)
+}
+
+// List lists all schemas registered by a tenant
+func (s *ArticleTypeSchemaService) List(ctx context.Context, tenantID primitive.ObjectID) ([]*model.ArticleTypeSchema, error) {
+	return s.repo.FindAll(ctx, tenantID)
+}
+
+// Delete removes the schema of an article type for a tenant
+func (s *ArticleTypeSchemaService) Delete(ctx context.Context, tenantID primitive.ObjectID, articleType model.ArticleType) error {
+	return s.repo.Delete(ctx, tenantID, articleType)
+}
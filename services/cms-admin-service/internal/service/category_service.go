@@ -24,8 +24,8 @@ func NewCategoryService(repo *repository.CategoryRepository) *CategoryService {
 	}
 }
 
-// Create creates a new category
-func (s *CategoryService) Create(ctx context.Context, category *model.Category, userID string) error {
+// Create creates a new category for a tenant
+func (s *CategoryService) Create(ctx context.Context, tenantID primitive.ObjectID, category *model.Category, userID string) error {
 	// Generate slug if not provided
 	if category.Slug == "" {
 		category.Slug = s.generateSlug(category.Name)
@@ -40,33 +40,56 @@ func (s *CategoryService) Create(ctx context.Context, category *model.Category,
 		return fmt.Errorf("categoryLink is required for Link category type")
 	}
 
+	category.TenantID = tenantID
+	if err := s.checkParent(ctx, category); err != nil {
+		return err
+	}
+
 	category.CreatedBy = userID
 	return s.repo.Create(ctx, category)
 }
 
 // FindByID finds a category by ID
-func (s *CategoryService) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Category, error) {
-	return s.repo.FindByID(ctx, id)
+func (s *CategoryService) FindByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.Category, error) {
+	return s.repo.FindByID(ctx, tenantID, id)
 }
 
 // FindBySlug finds a category by slug
-func (s *CategoryService) FindBySlug(ctx context.Context, slug string) (*model.Category, error) {
-	return s.repo.FindBySlug(ctx, slug)
+func (s *CategoryService) FindBySlug(ctx context.Context, tenantID primitive.ObjectID, slug string) (*model.Category, error) {
+	return s.repo.FindBySlug(ctx, tenantID, slug)
 }
 
-// Update updates a category
-func (s *CategoryService) Update(ctx context.Context, category *model.Category) error {
+// Update updates a category of a tenant
+func (s *CategoryService) Update(ctx context.Context, tenantID primitive.ObjectID, category *model.Category) error {
+	category.TenantID = tenantID
+	if err := s.checkParent(ctx, category); err != nil {
+		return err
+	}
 	return s.repo.Update(ctx, category)
 }
 
 // Delete deletes a category
-func (s *CategoryService) Delete(ctx context.Context, id primitive.ObjectID) error {
-	return s.repo.Delete(ctx, id)
+func (s *CategoryService) Delete(ctx context.Context, tenantID, id primitive.ObjectID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// checkParent ensures the parent category belongs to the same tenant
+func (s *CategoryService) checkParent(ctx context.Context, category *model.Category) error {
+	if category.ParentID == nil {
+		return nil
+	}
+	if *category.ParentID == category.ID {
+		return fmt.Errorf("category cannot be its own parent")
+	}
+	if _, err := s.repo.FindByID(ctx, category.TenantID, *category.ParentID); err != nil {
+		return fmt.Errorf("parent %w", err)
+	}
+	return nil
 }
 
-// GetTree gets the category tree structure
-func (s *CategoryService) GetTree(ctx context.Context) ([]*CategoryNode, error) {
-	allCategories, err := s.repo.FindAll(ctx)
+// GetTree gets the category tree structure of a tenant
+func (s *CategoryService) GetTree(ctx context.Context, tenantID primitive.ObjectID) ([]*CategoryNode, error) {
+	allCategories, err := s.repo.FindAll(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -100,8 +123,8 @@ func (s *CategoryService) GetTree(ctx context.Context) ([]*CategoryNode, error)
 }
 
 // GetChildren gets direct children of a category
-func (s *CategoryService) GetChildren(ctx context.Context, parentID *primitive.ObjectID) ([]*model.Category, error) {
-	return s.repo.FindByParentID(ctx, parentID)
+func (s *CategoryService) GetChildren(ctx context.Context, tenantID primitive.ObjectID, parentID *primitive.ObjectID) ([]*model.Category, error) {
+	return s.repo.FindByParentID(ctx, tenantID, parentID)
 }
 
 // generateSlug generates a URL-friendly slug from a name
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
@@ -18,18 +19,25 @@ const (
 
 // CommentService handles comment business logic
 type CommentService struct {
-	repo *repository.CommentRepository
+	repo           *repository.CommentRepository
+	articleRepo    *repository.ArticleRepository
+	keywordService *SensitiveKeywordService
 }
 
 // NewCommentService creates a new comment service
-func NewCommentService(repo *repository.CommentRepository) *CommentService {
+func NewCommentService(repo *repository.CommentRepository, articleRepo *repository.ArticleRepository, keywordService *SensitiveKeywordService) *CommentService {
 	return &CommentService{
-		repo: repo,
+		repo:           repo,
+		articleRepo:    articleRepo,
+		keywordService: keywordService,
 	}
 }
 
-// CreateComment creates a new comment
-func (s *CommentService) CreateComment(ctx context.Context, comment *model.Comment, userID string, userName string) error {
+// CreateComment creates a new comment on an article of the tenant. The content
+// is scanned with the tenant's sensitive keywords: blocked keywords refuse the
+// comment, review keywords note the matches for moderators and the rest are
+// returned as warnings.
+func (s *CommentService) CreateComment(ctx context.Context, tenantID primitive.ObjectID, comment *model.Comment, userID string, userName string) error {
 	// Validate content
 	comment.Content = strings.TrimSpace(comment.Content)
 	if comment.Content == "" {
@@ -39,6 +47,13 @@ func (s *CommentService) CreateComment(ctx context.Context, comment *model.Comme
 		return fmt.Errorf("comment exceeds maximum length of %d characters", MaxCommentLength)
 	}
 
+	if _, err := s.articleRepo.FindByID(ctx, tenantID, comment.ArticleID); err != nil {
+		return err
+	}
+	if err := s.scanComment(ctx, tenantID, comment); err != nil {
+		return err
+	}
+
 	// Check rate limit
 	allowed, err := s.repo.CheckRateLimit(ctx, userID, MaxCommentsPerHour)
 	if err != nil {
@@ -58,6 +73,9 @@ func (s *CommentService) CreateComment(ctx context.Context, comment *model.Comme
 		if err != nil {
 			return fmt.Errorf("parent comment not found: %w", err)
 		}
+		if parent.ArticleID != comment.ArticleID {
+			return fmt.Errorf("parent comment belongs to another article")
+		}
 
 		// Check nesting level
 		comment.Level = parent.Level + 1
@@ -166,3 +184,54 @@ func (s *CommentService) IsFavorited(ctx context.Context, userID string, article
 func (s *CommentService) GetPendingComments(ctx context.Context, page, limit int) ([]*model.Comment, int64, error) {
 	return s.repo.FindPendingComments(ctx, page, limit)
 }
+
+// ScreenComment checks a comment that is stored elsewhere, such as by the
+// stats service for the public site, before it is accepted: the article has to
+// belong to the tenant and blocked keywords refuse the comment. The scan tells
+// the caller whether the comment needs a moderator's attention.
+func (s *CommentService) ScreenComment(ctx context.Context, tenantID, articleID primitive.ObjectID, content string) (*model.ContentScanResult, error) {
+	if _, err := s.articleRepo.FindByID(ctx, tenantID, articleID); err != nil {
+		return nil, err
+	}
+	return s.scanContent(ctx, tenantID, strings.TrimSpace(content))
+}
+
+// scanComment checks a comment against the tenant's sensitive keywords
+func (s *CommentService) scanComment(ctx context.Context, tenantID primitive.ObjectID, comment *model.Comment) error {
+	comment.ContentWarnings = nil
+	scan, err := s.scanContent(ctx, tenantID, comment.Content)
+	if err != nil {
+		return err
+	}
+
+	if scan.RecommendedAction == model.KeywordActionReview {
+		// Comments always start pending; flag the matches for moderators
+		keywords := make([]string, 0, len(scan.Results))
+		for _, r := range scan.Results {
+			keywords = append(keywords, r.Keyword)
+		}
+		comment.ModerationNote = "Flagged by sensitive keywords: " + strings.Join(keywords, ", ")
+	}
+
+	if scan.HasViolations {
+		comment.ContentWarnings = scan.Results
+	}
+	return nil
+}
+
+// scanContent scans comment text with the tenant's sensitive keywords and
+// refuses it if a blocked keyword matches
+func (s *CommentService) scanContent(ctx context.Context, tenantID primitive.ObjectID, content string) (*model.ContentScanResult, error) {
+	if s.keywordService == nil {
+		return &model.ContentScanResult{ScannedAt: time.Now()}, nil
+	}
+
+	scan, err := s.keywordService.ScanFields(ctx, tenantID.Hex(), []ScanField{{Name: "content", Text: content}})
+	if err != nil {
+		return nil, err
+	}
+	if scan.RecommendedAction == model.KeywordActionBlock {
+		return nil, &ContentBlockedError{Scan: scan}
+	}
+	return scan, nil
+}
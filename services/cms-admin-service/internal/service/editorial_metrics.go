@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reviewQueueBuckets are the age ranges the review queue is counted in
+var reviewQueueBuckets = []struct {
+	label string
+	under time.Duration
+}{
+	{"<1d", 24 * time.Hour},
+	{"1-3d", 3 * 24 * time.Hour},
+	{"3-7d", 7 * 24 * time.Hour},
+	{">7d", math.MaxInt64},
+}
+
+// GetEditorialMetrics gets how long a tenant's articles took to publish, how
+// long they stayed in each status and how their reviews ended, overall and by
+// author, editor and category. Stays count in the period they ended in.
+func (s *StatisticsService) GetEditorialMetrics(ctx context.Context, tenantID primitive.ObjectID, startDate, endDate time.Time) (*model.PerformanceMetrics, error) {
+	stays, err := s.editorialRepo.FindStateStays(ctx, tenantID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	metrics := SummarizeStays(stays)
+
+	publishTimes, err := s.editorialRepo.FindPublishTimes(ctx, tenantID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	metrics.PublishTime = SummarizeDurations(publishTimes)
+	metrics.AveragePublishTime = metrics.PublishTime.Average
+
+	overview, err := s.repo.GetArticleStatistics(ctx, startDate, endDate, &tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if days := endDate.Sub(startDate).Hours() / 24; days > 0 {
+		metrics.ArticlesPerDay = float64(overview.TotalArticles) / days
+		metrics.ViewsPerDay = float64(overview.TotalViews) / days
+	}
+
+	categories, err := s.categoryRepo.FindAll(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(categories))
+	for _, category := range categories {
+		names[category.ID.Hex()] = category.Name
+	}
+	for _, group := range metrics.ByCategory {
+		group.Name = names[group.ID]
+	}
+
+	metrics.Period = model.StatisticsPeriod{StartDate: startDate, EndDate: endDate, Type: model.PeriodCustom}
+	return metrics, nil
+}
+
+// GetArticleEditorialMetrics gets how long each of a tenant's articles
+// stayed in each status, for stays that ended in the period. Articles that
+// spent longest in review come first.
+func (s *StatisticsService) GetArticleEditorialMetrics(ctx context.Context, tenantID primitive.ObjectID, startDate, endDate time.Time, limit int) ([]*model.ArticleEditorialMetrics, error) {
+	stays, err := s.editorialRepo.FindStateStays(ctx, tenantID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	byArticle := make(map[primitive.ObjectID]*model.ArticleEditorialMetrics)
+	articles := []*model.ArticleEditorialMetrics{}
+	for _, stay := range stays {
+		article, ok := byArticle[stay.ArticleID]
+		if !ok {
+			article = &model.ArticleEditorialMetrics{
+				ArticleID:   stay.ArticleID,
+				Title:       stay.Title,
+				AuthorID:    stay.AuthorID,
+				AuthorName:  stay.AuthorName,
+				CategoryID:  stay.CategoryID,
+				TimeInState: make(map[model.ArticleStatus]float64),
+			}
+			byArticle[stay.ArticleID] = article
+			articles = append(articles, article)
+		}
+		article.TimeInState[stay.Status] += stay.Hours()
+		if stay.Status == model.ArticleStatusPendingReview {
+			article.ReviewRounds++
+			if isRejection(stay) {
+				article.Rejections++
+			}
+		}
+	}
+
+	sort.SliceStable(articles, func(i, j int) bool {
+		return articles[i].TimeInState[model.ArticleStatusPendingReview] > articles[j].TimeInState[model.ArticleStatusPendingReview]
+	})
+	if limit > 0 && len(articles) > limit {
+		articles = articles[:limit]
+	}
+	return articles, nil
+}
+
+// GetReviewQueue gets the articles of a tenant waiting in pending_review and
+// how long they have waited
+func (s *StatisticsService) GetReviewQueue(ctx context.Context, tenantID primitive.ObjectID, now time.Time) (*model.ReviewQueue, error) {
+	articles, err := s.editorialRepo.FindReviewQueue(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &model.ReviewQueue{
+		Count:       len(articles),
+		Buckets:     make([]*model.AgeBucket, len(reviewQueueBuckets)),
+		Articles:    articles,
+		GeneratedAt: now,
+	}
+	for i, bucket := range reviewQueueBuckets {
+		queue.Buckets[i] = &model.AgeBucket{Label: bucket.label}
+	}
+
+	ages := make([]float64, len(articles))
+	for i, article := range articles {
+		age := max(now.Sub(article.SubmittedAt), 0)
+		article.AgeHours = age.Hours()
+		ages[i] = article.AgeHours
+		for j, bucket := range reviewQueueBuckets {
+			if age < bucket.under {
+				queue.Buckets[j].Count++
+				break
+			}
+		}
+	}
+	queue.Age = SummarizeDurations(ages)
+	return queue, nil
+}
+
+// SummarizeStays computes the times in state and review outcomes of a set of
+// stays, overall and by author, editor and category. A review is a stay in
+// pending_review that ended in publication or a rejection; the editor is who
+// made that decision. Withdrawn and deleted submissions are not reviews.
+func SummarizeStays(stays []*model.StateStay) *model.PerformanceMetrics {
+	overall := newStayGroup("", "")
+	authors := newStayGroups()
+	editors := newStayGroups()
+	categories := newStayGroups()
+
+	for _, stay := range stays {
+		overall.add(stay)
+		authors.get(stay.AuthorID, stay.AuthorName).add(stay)
+		categories.get(stay.CategoryID.Hex(), "").add(stay)
+		if isReview(stay) {
+			editors.get(stay.LeftBy, stay.LeftByName).add(stay)
+		}
+	}
+
+	summary := overall.summarize()
+	metrics := &model.PerformanceMetrics{
+		TimeInState:   summary.TimeInState,
+		Reviewed:      summary.Reviewed,
+		Rejected:      summary.Rejected,
+		RejectionRate: summary.RejectionRate,
+		ByAuthor:      authors.summarize(),
+		ByEditor:      editors.summarize(),
+		ByCategory:    categories.summarize(),
+	}
+	if review := summary.TimeInState[model.ArticleStatusPendingReview]; review != nil {
+		metrics.ReviewTime = *review
+		metrics.AverageReviewTime = review.Average
+	}
+	return metrics
+}
+
+// SummarizeDurations computes the count, average, median, 90th percentile and
+// maximum of durations in hours. Percentiles interpolate between the nearest
+// values.
+func SummarizeDurations(hours []float64) model.DurationStats {
+	if len(hours) == 0 {
+		return model.DurationStats{}
+	}
+	sorted := append([]float64(nil), hours...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, h := range sorted {
+		sum += h
+	}
+	return model.DurationStats{
+		Count:   len(sorted),
+		Average: sum / float64(len(sorted)),
+		Median:  percentile(sorted, 0.5),
+		P90:     percentile(sorted, 0.9),
+		Max:     sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the p-th percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// isReview reports whether a stay was a review that ended in a decision
+func isReview(stay *model.StateStay) bool {
+	return stay.Status == model.ArticleStatusPendingReview &&
+		(stay.NextStatus == model.ArticleStatusPublished || isRejection(stay))
+}
+
+// isRejection reports whether a stay in review ended in a rejection
+func isRejection(stay *model.StateStay) bool {
+	return stay.Status == model.ArticleStatusPendingReview && stay.LeftAction == model.ActionTypeReject
+}
+
+// stayGroup collects the stays of one author, editor or category
+type stayGroup struct {
+	id       string
+	name     string
+	articles map[primitive.ObjectID]bool
+	hours    map[model.ArticleStatus][]float64
+	reviewed int
+	rejected int
+}
+
+func newStayGroup(id, name string) *stayGroup {
+	return &stayGroup{
+		id:       id,
+		name:     name,
+		articles: make(map[primitive.ObjectID]bool),
+		hours:    make(map[model.ArticleStatus][]float64),
+	}
+}
+
+func (g *stayGroup) add(stay *model.StateStay) {
+	g.articles[stay.ArticleID] = true
+	g.hours[stay.Status] = append(g.hours[stay.Status], stay.Hours())
+	if isReview(stay) {
+		g.reviewed++
+		if isRejection(stay) {
+			g.rejected++
+		}
+	}
+}
+
+func (g *stayGroup) summarize() *model.EditorialMetrics {
+	metrics := &model.EditorialMetrics{
+		ID:          g.id,
+		Name:        g.name,
+		Articles:    len(g.articles),
+		TimeInState: make(map[model.ArticleStatus]*model.DurationStats, len(g.hours)),
+		Reviewed:    g.reviewed,
+		Rejected:    g.rejected,
+	}
+	for status, hours := range g.hours {
+		stats := SummarizeDurations(hours)
+		metrics.TimeInState[status] = &stats
+	}
+	if g.reviewed > 0 {
+		metrics.RejectionRate = float64(g.rejected) / float64(g.reviewed)
+	}
+	return metrics
+}
+
+// stayGroups keeps stay groups by ID in the order they were first seen
+type stayGroups struct {
+	byID  map[string]*stayGroup
+	order []*stayGroup
+}
+
+func newStayGroups() *stayGroups {
+	return &stayGroups{byID: make(map[string]*stayGroup)}
+}
+
+func (g *stayGroups) get(id, name string) *stayGroup {
+	group, ok := g.byID[id]
+	if !ok {
+		group = newStayGroup(id, name)
+		g.byID[id] = group
+		g.order = append(g.order, group)
+	}
+	return group
+}
+
+// summarize summarizes the groups, those with the most articles first
+func (g *stayGroups) summarize() []*model.EditorialMetrics {
+	metrics := make([]*model.EditorialMetrics, len(g.order))
+	for i, group := range g.order {
+		metrics[i] = group.summarize()
+	}
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].Articles > metrics[j].Articles
+	})
+	return metrics
+}
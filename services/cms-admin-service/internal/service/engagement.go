@@ -0,0 +1,37 @@
+package service
+
+import (
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+)
+
+// maxActiveSeconds caps the active time of one read, so a tab left open in
+// the foreground does not skew the averages
+const maxActiveSeconds = 30 * 60
+
+// newEngagementEvent normalizes a beacon, or reports false for a crawler
+func newEngagementEvent(filter *util.ViewFilter, beacon *model.EngagementBeacon, visitor model.Visitor) (model.EngagementEvent, bool) {
+	if filter != nil && filter.IsBot(visitor.UserAgent) {
+		return model.EngagementEvent{}, false
+	}
+
+	event := model.EngagementEvent{
+		ArticleID:     beacon.ArticleID,
+		Timestamp:     time.Now(),
+		ScrollDepth:   min(max(beacon.ScrollDepth, 0), 100) / 25 * 25,
+		ActiveSeconds: min(max(beacon.ActiveSeconds, 0), maxActiveSeconds),
+		Device:        beacon.DeviceClass,
+		Referrer:      util.ReferrerHost(beacon.Referrer),
+		UTMSource:     util.NormalizeUTM(beacon.UTMSource),
+		UTMMedium:     util.NormalizeUTM(beacon.UTMMedium),
+		UTMCampaign:   util.NormalizeUTM(beacon.UTMCampaign),
+	}
+	switch event.Device {
+	case model.DeviceMobile, model.DeviceTablet, model.DeviceDesktop:
+	default:
+		event.Device = util.DetectDevice(visitor.UserAgent)
+	}
+	return event, true
+}
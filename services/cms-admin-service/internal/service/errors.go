@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+)
+
+// ErrForbidden is returned when the caller is not allowed to perform an action
+var ErrForbidden = errors.New("forbidden")
+
+// ErrAIProvider is returned when an AI provider fails or cannot be reached
+var ErrAIProvider = errors.New("AI provider error")
+
+// ErrInvalidPreviewLink is returned for a preview token that is malformed,
+// expired or revoked, or whose article no longer exists
+var ErrInvalidPreviewLink = errors.New("preview link is invalid or has expired")
+
+// ErrHeldForReview is returned when the scheduler moves an article whose
+// content matches sensitive keywords to review instead of publishing it
+var ErrHeldForReview = errors.New("article was held for review instead of being published")
+
+// ContentBlockedError is returned when content matches a sensitive keyword
+// whose action is block
+type ContentBlockedError struct {
+	Scan *model.ContentScanResult
+}
+
+func (e *ContentBlockedError) Error() string {
+	return "content contains blocked keywords"
+}
+
+// QuotaExceededError is returned when a tenant or user has used up an AI quota
+type QuotaExceededError struct {
+	Scope    string // e.g. "tenant daily" or "user monthly"
+	Limit    int
+	ResetsAt time.Time
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s AI quota of %d calls exceeded; resets at %s", e.Scope, e.Limit, e.ResetsAt.Format(time.RFC3339))
+}
+
+// VersionConflictError is returned when a change is based on a version of an
+// article that is no longer the current one. Changes lists what was saved
+// since the base version.
+type VersionConflictError struct {
+	BaseVersion    int
+	CurrentVersion int
+	Changes        []model.FieldChange
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("article has changed since version %d; the current version is %d", e.BaseVersion, e.CurrentVersion)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return repository.ErrConflict
+}
+
+// EditLockedError is returned when someone else holds the edit lock on an
+// article
+type EditLockedError struct {
+	Lock *model.EditLock
+}
+
+func (e *EditLockedError) Error() string {
+	return fmt.Sprintf("article is being edited by %s until %s", lockHolderName(e.Lock), e.Lock.ExpiresAt.Format(time.RFC3339))
+}
+
+func (e *EditLockedError) Unwrap() error {
+	return repository.ErrConflict
+}
+
+// lockHolderName names the holder of a lock for messages
+func lockHolderName(lock *model.EditLock) string {
+	if lock.UserName != "" {
+		return lock.UserName
+	}
+	return lock.UserID
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+)
+
+// KeywordMatcher is the compiled form of a tenant's keyword list. Literal
+// keywords share one Aho-Corasick automaton, so a scan costs one pass over the
+// text however many keywords there are; patterns are compiled once. Matching
+// ignores case, and positions are rune offsets into the scanned text.
+type KeywordMatcher struct {
+	keywords []*model.SensitiveKeyword
+	literals *util.AhoCorasick
+	// literalKeywords maps an automaton pattern to its keyword
+	literalKeywords []int
+	// literalRunes holds the lowercased keyword, to check keywords that must
+	// match with their diacritics
+	literalRunes [][]rune
+	patterns     []compiledPattern
+	builtAt      time.Time
+}
+
+type compiledPattern struct {
+	keyword int
+	re      *regexp.Regexp
+}
+
+type keywordMatch struct {
+	start, end int
+}
+
+// NewKeywordMatcher compiles keywords for scanning. Inactive keywords and
+// patterns that do not compile are left out.
+func NewKeywordMatcher(keywords []*model.SensitiveKeyword) *KeywordMatcher {
+	m := &KeywordMatcher{keywords: keywords, builtAt: time.Now()}
+
+	var folded [][]rune
+	for i, kw := range keywords {
+		if !kw.IsActive || kw.Keyword == "" {
+			continue
+		}
+
+		if kw.IsRegex {
+			pattern := kw.Keyword
+			if kw.IgnoreDiacritics {
+				pattern = strings.Map(util.RemoveDiacritic, pattern)
+			}
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				continue
+			}
+			m.patterns = append(m.patterns, compiledPattern{keyword: i, re: re})
+			continue
+		}
+
+		lower := []rune(kw.Keyword)
+		fold := make([]rune, len(lower))
+		for j, r := range lower {
+			lower[j] = unicode.ToLower(r)
+			fold[j] = util.FoldRune(r)
+		}
+		folded = append(folded, fold)
+		m.literalKeywords = append(m.literalKeywords, i)
+		m.literalRunes = append(m.literalRunes, lower)
+	}
+
+	m.literals = util.NewAhoCorasick(folded)
+	return m
+}
+
+// Scan scans the fields. Matches are reported per field and keyword, and the
+// recommended action is the strictest action among all matched keywords.
+func (m *KeywordMatcher) Scan(fields []ScanField) *model.ContentScanResult {
+	result := &model.ContentScanResult{
+		HasViolations:     false,
+		Results:           []model.KeywordDetectionResult{},
+		HighestSeverity:   "none",
+		RecommendedAction: "none",
+		ScannedAt:         time.Now(),
+	}
+
+	highestLevel := 0
+	strictestAction := 0
+
+	for _, field := range fields {
+		if field.Text == "" {
+			continue
+		}
+
+		runes, found := m.matchKeywords(field.Text)
+		for _, f := range found {
+			kw := m.keywords[f.keyword]
+
+			detection := model.KeywordDetectionResult{
+				Field:       field.Name,
+				Keyword:     kw.Keyword,
+				Matches:     make([]string, len(f.matches)),
+				Positions:   make([]int, len(f.matches)),
+				Severity:    kw.Severity,
+				Action:      kw.Action,
+				Category:    kw.Category,
+				Description: kw.Description,
+			}
+			for j, match := range f.matches {
+				detection.Matches[j] = string(runes[match.start:match.end])
+				detection.Positions[j] = match.start
+			}
+
+			result.HasViolations = true
+			result.Results = append(result.Results, detection)
+
+			// Track highest severity and strictest action
+			if level, ok := severityLevels[kw.Severity]; ok && level > highestLevel {
+				highestLevel = level
+				result.HighestSeverity = kw.Severity
+			}
+			if level, ok := actionLevels[kw.Action]; ok && level > strictestAction {
+				strictestAction = level
+				result.RecommendedAction = kw.Action
+			}
+		}
+	}
+
+	return result
+}
+
+type keywordMatches struct {
+	keyword int
+	matches []keywordMatch
+}
+
+// matchKeywords finds the non-overlapping matches of every keyword in text,
+// ordered by keyword. It also returns the runes of text that the match
+// offsets refer to.
+func (m *KeywordMatcher) matchKeywords(text string) ([]rune, []keywordMatches) {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+		folded[i] = util.FoldRune(r)
+	}
+
+	byKeyword := make(map[int][]keywordMatch)
+	add := func(keyword int, match keywordMatch) {
+		kw := m.keywords[keyword]
+		if kw.WholeWord && !isWholeWord(runes, match.start, match.end) {
+			return
+		}
+		existing := byKeyword[keyword]
+		if n := len(existing); n > 0 && match.start < existing[n-1].end {
+			return
+		}
+		byKeyword[keyword] = append(existing, match)
+	}
+
+	m.literals.FindAll(folded, func(pattern, start, end int) {
+		keyword := m.literalKeywords[pattern]
+		if !m.keywords[keyword].IgnoreDiacritics && !equalRunes(lower[start:end], m.literalRunes[pattern]) {
+			return
+		}
+		add(keyword, keywordMatch{start: start, end: end})
+	})
+
+	var stripped string
+	for _, p := range m.patterns {
+		target := text
+		if m.keywords[p.keyword].IgnoreDiacritics {
+			if stripped == "" {
+				stripped = strings.Map(util.RemoveDiacritic, text)
+			}
+			target = stripped
+		}
+
+		// Convert byte offsets to rune offsets, counting forward from the
+		// previous match
+		byteOffset, runeOffset := 0, 0
+		toRune := func(b int) int {
+			runeOffset += utf8.RuneCountInString(target[byteOffset:b])
+			byteOffset = b
+			return runeOffset
+		}
+		for _, loc := range p.re.FindAllStringIndex(target, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			start := toRune(loc[0])
+			end := toRune(loc[1])
+			add(p.keyword, keywordMatch{start: start, end: end})
+		}
+	}
+
+	results := make([]keywordMatches, 0, len(byKeyword))
+	for i := range m.keywords {
+		if matches, ok := byKeyword[i]; ok {
+			results = append(results, keywordMatches{keyword: i, matches: matches})
+		}
+	}
+	return runes, results
+}
+
+// isWholeWord reports whether runes[start:end] is not part of a longer word
+func isWholeWord(runes []rune, start, end int) bool {
+	if start > 0 && util.IsWordRune(runes[start-1]) {
+		return false
+	}
+	if end < len(runes) && util.IsWordRune(runes[end]) {
+		return false
+	}
+	return true
+}
+
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -25,64 +27,41 @@ func NewPollService(repo *repository.PollRepository, articleRepo *repository.Art
 }
 
 // CreatePoll creates a new poll for an article
-func (s *PollService) CreatePoll(ctx context.Context, poll *model.Poll, userID string, userRole model.Role) error {
+func (s *PollService) CreatePoll(ctx context.Context, tenantID primitive.ObjectID, poll *model.Poll, userID string, userRole model.Role) error {
 	// Only editors and moderators can create polls
 	if userRole != model.RoleEditor && userRole != model.RoleModerator {
-		return fmt.Errorf("insufficient permissions: only editors and moderators can create polls")
+		return fmt.Errorf("%w: only editors and moderators can create polls", ErrForbidden)
 	}
 
-	// Validate poll
-	poll.Question = strings.TrimSpace(poll.Question)
-	if poll.Question == "" {
-		return fmt.Errorf("poll question cannot be empty")
-	}
-
-	if len(poll.Options) < 2 {
-		return fmt.Errorf("poll must have at least 2 options")
-	}
-
-	if len(poll.Options) > 10 {
-		return fmt.Errorf("poll cannot have more than 10 options")
-	}
-
-	// Validate and assign IDs to options
-	for i := range poll.Options {
-		poll.Options[i].Text = strings.TrimSpace(poll.Options[i].Text)
-		if poll.Options[i].Text == "" {
-			return fmt.Errorf("poll option text cannot be empty")
-		}
-		if poll.Options[i].ID == "" {
-			poll.Options[i].ID = fmt.Sprintf("option_%d", i+1)
-		}
+	if err := validatePoll(poll); err != nil {
+		return err
 	}
 
-	// Validate multiple selection settings
-	if poll.IsMultiple && poll.MaxSelections > len(poll.Options) {
-		poll.MaxSelections = len(poll.Options)
+	// The article must belong to the tenant before a poll is attached to it
+	if _, err := s.articleRepo.FindByID(ctx, tenantID, poll.ArticleID); err != nil {
+		return err
 	}
 
+	poll.TenantID = tenantID
 	poll.CreatedBy = userID
+	if poll.StartDate.IsZero() {
+		poll.StartDate = time.Now()
+	}
 
 	// Create poll
 	if err := s.repo.Create(ctx, poll); err != nil {
 		return err
 	}
+	poll.IsOpen = poll.AcceptsVotes(time.Now())
 
 	// Update article to link poll
-	article, err := s.articleRepo.FindByID(ctx, poll.ArticleID)
-	if err != nil {
-		return fmt.Errorf("article not found: %w", err)
-	}
-
-	article.HasPoll = true
-	article.PollID = &poll.ID
-
-	return s.articleRepo.Update(ctx, article)
+	return s.articleRepo.SetPoll(ctx, tenantID, poll.ArticleID, &poll.ID)
 }
 
-// GetPoll gets a poll by ID
-func (s *PollService) GetPoll(ctx context.Context, pollID primitive.ObjectID, userID string) (*model.Poll, *model.PollVote, error) {
-	poll, err := s.repo.FindByID(ctx, pollID)
+// GetPoll gets a published poll by ID along with the caller's vote, if any.
+// Polls that have been deactivated are hidden.
+func (s *PollService) GetPoll(ctx context.Context, tenantID, pollID primitive.ObjectID, userID string) (*model.Poll, *model.PollVote, error) {
+	poll, err := s.findVisible(ctx, tenantID, pollID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -97,87 +76,225 @@ func (s *PollService) GetPoll(ctx context.Context, pollID primitive.ObjectID, us
 }
 
 // GetArticlePoll gets the poll for an article
-func (s *PollService) GetArticlePoll(ctx context.Context, articleID primitive.ObjectID, userID string) (*model.Poll, *model.PollVote, error) {
-	poll, err := s.repo.FindByArticleID(ctx, articleID)
-	if err != nil {
-		return nil, nil, err
+func (s *PollService) GetArticlePoll(ctx context.Context, tenantID, articleID primitive.ObjectID) (*model.Poll, error) {
+	return s.repo.FindByArticleID(ctx, tenantID, articleID)
+}
+
+// VoteOnPoll records a vote on a poll. Signed-in users vote once per poll;
+// anonymous visitors vote once per IP address.
+func (s *PollService) VoteOnPoll(ctx context.Context, tenantID, pollID primitive.ObjectID, userID string, optionIDs []string, ipAddress string) error {
+	if len(optionIDs) == 0 {
+		return validator.ValidationErrors{{Field: "optionIds", Message: "must select at least one option"}}
+	}
+	if userID == "" && ipAddress == "" {
+		return fmt.Errorf("%w: cannot identify voter", ErrForbidden)
 	}
 
-	// Get user's vote if they voted
-	var userVote *model.PollVote
-	if userID != "" {
-		userVote, _ = s.repo.GetUserVote(ctx, poll.ID, userID)
+	poll, err := s.repo.FindByID(ctx, tenantID, pollID)
+	if err != nil {
+		return err
 	}
 
-	return poll, userVote, nil
-}
+	if !poll.AcceptsVotes(time.Now()) {
+		return fmt.Errorf("poll is not open for voting: %w", repository.ErrConflict)
+	}
 
-// VoteOnPoll records a user's vote on a poll
-func (s *PollService) VoteOnPoll(ctx context.Context, pollID primitive.ObjectID, userID string, optionIDs []string, ipAddress string) error {
-	if userID == "" {
-		return fmt.Errorf("user must be logged in to vote")
+	if err := validateVote(poll, optionIDs); err != nil {
+		return err
 	}
 
-	if len(optionIDs) == 0 {
-		return fmt.Errorf("must select at least one option")
+	// Check for an earlier vote; the unique vote indexes catch concurrent ones
+	var voted bool
+	if userID != "" {
+		voted, err = s.repo.HasUserVoted(ctx, pollID, userID)
+	} else {
+		voted, err = s.repo.HasIPVoted(ctx, pollID, ipAddress)
+	}
+	if err != nil {
+		return err
+	}
+	if voted {
+		return fmt.Errorf("vote %w", repository.ErrDuplicate)
 	}
 
 	vote := &model.PollVote{
-		PollID:    pollID,
 		UserID:    userID,
 		OptionIDs: optionIDs,
 		IPAddress: ipAddress,
 	}
 
-	return s.repo.Vote(ctx, vote)
+	return s.repo.Vote(ctx, poll, vote)
 }
 
-// UpdatePoll updates a poll (before any votes)
-func (s *PollService) UpdatePoll(ctx context.Context, poll *model.Poll, userID string, userRole model.Role) error {
+// UpdatePoll updates the poll of an article (before any votes)
+func (s *PollService) UpdatePoll(ctx context.Context, tenantID, articleID primitive.ObjectID, poll *model.Poll, userRole model.Role) error {
 	// Only editors and moderators can update polls
 	if userRole != model.RoleEditor && userRole != model.RoleModerator {
-		return fmt.Errorf("insufficient permissions: only editors and moderators can update polls")
+		return fmt.Errorf("%w: only editors and moderators can update polls", ErrForbidden)
 	}
 
 	// Get existing poll to check if it has votes
-	existing, err := s.repo.FindByID(ctx, poll.ID)
+	existing, err := s.repo.FindByArticleID(ctx, tenantID, articleID)
 	if err != nil {
 		return err
 	}
 
 	if existing.TotalVotes > 0 {
-		return fmt.Errorf("cannot update poll after votes have been cast")
+		return fmt.Errorf("cannot update poll after votes have been cast: %w", repository.ErrConflict)
 	}
 
-	// Validate poll
-	poll.Question = strings.TrimSpace(poll.Question)
-	if poll.Question == "" {
-		return fmt.Errorf("poll question cannot be empty")
+	if err := validatePoll(poll); err != nil {
+		return err
 	}
 
-	if len(poll.Options) < 2 {
-		return fmt.Errorf("poll must have at least 2 options")
+	// Identity and audit fields are never taken from the request body
+	poll.ID = existing.ID
+	poll.TenantID = existing.TenantID
+	poll.ArticleID = existing.ArticleID
+	poll.TotalVotes = 0
+	poll.CreatedAt = existing.CreatedAt
+	poll.CreatedBy = existing.CreatedBy
+	if poll.StartDate.IsZero() {
+		poll.StartDate = existing.StartDate
+	}
+
+	if err := s.repo.Update(ctx, poll); err != nil {
+		return err
+	}
+	poll.IsOpen = poll.AcceptsVotes(time.Now())
+	return nil
+}
+
+// DeletePoll deletes the poll of an article together with its votes
+func (s *PollService) DeletePoll(ctx context.Context, tenantID, articleID primitive.ObjectID, userRole model.Role) error {
+	// Only editors and moderators can delete polls
+	if userRole != model.RoleEditor && userRole != model.RoleModerator {
+		return fmt.Errorf("%w: only editors and moderators can delete polls", ErrForbidden)
+	}
+
+	poll, err := s.repo.FindByArticleID(ctx, tenantID, articleID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, tenantID, poll.ID); err != nil {
+		return err
 	}
 
-	return s.repo.Update(ctx, poll)
+	return s.articleRepo.SetPoll(ctx, tenantID, articleID, nil)
 }
 
-// SetPollStatus activates or deactivates a poll
-func (s *PollService) SetPollStatus(ctx context.Context, pollID primitive.ObjectID, isActive bool, userRole model.Role) error {
+// SetPollStatus activates or deactivates the poll of an article
+func (s *PollService) SetPollStatus(ctx context.Context, tenantID, articleID primitive.ObjectID, isActive bool, userRole model.Role) error {
 	// Only editors and moderators can change poll status
 	if userRole != model.RoleEditor && userRole != model.RoleModerator {
-		return fmt.Errorf("insufficient permissions: only editors and moderators can change poll status")
+		return fmt.Errorf("%w: only editors and moderators can change poll status", ErrForbidden)
 	}
 
-	return s.repo.SetPollStatus(ctx, pollID, isActive)
+	poll, err := s.repo.FindByArticleID(ctx, tenantID, articleID)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetPollStatus(ctx, tenantID, poll.ID, isActive)
 }
 
-// GetPollResults gets poll results
-func (s *PollService) GetPollResults(ctx context.Context, pollID primitive.ObjectID) (*model.Poll, error) {
-	return s.repo.GetPollResults(ctx, pollID)
+// GetPollResults gets the results of a poll. Results stay available after the
+// poll ends but are hidden once it has been deactivated.
+func (s *PollService) GetPollResults(ctx context.Context, tenantID, pollID primitive.ObjectID) (*model.Poll, error) {
+	return s.findVisible(ctx, tenantID, pollID)
 }
 
 // HasUserVoted checks if a user has voted on a poll
 func (s *PollService) HasUserVoted(ctx context.Context, pollID primitive.ObjectID, userID string) (bool, error) {
 	return s.repo.HasUserVoted(ctx, pollID, userID)
 }
+
+// findVisible finds a poll that may be shown publicly
+func (s *PollService) findVisible(ctx context.Context, tenantID, pollID primitive.ObjectID) (*model.Poll, error) {
+	poll, err := s.repo.GetPollResults(ctx, tenantID, pollID)
+	if err != nil {
+		return nil, err
+	}
+	if !poll.IsActive {
+		return nil, fmt.Errorf("poll %w", repository.ErrNotFound)
+	}
+	return poll, nil
+}
+
+// validatePoll checks the question, options and voting period of a poll and
+// assigns IDs to options that have none
+func validatePoll(poll *model.Poll) error {
+	var errs validator.ValidationErrors
+
+	poll.Question = strings.TrimSpace(poll.Question)
+	if poll.Question == "" {
+		errs = append(errs, validator.ValidationError{Field: "question", Message: "poll question cannot be empty"})
+	}
+
+	if len(poll.Options) < 2 {
+		errs = append(errs, validator.ValidationError{Field: "options", Message: "poll must have at least 2 options"})
+	}
+	if len(poll.Options) > 10 {
+		errs = append(errs, validator.ValidationError{Field: "options", Message: "poll cannot have more than 10 options"})
+	}
+
+	// Validate and assign IDs to options
+	seen := make(map[string]bool)
+	for i := range poll.Options {
+		poll.Options[i].Text = strings.TrimSpace(poll.Options[i].Text)
+		if poll.Options[i].Text == "" {
+			errs = append(errs, validator.ValidationError{Field: fmt.Sprintf("options[%d].text", i), Message: "poll option text cannot be empty"})
+		}
+		if poll.Options[i].ID == "" {
+			poll.Options[i].ID = fmt.Sprintf("option_%d", i+1)
+		}
+		if seen[poll.Options[i].ID] {
+			errs = append(errs, validator.ValidationError{Field: fmt.Sprintf("options[%d].id", i), Message: fmt.Sprintf("duplicate option ID %s", poll.Options[i].ID)})
+		}
+		seen[poll.Options[i].ID] = true
+	}
+
+	// Validate multiple selection settings
+	if poll.IsMultiple && poll.MaxSelections > len(poll.Options) {
+		poll.MaxSelections = len(poll.Options)
+	}
+
+	if poll.EndDate != nil && !poll.StartDate.IsZero() && !poll.EndDate.After(poll.StartDate) {
+		errs = append(errs, validator.ValidationError{Field: "endDate", Message: "end date must be after start date"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateVote checks the selected options against the poll
+func validateVote(poll *model.Poll, optionIDs []string) error {
+	validOptions := make(map[string]bool)
+	for _, opt := range poll.Options {
+		validOptions[opt.ID] = true
+	}
+
+	selected := make(map[string]bool)
+	for _, optID := range optionIDs {
+		if !validOptions[optID] {
+			return validator.ValidationErrors{{Field: "optionIds", Message: fmt.Sprintf("invalid option ID: %s", optID)}}
+		}
+		if selected[optID] {
+			return validator.ValidationErrors{{Field: "optionIds", Message: fmt.Sprintf("option %s selected more than once", optID)}}
+		}
+		selected[optID] = true
+	}
+
+	// Check multiple selection rules
+	if !poll.IsMultiple && len(optionIDs) > 1 {
+		return validator.ValidationErrors{{Field: "optionIds", Message: "poll does not allow multiple selections"}}
+	}
+	if poll.IsMultiple && poll.MaxSelections > 0 && len(optionIDs) > poll.MaxSelections {
+		return validator.ValidationErrors{{Field: "optionIds", Message: fmt.Sprintf("exceeded maximum selections: %d", poll.MaxSelections)}}
+	}
+
+	return nil
+}
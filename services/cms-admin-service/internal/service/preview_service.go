@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxPreviewTTL is the longest a preview link can be valid
+const maxPreviewTTL = 30 * 24 * time.Hour
+
+// PreviewService mints and serves preview links, which show one version of an
+// article to people outside the CMS whether or not it is published
+type PreviewService struct {
+	articleRepo *repository.ArticleRepository
+	versionRepo *repository.ArticleVersionRepository
+	linkRepo    *repository.PreviewLinkRepository
+	secret      []byte
+	baseURL     string
+	defaultTTL  time.Duration
+}
+
+// NewPreviewService creates a new preview service. Tokens are signed with
+// secret; without one no links can be created. The link URL is baseURL
+// followed by the token.
+func NewPreviewService(
+	articleRepo *repository.ArticleRepository,
+	versionRepo *repository.ArticleVersionRepository,
+	linkRepo *repository.PreviewLinkRepository,
+	secret string,
+	baseURL string,
+	defaultTTL time.Duration,
+) *PreviewService {
+	return &PreviewService{
+		articleRepo: articleRepo,
+		versionRepo: versionRepo,
+		linkRepo:    linkRepo,
+		secret:      []byte(secret),
+		baseURL:     baseURL,
+		defaultTTL:  defaultTTL,
+	}
+}
+
+// CreateLink mints a preview link for a version of an article. A version of 0
+// previews the current version and a ttl of 0 uses the default.
+func (s *PreviewService) CreateLink(ctx context.Context, tenantID, articleID primitive.ObjectID, version int, ttl time.Duration, userID string) (*model.PreviewLink, error) {
+	if len(s.secret) == 0 {
+		return nil, fmt.Errorf("preview links are not configured")
+	}
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if ttl < 0 || ttl > maxPreviewTTL {
+		return nil, fmt.Errorf("preview links can be valid for at most %s", maxPreviewTTL)
+	}
+
+	article, err := s.articleRepo.FindByID(ctx, tenantID, articleID)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		version = article.CurrentVersion
+	} else if version != article.CurrentVersion {
+		if _, err := s.versionRepo.FindByVersionNumber(ctx, articleID, version); err != nil {
+			return nil, fmt.Errorf("version %d: %w", version, err)
+		}
+	}
+
+	link := &model.PreviewLink{
+		TenantID:  tenantID,
+		ArticleID: articleID,
+		Version:   version,
+		CreatedBy: userID,
+		ExpiresAt: time.Now().Add(ttl).Truncate(time.Second),
+	}
+	if err := s.linkRepo.Create(ctx, link); err != nil {
+		return nil, err
+	}
+
+	link.Token, err = util.SignPreviewToken(s.secret, &util.PreviewClaims{
+		LinkID:    link.ID,
+		TenantID:  tenantID,
+		ArticleID: articleID,
+		Version:   version,
+		ExpiresAt: link.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	link.URL = s.baseURL + link.Token
+	return link, nil
+}
+
+// ListLinks lists the preview links of an article that have not expired
+func (s *PreviewService) ListLinks(ctx context.Context, tenantID, articleID primitive.ObjectID) ([]*model.PreviewLink, error) {
+	if _, err := s.articleRepo.FindByID(ctx, tenantID, articleID); err != nil {
+		return nil, err
+	}
+	return s.linkRepo.FindActiveByArticleID(ctx, tenantID, articleID)
+}
+
+// RevokeLinks revokes every preview link of an article and returns how many
+// there were
+func (s *PreviewService) RevokeLinks(ctx context.Context, tenantID, articleID primitive.ObjectID) (int64, error) {
+	if _, err := s.articleRepo.FindByID(ctx, tenantID, articleID); err != nil {
+		return 0, err
+	}
+	return s.linkRepo.DeleteByArticleID(ctx, tenantID, articleID)
+}
+
+// GetPreview returns the article version a preview token was minted for,
+// regardless of its status. Invalid, expired and revoked tokens return
+// ErrInvalidPreviewLink.
+func (s *PreviewService) GetPreview(ctx context.Context, token string) (*model.Article, error) {
+	if len(s.secret) == 0 {
+		return nil, ErrInvalidPreviewLink
+	}
+	claims, err := util.ParsePreviewToken(s.secret, token)
+	if err != nil || time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrInvalidPreviewLink
+	}
+
+	if _, err := s.linkRepo.FindActive(ctx, claims.TenantID, claims.ArticleID, claims.LinkID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidPreviewLink
+		}
+		return nil, err
+	}
+
+	article, err := s.articleRepo.FindByID(ctx, claims.TenantID, claims.ArticleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidPreviewLink
+		}
+		return nil, err
+	}
+	if claims.Version == article.CurrentVersion {
+		return article, nil
+	}
+
+	version, err := s.versionRepo.FindByVersionNumber(ctx, claims.ArticleID, claims.Version)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidPreviewLink
+		}
+		return nil, err
+	}
+	snapshot := *versionSnapshot(version)
+	snapshot.TenantID = article.TenantID
+	snapshot.CurrentVersion = version.VersionNum
+	return &snapshot, nil
+}
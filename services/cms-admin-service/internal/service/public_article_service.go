@@ -9,15 +9,17 @@ import (
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/cache"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // PublicArticleService handles article business logic for public/user-facing APIs with caching
 type PublicArticleService struct {
-	repo      *repository.ArticleRepository
-	cache     cache.Cache
-	cacheTTL  time.Duration
-	viewQueue ViewQueue
+	repo       *repository.ArticleRepository
+	cache      cache.Cache
+	cacheTTL   time.Duration
+	viewQueue  ViewQueue
+	viewFilter *util.ViewFilter
 }
 
 // NewPublicArticleService creates a new public article service
@@ -26,18 +28,20 @@ func NewPublicArticleService(
 	cache cache.Cache,
 	cacheTTL time.Duration,
 	viewQueue ViewQueue,
+	viewFilter *util.ViewFilter,
 ) *PublicArticleService {
 	return &PublicArticleService{
-		repo:      repo,
-		cache:     cache,
-		cacheTTL:  cacheTTL,
-		viewQueue: viewQueue,
+		repo:       repo,
+		cache:      cache,
+		cacheTTL:   cacheTTL,
+		viewQueue:  viewQueue,
+		viewFilter: viewFilter,
 	}
 }
 
 // GetArticleByID gets a published article by ID with caching
-func (s *PublicArticleService) GetArticleByID(ctx context.Context, id primitive.ObjectID) (*model.Article, error) {
-	cacheKey := fmt.Sprintf("article:public:id:%s", id.Hex())
+func (s *PublicArticleService) GetArticleByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.Article, error) {
+	cacheKey := fmt.Sprintf("article:public:%s:id:%s", tenantID.Hex(), id.Hex())
 
 	// Try to get from cache
 	var article model.Article
@@ -49,7 +53,7 @@ func (s *PublicArticleService) GetArticleByID(ctx context.Context, id primitive.
 
 	// Cache miss - get from database
 	log.Printf("Cache miss for article ID: %s", id.Hex())
-	dbArticle, err := s.repo.FindByID(ctx, id)
+	dbArticle, err := s.repo.FindByID(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -68,8 +72,8 @@ func (s *PublicArticleService) GetArticleByID(ctx context.Context, id primitive.
 }
 
 // GetArticleBySlug gets a published article by slug with caching
-func (s *PublicArticleService) GetArticleBySlug(ctx context.Context, slug string) (*model.Article, error) {
-	cacheKey := fmt.Sprintf("article:public:slug:%s", slug)
+func (s *PublicArticleService) GetArticleBySlug(ctx context.Context, tenantID primitive.ObjectID, slug string) (*model.Article, error) {
+	cacheKey := fmt.Sprintf("article:public:%s:slug:%s", tenantID.Hex(), slug)
 
 	// Try to get from cache
 	var article model.Article
@@ -81,7 +85,7 @@ func (s *PublicArticleService) GetArticleBySlug(ctx context.Context, slug string
 
 	// Cache miss - get from database
 	log.Printf("Cache miss for article slug: %s", slug)
-	dbArticle, err := s.repo.FindBySlug(ctx, slug)
+	dbArticle, err := s.repo.FindBySlug(ctx, tenantID, slug)
 	if err != nil {
 		return nil, err
 	}
@@ -100,9 +104,9 @@ func (s *PublicArticleService) GetArticleBySlug(ctx context.Context, slug string
 }
 
 // ListPublicArticles lists published articles with caching
-func (s *PublicArticleService) ListPublicArticles(ctx context.Context, filter map[string]interface{}, page, limit int, sort map[string]int) ([]*model.Article, int64, error) {
-	// Create cache key based on filter, page, limit, sort
-	cacheKey := fmt.Sprintf("articles:public:list:%v:%d:%d:%v", filter, page, limit, sort)
+func (s *PublicArticleService) ListPublicArticles(ctx context.Context, tenantID primitive.ObjectID, filter map[string]interface{}, page, limit int, sort map[string]int) ([]*model.Article, int64, error) {
+	// Create cache key based on tenant, filter, page, limit, sort
+	cacheKey := fmt.Sprintf("articles:public:%s:list:%v:%d:%d:%v", tenantID.Hex(), filter, page, limit, sort)
 
 	// Try to get from cache
 	var cachedResult struct {
@@ -127,7 +131,7 @@ func (s *PublicArticleService) ListPublicArticles(ctx context.Context, filter ma
 		filter["publishAt"] = map[string]interface{}{"$lte": now}
 	}
 
-	articles, total, err := s.repo.FindAll(ctx, filter, page, limit, sort)
+	articles, total, err := s.repo.FindAll(ctx, tenantID, filter, page, limit, sort)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -150,11 +154,32 @@ func (s *PublicArticleService) ListPublicArticles(ctx context.Context, filter ma
 	return accessibleArticles, total, nil
 }
 
-// IncrementViewCount increments the view count for an article (no cache)
-func (s *PublicArticleService) IncrementViewCount(ctx context.Context, id primitive.ObjectID) error {
+// GetTrendingArticles lists the published articles of a category with the
+// highest trending score
+func (s *PublicArticleService) GetTrendingArticles(ctx context.Context, tenantID, categoryID primitive.ObjectID, limit int) ([]*model.Article, error) {
+	filter := map[string]interface{}{
+		"categoryId":    categoryID,
+		"trendingScore": map[string]interface{}{"$gt": 0},
+	}
+	articles, _, err := s.ListPublicArticles(ctx, tenantID, filter, 1, limit, map[string]int{"trendingScore": -1})
+	return articles, err
+}
+
+// IncrementViewCount increments the view count for an article (no cache).
+// Views by crawlers are ignored.
+func (s *PublicArticleService) IncrementViewCount(ctx context.Context, tenantID, id primitive.ObjectID, visitor model.Visitor) error {
+	// Only published articles of the tenant are counted
+	if _, err := s.GetArticleByID(ctx, tenantID, id); err != nil {
+		return err
+	}
+	event, counted := newViewEvent(s.viewFilter, id, visitor)
+	if !counted {
+		return nil
+	}
+
 	// Enqueue view event for asynchronous processing
 	if s.viewQueue != nil {
-		return s.viewQueue.Enqueue(id)
+		return s.viewQueue.Enqueue(event)
 	}
 
 	// Fallback to synchronous processing if queue not available
@@ -166,8 +191,8 @@ func (s *PublicArticleService) InvalidateArticleCache(ctx context.Context, artic
 	log.Printf("Invalidating cache for article: %s", article.ID.Hex())
 
 	keys := []string{
-		fmt.Sprintf("article:public:id:%s", article.ID.Hex()),
-		fmt.Sprintf("article:public:slug:%s", article.Slug),
+		fmt.Sprintf("article:public:%s:id:%s", article.TenantID.Hex(), article.ID.Hex()),
+		fmt.Sprintf("article:public:%s:slug:%s", article.TenantID.Hex(), article.Slug),
 	}
 
 	if err := s.cache.Delete(ctx, keys...); err != nil {
@@ -176,7 +201,7 @@ func (s *PublicArticleService) InvalidateArticleCache(ctx context.Context, artic
 	}
 
 	// Invalidate list caches
-	if err := s.cache.DeletePattern(ctx, "articles:public:list:*"); err != nil {
+	if err := s.cache.DeletePattern(ctx, fmt.Sprintf("articles:public:%s:list:*", article.TenantID.Hex())); err != nil {
 		log.Printf("Failed to invalidate article list cache: %v", err)
 		return err
 	}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // RSS represents an RSS 2.0 feed
@@ -72,8 +73,8 @@ func NewRSSService(articleRepo *repository.ArticleRepository, baseURL string) *R
 	}
 }
 
-// GenerateFeed generates an RSS feed for published articles
-func (s *RSSService) GenerateFeed(ctx context.Context, limit int, categoryID *string) (string, error) {
+// GenerateFeed generates an RSS feed for a tenant's published articles
+func (s *RSSService) GenerateFeed(ctx context.Context, tenantID primitive.ObjectID, limit int, categoryID *string) (string, error) {
 	if limit <= 0 || limit > 100 {
 		limit = 50 // Default limit
 	}
@@ -88,7 +89,7 @@ func (s *RSSService) GenerateFeed(ctx context.Context, limit int, categoryID *st
 	}
 
 	// Get articles
-	articles, _, err := s.articleRepo.FindAll(ctx, filter, 1, limit, map[string]int{
+	articles, _, err := s.articleRepo.FindAll(ctx, tenantID, filter, 1, limit, map[string]int{
 		"publishAt": -1, // Sort by publish date descending
 	})
 	if err != nil {
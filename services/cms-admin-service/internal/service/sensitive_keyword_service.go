@@ -2,37 +2,77 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// matcherTTL bounds how long a compiled matcher is used. Changes made through
+// this instance take effect at once; the TTL picks up changes made elsewhere.
+const matcherTTL = 5 * time.Minute
+
+// SensitiveKeywordService handles sensitive keyword management and content scanning
 type SensitiveKeywordService struct {
 	repo *repository.SensitiveKeywordRepository
+
+	// Compiled matchers per tenant. A tenant's generation is bumped whenever
+	// its keywords change, so a matcher built from older keywords is not cached.
+	mu          sync.RWMutex
+	matchers    map[string]*KeywordMatcher
+	generations map[string]uint64
 }
 
+// NewSensitiveKeywordService creates a new sensitive keyword service
 func NewSensitiveKeywordService(repo *repository.SensitiveKeywordRepository) *SensitiveKeywordService {
-	return &SensitiveKeywordService{repo: repo}
+	return &SensitiveKeywordService{
+		repo:        repo,
+		matchers:    make(map[string]*KeywordMatcher),
+		generations: make(map[string]uint64),
+	}
 }
 
+// ScanField is a named piece of text to scan
+type ScanField struct {
+	Name string
+	Text string
+}
+
+var (
+	severityLevels = map[string]int{
+		model.KeywordSeverityLow:      1,
+		model.KeywordSeverityMedium:   2,
+		model.KeywordSeverityHigh:     3,
+		model.KeywordSeverityCritical: 4,
+	}
+	actionLevels = map[string]int{
+		model.KeywordActionWarn:   1,
+		model.KeywordActionReview: 2,
+		model.KeywordActionBlock:  3,
+	}
+)
+
 // Create adds a new sensitive keyword
 func (s *SensitiveKeywordService) Create(ctx context.Context, keyword *model.SensitiveKeyword) error {
-	// Validate regex if isRegex is true
-	if keyword.IsRegex {
-		if _, err := regexp.Compile(keyword.Keyword); err != nil {
-			return err
-		}
+	if err := validateKeyword(keyword, ""); err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, keyword); err != nil {
+		return err
 	}
-	return s.repo.Create(ctx, keyword)
+	s.invalidate(keyword.TenantID)
+	return nil
 }
 
 // GetByID retrieves a keyword by ID
-func (s *SensitiveKeywordService) GetByID(ctx context.Context, id primitive.ObjectID) (*model.SensitiveKeyword, error) {
-	return s.repo.GetByID(ctx, id)
+func (s *SensitiveKeywordService) GetByID(ctx context.Context, tenantID string, id primitive.ObjectID) (*model.SensitiveKeyword, error) {
+	return s.repo.GetByID(ctx, tenantID, id)
 }
 
 // GetByTenant retrieves all keywords for a tenant
@@ -42,111 +82,135 @@ func (s *SensitiveKeywordService) GetByTenant(ctx context.Context, tenantID stri
 
 // Update updates a keyword
 func (s *SensitiveKeywordService) Update(ctx context.Context, keyword *model.SensitiveKeyword) error {
-	if keyword.IsRegex {
-		if _, err := regexp.Compile(keyword.Keyword); err != nil {
-			return err
-		}
+	if err := validateKeyword(keyword, ""); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, keyword); err != nil {
+		return err
 	}
-	return s.repo.Update(ctx, keyword)
+	s.invalidate(keyword.TenantID)
+	return nil
 }
 
 // Delete deletes a keyword
-func (s *SensitiveKeywordService) Delete(ctx context.Context, id primitive.ObjectID) error {
-	return s.repo.Delete(ctx, id)
+func (s *SensitiveKeywordService) Delete(ctx context.Context, tenantID string, id primitive.ObjectID) error {
+	if err := s.repo.Delete(ctx, tenantID, id); err != nil {
+		return err
+	}
+	s.invalidate(tenantID)
+	return nil
 }
 
 // ScanContent scans content for sensitive keywords
 func (s *SensitiveKeywordService) ScanContent(ctx context.Context, tenantID, content string) (*model.ContentScanResult, error) {
+	return s.ScanFields(ctx, tenantID, []ScanField{{Text: content}})
+}
+
+// ScanFields scans several named texts against the tenant's compiled keyword
+// matcher. Matches are reported per field, and the recommended action is the
+// strictest action among all matched keywords.
+func (s *SensitiveKeywordService) ScanFields(ctx context.Context, tenantID string, fields []ScanField) (*model.ContentScanResult, error) {
+	matcher, err := s.matcher(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return matcher.Scan(fields), nil
+}
+
+// matcher returns the cached matcher of a tenant, compiling it from the active
+// keywords when missing or older than matcherTTL
+func (s *SensitiveKeywordService) matcher(ctx context.Context, tenantID string) (*KeywordMatcher, error) {
+	s.mu.RLock()
+	matcher, ok := s.matchers[tenantID]
+	generation := s.generations[tenantID]
+	s.mu.RUnlock()
+	if ok && time.Since(matcher.builtAt) < matcherTTL {
+		return matcher, nil
+	}
+
 	keywords, err := s.repo.GetByTenant(ctx, tenantID, true)
 	if err != nil {
 		return nil, err
 	}
+	matcher = NewKeywordMatcher(keywords)
 
-	result := &model.ContentScanResult{
-		HasViolations:     false,
-		Results:           []model.KeywordDetectionResult{},
-		HighestSeverity:   "none",
-		RecommendedAction: "none",
-		ScannedAt:         time.Now(),
+	s.mu.Lock()
+	if s.generations[tenantID] == generation {
+		s.matchers[tenantID] = matcher
 	}
+	s.mu.Unlock()
 
-	severityLevels := map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
-	highestLevel := 0
+	return matcher, nil
+}
 
-	contentLower := strings.ToLower(content)
+// invalidate drops the cached matcher of a tenant after its keywords change
+func (s *SensitiveKeywordService) invalidate(tenantID string) {
+	s.mu.Lock()
+	delete(s.matchers, tenantID)
+	s.generations[tenantID]++
+	s.mu.Unlock()
+}
 
-	for _, kw := range keywords {
-		var matches []string
-		var positions []int
-
-		if kw.IsRegex {
-			// Regex matching
-			re, err := regexp.Compile("(?i)" + kw.Keyword)
-			if err != nil {
-				continue
-			}
-			found := re.FindAllStringIndex(content, -1)
-			if len(found) > 0 {
-				for _, match := range found {
-					matches = append(matches, content[match[0]:match[1]])
-					positions = append(positions, match[0])
-				}
-			}
-		} else {
-			// Simple keyword matching
-			keywordLower := strings.ToLower(kw.Keyword)
-			keywordLen := len(keywordLower)
-			index := 0
-			for {
-				pos := strings.Index(contentLower[index:], keywordLower)
-				if pos == -1 {
-					break
-				}
-				actualPos := index + pos
-				// Ensure we don't go out of bounds
-				endPos := actualPos + keywordLen
-				if endPos > len(content) {
-					endPos = len(content)
-				}
-				matches = append(matches, content[actualPos:endPos])
-				positions = append(positions, actualPos)
-				index = actualPos + keywordLen
-			}
-		}
+// BulkImport imports multiple keywords at once. Nothing is imported unless
+// every keyword is valid.
+func (s *SensitiveKeywordService) BulkImport(ctx context.Context, keywords []*model.SensitiveKeyword) error {
+	if len(keywords) == 0 {
+		return validator.ValidationErrors{{Field: "keywords", Message: "no keywords to import"}}
+	}
 
-		if len(matches) > 0 {
-			result.HasViolations = true
-			result.Results = append(result.Results, model.KeywordDetectionResult{
-				Keyword:     kw.Keyword,
-				Matches:     matches,
-				Positions:   positions,
-				Severity:    kw.Severity,
-				Action:      kw.Action,
-				Category:    kw.Category,
-				Description: kw.Description,
-			})
-
-			// Track highest severity
-			if level, ok := severityLevels[kw.Severity]; ok && level > highestLevel {
-				highestLevel = level
-				result.HighestSeverity = kw.Severity
-				result.RecommendedAction = kw.Action
-			}
+	var errs validator.ValidationErrors
+	for i, kw := range keywords {
+		if err := validateKeyword(kw, fmt.Sprintf("keywords[%d].", i)); err != nil {
+			errs = append(errs, err.(validator.ValidationErrors)...)
 		}
 	}
+	if len(errs) > 0 {
+		return errs
+	}
 
-	return result, nil
+	// Invalidate even on error, as part of the batch may have been inserted
+	err := s.repo.BulkCreate(ctx, keywords)
+	tenants := make(map[string]bool)
+	for _, kw := range keywords {
+		if !tenants[kw.TenantID] {
+			tenants[kw.TenantID] = true
+			s.invalidate(kw.TenantID)
+		}
+	}
+	return err
 }
 
-// BulkImport imports multiple keywords at once
-func (s *SensitiveKeywordService) BulkImport(ctx context.Context, keywords []*model.SensitiveKeyword) error {
-	// Validate all regex patterns first
-	for _, kw := range keywords {
-		if kw.IsRegex {
-			if _, err := regexp.Compile(kw.Keyword); err != nil {
-				return err
-			}
+// validateKeyword checks a keyword and fills in the default severity and
+// action. Field names are prefixed for bulk imports.
+func validateKeyword(keyword *model.SensitiveKeyword, prefix string) error {
+	var errs validator.ValidationErrors
+
+	keyword.Keyword = strings.TrimSpace(keyword.Keyword)
+	if keyword.Keyword == "" {
+		errs = append(errs, validator.ValidationError{Field: prefix + "keyword", Message: "keyword is required"})
+	} else if keyword.IsRegex {
+		// Validate regex if isRegex is true
+		if _, err := regexp.Compile(keyword.Keyword); err != nil {
+			errs = append(errs, validator.ValidationError{Field: prefix + "keyword", Message: fmt.Sprintf("invalid pattern: %v", err)})
 		}
 	}
-	return s.repo.BulkCreate(ctx, keywords)
+
+	if keyword.Severity == "" {
+		keyword.Severity = model.KeywordSeverityMedium
+	}
+	if _, ok := severityLevels[keyword.Severity]; !ok {
+		errs = append(errs, validator.ValidationError{Field: prefix + "severity", Message: "severity must be low, medium, high or critical"})
+	}
+
+	if keyword.Action == "" {
+		keyword.Action = model.KeywordActionWarn
+	}
+	if _, ok := actionLevels[keyword.Action]; !ok {
+		errs = append(errs, validator.ValidationError{Field: prefix + "action", Message: "action must be warn, review or block"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// topSourcesLimit is how many referrers and UTM values of each kind the
+// engagement statistics list
+const topSourcesLimit = 10
+
+// topViewedLimit is how many of the most viewed articles the overview lists
+const topViewedLimit = 10
+
+// Views are counted in batches, so a period is only snapshotted once it has
+// been over for snapshotSettleTime
+const snapshotSettleTime = 15 * time.Minute
+
+// snapshotPeriods are the periods snapshotted, and how many closed periods of
+// each are looked at, so that periods missed while the service was down are
+// caught up
+var snapshotPeriods = []struct {
+	periodType string
+	catchUp    int
+}{
+	{model.PeriodDaily, 7},
+	{model.PeriodWeekly, 4},
+	{model.PeriodMonthly, 3},
+}
+
+// StatisticsService handles statistics business logic
+type StatisticsService struct {
+	repo          *repository.StatisticsRepository
+	snapshotRepo  *repository.StatisticsSnapshotRepository
+	editorialRepo *repository.EditorialRepository
+	articleRepo   *repository.ArticleRepository
+	categoryRepo  *repository.CategoryRepository
+	tenantRepo    *repository.TenantRepository
+}
+
+// NewStatisticsService creates a new statistics service
+func NewStatisticsService(
+	repo *repository.StatisticsRepository,
+	snapshotRepo *repository.StatisticsSnapshotRepository,
+	editorialRepo *repository.EditorialRepository,
+	articleRepo *repository.ArticleRepository,
+	categoryRepo *repository.CategoryRepository,
+	tenantRepo *repository.TenantRepository,
+) *StatisticsService {
+	return &StatisticsService{
+		repo:          repo,
+		snapshotRepo:  snapshotRepo,
+		editorialRepo: editorialRepo,
+		articleRepo:   articleRepo,
+		categoryRepo:  categoryRepo,
+		tenantRepo:    tenantRepo,
+	}
+}
+
+// GetOverview gets the article counts, views and most viewed articles of a
+// tenant between startDate and endDate
+func (s *StatisticsService) GetOverview(ctx context.Context, tenantID primitive.ObjectID, startDate, endDate time.Time) (*model.ArticleStatistics, error) {
+	stats, err := s.repo.GetArticleStatistics(ctx, startDate, endDate, &tenantID)
+	if err != nil {
+		return nil, err
+	}
+	stats.Period.Type = model.PeriodCustom
+
+	stats.TopViewedArticles, err = s.repo.GetTopViewedArticles(ctx, startDate, endDate, topViewedLimit, &tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// EachViewTrend calls fn with the views of a tenant's articles on each day
+// between startDate and endDate, oldest first
+func (s *StatisticsService) EachViewTrend(ctx context.Context, tenantID primitive.ObjectID, startDate, endDate time.Time, fn func(*model.ViewTrendData) error) error {
+	return s.repo.EachViewTrend(ctx, startDate, endDate, &tenantID, fn)
+}
+
+// EachArticleView calls fn with the view and engagement counts of an article
+// on each day between startDate and endDate, oldest first
+func (s *StatisticsService) EachArticleView(ctx context.Context, tenantID, articleID primitive.ObjectID, startDate, endDate time.Time, fn func(*model.ArticleView) error) error {
+	if _, err := s.articleRepo.FindByID(ctx, tenantID, articleID); err != nil {
+		return err
+	}
+	return s.repo.EachArticleView(ctx, articleID, startDate, endDate, fn)
+}
+
+// GetCategoryStatistics gets the article counts and views of a category
+func (s *StatisticsService) GetCategoryStatistics(ctx context.Context, tenantID, categoryID primitive.ObjectID, startDate, endDate time.Time) (*model.CategoryStatistics, error) {
+	if _, err := s.categoryRepo.FindByID(ctx, tenantID, categoryID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCategoryStatistics(ctx, tenantID, categoryID, startDate, endDate)
+}
+
+// GetAuthorStatistics gets the article counts and views of an author
+func (s *StatisticsService) GetAuthorStatistics(ctx context.Context, tenantID primitive.ObjectID, authorID string, startDate, endDate time.Time) (*model.AuthorStatistics, error) {
+	return s.repo.GetAuthorStatistics(ctx, tenantID, authorID, startDate, endDate)
+}
+
+// ListSnapshots lists a tenant's snapshots of a period type that start
+// between startDate and endDate
+func (s *StatisticsService) ListSnapshots(ctx context.Context, tenantID primitive.ObjectID, periodType string, startDate, endDate time.Time, page, limit int) ([]*model.StatisticsSnapshot, int64, error) {
+	return s.snapshotRepo.FindAll(ctx, tenantID, periodType, startDate, endDate, page, limit)
+}
+
+// GetSnapshot gets a tenant's snapshot
+func (s *StatisticsService) GetSnapshot(ctx context.Context, tenantID, id primitive.ObjectID) (*model.StatisticsSnapshot, error) {
+	return s.snapshotRepo.FindByID(ctx, tenantID, id)
+}
+
+// CreateSnapshots snapshots the statistics of every active tenant for the
+// days, weeks and months that have closed and have no snapshot yet. Existing
+// snapshots are never recomputed.
+func (s *StatisticsService) CreateSnapshots(ctx context.Context, now time.Time) (int, error) {
+	tenants, err := s.tenantRepo.FindActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	var errs []error
+	for _, tenant := range tenants {
+		for _, period := range snapshotPeriods {
+			current, _ := PeriodBounds(period.periodType, now.Add(-snapshotSettleTime))
+			for i := 0; i < period.catchUp; i++ {
+				start, end := PeriodBounds(period.periodType, current.AddDate(0, 0, -1))
+				current = start
+				if end.Before(tenant.CreatedAt) {
+					break
+				}
+
+				ok, err := s.createSnapshot(ctx, tenant.ID, period.periodType, start, end)
+				if err != nil {
+					errs = append(errs, err)
+				} else if ok {
+					created++
+				}
+			}
+		}
+	}
+	return created, errors.Join(errs...)
+}
+
+// createSnapshot snapshots the period from start up to end unless it already
+// has a snapshot, and reports whether it made one
+func (s *StatisticsService) createSnapshot(ctx context.Context, tenantID primitive.ObjectID, periodType string, start, end time.Time) (bool, error) {
+	exists, err := s.snapshotRepo.Exists(ctx, tenantID, periodType, start)
+	if err != nil || exists {
+		return false, err
+	}
+
+	last := end.Add(-time.Millisecond)
+	stats, err := s.GetOverview(ctx, tenantID, start, last)
+	if err != nil {
+		return false, err
+	}
+	stats.Period = model.StatisticsPeriod{StartDate: start, EndDate: last, Type: periodType}
+
+	trend, err := s.repo.GetViewTrend(ctx, start, last, &tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	err = s.snapshotRepo.Create(ctx, &model.StatisticsSnapshot{
+		TenantID:   tenantID,
+		Period:     stats.Period,
+		Statistics: stats,
+		ViewTrend:  trend,
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		// Another instance made it first
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PeriodBounds returns the start of the day, week (from Monday) or month
+// that contains t, and the start of the one after
+func PeriodBounds(periodType string, t time.Time) (time.Time, time.Time) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch periodType {
+	case model.PeriodWeekly:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7)
+	case model.PeriodMonthly:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 1, 0)
+	default:
+		return day, day.AddDate(0, 0, 1)
+	}
+}
+
+// GetArticleEngagement gets the engagement statistics of an article
+func (s *StatisticsService) GetArticleEngagement(ctx context.Context, tenantID, articleID primitive.ObjectID, startDate, endDate time.Time) (*model.EngagementStatistics, error) {
+	if _, err := s.articleRepo.FindByID(ctx, tenantID, articleID); err != nil {
+		return nil, err
+	}
+	return s.engagement(ctx, "article", articleID.Hex(), []primitive.ObjectID{articleID}, startDate, endDate)
+}
+
+// GetCategoryEngagement gets the engagement statistics of the articles in a
+// category
+func (s *StatisticsService) GetCategoryEngagement(ctx context.Context, tenantID, categoryID primitive.ObjectID, startDate, endDate time.Time) (*model.EngagementStatistics, error) {
+	if _, err := s.categoryRepo.FindByID(ctx, tenantID, categoryID); err != nil {
+		return nil, err
+	}
+	articleIDs, err := s.repo.FindArticleIDsByCategory(ctx, tenantID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	return s.engagement(ctx, "category", categoryID.Hex(), articleIDs, startDate, endDate)
+}
+
+// GetAuthorEngagement gets the engagement statistics of the articles by an
+// author
+func (s *StatisticsService) GetAuthorEngagement(ctx context.Context, tenantID primitive.ObjectID, authorID string, startDate, endDate time.Time) (*model.EngagementStatistics, error) {
+	articleIDs, err := s.repo.FindArticleIDsByAuthor(ctx, tenantID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	return s.engagement(ctx, "author", authorID, articleIDs, startDate, endDate)
+}
+
+// engagement sums the engagement of articles and adds their top traffic sources
+func (s *StatisticsService) engagement(ctx context.Context, scope, id string, articleIDs []primitive.ObjectID, startDate, endDate time.Time) (*model.EngagementStatistics, error) {
+	stats, err := s.repo.GetEngagementStatistics(ctx, articleIDs, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	stats.Scope = scope
+	stats.ID = id
+
+	for _, top := range []struct {
+		sourceType model.TrafficSourceType
+		target     *[]*model.SourceCount
+	}{
+		{model.TrafficReferrer, &stats.TopReferrers},
+		{model.TrafficUTMSource, &stats.TopUTMSources},
+		{model.TrafficUTMMedium, &stats.TopUTMMediums},
+		{model.TrafficUTMCampaign, &stats.TopUTMCampaigns},
+	} {
+		sources, err := s.repo.GetTopTrafficSources(ctx, articleIDs, top.sourceType, startDate, endDate, topSourcesLimit)
+		if err != nil {
+			return nil, err
+		}
+		*top.target = sources
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TenantArticleTypeConfigService handles tenant article type configuration business logic
+type TenantArticleTypeConfigService struct {
+	repo *repository.TenantArticleTypeConfigRepository
+}
+
+// NewTenantArticleTypeConfigService creates a new tenant article type config service
+func NewTenantArticleTypeConfigService(repo *repository.TenantArticleTypeConfigRepository) *TenantArticleTypeConfigService {
+	return &TenantArticleTypeConfigService{
+		repo: repo,
+	}
+}
+
+// Create creates the article type configuration of a tenant
+func (s *TenantArticleTypeConfigService) Create(ctx context.Context, tenantID primitive.ObjectID, config *model.TenantArticleTypeConfig, userID string) error {
+	if err := validator.NewArticleValidator().ValidateTypeConfig(config); err != nil {
+		return err
+	}
+
+	config.TenantID = tenantID
+	config.CreatedBy = userID
+	return s.repo.Create(ctx, config)
+}
+
+// Get gets the article type configuration of a tenant
+func (s *TenantArticleTypeConfigService) Get(ctx context.Context, tenantID primitive.ObjectID) (*model.TenantArticleTypeConfig, error) {
+	return s.repo.FindByTenantID(ctx, tenantID)
+}
+
+// Update updates the article type configuration of a tenant
+func (s *TenantArticleTypeConfigService) Update(ctx context.Context, tenantID primitive.ObjectID, config *model.TenantArticleTypeConfig) error {
+	if err := validator.NewArticleValidator().ValidateTypeConfig(config); err != nil {
+		return err
+	}
+
+	config.TenantID = tenantID
+	return s.repo.Update(ctx, config)
+}
+
+// Delete deletes the article type configuration of a tenant, allowing all types again
+func (s *TenantArticleTypeConfigService) Delete(ctx context.Context, tenantID primitive.ObjectID) error {
+	return s.repo.Delete(ctx, tenantID)
+}
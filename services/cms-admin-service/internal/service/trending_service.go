@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Weights of the trending signals, relative to one view
+const (
+	trendingViewWeight     = 1.0
+	trendingCommentWeight  = 5.0
+	trendingLikeWeight     = 2.0
+	trendingFavoriteWeight = 8.0
+)
+
+// TrendingConfig configures the trending score
+type TrendingConfig struct {
+	Window   time.Duration // How far back signals are counted
+	HalfLife time.Duration // Age at which a signal counts half
+	HotTopN  int           // Articles per tenant marked hot; 0 leaves the hot flag to editors
+}
+
+// TrendingService computes the trending score of articles: the views,
+// approved comments, comment likes and favourites of the last Window, each
+// weighted and halved for every HalfLife of age
+type TrendingService struct {
+	repo   *repository.TrendingRepository
+	config TrendingConfig
+}
+
+// NewTrendingService creates a new trending service
+func NewTrendingService(repo *repository.TrendingRepository, config TrendingConfig) *TrendingService {
+	return &TrendingService{
+		repo:   repo,
+		config: config,
+	}
+}
+
+// UpdateScores recomputes and stores the trending score of every article with
+// signals in the window, resets the others, and updates the hot flags if
+// HotTopN is set
+func (s *TrendingService) UpdateScores(ctx context.Context) error {
+	now := time.Now()
+	since := now.Add(-s.config.Window)
+
+	scores := make(map[primitive.ObjectID]float64)
+	for _, signal := range []struct {
+		weight float64
+		sums   func(ctx context.Context, since, now time.Time, halfLife time.Duration) (map[primitive.ObjectID]float64, error)
+	}{
+		{trendingViewWeight, s.repo.DecayedViews},
+		{trendingCommentWeight, s.repo.DecayedComments},
+		{trendingLikeWeight, s.repo.DecayedCommentLikes},
+		{trendingFavoriteWeight, s.repo.DecayedFavorites},
+	} {
+		sums, err := signal.sums(ctx, since, now, s.config.HalfLife)
+		if err != nil {
+			return err
+		}
+		for id, sum := range sums {
+			scores[id] += signal.weight * sum
+		}
+	}
+
+	if err := s.repo.SaveScores(ctx, scores); err != nil {
+		return err
+	}
+	if s.config.HotTopN > 0 {
+		return s.repo.UpdateHot(ctx, s.config.HotTopN)
+	}
+	return nil
+}
@@ -0,0 +1,553 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// versionField is an article field that versions are compared, restored and
+// merged by. Content, contentBlocks, tags, seo and customFields have their
+// own structured diff and merge; the rest are compared as whole values.
+type versionField struct {
+	name string
+	get  func(a *model.Article) interface{}
+	set  func(dst, src *model.Article)
+}
+
+var versionFields = []versionField{
+	{"title", func(a *model.Article) interface{} { return a.Title }, func(d, s *model.Article) { d.Title = s.Title }},
+	{"subtitle", func(a *model.Article) interface{} { return a.Subtitle }, func(d, s *model.Article) { d.Subtitle = s.Subtitle }},
+	{"slug", func(a *model.Article) interface{} { return a.Slug }, func(d, s *model.Article) { d.Slug = s.Slug }},
+	{"articleType", func(a *model.Article) interface{} { return a.ArticleType }, func(d, s *model.Article) { d.ArticleType = s.ArticleType }},
+	{"categoryId", func(a *model.Article) interface{} { return a.CategoryID }, func(d, s *model.Article) { d.CategoryID = s.CategoryID }},
+	{"eventStreamId", func(a *model.Article) interface{} { return a.EventStreamID }, func(d, s *model.Article) { d.EventStreamID = s.EventStreamID }},
+	{"summary", func(a *model.Article) interface{} { return a.Summary }, func(d, s *model.Article) { d.Summary = s.Summary }},
+	{"content", func(a *model.Article) interface{} { return a.Content }, func(d, s *model.Article) { d.Content = s.Content }},
+	{"contentBlocks", func(a *model.Article) interface{} { return a.ContentBlocks }, func(d, s *model.Article) { d.ContentBlocks = s.ContentBlocks }},
+	{"author", func(a *model.Article) interface{} { return a.Author }, func(d, s *model.Article) { d.Author = s.Author }},
+	{"contributors", func(a *model.Article) interface{} { return a.Contributors }, func(d, s *model.Article) { d.Contributors = s.Contributors }},
+	{"source", func(a *model.Article) interface{} { return a.Source }, func(d, s *model.Article) { d.Source = s.Source }},
+	{"tags", func(a *model.Article) interface{} { return a.Tags }, func(d, s *model.Article) { d.Tags = s.Tags }},
+	{"seo", func(a *model.Article) interface{} { return a.SEO }, func(d, s *model.Article) { d.SEO = s.SEO }},
+	{"customFields", func(a *model.Article) interface{} { return a.CustomFields }, func(d, s *model.Article) { d.CustomFields = s.CustomFields }},
+	{"attachments", func(a *model.Article) interface{} { return a.Attachments }, func(d, s *model.Article) { d.Attachments = s.Attachments }},
+	{"relatedArticles", func(a *model.Article) interface{} { return a.RelatedArticles }, func(d, s *model.Article) { d.RelatedArticles = s.RelatedArticles }},
+	{"featured", func(a *model.Article) interface{} { return a.Featured }, func(d, s *model.Article) { d.Featured = s.Featured }},
+	{"hot", func(a *model.Article) interface{} { return a.Hot }, func(d, s *model.Article) { d.Hot = s.Hot }},
+	{"publishAt", func(a *model.Article) interface{} { return storedTime(&a.PublishAt) }, func(d, s *model.Article) { d.PublishAt = s.PublishAt }},
+	{"expiredAt", func(a *model.Article) interface{} { return storedTime(a.ExpiredAt) }, func(d, s *model.Article) { d.ExpiredAt = s.ExpiredAt }},
+	{"isCommentable", func(a *model.Article) interface{} { return a.IsCommentable }, func(d, s *model.Article) { d.IsCommentable = s.IsCommentable }},
+	{"commentConfig", func(a *model.Article) interface{} { return a.CommentConfig }, func(d, s *model.Article) { d.CommentConfig = s.CommentConfig }},
+	{"accessControl", func(a *model.Article) interface{} { return a.AccessControl }, func(d, s *model.Article) { d.AccessControl = s.AccessControl }},
+	{"videoUrl", func(a *model.Article) interface{} { return a.VideoURL }, func(d, s *model.Article) { d.VideoURL = s.VideoURL }},
+	{"duration", func(a *model.Article) interface{} { return a.Duration }, func(d, s *model.Article) { d.Duration = s.Duration }},
+	{"thumbnail", func(a *model.Article) interface{} { return a.Thumbnail }, func(d, s *model.Article) { d.Thumbnail = s.Thumbnail }},
+	{"images", func(a *model.Article) interface{} { return a.Images }, func(d, s *model.Article) { d.Images = s.Images }},
+	{"galleryLayout", func(a *model.Article) interface{} { return a.GalleryLayout }, func(d, s *model.Article) { d.GalleryLayout = s.GalleryLayout }},
+	{"issuedDate", func(a *model.Article) interface{} { return storedTime(a.IssuedDate) }, func(d, s *model.Article) { d.IssuedDate = s.IssuedDate }},
+	{"lawNumber", func(a *model.Article) interface{} { return a.LawNumber }, func(d, s *model.Article) { d.LawNumber = s.LawNumber }},
+	{"effectiveDate", func(a *model.Article) interface{} { return storedTime(a.EffectiveDate) }, func(d, s *model.Article) { d.EffectiveDate = s.EffectiveDate }},
+	{"pdfAttachment", func(a *model.Article) interface{} { return a.PDFAttachment }, func(d, s *model.Article) { d.PDFAttachment = s.PDFAttachment }},
+	{"audioUrl", func(a *model.Article) interface{} { return a.AudioURL }, func(d, s *model.Article) { d.AudioURL = s.AudioURL }},
+	{"episodeNumber", func(a *model.Article) interface{} { return a.EpisodeNumber }, func(d, s *model.Article) { d.EpisodeNumber = s.EpisodeNumber }},
+	{"fileUrl", func(a *model.Article) interface{} { return a.FileURL }, func(d, s *model.Article) { d.FileURL = s.FileURL }},
+	{"fileSize", func(a *model.Article) interface{} { return a.FileSize }, func(d, s *model.Article) { d.FileSize = s.FileSize }},
+	{"mimeType", func(a *model.Article) interface{} { return a.MimeType }, func(d, s *model.Article) { d.MimeType = s.MimeType }},
+	{"eventStart", func(a *model.Article) interface{} { return storedTime(a.EventStart) }, func(d, s *model.Article) { d.EventStart = s.EventStart }},
+	{"eventEnd", func(a *model.Article) interface{} { return storedTime(a.EventEnd) }, func(d, s *model.Article) { d.EventEnd = s.EventEnd }},
+	{"venue", func(a *model.Article) interface{} { return a.Venue }, func(d, s *model.Article) { d.Venue = s.Venue }},
+	{"organizer", func(a *model.Article) interface{} { return a.Organizer }, func(d, s *model.Article) { d.Organizer = s.Organizer }},
+}
+
+// seoField is a field of the SEO metadata other than the keywords
+type seoField struct {
+	name string
+	get  func(s *model.SEO) interface{}
+	set  func(dst, src *model.SEO)
+}
+
+var seoFields = []seoField{
+	{"seo.title", func(s *model.SEO) interface{} { return s.Title }, func(d, s *model.SEO) { d.Title = s.Title }},
+	{"seo.description", func(s *model.SEO) interface{} { return s.Description }, func(d, s *model.SEO) { d.Description = s.Description }},
+	{"seo.canonical", func(s *model.SEO) interface{} { return s.Canonical }, func(d, s *model.SEO) { d.Canonical = s.Canonical }},
+	{"seo.noIndex", func(s *model.SEO) interface{} { return s.NoIndex }, func(d, s *model.SEO) { d.NoIndex = s.NoIndex }},
+}
+
+// DiffArticles compares two versions of an article field by field. Tags, SEO
+// keywords and custom fields are diffed as sets and maps, content blocks
+// block by block and the content word by word without splitting HTML tags.
+func DiffArticles(from, to *model.Article) []model.FieldChange {
+	changes := []model.FieldChange{}
+	for _, f := range versionFields {
+		switch f.name {
+		case "content":
+			if from.Content != to.Content {
+				change := valueChange(f.name, from.Content, to.Content)
+				change.Old, change.New = nil, nil
+				change.Segments = textSegments(from.Content, to.Content)
+				changes = append(changes, change)
+			}
+		case "contentBlocks":
+			if blocks := diffBlocks(from.ContentBlocks, to.ContentBlocks); len(blocks) > 0 {
+				changes = append(changes, model.FieldChange{Field: f.name, Type: model.ChangeModified, Blocks: blocks})
+			}
+		case "tags":
+			changes = appendSetChange(changes, f.name, from.Tags, to.Tags)
+		case "seo":
+			for _, sf := range seoFields {
+				if old, updated := sf.get(&from.SEO), sf.get(&to.SEO); !sameValue(old, updated) {
+					changes = append(changes, valueChange(sf.name, old, updated))
+				}
+			}
+			changes = appendSetChange(changes, "seo.keywords", from.SEO.Keywords, to.SEO.Keywords)
+		case "customFields":
+			changes = appendMapChanges(changes, f.name, from.CustomFields, to.CustomFields)
+		default:
+			if old, updated := f.get(from), f.get(to); !sameValue(old, updated) {
+				changes = append(changes, valueChange(f.name, old, updated))
+			}
+		}
+	}
+	return changes
+}
+
+// MergeArticles applies the changes edited made to base onto current, the
+// article as it is now. A field someone else changed in the meantime is
+// merged when the changes do not overlap: tags and keywords as sets, custom
+// fields key by key and the content word by word. Overlapping changes are
+// returned as conflicts and keep the current value.
+func MergeArticles(base, current, edited *model.Article) (*model.Article, []model.FieldConflict) {
+	merged := *current
+	var conflicts []model.FieldConflict
+
+	for _, f := range versionFields {
+		switch f.name {
+		case "content":
+			if edited.Content == base.Content || edited.Content == current.Content {
+				continue
+			}
+			tokens, ok := util.Merge3(util.TokenizeHTML(base.Content), util.TokenizeHTML(current.Content), util.TokenizeHTML(edited.Content))
+			if !ok {
+				conflicts = append(conflicts, model.FieldConflict{Field: f.name, Base: base.Content, Current: current.Content, Edited: edited.Content})
+				continue
+			}
+			merged.Content = strings.Join(tokens, "")
+		case "tags":
+			merged.Tags = mergeSet(base.Tags, current.Tags, edited.Tags)
+		case "seo":
+			for _, sf := range seoFields {
+				takeEdited, conflict := mergeValue(sf.name, sf.get(&base.SEO), sf.get(&current.SEO), sf.get(&edited.SEO))
+				switch {
+				case conflict != nil:
+					conflicts = append(conflicts, *conflict)
+				case takeEdited:
+					sf.set(&merged.SEO, &edited.SEO)
+				}
+			}
+			merged.SEO.Keywords = mergeSet(base.SEO.Keywords, current.SEO.Keywords, edited.SEO.Keywords)
+		case "customFields":
+			var fieldConflicts []model.FieldConflict
+			merged.CustomFields, fieldConflicts = mergeMap(f.name, base.CustomFields, current.CustomFields, edited.CustomFields)
+			conflicts = append(conflicts, fieldConflicts...)
+		default:
+			takeEdited, conflict := mergeValue(f.name, f.get(base), f.get(current), f.get(edited))
+			switch {
+			case conflict != nil:
+				conflicts = append(conflicts, *conflict)
+			case takeEdited:
+				f.set(&merged, edited)
+			}
+		}
+	}
+
+	return &merged, conflicts
+}
+
+// checkVersionFields checks that fields are restorable field names
+func checkVersionFields(fields []string) error {
+	var errs validator.ValidationErrors
+	for _, name := range fields {
+		if findVersionField(name) == nil {
+			errs = append(errs, validator.ValidationError{Field: "fields", Message: fmt.Sprintf("unknown field: %s", name)})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// copyVersionFields copies the named fields from src to dst
+func copyVersionFields(dst, src *model.Article, fields []string) {
+	for _, name := range fields {
+		if f := findVersionField(name); f != nil {
+			f.set(dst, src)
+		}
+	}
+}
+
+// restoreFields lists the fields restored from a whole version: those recorded
+// by versions without a full snapshot, or else every field except the hot
+// flag, which belongs to the trending worker
+func restoreFields(version *model.ArticleVersion) []string {
+	if version.FullSnapshot == nil {
+		return []string{"title", "subtitle", "slug", "articleType", "categoryId", "summary", "content", "author", "tags", "seo"}
+	}
+	fields := make([]string, 0, len(versionFields))
+	for _, f := range versionFields {
+		if f.name != "hot" {
+			fields = append(fields, f.name)
+		}
+	}
+	return fields
+}
+
+func findVersionField(name string) *versionField {
+	for i := range versionFields {
+		if versionFields[i].name == name {
+			return &versionFields[i]
+		}
+	}
+	return nil
+}
+
+// versionSnapshot returns the article as it was at a version. Versions saved
+// before full snapshots existed only have the basic fields.
+func versionSnapshot(version *model.ArticleVersion) *model.Article {
+	if version.FullSnapshot != nil {
+		return version.FullSnapshot
+	}
+	return &model.Article{
+		ID:             version.ArticleID,
+		Title:          version.Title,
+		Subtitle:       version.Subtitle,
+		Slug:           version.Slug,
+		ArticleType:    version.ArticleType,
+		CategoryID:     version.CategoryID,
+		Summary:        version.Summary,
+		Content:        version.Content,
+		Author:         version.Author,
+		Tags:           version.Tags,
+		SEO:            version.SEO,
+		Status:         version.Status,
+		CurrentVersion: version.VersionNum,
+	}
+}
+
+// valueChange describes a change of a whole value
+func valueChange(field string, old, updated interface{}) model.FieldChange {
+	change := model.FieldChange{Field: field, Type: model.ChangeModified, Old: old, New: updated}
+	switch {
+	case isEmptyValue(old):
+		change.Type = model.ChangeAdded
+	case isEmptyValue(updated):
+		change.Type = model.ChangeRemoved
+	}
+	return change
+}
+
+// textSegments diffs two HTML texts word by word
+func textSegments(old, updated string) []model.TextSegment {
+	a, b := util.TokenizeHTML(old), util.TokenizeHTML(updated)
+	var segments []model.TextSegment
+	for _, op := range util.Diff(a, b) {
+		switch op.Kind {
+		case util.DiffEqual:
+			segments = append(segments, model.TextSegment{Op: "equal", Text: strings.Join(a[op.AStart:op.AEnd], "")})
+		case util.DiffDelete:
+			segments = append(segments, model.TextSegment{Op: "delete", Text: strings.Join(a[op.AStart:op.AEnd], "")})
+		case util.DiffInsert:
+			segments = append(segments, model.TextSegment{Op: "insert", Text: strings.Join(b[op.BStart:op.BEnd], "")})
+		}
+	}
+	return segments
+}
+
+// diffBlocks matches unchanged blocks and pairs the rest up in order; a
+// removed block followed by an added block of the same type is reported as
+// a modification
+func diffBlocks(old, updated []model.ContentBlock) []model.BlockChange {
+	keys := func(blocks []model.ContentBlock) []string {
+		out := make([]string, len(blocks))
+		for i, block := range blocks {
+			out[i] = canonicalJSON(block)
+		}
+		return out
+	}
+
+	var changes []model.BlockChange
+	var deleted *util.DiffOp
+	flushDeleted := func(inserted *util.DiffOp) {
+		i, iEnd, j, jEnd := 0, 0, 0, 0
+		if deleted != nil {
+			i, iEnd = deleted.AStart, deleted.AEnd
+		}
+		if inserted != nil {
+			j, jEnd = inserted.BStart, inserted.BEnd
+		}
+		for ; i < iEnd && j < jEnd && old[i].Type == updated[j].Type; i, j = i+1, j+1 {
+			changes = append(changes, model.BlockChange{
+				Type:     model.ChangeModified,
+				OldIndex: intPtr(i),
+				NewIndex: intPtr(j),
+				Old:      &old[i],
+				New:      &updated[j],
+				Segments: textSegments(old[i].Content, updated[j].Content),
+			})
+		}
+		for ; i < iEnd; i++ {
+			changes = append(changes, model.BlockChange{Type: model.ChangeRemoved, OldIndex: intPtr(i), Old: &old[i]})
+		}
+		for ; j < jEnd; j++ {
+			changes = append(changes, model.BlockChange{Type: model.ChangeAdded, NewIndex: intPtr(j), New: &updated[j]})
+		}
+		deleted = nil
+	}
+
+	ops := util.Diff(keys(old), keys(updated))
+	for i := range ops {
+		switch ops[i].Kind {
+		case util.DiffDelete:
+			deleted = &ops[i]
+		case util.DiffInsert:
+			flushDeleted(&ops[i])
+		default:
+			if deleted != nil {
+				flushDeleted(nil)
+			}
+		}
+	}
+	if deleted != nil {
+		flushDeleted(nil)
+	}
+	return changes
+}
+
+// appendSetChange reports the strings added to and removed from a list
+func appendSetChange(changes []model.FieldChange, field string, old, updated []string) []model.FieldChange {
+	added, removed := setDifference(updated, old), setDifference(old, updated)
+	switch {
+	case len(added) > 0 || len(removed) > 0:
+		change := model.FieldChange{Field: field, Type: model.ChangeModified, Added: added, Removed: removed}
+		switch {
+		case len(old) == 0:
+			change.Type = model.ChangeAdded
+		case len(updated) == 0:
+			change.Type = model.ChangeRemoved
+		}
+		return append(changes, change)
+	case !sameValue(old, updated):
+		// Same strings in a different order
+		return append(changes, model.FieldChange{Field: field, Type: model.ChangeModified, Old: old, New: updated})
+	}
+	return changes
+}
+
+// appendMapChanges reports changed keys of a map, descending into nested maps
+func appendMapChanges(changes []model.FieldChange, prefix string, old, updated map[string]interface{}) []model.FieldChange {
+	for _, key := range unionKeys(old, updated) {
+		field := prefix + "." + key
+		oldValue, inOld := old[key]
+		newValue, inNew := updated[key]
+		oldMap, oldIsMap := asMap(oldValue)
+		newMap, newIsMap := asMap(newValue)
+		switch {
+		case oldIsMap && newIsMap:
+			changes = appendMapChanges(changes, field, oldMap, newMap)
+		case !inOld:
+			changes = append(changes, model.FieldChange{Field: field, Type: model.ChangeAdded, New: newValue})
+		case !inNew:
+			changes = append(changes, model.FieldChange{Field: field, Type: model.ChangeRemoved, Old: oldValue})
+		case !sameValue(oldValue, newValue):
+			changes = append(changes, model.FieldChange{Field: field, Type: model.ChangeModified, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
+
+// mergeValue decides a whole-value field: the edited value wins when only
+// the editor changed it, the current value when only someone else did
+func mergeValue(field string, base, current, edited interface{}) (bool, *model.FieldConflict) {
+	switch {
+	case sameValue(edited, base), sameValue(edited, current):
+		return false, nil
+	case sameValue(current, base):
+		return true, nil
+	}
+	return false, &model.FieldConflict{Field: field, Base: base, Current: current, Edited: edited}
+}
+
+// mergeSet applies the strings the editor added and removed to current
+func mergeSet(base, current, edited []string) []string {
+	removed := toSet(setDifference(base, edited))
+	added := setDifference(edited, base)
+	if len(removed) == 0 && len(added) == 0 {
+		return current
+	}
+
+	var merged []string
+	for _, s := range current {
+		if !removed[s] {
+			merged = append(merged, s)
+		}
+	}
+	inMerged := toSet(merged)
+	for _, s := range added {
+		if !inMerged[s] {
+			merged = append(merged, s)
+			inMerged[s] = true
+		}
+	}
+	return merged
+}
+
+// mergeMap merges the keys of a map one by one
+func mergeMap(prefix string, base, current, edited map[string]interface{}) (map[string]interface{}, []model.FieldConflict) {
+	var merged map[string]interface{}
+	if current != nil {
+		merged = make(map[string]interface{}, len(current))
+		for key, value := range current {
+			merged[key] = value
+		}
+	}
+
+	var conflicts []model.FieldConflict
+	for _, key := range unionKeys(base, edited) {
+		baseValue, inBase := base[key]
+		editedValue, inEdited := edited[key]
+		currentValue, inCurrent := current[key]
+
+		switch {
+		case inEdited == inBase && sameValue(editedValue, baseValue):
+			continue
+		case inEdited == inCurrent && sameValue(editedValue, currentValue):
+			continue
+		case inCurrent == inBase && sameValue(currentValue, baseValue):
+			if !inEdited {
+				delete(merged, key)
+				continue
+			}
+			if merged == nil {
+				merged = make(map[string]interface{})
+			}
+			merged[key] = editedValue
+		default:
+			conflicts = append(conflicts, model.FieldConflict{
+				Field:   prefix + "." + key,
+				Base:    baseValue,
+				Current: currentValue,
+				Edited:  editedValue,
+			})
+		}
+	}
+	return merged, conflicts
+}
+
+// sameValue compares values by their JSON form, so numbers decoded from
+// BSON and from JSON compare equal, and nil equals an empty list or map
+func sameValue(a, b interface{}) bool {
+	return canonicalJSON(a) == canonicalJSON(b)
+}
+
+func canonicalJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+	switch s := string(data); s {
+	case "null", "[]", "{}":
+		return ""
+	default:
+		return s
+	}
+}
+
+func isEmptyValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	if id, ok := v.(primitive.ObjectID); ok {
+		return id.IsZero()
+	}
+	return false
+}
+
+// storedTime returns a time as MongoDB stores it, to millisecond precision,
+// so a time read back from the database equals the one that was saved
+func storedTime(t *time.Time) interface{} {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t.UTC().Truncate(time.Millisecond)
+}
+
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case primitive.M:
+		return m, true
+	case primitive.D:
+		out := make(map[string]interface{}, len(m))
+		for _, e := range m {
+			out[e.Key] = e.Value
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+func unionKeys(maps ...map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setDifference returns the strings of a that are not in b, in order
+func setDifference(a, b []string) []string {
+	inB := toSet(b)
+	var out []string
+	for _, s := range a {
+		if !inB[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WorkflowService handles workflow definitions and transition rules
+type WorkflowService struct {
+	repo         *repository.WorkflowRepository
+	categoryRepo *repository.CategoryRepository
+}
+
+// NewWorkflowService creates a new workflow service
+func NewWorkflowService(repo *repository.WorkflowRepository, categoryRepo *repository.CategoryRepository) *WorkflowService {
+	return &WorkflowService{
+		repo:         repo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// DefaultWorkflow returns the workflow used when a tenant has not defined one.
+// It mirrors the original fixed rules: authors submit, editors and moderators
+// publish, reject and archive, and reviewed articles are only editable by them.
+func DefaultWorkflow() *model.Workflow {
+	reviewers := []model.Role{model.RoleEditor, model.RoleModerator}
+
+	return &model.Workflow{
+		Name:         "Default",
+		InitialState: model.ArticleStatusDraft,
+		States: []model.WorkflowState{
+			{Name: model.ArticleStatusDraft, Label: "Draft"},
+			{Name: model.ArticleStatusPendingReview, Label: "Pending review"},
+			{Name: model.ArticleStatusPublished, Label: "Published", EditableBy: reviewers},
+			{Name: model.ArticleStatusArchived, Label: "Archived", EditableBy: reviewers},
+		},
+		Transitions: []model.WorkflowTransition{
+			{
+				Name:         "submit",
+				Label:        "Submit for review",
+				From:         []model.ArticleStatus{model.ArticleStatusDraft},
+				To:           model.ArticleStatusPendingReview,
+				AllowedRoles: reviewers,
+				AllowAuthor:  true,
+			},
+			{
+				Name:         "withdraw",
+				Label:        "Withdraw",
+				From:         []model.ArticleStatus{model.ArticleStatusPendingReview},
+				To:           model.ArticleStatusDraft,
+				AllowedRoles: reviewers,
+				AllowAuthor:  true,
+			},
+			{
+				Name:         "publish",
+				Label:        "Publish",
+				From:         []model.ArticleStatus{model.ArticleStatusDraft, model.ArticleStatusPendingReview, model.ArticleStatusArchived},
+				To:           model.ArticleStatusPublished,
+				AllowedRoles: reviewers,
+			},
+			{
+				Name:         "reject",
+				Label:        "Reject",
+				From:         []model.ArticleStatus{model.ArticleStatusPendingReview, model.ArticleStatusPublished},
+				To:           model.ArticleStatusDraft,
+				AllowedRoles: reviewers,
+				IsRejection:  true,
+				RequireNote:  true,
+			},
+			{
+				Name:         "archive",
+				Label:        "Archive",
+				From:         []model.ArticleStatus{model.ArticleStatusPublished},
+				To:           model.ArticleStatusArchived,
+				AllowedRoles: reviewers,
+			},
+		},
+	}
+}
+
+// Create creates a workflow for a tenant or one of its categories
+func (s *WorkflowService) Create(ctx context.Context, tenantID primitive.ObjectID, workflow *model.Workflow, userID string) error {
+	workflow.TenantID = tenantID
+	if err := s.validate(ctx, workflow); err != nil {
+		return err
+	}
+
+	workflow.CreatedBy = userID
+	return s.repo.Create(ctx, workflow)
+}
+
+// FindByID finds a workflow by ID
+func (s *WorkflowService) FindByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.Workflow, error) {
+	return s.repo.FindByID(ctx, tenantID, id)
+}
+
+// FindAll lists the workflows of a tenant
+func (s *WorkflowService) FindAll(ctx context.Context, tenantID primitive.ObjectID) ([]*model.Workflow, error) {
+	return s.repo.FindAll(ctx, tenantID)
+}
+
+// Update updates a workflow. Articles already in a state that no longer
+// exists can only leave it through transitions of the new definition.
+func (s *WorkflowService) Update(ctx context.Context, tenantID primitive.ObjectID, workflow *model.Workflow) error {
+	workflow.TenantID = tenantID
+	if err := s.validate(ctx, workflow); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, workflow)
+}
+
+// Delete deletes a workflow; its scope falls back to the next broader workflow
+func (s *WorkflowService) Delete(ctx context.Context, tenantID, id primitive.ObjectID) error {
+	return s.repo.Delete(ctx, tenantID, id)
+}
+
+// Resolve returns the workflow governing a category: the category's own
+// workflow, else the tenant default, else DefaultWorkflow
+func (s *WorkflowService) Resolve(ctx context.Context, tenantID, categoryID primitive.ObjectID) (*model.Workflow, error) {
+	if !categoryID.IsZero() {
+		workflow, err := s.repo.FindByCategory(ctx, tenantID, &categoryID)
+		if err == nil {
+			return workflow, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	workflow, err := s.repo.FindByCategory(ctx, tenantID, nil)
+	if err == nil {
+		return workflow, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	return DefaultWorkflow(), nil
+}
+
+func (s *WorkflowService) validate(ctx context.Context, workflow *model.Workflow) error {
+	if err := validator.NewWorkflowValidator().Validate(workflow); err != nil {
+		return err
+	}
+
+	if workflow.CategoryID != nil && s.categoryRepo != nil {
+		if _, err := s.categoryRepo.FindByID(ctx, workflow.TenantID, *workflow.CategoryID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindTransition finds a transition by name
+func FindTransition(workflow *model.Workflow, name string) *model.WorkflowTransition {
+	for i := range workflow.Transitions {
+		if workflow.Transitions[i].Name == name {
+			return &workflow.Transitions[i]
+		}
+	}
+	return nil
+}
+
+// CanPerform reports whether an actor may perform a transition on an article
+// in its current state
+func CanPerform(transition *model.WorkflowTransition, article *model.Article, actor model.Actor) bool {
+	if !containsStatus(transition.From, article.Status) {
+		return false
+	}
+	return isAllowed(transition, article, actor)
+}
+
+// AvailableTransitions lists the transitions an actor may perform on an article
+func AvailableTransitions(workflow *model.Workflow, article *model.Article, actor model.Actor) []model.WorkflowTransition {
+	available := []model.WorkflowTransition{}
+	for i := range workflow.Transitions {
+		if CanPerform(&workflow.Transitions[i], article, actor) {
+			available = append(available, workflow.Transitions[i])
+		}
+	}
+	return available
+}
+
+// CanEdit reports whether a role may edit article content in a state
+func CanEdit(workflow *model.Workflow, status model.ArticleStatus, role model.Role) bool {
+	for _, state := range workflow.States {
+		if state.Name != status {
+			continue
+		}
+		if len(state.EditableBy) == 0 {
+			return true
+		}
+		for _, r := range state.EditableBy {
+			if r == role {
+				return true
+			}
+		}
+		return false
+	}
+	// States unknown to the workflow are not restricted
+	return true
+}
+
+func isAllowed(transition *model.WorkflowTransition, article *model.Article, actor model.Actor) bool {
+	if transition.AllowAuthor && actor.UserID != "" && article.CreatedBy == actor.UserID {
+		return true
+	}
+	for _, role := range transition.AllowedRoles {
+		if role == actor.Role {
+			return true
+		}
+	}
+	for _, group := range transition.AllowedGroups {
+		for _, g := range actor.GroupIDs {
+			if g == group {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsStatus(statuses []model.ArticleStatus, status model.ArticleStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
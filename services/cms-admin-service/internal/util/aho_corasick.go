@@ -0,0 +1,134 @@
+package util
+
+import "sort"
+
+// AhoCorasick finds all occurrences of a fixed set of patterns in one pass
+// over the text, regardless of how many patterns there are. Patterns and text
+// are rune slices, so reported positions are rune offsets.
+type AhoCorasick struct {
+	nodes []acNode
+}
+
+type acEdge struct {
+	r  rune
+	to int32
+}
+
+type acNode struct {
+	edges  []acEdge // sorted by rune
+	fail   int32    // longest proper suffix that is also a prefix of some pattern
+	output int32    // nearest node on the fail chain that ends a pattern, or -1
+	ends   []int32  // patterns ending at this node
+	depth  int32
+}
+
+// NewAhoCorasick builds a matcher for the given patterns. Empty patterns never match.
+func NewAhoCorasick(patterns [][]rune) *AhoCorasick {
+	a := &AhoCorasick{nodes: []acNode{{output: -1}}}
+
+	for i, pattern := range patterns {
+		if len(pattern) == 0 {
+			continue
+		}
+		node := int32(0)
+		for _, r := range pattern {
+			next := a.child(node, r)
+			if next < 0 {
+				next = int32(len(a.nodes))
+				a.nodes = append(a.nodes, acNode{output: -1, depth: a.nodes[node].depth + 1})
+				a.addEdge(node, r, next)
+			}
+			node = next
+		}
+		a.nodes[node].ends = append(a.nodes[node].ends, int32(i))
+	}
+
+	a.link()
+	return a
+}
+
+// FindAll calls fn for every occurrence of every pattern in text, in order of
+// the occurrence's end position. Overlapping occurrences are all reported.
+// start and end are rune offsets with end exclusive.
+func (a *AhoCorasick) FindAll(text []rune, fn func(pattern, start, end int)) {
+	node := int32(0)
+	for i, r := range text {
+		for {
+			if next := a.child(node, r); next >= 0 {
+				node = next
+				break
+			}
+			if node == 0 {
+				break
+			}
+			node = a.nodes[node].fail
+		}
+
+		for out := node; out > 0; out = a.nodes[out].output {
+			if len(a.nodes[out].ends) == 0 {
+				continue
+			}
+			start := i + 1 - int(a.nodes[out].depth)
+			for _, pattern := range a.nodes[out].ends {
+				fn(int(pattern), start, i+1)
+			}
+		}
+	}
+}
+
+// child returns the node reached from node by r, or -1
+func (a *AhoCorasick) child(node int32, r rune) int32 {
+	edges := a.nodes[node].edges
+	i := sort.Search(len(edges), func(i int) bool { return edges[i].r >= r })
+	if i < len(edges) && edges[i].r == r {
+		return edges[i].to
+	}
+	return -1
+}
+
+// addEdge inserts an edge keeping the edges of node sorted
+func (a *AhoCorasick) addEdge(node int32, r rune, to int32) {
+	edges := a.nodes[node].edges
+	i := sort.Search(len(edges), func(i int) bool { return edges[i].r >= r })
+	edges = append(edges, acEdge{})
+	copy(edges[i+1:], edges[i:])
+	edges[i] = acEdge{r: r, to: to}
+	a.nodes[node].edges = edges
+}
+
+// link computes failure and output links breadth first
+func (a *AhoCorasick) link() {
+	queue := make([]int32, 0, len(a.nodes))
+	for _, e := range a.nodes[0].edges {
+		a.nodes[e.to].fail = 0
+		queue = append(queue, e.to)
+	}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, e := range a.nodes[node].edges {
+			fail := a.nodes[node].fail
+			for {
+				if next := a.child(fail, e.r); next >= 0 {
+					a.nodes[e.to].fail = next
+					break
+				}
+				if fail == 0 {
+					a.nodes[e.to].fail = 0
+					break
+				}
+				fail = a.nodes[fail].fail
+			}
+
+			target := a.nodes[e.to].fail
+			if len(a.nodes[target].ends) > 0 {
+				a.nodes[e.to].output = target
+			} else {
+				a.nodes[e.to].output = a.nodes[target].output
+			}
+			queue = append(queue, e.to)
+		}
+	}
+}
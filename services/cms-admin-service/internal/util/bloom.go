@@ -0,0 +1,60 @@
+package util
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// BloomFilter is a fixed-size set that can report false positives but never
+// false negatives
+type BloomFilter struct {
+	bits   []uint64
+	size   uint64 // Number of bits
+	hashes uint64 // Number of bit positions per key
+}
+
+// NewBloomFilter creates a Bloom filter sized for capacity keys at the given
+// false positive rate
+func NewBloomFilter(capacity int, falsePositiveRate float64) *BloomFilter {
+	n := float64(max(capacity, 1))
+	size := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	size = max(size, 64)
+	hashes := uint64(math.Round(float64(size) / n * math.Ln2))
+	return &BloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: max(hashes, 1),
+	}
+}
+
+// Add adds a key to the filter
+func (f *BloomFilter) Add(key string) {
+	h1, h2 := bloomHashes(key)
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % f.size
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// Test reports whether a key may have been added
+func (f *BloomFilter) Test(key string) bool {
+	h1, h2 := bloomHashes(key)
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % f.size
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHashes derives the two hashes combined into the bit positions of a key
+// (Kirsch-Mitzenmacher double hashing)
+func bloomHashes(key string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h1 := h.Sum64()
+	h.Write([]byte{0})
+	h2 := h.Sum64() | 1 // Odd, so the positions do not repeat early
+	return h1, h2
+}
@@ -0,0 +1,282 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DiffKind is the kind of a diff operation
+type DiffKind int
+
+const (
+	DiffEqual DiffKind = iota
+	DiffDelete
+	DiffInsert
+)
+
+// DiffOp is one step of an edit script: a[AStart:AEnd] is kept, deleted or
+// replaced by the inserted b[BStart:BEnd]
+type DiffOp struct {
+	Kind   DiffKind
+	AStart int
+	AEnd   int
+	BStart int
+	BEnd   int
+}
+
+// Diff returns a shortest edit script turning a into b (Myers' algorithm).
+// Within each changed region the deletion comes before the insertion.
+func Diff(a, b []string) []DiffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []DiffOp
+	emit := func(kind DiffKind, aStart, aEnd, bStart, bEnd int) {
+		if aStart == aEnd && bStart == bEnd {
+			return
+		}
+		if n := len(ops); n > 0 && ops[n-1].Kind == kind && ops[n-1].AEnd == aStart && ops[n-1].BEnd == bStart {
+			ops[n-1].AEnd, ops[n-1].BEnd = aEnd, bEnd
+			return
+		}
+		ops = append(ops, DiffOp{Kind: kind, AStart: aStart, AEnd: aEnd, BStart: bStart, BEnd: bEnd})
+	}
+
+	emit(DiffEqual, 0, prefix, 0, prefix)
+
+	// Group each run of single-token edits into one deletion and one insertion
+	aStart, bStart := prefix, prefix
+	x, y := prefix, prefix
+	flush := func() {
+		emit(DiffDelete, aStart, x, bStart, bStart)
+		emit(DiffInsert, x, x, bStart, y)
+	}
+	for _, step := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		switch step {
+		case DiffEqual:
+			flush()
+			emit(DiffEqual, x, x+1, y, y+1)
+			x, y = x+1, y+1
+			aStart, bStart = x, y
+		case DiffDelete:
+			x++
+		case DiffInsert:
+			y++
+		}
+	}
+	flush()
+
+	emit(DiffEqual, len(a)-suffix, len(a), len(b)-suffix, len(b))
+	return ops
+}
+
+// myers returns the single-token steps of a shortest edit script
+func myers(a, b []string) []DiffKind {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m, offset)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the saved frontiers back from (n, m) to the start
+func backtrack(trace [][]int, n, m, offset int) []DiffKind {
+	var steps []DiffKind
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			steps = append(steps, DiffEqual)
+			x, y = x-1, y-1
+		}
+		if d > 0 {
+			if x == prevX {
+				steps = append(steps, DiffInsert)
+			} else {
+				steps = append(steps, DiffDelete)
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return steps
+}
+
+// Merge3 merges the changes ours and theirs made to base. It reports false
+// when both sides changed the same or adjacent tokens differently.
+func Merge3(base, ours, theirs []string) ([]string, bool) {
+	oursHunks, theirsHunks := diffHunks(base, ours), diffHunks(base, theirs)
+
+	var merged []string
+	pos, i, j := 0, 0, 0
+	for i < len(oursHunks) || j < len(theirsHunks) {
+		var start int
+		if j >= len(theirsHunks) || (i < len(oursHunks) && oursHunks[i].start <= theirsHunks[j].start) {
+			start = oursHunks[i].start
+		} else {
+			start = theirsHunks[j].start
+		}
+
+		// Grow the region until no hunk on either side overlaps or touches it
+		end := start
+		i0, j0 := i, j
+		for {
+			if i < len(oursHunks) && oursHunks[i].start <= end {
+				end = max(end, oursHunks[i].end)
+				i++
+				continue
+			}
+			if j < len(theirsHunks) && theirsHunks[j].start <= end {
+				end = max(end, theirsHunks[j].end)
+				j++
+				continue
+			}
+			break
+		}
+
+		merged = append(merged, base[pos:start]...)
+		oursPart := applyHunks(base, start, end, oursHunks[i0:i])
+		theirsPart := applyHunks(base, start, end, theirsHunks[j0:j])
+		switch {
+		case j == j0:
+			merged = append(merged, oursPart...)
+		case i == i0:
+			merged = append(merged, theirsPart...)
+		case strings.Join(oursPart, "") == strings.Join(theirsPart, ""):
+			merged = append(merged, oursPart...)
+		default:
+			return nil, false
+		}
+		pos = end
+	}
+
+	return append(merged, base[pos:]...), true
+}
+
+// hunk replaces base[start:end] with tokens
+type hunk struct {
+	start  int
+	end    int
+	tokens []string
+}
+
+func diffHunks(base, other []string) []hunk {
+	var hunks []hunk
+	changed := false
+	for _, op := range Diff(base, other) {
+		if op.Kind == DiffEqual {
+			changed = false
+			continue
+		}
+		if !changed {
+			hunks = append(hunks, hunk{start: op.AStart, end: op.AStart})
+			changed = true
+		}
+		h := &hunks[len(hunks)-1]
+		h.end = op.AEnd
+		h.tokens = append(h.tokens, other[op.BStart:op.BEnd]...)
+	}
+	return hunks
+}
+
+func applyHunks(base []string, start, end int, hunks []hunk) []string {
+	var out []string
+	pos := start
+	for _, h := range hunks {
+		out = append(out, base[pos:h.start]...)
+		out = append(out, h.tokens...)
+		pos = h.end
+	}
+	return append(out, base[pos:end]...)
+}
+
+// TokenizeHTML splits HTML into words, runs of whitespace, single punctuation
+// characters, character references and whole tags, so a diff never splits a
+// tag or a word. Joining the tokens gives back the input.
+func TokenizeHTML(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		j := i + size
+		switch {
+		case r == '<':
+			if end := strings.IndexByte(s[i:], '>'); end >= 0 {
+				j = i + end + 1
+			}
+		case r == '&':
+			if end := strings.IndexByte(s[i:], ';'); end > 1 && end <= 10 && isEntityName(s[i+1:i+end]) {
+				j = i + end + 1
+			}
+		case IsWordRune(r):
+			j = scanRunes(s, j, IsWordRune)
+		case unicode.IsSpace(r):
+			j = scanRunes(s, j, unicode.IsSpace)
+		}
+		tokens = append(tokens, s[i:j])
+		i = j
+	}
+	return tokens
+}
+
+func scanRunes(s string, i int, in func(rune) bool) int {
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !in(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+func isEntityName(name string) bool {
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '#' && i == 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
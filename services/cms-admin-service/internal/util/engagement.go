@@ -0,0 +1,53 @@
+package util
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+)
+
+// maxSourceLength bounds referrer hosts and UTM values, which come from the
+// reader's browser
+const maxSourceLength = 100
+
+// ReferrerHost returns the host of a referrer URL without a leading "www.",
+// or model.DirectTraffic when there is no usable referrer
+func ReferrerHost(referrer string) string {
+	u, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return model.DirectTraffic
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if host == "" {
+		return model.DirectTraffic
+	}
+	return truncateRunes(host, maxSourceLength)
+}
+
+// NormalizeUTM lower-cases and trims a UTM parameter value
+func NormalizeUTM(value string) string {
+	return truncateRunes(strings.ToLower(strings.TrimSpace(value)), maxSourceLength)
+}
+
+// DetectDevice guesses the device class from a User-Agent
+func DetectDevice(userAgent string) model.DeviceClass {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "ipad"), strings.Contains(ua, "tablet"),
+		strings.Contains(ua, "android") && !strings.Contains(ua, "mobile"):
+		return model.DeviceTablet
+	case strings.Contains(ua, "mobi"), strings.Contains(ua, "iphone"), strings.Contains(ua, "ipod"):
+		return model.DeviceMobile
+	default:
+		return model.DeviceDesktop
+	}
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidPreviewToken is returned for a malformed preview token or one
+// whose signature does not match
+var ErrInvalidPreviewToken = errors.New("invalid preview token")
+
+// PreviewClaims are the contents of a preview token: the preview link it was
+// minted for and the article version it shows
+type PreviewClaims struct {
+	LinkID    primitive.ObjectID `json:"lid"`
+	TenantID  primitive.ObjectID `json:"tid"`
+	ArticleID primitive.ObjectID `json:"aid"`
+	Version   int                `json:"ver"`
+	ExpiresAt int64              `json:"exp"` // Unix seconds
+}
+
+// SignPreviewToken encodes the claims and signs them with HMAC-SHA256. The
+// token is the base64url claims and signature joined by a dot, so it can be
+// used in a URL path as is.
+func SignPreviewToken(secret []byte, claims *PreviewClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(previewSignature(secret, encoded)), nil
+}
+
+// ParsePreviewToken verifies the signature of a preview token and returns its
+// claims. It does not check the expiry.
+func ParsePreviewToken(secret []byte, token string) (*PreviewClaims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidPreviewToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, previewSignature(secret, encoded)) {
+		return nil, ErrInvalidPreviewToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidPreviewToken
+	}
+	var claims PreviewClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidPreviewToken
+	}
+	return &claims, nil
+}
+
+func previewSignature(secret []byte, encoded string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
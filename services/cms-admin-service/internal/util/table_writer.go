@@ -0,0 +1,226 @@
+package util
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Export formats
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// csvFlushRows is how many CSV rows are buffered before they are sent on
+const csvFlushRows = 100
+
+// TableWriter writes a report as one or more named tables
+type TableWriter interface {
+	// Table starts a new table with its column headers
+	Table(name string, header ...string) error
+	// Row adds a row to the current table
+	Row(values ...interface{}) error
+	// Close writes what is left; the writer cannot be used afterwards
+	Close() error
+}
+
+// NewTableWriter creates a table writer for an export format
+func NewTableWriter(w io.Writer, format string) (TableWriter, error) {
+	switch format {
+	case ExportCSV:
+		return newCSVTableWriter(w)
+	case ExportXLSX:
+		return &xlsxTableWriter{w: w, file: excelize.NewFile()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// ExportContentType returns the MIME type of an export format
+func ExportContentType(format string) string {
+	if format == ExportXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// csvTableWriter streams tables as CSV, one after the other, each under a row
+// holding its name. Rows are flushed as they are written, so long reports
+// need no more memory than short ones.
+type csvTableWriter struct {
+	out    io.Writer
+	csv    *csv.Writer
+	tables int
+	rows   int
+}
+
+func newCSVTableWriter(w io.Writer) (*csvTableWriter, error) {
+	// The byte order mark makes spreadsheet programs read the file as UTF-8
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return nil, err
+	}
+	return &csvTableWriter{out: w, csv: csv.NewWriter(w)}, nil
+}
+
+func (t *csvTableWriter) Table(name string, header ...string) error {
+	if t.tables > 0 {
+		if err := t.csv.Write(nil); err != nil {
+			return err
+		}
+	}
+	t.tables++
+	if err := t.csv.Write([]string{name}); err != nil {
+		return err
+	}
+	return t.csv.Write(header)
+}
+
+func (t *csvTableWriter) Row(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatCell(value)
+	}
+	if err := t.csv.Write(record); err != nil {
+		return err
+	}
+	if t.rows++; t.rows%csvFlushRows == 0 {
+		return t.flush()
+	}
+	return nil
+}
+
+func (t *csvTableWriter) Close() error {
+	return t.flush()
+}
+
+func (t *csvTableWriter) flush() error {
+	t.csv.Flush()
+	if err := t.csv.Error(); err != nil {
+		return err
+	}
+	if flusher, ok := t.out.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// formatCell formats a CSV value; times are written as dates, or as RFC 3339
+// when they are not at midnight
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return formatTime(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
+
+// xlsxTableWriter writes each table to its own worksheet. Rows go through
+// excelize's stream writer, which keeps large sheets on disk rather than in
+// memory; the workbook is written out on Close.
+type xlsxTableWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func (t *xlsxTableWriter) Table(name string, header ...string) error {
+	if err := t.flushSheet(); err != nil {
+		return err
+	}
+
+	// A new workbook comes with one empty sheet, which the first table takes
+	name = sheetName(name)
+	if t.stream == nil {
+		if err := t.file.SetSheetName(t.file.GetSheetName(0), name); err != nil {
+			return err
+		}
+	} else if _, err := t.file.NewSheet(name); err != nil {
+		return err
+	}
+
+	stream, err := t.file.NewStreamWriter(name)
+	if err != nil {
+		return err
+	}
+	t.stream = stream
+	t.row = 0
+
+	cells := make([]interface{}, len(header))
+	for i, title := range header {
+		cells[i] = title
+	}
+	return t.Row(cells...)
+}
+
+func (t *xlsxTableWriter) Row(values ...interface{}) error {
+	if t.stream == nil {
+		return fmt.Errorf("no table started")
+	}
+	t.row++
+	cell, err := excelize.CoordinatesToCellName(1, t.row)
+	if err != nil {
+		return err
+	}
+
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		if v, ok := value.(time.Time); ok {
+			cells[i] = formatTime(v)
+		} else {
+			cells[i] = value
+		}
+	}
+	return t.stream.SetRow(cell, cells)
+}
+
+func (t *xlsxTableWriter) Close() error {
+	defer t.file.Close()
+	if err := t.flushSheet(); err != nil {
+		return err
+	}
+	_, err := t.file.WriteTo(t.w)
+	return err
+}
+
+func (t *xlsxTableWriter) flushSheet() error {
+	if t.stream == nil {
+		return nil
+	}
+	return t.stream.Flush()
+}
+
+// sheetName makes a table name a valid worksheet name: at most 31 characters
+// and none of : \ / ? * [ ]
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	return truncateRunes(name, 31)
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// baseLetters maps accented Latin letters, including every Vietnamese letter,
+// to the letter without its marks
+var baseLetters = buildBaseLetters()
+
+func buildBaseLetters() map[rune]rune {
+	letters := map[rune]rune{'đ': 'd', 'Đ': 'D'}
+	ranges := [][2]rune{
+		{0x00C0, 0x024F}, // Latin-1 Supplement and Latin Extended-A/B
+		{0x1E00, 0x1EFF}, // Latin Extended Additional, where most Vietnamese letters live
+	}
+	for _, rg := range ranges {
+		for r := rg[0]; r <= rg[1]; r++ {
+			decomposed := norm.NFD.String(string(r))
+			base, size := utf8.DecodeRuneInString(decomposed)
+			if size == len(decomposed) || base >= utf8.RuneSelf {
+				continue
+			}
+			letters[r] = base
+		}
+	}
+	return letters
+}
+
+// RemoveDiacritic returns r without its diacritics, so 'ầ' becomes 'a' and
+// 'Đ' becomes 'D'. Case is kept, and every rune maps to exactly one rune so
+// positions in folded text line up with the original.
+func RemoveDiacritic(r rune) rune {
+	if r < utf8.RuneSelf {
+		return r
+	}
+	if base, ok := baseLetters[r]; ok {
+		return base
+	}
+	return r
+}
+
+// FoldRune lowercases r and removes its diacritics
+func FoldRune(r rune) rune {
+	return unicode.ToLower(RemoveDiacritic(r))
+}
+
+// IsWordRune reports whether r is part of a word
+func IsWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
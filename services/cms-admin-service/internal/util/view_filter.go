@@ -0,0 +1,101 @@
+package util
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultBotPatterns are User-Agent substrings of crawlers, link previewers,
+// monitoring services and HTTP libraries, in lower case
+var defaultBotPatterns = []string{
+	"bot", "crawl", "spider", "slurp", "archiver", "facebookexternalhit",
+	"embedly", "quora link preview", "whatsapp", "skypeuripreview", "headlesschrome",
+	"phantomjs", "lighthouse", "pingdom", "uptimerobot", "statuscake", "monitor",
+	"curl/", "wget/", "python-requests", "python-urllib", "go-http-client",
+	"java/", "okhttp", "apache-httpclient", "axios/", "node-fetch", "libwww-perl",
+}
+
+// dedupFalsePositiveRate is the share of first views that a ViewFilter wrongly
+// takes for repeat views
+const dedupFalsePositiveRate = 0.001
+
+// ViewFilter decides how article views are counted: it recognises crawlers by
+// their User-Agent and repeat views by the same visitor within a window.
+//
+// Views are remembered in two Bloom filters, the current one and the one
+// before it, which rotate at least a window apart. A repeat within the window
+// is therefore always recognised, and views are forgotten after one to three
+// windows. The filters are kept in memory, per process.
+type ViewFilter struct {
+	mu          sync.Mutex
+	window      time.Duration
+	capacity    int
+	current     *BloomFilter
+	previous    *BloomFilter
+	rotatedAt   time.Time
+	botPatterns []string
+}
+
+// NewViewFilter creates a view filter remembering views for window. capacity
+// is the number of views expected per window; the filters get less accurate
+// beyond it. botPatterns are matched in addition to the built-in list.
+func NewViewFilter(window time.Duration, capacity int, botPatterns []string) *ViewFilter {
+	patterns := append([]string(nil), defaultBotPatterns...)
+	for _, pattern := range botPatterns {
+		if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return &ViewFilter{
+		window:      window,
+		capacity:    capacity,
+		current:     NewBloomFilter(capacity, dedupFalsePositiveRate),
+		previous:    NewBloomFilter(capacity, dedupFalsePositiveRate),
+		rotatedAt:   time.Now(),
+		botPatterns: patterns,
+	}
+}
+
+// IsBot reports whether a User-Agent belongs to a crawler or a script.
+// Requests without a User-Agent count as bots.
+func (f *ViewFilter) IsBot(userAgent string) bool {
+	userAgent = strings.ToLower(strings.TrimSpace(userAgent))
+	if userAgent == "" {
+		return true
+	}
+	for _, pattern := range f.botPatterns {
+		if strings.Contains(userAgent, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFirstView records a view of an article by a visitor and reports whether
+// the visitor had not viewed it within the window
+func (f *ViewFilter) IsFirstView(articleID, visitor string, now time.Time) bool {
+	key := articleID + "\x00" + visitor
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// The current filter only holds views from before rotatedAt+window, so
+	// after two windows neither filter holds a view within the window
+	if elapsed := now.Sub(f.rotatedAt); elapsed >= 2*f.window {
+		f.previous = NewBloomFilter(f.capacity, dedupFalsePositiveRate)
+		f.current = NewBloomFilter(f.capacity, dedupFalsePositiveRate)
+		f.rotatedAt = now
+	} else if elapsed >= f.window {
+		f.previous = f.current
+		f.current = NewBloomFilter(f.capacity, dedupFalsePositiveRate)
+		f.rotatedAt = now
+	}
+
+	if f.current.Test(key) {
+		return false
+	}
+	f.current.Add(key)
+	return !f.previous.Test(key)
+}
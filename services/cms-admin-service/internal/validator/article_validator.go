@@ -13,8 +13,8 @@ import (
 
 // ValidationError represents a validation error with field information
 type ValidationError struct {
-	Field   string
-	Message string
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 func (e ValidationError) Error() string {
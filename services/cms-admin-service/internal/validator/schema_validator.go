@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// schemaURL is the resource name tenant schemas are compiled under
+const schemaURL = "article-type-schema.json"
+
+var schemaPrinter = message.NewPrinter(language.English)
+
+// noRemoteLoader refuses to resolve external references so tenant schemas
+// cannot make the service read local files or fetch URLs
+type noRemoteLoader struct{}
+
+func (noRemoteLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("external schema reference %q is not allowed", url)
+}
+
+// CompileSchema parses and compiles a JSON Schema document. Format assertions
+// are enabled so "format": "date-time" and friends are enforced.
+func CompileSchema(raw []byte) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, ValidationError{Field: "schema", Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(noRemoteLoader{})
+	compiler.AssertFormat()
+	if err := compiler.AddResource(schemaURL, doc); err != nil {
+		return nil, ValidationError{Field: "schema", Message: err.Error()}
+	}
+
+	schema, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, ValidationError{Field: "schema", Message: err.Error()}
+	}
+	return schema, nil
+}
+
+// ValidateSchema validates an article's JSON representation against a compiled
+// schema. Each violation is reported with the dotted path of the offending
+// value, e.g. "customFields.venue.address" or "episodeNumber".
+func (v *ArticleValidator) ValidateSchema(article *model.Article, schema *jsonschema.Schema) error {
+	raw, err := json.Marshal(article)
+	if err != nil {
+		return err
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+
+	err = schema.Validate(instance)
+	if err == nil {
+		return nil
+	}
+
+	var schemaErr *jsonschema.ValidationError
+	if !errors.As(err, &schemaErr) {
+		return err
+	}
+
+	var errs ValidationErrors
+	collectSchemaErrors(schemaErr, &errs)
+	return errs
+}
+
+// collectSchemaErrors flattens the leaves of a schema validation error tree
+func collectSchemaErrors(err *jsonschema.ValidationError, errs *ValidationErrors) {
+	if len(err.Causes) == 0 {
+		field := strings.Join(err.InstanceLocation, ".")
+		if field == "" {
+			field = "article"
+		}
+		*errs = append(*errs, ValidationError{Field: field, Message: err.ErrorKind.LocalizedString(schemaPrinter)})
+		return
+	}
+	for _, cause := range err.Causes {
+		collectSchemaErrors(cause, errs)
+	}
+}
+
+// ValidateSchemaDocument checks that a schema is registered for a known
+// article type and compiles
+func (v *ArticleValidator) ValidateSchemaDocument(articleType model.ArticleType, raw []byte) error {
+	if !v.isValidArticleType(articleType) {
+		return ValidationErrors{{Field: "articleType", Message: fmt.Sprintf("invalid article type %q", articleType)}}
+	}
+	if _, err := CompileSchema(raw); err != nil {
+		return ValidationErrors{err.(ValidationError)}
+	}
+	return nil
+}
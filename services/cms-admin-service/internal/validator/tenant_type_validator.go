@@ -0,0 +1,152 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+)
+
+// ValidateTypeConfig validates a tenant article type configuration
+func (v *ArticleValidator) ValidateTypeConfig(config *model.TenantArticleTypeConfig) error {
+	var errs ValidationErrors
+
+	for i, t := range config.AllowedTypes {
+		if !v.isValidArticleType(t) {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("allowedTypes[%d]", i), Message: fmt.Sprintf("invalid article type %q", t)})
+		}
+	}
+	for i, t := range config.DisallowedTypes {
+		if !v.isValidArticleType(t) {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("disallowedTypes[%d]", i), Message: fmt.Sprintf("invalid article type %q", t)})
+		}
+	}
+
+	seen := make(map[model.ArticleType]bool)
+	for i, tc := range config.CustomTypeConfigs {
+		field := fmt.Sprintf("customTypeConfigs[%d]", i)
+		if !v.isValidArticleType(tc.ArticleType) {
+			errs = append(errs, ValidationError{Field: field + ".articleType", Message: fmt.Sprintf("invalid article type %q", tc.ArticleType)})
+		} else if seen[tc.ArticleType] {
+			errs = append(errs, ValidationError{Field: field + ".articleType", Message: fmt.Sprintf("duplicate configuration for %s", tc.ArticleType)})
+		}
+		seen[tc.ArticleType] = true
+
+		if tc.MaxArticles < 0 {
+			errs = append(errs, ValidationError{Field: field + ".maxArticles", Message: "max articles must not be negative"})
+		}
+		for j, path := range tc.RequiredFields {
+			if strings.TrimSpace(path) == "" || strings.Contains(path, "..") || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("%s.requiredFields[%d]", field, j), Message: fmt.Sprintf("invalid field path %q", path)})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateTenantRules checks an article against its tenant's type configuration:
+// the article type must be allowed and every required field of the type must
+// be present. Required fields are JSON field paths of the article, with dots
+// descending into nested objects such as "customFields.venue.address".
+// A nil configuration allows everything.
+func (v *ArticleValidator) ValidateTenantRules(article *model.Article, config *model.TenantArticleTypeConfig) error {
+	if config == nil {
+		return nil
+	}
+
+	if !IsTypeAllowed(config, article.ArticleType) {
+		return ValidationErrors{{Field: "articleType", Message: fmt.Sprintf("article type %s is not allowed for this tenant", article.ArticleType)}}
+	}
+
+	typeConfig := FindTypeConfig(config, article.ArticleType)
+	if typeConfig == nil || len(typeConfig.RequiredFields) == 0 {
+		return nil
+	}
+
+	// Resolve paths against the JSON shape clients send and receive
+	raw, err := json.Marshal(article)
+	if err != nil {
+		return err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+
+	var errs ValidationErrors
+	for _, path := range typeConfig.RequiredFields {
+		if isEmptyValue(lookupPath(doc, path)) {
+			errs = append(errs, ValidationError{Field: path, Message: fmt.Sprintf("%s is required for %s articles", path, article.ArticleType)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// IsTypeAllowed reports whether a tenant configuration permits an article type.
+// Explicitly disallowed types always lose; otherwise the type must be listed
+// in AllowedTypes unless AllowAllTypes is set.
+func IsTypeAllowed(config *model.TenantArticleTypeConfig, articleType model.ArticleType) bool {
+	for _, t := range config.DisallowedTypes {
+		if t == articleType {
+			return false
+		}
+	}
+	if config.AllowAllTypes {
+		return true
+	}
+	for _, t := range config.AllowedTypes {
+		if t == articleType {
+			return true
+		}
+	}
+	return false
+}
+
+// FindTypeConfig returns the custom configuration for an article type, if any
+func FindTypeConfig(config *model.TenantArticleTypeConfig, articleType model.ArticleType) *model.CustomTypeConfig {
+	for i := range config.CustomTypeConfigs {
+		if config.CustomTypeConfigs[i].ArticleType == articleType {
+			return &config.CustomTypeConfigs[i]
+		}
+	}
+	return nil
+}
+
+// lookupPath walks a dotted path through nested JSON objects
+func lookupPath(doc map[string]interface{}, path string) interface{} {
+	var current interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
+
+// isEmptyValue reports whether a decoded JSON value counts as missing
+func isEmptyValue(value interface{}) bool {
+	switch val := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case []interface{}:
+		return len(val) == 0
+	case map[string]interface{}:
+		return len(val) == 0
+	default:
+		// Numbers and booleans count as provided once present
+		return false
+	}
+}
@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+)
+
+// WorkflowValidator validates workflow definitions
+type WorkflowValidator struct{}
+
+// NewWorkflowValidator creates a new workflow validator
+func NewWorkflowValidator() *WorkflowValidator {
+	return &WorkflowValidator{}
+}
+
+// Validate checks that a workflow is internally consistent: states are unique,
+// the initial state and every transition endpoint are declared states, and
+// every transition names someone who may perform it
+func (v *WorkflowValidator) Validate(workflow *model.Workflow) error {
+	var errs ValidationErrors
+
+	if strings.TrimSpace(workflow.Name) == "" {
+		errs = append(errs, ValidationError{Field: "name", Message: "workflow name is required"})
+	}
+
+	states := make(map[model.ArticleStatus]bool)
+	if len(workflow.States) == 0 {
+		errs = append(errs, ValidationError{Field: "states", Message: "at least one state is required"})
+	}
+	for i, state := range workflow.States {
+		field := fmt.Sprintf("states[%d].name", i)
+		switch {
+		case strings.TrimSpace(string(state.Name)) == "":
+			errs = append(errs, ValidationError{Field: field, Message: "state name is required"})
+		case state.Name == model.ArticleStatusDeleted:
+			errs = append(errs, ValidationError{Field: field, Message: "deleted is reserved and cannot be a workflow state"})
+		case states[state.Name]:
+			errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf("duplicate state %s", state.Name)})
+		}
+		states[state.Name] = true
+	}
+
+	if !states[workflow.InitialState] {
+		errs = append(errs, ValidationError{Field: "initialState", Message: fmt.Sprintf("initial state %q is not a declared state", workflow.InitialState)})
+	}
+
+	names := make(map[string]bool)
+	for i, t := range workflow.Transitions {
+		field := fmt.Sprintf("transitions[%d]", i)
+		if strings.TrimSpace(t.Name) == "" {
+			errs = append(errs, ValidationError{Field: field + ".name", Message: "transition name is required"})
+		} else if names[t.Name] {
+			errs = append(errs, ValidationError{Field: field + ".name", Message: fmt.Sprintf("duplicate transition %s", t.Name)})
+		}
+		names[t.Name] = true
+
+		if len(t.From) == 0 {
+			errs = append(errs, ValidationError{Field: field + ".from", Message: "at least one source state is required"})
+		}
+		for j, from := range t.From {
+			if !states[from] {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("%s.from[%d]", field, j), Message: fmt.Sprintf("state %q is not declared", from)})
+			} else if from == t.To {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("%s.from[%d]", field, j), Message: "a transition cannot start and end in the same state"})
+			}
+		}
+		if !states[t.To] {
+			errs = append(errs, ValidationError{Field: field + ".to", Message: fmt.Sprintf("state %q is not declared", t.To)})
+		}
+
+		if len(t.AllowedRoles) == 0 && len(t.AllowedGroups) == 0 && !t.AllowAuthor {
+			errs = append(errs, ValidationError{Field: field + ".allowedRoles", Message: "at least one role, group or the author must be allowed"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
@@ -63,7 +64,11 @@ func (s *Scheduler) processPublishSchedule(ctx context.Context) {
 	for _, article := range articles {
 		log.Printf("Auto-publishing article: %s (ID: %s)", article.Title, article.ID.Hex())
 
-		err := s.articleService.UpdateStatus(ctx, article.ID, model.ArticleStatusPublished, "scheduler", model.RoleModerator)
+		err := s.articleService.ApplyScheduledStatus(ctx, article, model.ArticleStatusPublished)
+		if errors.Is(err, service.ErrHeldForReview) {
+			log.Printf("Held article %s for review: content matches sensitive keywords", article.ID.Hex())
+			continue
+		}
 		if err != nil {
 			log.Printf("Error publishing article %s: %v", article.ID.Hex(), err)
 			continue
@@ -88,7 +93,7 @@ func (s *Scheduler) processExpireSchedule(ctx context.Context) {
 	for _, article := range articles {
 		log.Printf("Auto-expiring article: %s (ID: %s)", article.Title, article.ID.Hex())
 
-		err := s.articleService.UpdateStatus(ctx, article.ID, model.ArticleStatusArchived, "scheduler", model.RoleModerator)
+		err := s.articleService.ApplyScheduledStatus(ctx, article, model.ArticleStatusArchived)
 		if err != nil {
 			log.Printf("Error expiring article %s: %v", article.ID.Hex(), err)
 			continue
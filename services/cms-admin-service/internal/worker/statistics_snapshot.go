@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+)
+
+// SnapshotWorker periodically snapshots the statistics of closed days, weeks
+// and months
+type SnapshotWorker struct {
+	statisticsService *service.StatisticsService
+	interval          time.Duration
+	stopChan          chan bool
+}
+
+// NewSnapshotWorker creates a new statistics snapshot worker
+func NewSnapshotWorker(statisticsService *service.StatisticsService, interval time.Duration) *SnapshotWorker {
+	return &SnapshotWorker{
+		statisticsService: statisticsService,
+		interval:          interval,
+		stopChan:          make(chan bool),
+	}
+}
+
+// Start creates the missing snapshots once, then every interval until stopped
+func (w *SnapshotWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Println("Statistics snapshot worker started")
+	w.createSnapshots(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			w.createSnapshots(ctx)
+		case <-w.stopChan:
+			log.Println("Statistics snapshot worker stopped")
+			return
+		case <-ctx.Done():
+			log.Println("Statistics snapshot worker stopped due to context cancellation")
+			return
+		}
+	}
+}
+
+// Stop stops the worker
+func (w *SnapshotWorker) Stop() {
+	close(w.stopChan)
+}
+
+func (w *SnapshotWorker) createSnapshots(ctx context.Context) {
+	created, err := w.statisticsService.CreateSnapshots(ctx, time.Now())
+	if err != nil {
+		log.Printf("Error creating statistics snapshots: %v", err)
+	}
+	if created > 0 {
+		log.Printf("Created %d statistics snapshots", created)
+	}
+}
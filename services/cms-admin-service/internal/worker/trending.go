@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+)
+
+// TrendingWorker periodically recomputes the trending scores of articles
+type TrendingWorker struct {
+	trendingService *service.TrendingService
+	interval        time.Duration
+	stopChan        chan bool
+}
+
+// NewTrendingWorker creates a new trending worker
+func NewTrendingWorker(trendingService *service.TrendingService, interval time.Duration) *TrendingWorker {
+	return &TrendingWorker{
+		trendingService: trendingService,
+		interval:        interval,
+		stopChan:        make(chan bool),
+	}
+}
+
+// Start computes the scores once, then every interval until stopped
+func (w *TrendingWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Println("Trending worker started")
+	w.updateScores(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			w.updateScores(ctx)
+		case <-w.stopChan:
+			log.Println("Trending worker stopped")
+			return
+		case <-ctx.Done():
+			log.Println("Trending worker stopped due to context cancellation")
+			return
+		}
+	}
+}
+
+// Stop stops the worker
+func (w *TrendingWorker) Stop() {
+	close(w.stopChan)
+}
+
+func (w *TrendingWorker) updateScores(ctx context.Context) {
+	start := time.Now()
+	if err := w.trendingService.UpdateScores(ctx); err != nil {
+		log.Printf("Error updating trending scores: %v", err)
+		return
+	}
+	log.Printf("Updated trending scores in %s", time.Since(start).Round(time.Millisecond))
+}
@@ -2,29 +2,81 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// ViewEvent represents a view event to be processed
-type ViewEvent struct {
-	ArticleID primitive.ObjectID
-	Timestamp time.Time
+// ErrViewQueueStopped is returned when a view is enqueued after Stop
+var ErrViewQueueStopped = errors.New("view queue is stopped")
+
+const (
+	maxFlushAttempts  = 5
+	initialRetryDelay = 500 * time.Millisecond
+	maxRetryDelay     = 10 * time.Second
+	// maxPendingBatches bounds the failed batches kept for later flushes while
+	// the database is unavailable; the oldest are dropped beyond it
+	maxPendingBatches = 100
+	// drainTimeout bounds how long Stop spends saving the remaining views
+	drainTimeout = 30 * time.Second
+)
+
+// viewBatch holds the views and engagement events of one flush aggregated per
+// article, per article and day, and per article, day and traffic source. Its
+// ID stays the same across retries so the repositories can skip a batch they
+// have already counted.
+type viewBatch struct {
+	id      primitive.ObjectID
+	events  int
+	totals  map[primitive.ObjectID]int
+	daily   map[dailyViewKey]*model.ArticleView
+	sources map[trafficSourceKey]*model.TrafficSource
+}
+
+type dailyViewKey struct {
+	articleID primitive.ObjectID
+	date      time.Time
+}
+
+type trafficSourceKey struct {
+	dailyViewKey
+	sourceType model.TrafficSourceType
+	value      string
+}
+
+// queuedEvents are the events taken from the queues since the last flush
+type queuedEvents struct {
+	views       []model.ViewEvent
+	engagements []model.EngagementEvent
 }
 
-// ViewQueue handles asynchronous view counting
+func (e *queuedEvents) len() int {
+	return len(e.views) + len(e.engagements)
+}
+
+func (e *queuedEvents) reset() {
+	e.views = e.views[:0]
+	e.engagements = e.engagements[:0]
+}
+
+// ViewQueue handles asynchronous view and engagement counting
 type ViewQueue struct {
-	queue         chan ViewEvent
-	articleRepo   *repository.ArticleRepository
-	viewStatsRepo *repository.ViewStatsRepository
-	batchSize     int
-	flushInterval time.Duration
-	stopChan      chan bool
-	wg            sync.WaitGroup
+	queue           chan model.ViewEvent
+	engagementQueue chan model.EngagementEvent
+	articleRepo     *repository.ArticleRepository
+	viewStatsRepo   *repository.ViewStatsRepository
+	batchSize       int
+	flushInterval   time.Duration
+	pending         []*viewBatch // Batches whose flush failed, oldest first
+	mu              sync.RWMutex
+	stopped         bool
+	stopChan        chan struct{}
+	wg              sync.WaitGroup
 }
 
 // NewViewQueue creates a new view queue
@@ -36,12 +88,13 @@ func NewViewQueue(
 	flushInterval time.Duration,
 ) *ViewQueue {
 	return &ViewQueue{
-		queue:         make(chan ViewEvent, queueSize),
-		articleRepo:   articleRepo,
-		viewStatsRepo: viewStatsRepo,
-		batchSize:     batchSize,
-		flushInterval: flushInterval,
-		stopChan:      make(chan bool),
+		queue:           make(chan model.ViewEvent, queueSize),
+		engagementQueue: make(chan model.EngagementEvent, queueSize),
+		articleRepo:     articleRepo,
+		viewStatsRepo:   viewStatsRepo,
+		batchSize:       batchSize,
+		flushInterval:   flushInterval,
+		stopChan:        make(chan struct{}),
 	}
 }
 
@@ -52,139 +105,261 @@ func (q *ViewQueue) Start(ctx context.Context) {
 	log.Println("View queue processor started")
 }
 
-// Stop stops the queue processor
+// Stop stops accepting events and waits until the queued events are saved or
+// drainTimeout has passed
 func (q *ViewQueue) Stop() {
-	close(q.stopChan)
+	if q.close() {
+		close(q.stopChan)
+	}
 	q.wg.Wait()
 	log.Println("View queue processor stopped")
 }
 
+// close stops accepting views and reports whether the queue was still open
+func (q *ViewQueue) close() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.stopped {
+		return false
+	}
+	q.stopped = true
+	return true
+}
+
 // Enqueue adds a view event to the queue
-func (q *ViewQueue) Enqueue(articleID primitive.ObjectID) error {
+func (q *ViewQueue) Enqueue(event model.ViewEvent) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.stopped {
+		return ErrViewQueueStopped
+	}
+
 	select {
-	case q.queue <- ViewEvent{
-		ArticleID: articleID,
-		Timestamp: time.Now(),
-	}:
+	case q.queue <- event:
 		return nil
 	default:
-		log.Printf("Warning: View queue is full, dropping view event for article %s", articleID.Hex())
+		log.Printf("Warning: View queue is full, dropping view event for article %s", event.ArticleID.Hex())
 		return nil // Don't block, just log and continue
 	}
 }
 
-// processQueue processes view events from the queue
+// EnqueueEngagement adds an engagement event to the queue
+func (q *ViewQueue) EnqueueEngagement(event model.EngagementEvent) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.stopped {
+		return ErrViewQueueStopped
+	}
+
+	select {
+	case q.engagementQueue <- event:
+		return nil
+	default:
+		log.Printf("Warning: Engagement queue is full, dropping engagement event for article %s", event.ArticleID.Hex())
+		return nil
+	}
+}
+
+// processQueue processes view and engagement events from the queues
 func (q *ViewQueue) processQueue(ctx context.Context) {
 	defer q.wg.Done()
 
-	batch := make([]ViewEvent, 0, q.batchSize)
+	batch := &queuedEvents{}
 	ticker := time.NewTicker(q.flushInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case event := <-q.queue:
-			batch = append(batch, event)
+			batch.views = append(batch.views, event)
 
 			// Process batch if it reaches the batch size
-			if len(batch) >= q.batchSize {
+			if batch.len() >= q.batchSize {
 				q.processBatch(ctx, batch)
-				batch = batch[:0] // Clear batch
+				batch.reset()
 			}
 
-		case <-ticker.C:
-			// Periodically flush the batch even if not full
-			if len(batch) > 0 {
+		case event := <-q.engagementQueue:
+			batch.engagements = append(batch.engagements, event)
+			if batch.len() >= q.batchSize {
 				q.processBatch(ctx, batch)
-				batch = batch[:0] // Clear batch
+				batch.reset()
 			}
 
-		case <-q.stopChan:
-			// Process remaining events before stopping
-			if len(batch) > 0 {
-				q.processBatch(ctx, batch)
-			}
-			// Drain remaining queue
-			for len(q.queue) > 0 {
-				event := <-q.queue
-				batch = append(batch, event)
-				if len(batch) >= q.batchSize {
-					q.processBatch(ctx, batch)
-					batch = batch[:0]
-				}
-			}
-			if len(batch) > 0 {
+		case <-ticker.C:
+			// Periodically flush the batch even if not full, and retry
+			// batches that failed before
+			if batch.len() > 0 || len(q.pending) > 0 {
 				q.processBatch(ctx, batch)
+				batch.reset()
 			}
+
+		case <-q.stopChan:
+			q.drain(batch)
 			return
 
 		case <-ctx.Done():
-			log.Println("View queue processor stopped due to context cancellation")
+			log.Println("View queue processor stopping due to context cancellation")
+			q.close()
+			q.drain(batch)
 			return
 		}
 	}
 }
 
-// processBatch processes a batch of view events
-func (q *ViewQueue) processBatch(ctx context.Context, batch []ViewEvent) {
-	if len(batch) == 0 {
-		return
-	}
+// drain saves the batch in progress and everything left in the queues.
+// Enqueue no longer accepts events, so the queues cannot grow meanwhile.
+func (q *ViewQueue) drain(batch *queuedEvents) {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
 
-	log.Printf("Processing batch of %d view events", len(batch))
+	for len(q.queue) > 0 || len(q.engagementQueue) > 0 {
+		select {
+		case event := <-q.queue:
+			batch.views = append(batch.views, event)
+		case event := <-q.engagementQueue:
+			batch.engagements = append(batch.engagements, event)
+		}
+		if batch.len() >= q.batchSize {
+			q.processBatch(ctx, batch)
+			batch.reset()
+		}
+	}
+	q.processBatch(ctx, batch)
 
-	// Aggregate views by article and date
-	viewCounts := make(map[string]map[string]int) // articleID -> date -> count
+	lost := 0
+	for _, pending := range q.pending {
+		lost += pending.events
+	}
+	if lost > 0 {
+		log.Printf("Error: %d view and engagement events could not be saved before shutdown", lost)
+	}
+}
 
-	for _, event := range batch {
-		articleID := event.ArticleID.Hex()
-		date := time.Date(event.Timestamp.Year(), event.Timestamp.Month(), event.Timestamp.Day(), 0, 0, 0, 0, event.Timestamp.Location())
-		dateKey := date.Format("2006-01-02")
+// processBatch aggregates a batch of events and saves it after any batches
+// still pending from failed flushes. Each article and day is written once with
+// the aggregated counts. A batch that fails every retry stays pending for the
+// next flush, and so do the batches after it.
+func (q *ViewQueue) processBatch(ctx context.Context, events *queuedEvents) {
+	if events.len() > 0 {
+		q.pending = append(q.pending, newViewBatch(events.views, events.engagements))
+	}
+	if dropped := len(q.pending) - maxPendingBatches; dropped > 0 {
+		lost := 0
+		for _, batch := range q.pending[:dropped] {
+			lost += batch.events
+		}
+		log.Printf("Error: dropping %d view and engagement events that could not be saved", lost)
+		q.pending = q.pending[dropped:]
+	}
 
-		if _, exists := viewCounts[articleID]; !exists {
-			viewCounts[articleID] = make(map[string]int)
+	for len(q.pending) > 0 {
+		batch := q.pending[0]
+		if err := q.writeBatch(ctx, batch); err != nil {
+			log.Printf("Error saving %d view and engagement events, keeping %d batches for the next flush: %v", batch.events, len(q.pending), err)
+			return
 		}
-		viewCounts[articleID][dateKey]++
+		q.pending = q.pending[1:]
+		log.Printf("Successfully processed %d view and engagement events", batch.events)
 	}
+	q.pending = nil
+}
 
-	// Update database
-	for articleIDHex, dates := range viewCounts {
-		articleID, err := primitive.ObjectIDFromHex(articleIDHex)
-		if err != nil {
-			log.Printf("Error parsing article ID %s: %v", articleIDHex, err)
-			continue
+// writeBatch saves a batch, retrying with exponential backoff. All writes
+// skip documents that already counted the batch, so retrying after a partial
+// failure is safe.
+func (q *ViewQueue) writeBatch(ctx context.Context, batch *viewBatch) error {
+	delay := initialRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := q.articleRepo.AddViewCounts(ctx, batch.id, batch.totals)
+		if err == nil {
+			err = q.viewStatsRepo.AddViews(ctx, batch.id, batch.dailyViews())
+		}
+		if err == nil {
+			err = q.viewStatsRepo.AddTrafficSources(ctx, batch.id, batch.trafficSources())
+		}
+		if err == nil || attempt == maxFlushAttempts {
+			return err
 		}
 
-		// Increment article view count
-		totalViews := 0
-		for _, count := range dates {
-			totalViews += count
+		log.Printf("Error saving view batch %s (attempt %d of %d), retrying in %s: %v", batch.id.Hex(), attempt, maxFlushAttempts, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
 		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
 
-		// Update article's total view count (non-blocking)
-		if err := q.articleRepo.IncrementViewCount(ctx, articleID); err != nil {
-			log.Printf("Error incrementing view count for article %s: %v", articleIDHex, err)
+// newViewBatch aggregates view events per article and per article and day,
+// and engagement events per article and day and per traffic source
+func newViewBatch(views []model.ViewEvent, engagements []model.EngagementEvent) *viewBatch {
+	batch := &viewBatch{
+		id:      primitive.NewObjectID(),
+		events:  len(views) + len(engagements),
+		totals:  make(map[primitive.ObjectID]int),
+		daily:   make(map[dailyViewKey]*model.ArticleView),
+		sources: make(map[trafficSourceKey]*model.TrafficSource),
+	}
+	for _, event := range views {
+		batch.totals[event.ArticleID]++
+
+		view := batch.dailyView(event.ArticleID, event.Timestamp)
+		view.Views++
+		if event.Unique {
+			view.UniqueViews++
 		}
+	}
+	for _, event := range engagements {
+		view := batch.dailyView(event.ArticleID, event.Timestamp)
+		view.Engagement.Add(event)
 
-		// Update daily stats
-		for dateStr, count := range dates {
-			date, err := time.Parse("2006-01-02", dateStr)
-			if err != nil {
-				log.Printf("Error parsing date %s: %v", dateStr, err)
-				continue
+		for sourceType, value := range event.Sources() {
+			key := trafficSourceKey{
+				dailyViewKey: dailyViewKey{articleID: view.ArticleID, date: view.Date},
+				sourceType:   sourceType,
+				value:        value,
 			}
-
-			// Record views for the specific date
-			for i := 0; i < count; i++ {
-				if err := q.viewStatsRepo.RecordView(ctx, articleID, date); err != nil {
-					log.Printf("Error recording view stats for article %s on %s: %v", articleIDHex, dateStr, err)
-					break // Avoid logging same error multiple times
-				}
+			source := batch.sources[key]
+			if source == nil {
+				source = &model.TrafficSource{ArticleID: view.ArticleID, Date: view.Date, Type: sourceType, Value: value}
+				batch.sources[key] = source
 			}
+			source.Count++
 		}
 	}
+	return batch
+}
+
+// dailyView returns the daily counts of an article on the day of ts
+func (b *viewBatch) dailyView(articleID primitive.ObjectID, ts time.Time) *model.ArticleView {
+	date := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, ts.Location())
+	key := dailyViewKey{articleID: articleID, date: date}
+	view := b.daily[key]
+	if view == nil {
+		view = &model.ArticleView{ArticleID: articleID, Date: date}
+		b.daily[key] = view
+	}
+	return view
+}
 
-	log.Printf("Successfully processed %d view events", len(batch))
+// dailyViews lists the daily view counts of the batch
+func (b *viewBatch) dailyViews() []*model.ArticleView {
+	views := make([]*model.ArticleView, 0, len(b.daily))
+	for _, view := range b.daily {
+		views = append(views, view)
+	}
+	return views
+}
+
+// trafficSources lists the reads per traffic source of the batch
+func (b *viewBatch) trafficSources() []*model.TrafficSource {
+	sources := make([]*model.TrafficSource, 0, len(b.sources))
+	for _, source := range b.sources {
+		sources = append(sources, source)
+	}
+	return sources
 }
 
 // GetQueueSize returns the current queue size
@@ -0,0 +1,223 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/ai"
+)
+
+// newStandIn starts a test server that checks each request and replies with body
+func newStandIn(t *testing.T, check func(r *http.Request, body []byte), status int, reply string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if check != nil {
+			check(r, body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, reply)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newProvider(t *testing.T, name string, cfg ai.Config) ai.Provider {
+	t.Helper()
+	provider, err := ai.New(name, cfg)
+	if err != nil {
+		t.Fatalf("New(%q) error = %v", name, err)
+	}
+	return provider
+}
+
+func TestOpenAI_Translate(t *testing.T) {
+	server := newStandIn(t, func(r *http.Request, body []byte) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req struct {
+			Model     string `json:"model"`
+			MaxTokens int    `json:"max_tokens"`
+			Messages  []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatalf("invalid request body: %v", err)
+		}
+		if req.Model != "local-model" || req.MaxTokens != 256 {
+			t.Errorf("model = %q, max_tokens = %d", req.Model, req.MaxTokens)
+		}
+		if len(req.Messages) != 2 || req.Messages[1].Content != "Xin chào" {
+			t.Errorf("messages = %+v", req.Messages)
+		}
+	}, http.StatusOK, `{
+		"choices": [{"message": {"content": "`+"```json\\n"+`{\"translatedText\": \"Hello\", \"sourceLang\": \"vi\"}`+"\\n```"+`"}}],
+		"usage": {"prompt_tokens": 40, "completion_tokens": 8}
+	}`)
+
+	provider := newProvider(t, "openai", ai.Config{APIKey: "secret", Endpoint: server.URL, Model: "local-model", MaxTokens: 256})
+	result, usage, err := provider.(ai.Translator).Translate(context.Background(), "Xin chào", "", "en")
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if result.TranslatedText != "Hello" || result.SourceLang != "vi" || result.Provider != "openai" {
+		t.Errorf("result = %+v", result)
+	}
+	if usage.Tokens() != 48 {
+		t.Errorf("tokens = %d, want 48", usage.Tokens())
+	}
+}
+
+func TestOpenAI_CheckSpellingLocatesCorrections(t *testing.T) {
+	server := newStandIn(t, nil, http.StatusOK, `{"choices": [{"message": {"content":
+		"{\"corrections\": [{\"word\": \"Viet Nam\", \"suggestions\": [\"Việt Nam\"], \"type\": \"spelling\"}, {\"word\": \"not in text\", \"suggestions\": []}]}"}}]}`)
+
+	provider := newProvider(t, "openai", ai.Config{Endpoint: server.URL})
+	result, _, err := provider.(ai.SpellChecker).CheckSpelling(context.Background(), "Đẹp nhất Viet Nam", "vi")
+	if err != nil {
+		t.Fatalf("CheckSpelling() error = %v", err)
+	}
+	if len(result.Corrections) != 1 {
+		t.Fatalf("corrections = %+v, want only the one found in the text", result.Corrections)
+	}
+	if c := result.Corrections[0]; c.Position != 9 || c.Length != 8 {
+		t.Errorf("position = %d, length = %d, want 9, 8", c.Position, c.Length)
+	}
+}
+
+func TestAnthropic_DetectViolation(t *testing.T) {
+	server := newStandIn(t, func(r *http.Request, body []byte) {
+		if r.Header.Get("x-api-key") != "secret" || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		var req struct {
+			System    string `json:"system"`
+			MaxTokens int    `json:"max_tokens"`
+		}
+		json.Unmarshal(body, &req)
+		if req.System == "" || req.MaxTokens == 0 {
+			t.Errorf("system prompt and max_tokens are required, got %+v", req)
+		}
+	}, http.StatusOK, `{
+		"content": [{"type": "text", "text": "{\"hasViolation\": true, \"violationType\": \"violence\", \"confidence\": 1.4, \"explanation\": \"Threat\"}"}],
+		"usage": {"input_tokens": 100, "output_tokens": 20}
+	}`)
+
+	provider := newProvider(t, "anthropic", ai.Config{APIKey: "secret", Endpoint: server.URL})
+	result, usage, err := provider.(ai.Moderator).DetectViolation(context.Background(), "some text")
+	if err != nil {
+		t.Fatalf("DetectViolation() error = %v", err)
+	}
+	if !result.HasViolation || result.ViolationType != "violence" || result.Confidence != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if usage.InputTokens != 100 || usage.OutputTokens != 20 {
+		t.Errorf("usage = %+v", usage)
+	}
+}
+
+func TestDeepL_Translate(t *testing.T) {
+	server := newStandIn(t, func(r *http.Request, body []byte) {
+		if got := r.Header.Get("Authorization"); got != "DeepL-Auth-Key secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if !strings.Contains(string(body), `"target_lang":"VI"`) {
+			t.Errorf("body = %s", body)
+		}
+	}, http.StatusOK, `{"translations": [{"detected_source_language": "EN", "text": "Xin chào"}]}`)
+
+	provider := newProvider(t, "deepl", ai.Config{APIKey: "secret", Endpoint: server.URL})
+	result, usage, err := provider.(ai.Translator).Translate(context.Background(), "Hello", "", "vi")
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if result.TranslatedText != "Xin chào" || result.SourceLang != "en" {
+		t.Errorf("result = %+v", result)
+	}
+	if usage.Characters != 5 {
+		t.Errorf("characters = %d, want 5", usage.Characters)
+	}
+}
+
+func TestGoogleTranslate_Translate(t *testing.T) {
+	server := newStandIn(t, func(r *http.Request, body []byte) {
+		if got := r.URL.Query().Get("key"); got != "secret" {
+			t.Errorf("key = %q", got)
+		}
+	}, http.StatusOK, `{"data": {"translations": [{"translatedText": "Bonjour", "detectedSourceLanguage": "en"}]}}`)
+
+	provider := newProvider(t, "google", ai.Config{APIKey: "secret", Endpoint: server.URL})
+	result, _, err := provider.(ai.Translator).Translate(context.Background(), "Hello", "", "fr")
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if result.TranslatedText != "Bonjour" || result.SourceLang != "en" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestLanguageTool_CheckSpellingUsesRuneOffsets(t *testing.T) {
+	// The emoji takes two UTF-16 code units, so "teh" starts at offset 3
+	server := newStandIn(t, func(r *http.Request, body []byte) {
+		form, _ := url.ParseQuery(string(body))
+		if form.Get("language") != "en-US" || form.Get("text") == "" {
+			t.Errorf("form = %v", form)
+		}
+	}, http.StatusOK, `{"matches": [{
+		"message": "Possible spelling mistake",
+		"offset": 3, "length": 3,
+		"replacements": [{"value": "the"}],
+		"rule": {"issueType": "misspelling"}
+	}]}`)
+
+	provider := newProvider(t, "languagetool", ai.Config{Endpoint: server.URL})
+	result, _, err := provider.(ai.SpellChecker).CheckSpelling(context.Background(), "🙂 teh cat", "en-US")
+	if err != nil {
+		t.Fatalf("CheckSpelling() error = %v", err)
+	}
+	if len(result.Corrections) != 1 {
+		t.Fatalf("corrections = %+v", result.Corrections)
+	}
+	c := result.Corrections[0]
+	if c.Word != "teh" || c.Position != 2 || c.Type != "spelling" || c.Suggestions[0] != "the" {
+		t.Errorf("correction = %+v", c)
+	}
+}
+
+func TestProvider_ErrorResponse(t *testing.T) {
+	server := newStandIn(t, nil, http.StatusUnauthorized, `{"error": {"message": "Invalid API key"}}`)
+
+	provider := newProvider(t, "openai", ai.Config{Endpoint: server.URL})
+	_, _, err := provider.(ai.ContentImprover).ImproveContent(context.Background(), "text")
+
+	var apiErr *ai.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want an APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "Invalid API key" {
+		t.Errorf("error = %+v", apiErr)
+	}
+}
+
+func TestProvider_Capabilities(t *testing.T) {
+	if _, ok := newProvider(t, "deepl", ai.Config{}).(ai.SpellChecker); ok {
+		t.Error("deepl should not support spell checking")
+	}
+	if _, ok := newProvider(t, "languagetool", ai.Config{}).(ai.Translator); ok {
+		t.Error("languagetool should not support translation")
+	}
+	if _, err := ai.New("unknown", ai.Config{}); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
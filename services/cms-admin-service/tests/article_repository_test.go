@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// An edit read the article before a transition or schedule approval that
+// left the version unchanged. Saving it must not write back the status and
+// schedule it read, so the update has to be refused.
+func TestArticleRepository_UpdateIfUnchanged_RefusesStaleState(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	approvedAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		schedule *model.PublishSchedule
+	}{
+		{"published in the meantime", nil},
+		{"schedule withdrawn in the meantime", &model.PublishSchedule{Transition: "publish", ApprovedAt: approvedAt}},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			read := &model.Article{
+				ID:             primitive.NewObjectID(),
+				TenantID:       primitive.NewObjectID(),
+				Status:         model.ArticleStatusPendingReview,
+				Schedule:       tt.schedule,
+				CurrentVersion: 3,
+			}
+			edited := *read
+			edited.Title = "Edited"
+			edited.CurrentVersion = 4
+
+			// The article no longer matches the copy the edit was based on
+			mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+			repo := repository.NewArticleRepository(mt.DB)
+			err := repo.UpdateIfUnchanged(context.Background(), &edited, read)
+			if !errors.Is(err, repository.ErrConflict) {
+				t.Fatalf("UpdateIfUnchanged() error = %v, want ErrConflict", err)
+			}
+
+			filter := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+			if got := filter.Lookup("currentVersion").AsInt64(); got != 3 {
+				t.Errorf("filter currentVersion = %d, want 3", got)
+			}
+			if got := filter.Lookup("status").StringValue(); got != string(model.ArticleStatusPendingReview) {
+				t.Errorf("filter status = %q, want %q", got, model.ArticleStatusPendingReview)
+			}
+			if tt.schedule == nil {
+				if v, err := filter.LookupErr("schedule"); err != nil || v.Type != bson.TypeNull {
+					t.Errorf("filter must require no schedule, got %v", v)
+				}
+			} else if got := filter.Lookup("schedule.approvedAt").Time(); !got.Equal(approvedAt) {
+				t.Errorf("filter schedule.approvedAt = %v, want %v", got, approvedAt)
+			}
+		})
+	}
+}
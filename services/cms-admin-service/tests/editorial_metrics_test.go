@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSummarizeDurations(t *testing.T) {
+	if got := service.SummarizeDurations(nil); got != (model.DurationStats{}) {
+		t.Errorf("SummarizeDurations(nil) = %+v, want zero", got)
+	}
+
+	got := service.SummarizeDurations([]float64{10, 1, 4, 2, 3})
+	want := model.DurationStats{Count: 5, Average: 4, Median: 3, P90: 7.6, Max: 10}
+	if got.Count != want.Count || got.Max != want.Max ||
+		math.Abs(got.Average-want.Average) > 1e-9 ||
+		math.Abs(got.Median-want.Median) > 1e-9 ||
+		math.Abs(got.P90-want.P90) > 1e-9 {
+		t.Errorf("SummarizeDurations() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSummarizeStays(t *testing.T) {
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	category := primitive.NewObjectID()
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+	stay := func(article primitive.ObjectID, author string, status, next model.ArticleStatus, hours int, editor string, action model.ActionType) *model.StateStay {
+		return &model.StateStay{
+			ArticleID:  article,
+			AuthorID:   author,
+			CategoryID: category,
+			Status:     status,
+			EnteredAt:  start,
+			LeftAt:     start.Add(time.Duration(hours) * time.Hour),
+			NextStatus: next,
+			LeftBy:     editor,
+			LeftAction: action,
+		}
+	}
+
+	metrics := service.SummarizeStays([]*model.StateStay{
+		stay(first, "alice", model.ArticleStatusDraft, model.ArticleStatusPendingReview, 2, "alice", model.ActionTypeTransition),
+		stay(first, "alice", model.ArticleStatusPendingReview, model.ArticleStatusDraft, 6, "ed", model.ActionTypeReject),
+		stay(first, "alice", model.ArticleStatusPendingReview, model.ArticleStatusPublished, 4, "ed", model.ActionTypePublish),
+		// Withdrawn by the author, not a review
+		stay(second, "bob", model.ArticleStatusPendingReview, model.ArticleStatusDraft, 1, "bob", model.ActionTypeTransition),
+		stay(second, "bob", model.ArticleStatusPendingReview, model.ArticleStatusPublished, 8, "sam", model.ActionTypePublish),
+	})
+
+	if metrics.Reviewed != 3 || metrics.Rejected != 1 {
+		t.Errorf("reviewed, rejected = %d, %d, want 3, 1", metrics.Reviewed, metrics.Rejected)
+	}
+	if math.Abs(metrics.RejectionRate-1.0/3) > 1e-9 {
+		t.Errorf("RejectionRate = %v, want 1/3", metrics.RejectionRate)
+	}
+	if review := metrics.TimeInState[model.ArticleStatusPendingReview]; review == nil || review.Count != 4 || review.Median != 5 {
+		t.Errorf("pending_review time = %+v, want 4 stays with median 5", review)
+	}
+	if metrics.ReviewTime.Count != 4 || metrics.AverageReviewTime != 4.75 {
+		t.Errorf("ReviewTime = %+v, average %v, want 4 stays averaging 4.75", metrics.ReviewTime, metrics.AverageReviewTime)
+	}
+
+	if len(metrics.ByEditor) != 2 {
+		t.Fatalf("ByEditor has %d editors, want 2", len(metrics.ByEditor))
+	}
+	editors := map[string]*model.EditorialMetrics{}
+	for _, editor := range metrics.ByEditor {
+		editors[editor.ID] = editor
+	}
+	if ed := editors["ed"]; ed == nil || ed.Reviewed != 2 || ed.RejectionRate != 0.5 {
+		t.Errorf("editor ed = %+v, want 2 reviews with rejection rate 0.5", ed)
+	}
+	if editors["bob"] != nil {
+		t.Error("withdrawing author counted as editor")
+	}
+
+	if len(metrics.ByAuthor) != 2 || len(metrics.ByCategory) != 1 || metrics.ByCategory[0].Articles != 2 {
+		t.Errorf("ByAuthor has %d authors and ByCategory %d categories, want 2 and 1 with 2 articles", len(metrics.ByAuthor), len(metrics.ByCategory))
+	}
+}
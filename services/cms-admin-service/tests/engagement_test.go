@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/worker"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReferrerHost(t *testing.T) {
+	tests := []struct {
+		referrer string
+		want     string
+	}{
+		{"https://www.google.com/search?q=tin+tuc", "google.com"},
+		{"http://News.Example.org:8080/a/b", "news.example.org"},
+		{"", model.DirectTraffic},
+		{"android-app://com.google.android.gm/", model.DirectTraffic},
+		{"not a url", model.DirectTraffic},
+	}
+
+	for _, tt := range tests {
+		if got := util.ReferrerHost(tt.referrer); got != tt.want {
+			t.Errorf("ReferrerHost(%q) = %q, want %q", tt.referrer, got, tt.want)
+		}
+	}
+}
+
+func TestDetectDevice(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      model.DeviceClass
+	}{
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148", model.DeviceMobile},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36", model.DeviceMobile},
+		{"Mozilla/5.0 (Linux; Android 13; SM-X710) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", model.DeviceTablet},
+		{"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148", model.DeviceTablet},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36", model.DeviceDesktop},
+	}
+
+	for _, tt := range tests {
+		if got := util.DetectDevice(tt.userAgent); got != tt.want {
+			t.Errorf("DetectDevice(%q) = %q, want %q", tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestEngagementCounts_Add(t *testing.T) {
+	var counts model.EngagementCounts
+	counts.Add(model.EngagementEvent{ScrollDepth: 100, ActiveSeconds: 90, Device: model.DeviceMobile})
+	counts.Add(model.EngagementEvent{ScrollDepth: 25, ActiveSeconds: 10, Device: model.DeviceDesktop})
+
+	want := model.EngagementCounts{Reads: 2, ActiveSeconds: 100, Depth25: 1, Depth100: 1, Mobile: 1, Desktop: 1}
+	if counts != want {
+		t.Errorf("counts = %+v, want %+v", counts, want)
+	}
+}
+
+func TestEngagementEvent_Sources(t *testing.T) {
+	event := model.EngagementEvent{Referrer: "facebook.com", UTMCampaign: "summer"}
+
+	sources := event.Sources()
+	if len(sources) != 2 || sources[model.TrafficReferrer] != "facebook.com" || sources[model.TrafficUTMCampaign] != "summer" {
+		t.Errorf("Sources() = %v", sources)
+	}
+}
+
+func TestViewQueue_EnqueueEngagementAfterStop(t *testing.T) {
+	queue := worker.NewViewQueue(nil, nil, 10, 5, time.Minute)
+	queue.Stop()
+
+	event := model.EngagementEvent{ArticleID: primitive.NewObjectID(), Timestamp: time.Now()}
+	if err := queue.EnqueueEngagement(event); err != worker.ErrViewQueueStopped {
+		t.Errorf("EnqueueEngagement() after Stop error = %v, want ErrViewQueueStopped", err)
+	}
+}
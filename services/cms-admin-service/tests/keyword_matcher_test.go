@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+)
+
+func TestAhoCorasick_FindAll(t *testing.T) {
+	patterns := [][]rune{[]rune("he"), []rune("she"), []rune("his"), []rune("hers")}
+	ac := util.NewAhoCorasick(patterns)
+
+	type occurrence struct{ pattern, start, end int }
+	var got []occurrence
+	ac.FindAll([]rune("ushers"), func(pattern, start, end int) {
+		got = append(got, occurrence{pattern, start, end})
+	})
+
+	want := []occurrence{{1, 1, 4}, {0, 2, 4}, {3, 2, 6}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAll() = %v, want %v", got, want)
+	}
+}
+
+func TestFoldRune(t *testing.T) {
+	folded := []rune{}
+	for _, r := range "Đường Ma Túy Ở Hà Nội" {
+		folded = append(folded, util.FoldRune(r))
+	}
+	if got, want := string(folded), "duong ma tuy o ha noi"; got != want {
+		t.Errorf("folded = %q, want %q", got, want)
+	}
+}
+
+func TestKeywordMatcher_Scan(t *testing.T) {
+	keywords := []*model.SensitiveKeyword{
+		{Keyword: "ma túy", Action: model.KeywordActionBlock, Severity: model.KeywordSeverityCritical, IsActive: true},
+		{Keyword: "ma tuy", Action: model.KeywordActionReview, Severity: model.KeywordSeverityHigh, IgnoreDiacritics: true, IsActive: true},
+		{Keyword: "cờ bạc", Action: model.KeywordActionWarn, Severity: model.KeywordSeverityLow, WholeWord: true, IsActive: true},
+		{Keyword: `\d{3}-\d{4}`, IsRegex: true, Action: model.KeywordActionWarn, Severity: model.KeywordSeverityLow, IsActive: true},
+		{Keyword: "inactive", Action: model.KeywordActionBlock, Severity: model.KeywordSeverityCritical},
+	}
+	matcher := service.NewKeywordMatcher(keywords)
+
+	tests := []struct {
+		name      string
+		text      string
+		keywords  []string
+		positions [][]int
+		action    string
+	}{
+		{
+			name:      "Diacritics must match unless ignored",
+			text:      "Bán MA TUY và ma túy",
+			keywords:  []string{"ma túy", "ma tuy"},
+			positions: [][]int{{14}, {4, 14}},
+			action:    model.KeywordActionBlock,
+		},
+		{
+			name:      "Whole word only",
+			text:      "cờ bạc, cờ bạcx",
+			keywords:  []string{"cờ bạc"},
+			positions: [][]int{{0}},
+			action:    model.KeywordActionWarn,
+		},
+		{
+			name:      "Pattern positions are runes",
+			text:      "Gọi số 555-1234",
+			keywords:  []string{`\d{3}-\d{4}`},
+			positions: [][]int{{7}},
+			action:    model.KeywordActionWarn,
+		},
+		{
+			name:   "Inactive keywords are ignored",
+			text:   "inactive",
+			action: "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := matcher.Scan([]service.ScanField{{Name: "content", Text: tt.text}})
+
+			var gotKeywords []string
+			var gotPositions [][]int
+			for _, r := range result.Results {
+				gotKeywords = append(gotKeywords, r.Keyword)
+				gotPositions = append(gotPositions, r.Positions)
+			}
+			if !reflect.DeepEqual(gotKeywords, tt.keywords) {
+				t.Errorf("keywords = %v, want %v", gotKeywords, tt.keywords)
+			}
+			if !reflect.DeepEqual(gotPositions, tt.positions) {
+				t.Errorf("positions = %v, want %v", gotPositions, tt.positions)
+			}
+			if result.RecommendedAction != tt.action {
+				t.Errorf("RecommendedAction = %q, want %q", result.RecommendedAction, tt.action)
+			}
+		})
+	}
+}
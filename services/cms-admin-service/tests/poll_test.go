@@ -0,0 +1,34 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+)
+
+func TestPoll_AcceptsVotes(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		poll model.Poll
+		want bool
+	}{
+		{"Active without end date", model.Poll{IsActive: true, StartDate: past}, true},
+		{"Active before end date", model.Poll{IsActive: true, StartDate: past, EndDate: &future}, true},
+		{"Inactive", model.Poll{IsActive: false, StartDate: past}, false},
+		{"Not started", model.Poll{IsActive: true, StartDate: future}, false},
+		{"Ended", model.Poll{IsActive: true, StartDate: past.Add(-time.Hour), EndDate: &past}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.poll.AcceptsVotes(now); got != tt.want {
+				t.Errorf("AcceptsVotes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package service_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPreviewToken(t *testing.T) {
+	secret := []byte("preview-secret")
+	claims := &util.PreviewClaims{
+		LinkID:    primitive.NewObjectID(),
+		TenantID:  primitive.NewObjectID(),
+		ArticleID: primitive.NewObjectID(),
+		Version:   4,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+
+	token, err := util.SignPreviewToken(secret, claims)
+	if err != nil {
+		t.Fatalf("SignPreviewToken() error = %v", err)
+	}
+
+	t.Run("Round trip", func(t *testing.T) {
+		got, err := util.ParsePreviewToken(secret, token)
+		if err != nil {
+			t.Fatalf("ParsePreviewToken() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, claims) {
+			t.Errorf("ParsePreviewToken() = %+v, want %+v", got, claims)
+		}
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		if _, err := util.ParsePreviewToken([]byte("other-secret"), token); err != util.ErrInvalidPreviewToken {
+			t.Errorf("ParsePreviewToken() error = %v, want ErrInvalidPreviewToken", err)
+		}
+	})
+
+	t.Run("Changed claims", func(t *testing.T) {
+		forged := *claims
+		forged.Version = 5
+		forgedToken, _ := util.SignPreviewToken([]byte("other-secret"), &forged)
+		payload, _, _ := strings.Cut(forgedToken, ".")
+		_, signature, _ := strings.Cut(token, ".")
+
+		if _, err := util.ParsePreviewToken(secret, payload+"."+signature); err != util.ErrInvalidPreviewToken {
+			t.Errorf("ParsePreviewToken() error = %v, want ErrInvalidPreviewToken", err)
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		for _, token := range []string{"", "abc", "abc.def", "..."} {
+			if _, err := util.ParsePreviewToken(secret, token); err != util.ErrInvalidPreviewToken {
+				t.Errorf("ParsePreviewToken(%q) error = %v, want ErrInvalidPreviewToken", token, err)
+			}
+		}
+	})
+}
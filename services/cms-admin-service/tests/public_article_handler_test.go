@@ -0,0 +1,80 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/handler"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// publicArticles records the list query the handler passes on
+type publicArticles struct {
+	handler.PublicArticleReader
+	tenantID primitive.ObjectID
+	filter   map[string]interface{}
+	sort     map[string]int
+	articles []*model.Article
+}
+
+func (p *publicArticles) ListPublicArticles(ctx context.Context, tenantID primitive.ObjectID, filter map[string]interface{}, page, limit int, sort map[string]int) ([]*model.Article, int64, error) {
+	p.tenantID, p.filter, p.sort = tenantID, filter, sort
+	return p.articles, int64(len(p.articles)), nil
+}
+
+func TestPublicArticleHandler_ListArticlesSort(t *testing.T) {
+	tenantID := primitive.NewObjectID()
+	categoryID := primitive.NewObjectID()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantSort   map[string]int
+		wantFilter map[string]interface{}
+	}{
+		{"Trending in a category", "?sort=trending&categoryId=" + categoryID.Hex(), map[string]int{"trendingScore": -1}, map[string]interface{}{"categoryId": categoryID}},
+		{"Descending field", "?sort=-publishedAt", map[string]int{"publishedAt": -1}, map[string]interface{}{}},
+		{"Default", "", map[string]int{}, map[string]interface{}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles := &publicArticles{articles: []*model.Article{{ID: primitive.NewObjectID(), Title: "Hot"}}}
+			h := handler.NewPublicArticleHandler(articles)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/public/articles"+tt.query, nil)
+			r = r.WithContext(context.WithValue(r.Context(), "tenantID", tenantID))
+			w := httptest.NewRecorder()
+			h.ListArticles(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+			}
+			if articles.tenantID != tenantID {
+				t.Errorf("tenant = %s, want %s", articles.tenantID.Hex(), tenantID.Hex())
+			}
+			if !reflect.DeepEqual(articles.sort, tt.wantSort) {
+				t.Errorf("sort = %v, want %v", articles.sort, tt.wantSort)
+			}
+			if !reflect.DeepEqual(articles.filter, tt.wantFilter) {
+				t.Errorf("filter = %v, want %v", articles.filter, tt.wantFilter)
+			}
+
+			var body struct {
+				Data  []model.Article `json:"data"`
+				Total int64           `json:"total"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(body.Data) != 1 || body.Total != 1 {
+				t.Errorf("response = %+v, want one article", body)
+			}
+		})
+	}
+}
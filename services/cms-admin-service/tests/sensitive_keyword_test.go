@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
+)
+
+func TestSensitiveKeywordService_RejectsInvalidKeywords(t *testing.T) {
+	// Validation runs before the repository is touched
+	svc := service.NewSensitiveKeywordService(nil)
+
+	tests := []struct {
+		name    string
+		keyword model.SensitiveKeyword
+		field   string
+	}{
+		{"Empty keyword", model.SensitiveKeyword{Keyword: "  "}, "keyword"},
+		{"Invalid pattern", model.SensitiveKeyword{Keyword: "(abc", IsRegex: true}, "keyword"},
+		{"Unknown severity", model.SensitiveKeyword{Keyword: "abc", Severity: "extreme"}, "severity"},
+		{"Unknown action", model.SensitiveKeyword{Keyword: "abc", Action: "delete"}, "action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kw := tt.keyword
+			err := svc.Create(context.Background(), &kw)
+
+			var errs validator.ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Create() error = %v, want validation errors", err)
+			}
+			if errs[0].Field != tt.field {
+				t.Errorf("field = %q, want %q", errs[0].Field, tt.field)
+			}
+		})
+	}
+}
+
+func TestSensitiveKeywordService_BulkImportValidatesAll(t *testing.T) {
+	svc := service.NewSensitiveKeywordService(nil)
+
+	keywords := []*model.SensitiveKeyword{
+		{Keyword: "fine"},
+		{Keyword: ""},
+		{Keyword: "also fine", Action: "explode"},
+	}
+
+	err := svc.BulkImport(context.Background(), keywords)
+
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("BulkImport() error = %v, want validation errors", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("got %d errors, want 2: %v", len(errs), errs)
+	}
+	if errs[0].Field != "keywords[1].keyword" || errs[1].Field != "keywords[2].action" {
+		t.Errorf("unexpected fields: %q, %q", errs[0].Field, errs[1].Field)
+	}
+	if keywords[0].Severity != model.KeywordSeverityMedium || keywords[0].Action != model.KeywordActionWarn {
+		t.Errorf("defaults not applied: severity %q, action %q", keywords[0].Severity, keywords[0].Action)
+	}
+}
@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+	"github.com/xuri/excelize/v2"
+)
+
+func writeTables(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tables, err := util.NewTableWriter(&buf, format)
+	if err != nil {
+		t.Fatalf("NewTableWriter() error = %v", err)
+	}
+
+	steps := []error{
+		tables.Table("Summary", "Metric", "Value"),
+		tables.Row("Views", int64(1200)),
+		tables.Row("Completion rate", 0.25),
+		tables.Table("Daily views", "Date", "Views"),
+		tables.Row(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), 40),
+		tables.Row("Tin nóng, \"mới\"", nil),
+		tables.Close(),
+	}
+	for _, err := range steps {
+		if err != nil {
+			t.Fatalf("write error = %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestTableWriter_CSV(t *testing.T) {
+	got := string(writeTables(t, util.ExportCSV))
+	want := "\uFEFF" +
+		"Summary\nMetric,Value\nViews,1200\nCompletion rate,0.25\n" +
+		"\n" +
+		"Daily views\nDate,Views\n2024-05-01,40\n\"Tin nóng, \"\"mới\"\"\",\n"
+	if got != want {
+		t.Errorf("CSV = %q, want %q", got, want)
+	}
+}
+
+func TestTableWriter_XLSX(t *testing.T) {
+	file, err := excelize.OpenReader(bytes.NewReader(writeTables(t, util.ExportXLSX)))
+	if err != nil {
+		t.Fatalf("OpenReader() error = %v", err)
+	}
+	defer file.Close()
+
+	if got, want := file.GetSheetList(), []string{"Summary", "Daily views"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sheets = %v, want %v", got, want)
+	}
+	rows, err := file.GetRows("Daily views")
+	if err != nil {
+		t.Fatalf("GetRows() error = %v", err)
+	}
+	want := [][]string{{"Date", "Views"}, {"2024-05-01", "40"}, {"Tin nóng, \"mới\""}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
+
+func TestTableWriter_UnknownFormat(t *testing.T) {
+	if _, err := util.NewTableWriter(&bytes.Buffer{}, "pdf"); err == nil {
+		t.Error("NewTableWriter(pdf) should fail")
+	}
+}
+
+func TestPeriodBounds(t *testing.T) {
+	// Wednesday afternoon
+	now := time.Date(2024, 5, 15, 14, 30, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		periodType string
+		start, end time.Time
+	}{
+		{model.PeriodDaily, day(5, 15), day(5, 16)},
+		{model.PeriodWeekly, day(5, 13), day(5, 20)},
+		{model.PeriodMonthly, day(5, 1), day(6, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.periodType, func(t *testing.T) {
+			start, end := service.PeriodBounds(tt.periodType, now)
+			if !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Errorf("PeriodBounds() = %v, %v, want %v, %v", start, end, tt.start, tt.end)
+			}
+		})
+	}
+
+	// Sunday belongs to the week that started the Monday before
+	if start, _ := service.PeriodBounds(model.PeriodWeekly, day(5, 19)); !start.Equal(day(5, 13)) {
+		t.Errorf("week of Sunday starts %v, want %v", start, day(5, 13))
+	}
+}
@@ -0,0 +1,23 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestArticleService_SetHotRequiresEditor(t *testing.T) {
+	svc := service.NewArticleService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	hot := true
+
+	for _, role := range []model.Role{model.RoleWriter, ""} {
+		_, err := svc.SetHot(context.Background(), primitive.NewObjectID(), primitive.NewObjectID(), &hot, model.Actor{UserID: "u1", Role: role})
+		if !errors.Is(err, service.ErrForbidden) {
+			t.Errorf("SetHot() as %q error = %v, want ErrForbidden", role, err)
+		}
+	}
+}
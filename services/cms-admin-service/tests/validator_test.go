@@ -205,3 +205,234 @@ func TestArticleValidator_ValidateID(t *testing.T) {
 		})
 	}
 }
+
+func TestArticleValidator_ValidateTenantRules(t *testing.T) {
+	v := validator.NewArticleValidator()
+
+	config := &model.TenantArticleTypeConfig{
+		AllowedTypes:    []model.ArticleType{model.ArticleTypeNews, model.ArticleTypeEventInfo},
+		DisallowedTypes: []model.ArticleType{model.ArticleTypeVideo},
+		CustomTypeConfigs: []model.CustomTypeConfig{
+			{
+				ArticleType:    model.ArticleTypeEventInfo,
+				RequiredFields: []string{"summary", "customFields.venue.address"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		article    *model.Article
+		config     *model.TenantArticleTypeConfig
+		wantFields []string
+	}{
+		{
+			name:    "No config allows any type",
+			article: &model.Article{ArticleType: model.ArticleTypeVideo},
+			config:  nil,
+		},
+		{
+			name:    "Allowed type without rules",
+			article: &model.Article{ArticleType: model.ArticleTypeNews},
+			config:  config,
+		},
+		{
+			name:       "Type not in allowed list",
+			article:    &model.Article{ArticleType: model.ArticleTypeJob},
+			config:     config,
+			wantFields: []string{"articleType"},
+		},
+		{
+			name:       "Disallowed type wins over allow all",
+			article:    &model.Article{ArticleType: model.ArticleTypeVideo},
+			config:     &model.TenantArticleTypeConfig{AllowAllTypes: true, DisallowedTypes: []model.ArticleType{model.ArticleTypeVideo}},
+			wantFields: []string{"articleType"},
+		},
+		{
+			name:       "Missing required fields",
+			article:    &model.Article{ArticleType: model.ArticleTypeEventInfo},
+			config:     config,
+			wantFields: []string{"summary", "customFields.venue.address"},
+		},
+		{
+			name: "Required fields present",
+			article: &model.Article{
+				ArticleType: model.ArticleTypeEventInfo,
+				Summary:     "Annual meeting",
+				CustomFields: map[string]interface{}{
+					"venue": map[string]interface{}{"address": "1 Main Street"},
+				},
+			},
+			config: config,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.ValidateTenantRules(tt.article, tt.config)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Errorf("ValidateTenantRules() unexpected error = %v", err)
+				}
+				return
+			}
+
+			errs, ok := err.(validator.ValidationErrors)
+			if !ok {
+				t.Fatalf("ValidateTenantRules() error = %v, want ValidationErrors", err)
+			}
+			if len(errs) != len(tt.wantFields) {
+				t.Fatalf("ValidateTenantRules() got %d errors, want %d: %v", len(errs), len(tt.wantFields), errs)
+			}
+			for i, field := range tt.wantFields {
+				if errs[i].Field != field {
+					t.Errorf("error %d field = %s, want %s", i, errs[i].Field, field)
+				}
+			}
+		})
+	}
+}
+
+func TestArticleValidator_ValidateTypeConfig(t *testing.T) {
+	v := validator.NewArticleValidator()
+
+	tests := []struct {
+		name    string
+		config  *model.TenantArticleTypeConfig
+		wantErr bool
+	}{
+		{
+			name: "Valid config",
+			config: &model.TenantArticleTypeConfig{
+				AllowedTypes:      []model.ArticleType{model.ArticleTypeNews},
+				CustomTypeConfigs: []model.CustomTypeConfig{{ArticleType: model.ArticleTypeNews, MaxArticles: 10, RequiredFields: []string{"customFields.source"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Unknown allowed type",
+			config:  &model.TenantArticleTypeConfig{AllowedTypes: []model.ArticleType{"Unknown"}},
+			wantErr: true,
+		},
+		{
+			name: "Duplicate type config",
+			config: &model.TenantArticleTypeConfig{
+				CustomTypeConfigs: []model.CustomTypeConfig{{ArticleType: model.ArticleTypeNews}, {ArticleType: model.ArticleTypeNews}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative max articles",
+			config: &model.TenantArticleTypeConfig{
+				CustomTypeConfigs: []model.CustomTypeConfig{{ArticleType: model.ArticleTypeNews, MaxArticles: -1}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Malformed field path",
+			config: &model.TenantArticleTypeConfig{
+				CustomTypeConfigs: []model.CustomTypeConfig{{ArticleType: model.ArticleTypeNews, RequiredFields: []string{"customFields..x"}}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.ValidateTypeConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTypeConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestArticleValidator_ValidateSchema(t *testing.T) {
+	v := validator.NewArticleValidator()
+
+	schema, err := validator.CompileSchema([]byte(`{
+		"type": "object",
+		"required": ["lawNumber", "customFields"],
+		"properties": {
+			"lawNumber": {"type": "string", "pattern": "^[0-9]+/[0-9]{4}$"},
+			"customFields": {
+				"type": "object",
+				"required": ["issuer"],
+				"properties": {
+					"issuer": {"type": "string", "minLength": 1},
+					"pages": {"type": "integer", "minimum": 1}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("CompileSchema() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		article    *model.Article
+		wantFields []string
+	}{
+		{
+			name: "Valid legal document",
+			article: &model.Article{
+				ArticleType:  model.ArticleTypeLegalDocument,
+				LawNumber:    "12/2024",
+				CustomFields: map[string]interface{}{"issuer": "Ministry", "pages": 3},
+			},
+		},
+		{
+			name: "Invalid nested custom field",
+			article: &model.Article{
+				ArticleType:  model.ArticleTypeLegalDocument,
+				LawNumber:    "12/2024",
+				CustomFields: map[string]interface{}{"issuer": "Ministry", "pages": 0},
+			},
+			wantFields: []string{"customFields.pages"},
+		},
+		{
+			name: "Invalid type-specific field",
+			article: &model.Article{
+				ArticleType:  model.ArticleTypeLegalDocument,
+				LawNumber:    "twelve",
+				CustomFields: map[string]interface{}{"issuer": "Ministry"},
+			},
+			wantFields: []string{"lawNumber"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.ValidateSchema(tt.article, schema)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Errorf("ValidateSchema() unexpected error = %v", err)
+				}
+				return
+			}
+
+			errs, ok := err.(validator.ValidationErrors)
+			if !ok {
+				t.Fatalf("ValidateSchema() error = %v, want ValidationErrors", err)
+			}
+			if len(errs) != len(tt.wantFields) {
+				t.Fatalf("ValidateSchema() got %d errors, want %d: %v", len(errs), len(tt.wantFields), errs)
+			}
+			for i, field := range tt.wantFields {
+				if errs[i].Field != field {
+					t.Errorf("error %d field = %s, want %s", i, errs[i].Field, field)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileSchema_RejectsExternalReferences(t *testing.T) {
+	if _, err := validator.CompileSchema([]byte(`{"$ref": "file:///etc/passwd"}`)); err == nil {
+		t.Error("CompileSchema() expected error for external reference")
+	}
+	if _, err := validator.CompileSchema([]byte(`{"type": 5}`)); err == nil {
+		t.Error("CompileSchema() expected error for invalid schema")
+	}
+}
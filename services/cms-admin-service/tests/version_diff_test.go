@@ -0,0 +1,229 @@
+package service_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+)
+
+func TestTokenizeHTML(t *testing.T) {
+	got := util.TokenizeHTML(`<p class="lead">Xin chào,&nbsp;thế giới</p>`)
+	want := []string{`<p class="lead">`, "Xin", " ", "chào", ",", "&nbsp;", "thế", " ", "giới", "</p>"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeHTML() = %q, want %q", got, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := strings.Split("the quick brown fox jumps", " ")
+	b := strings.Split("the slow brown fox leaps high", " ")
+
+	var got []string
+	for _, op := range util.Diff(a, b) {
+		switch op.Kind {
+		case util.DiffEqual:
+			got = append(got, "="+strings.Join(a[op.AStart:op.AEnd], " "))
+		case util.DiffDelete:
+			got = append(got, "-"+strings.Join(a[op.AStart:op.AEnd], " "))
+		case util.DiffInsert:
+			got = append(got, "+"+strings.Join(b[op.BStart:op.BEnd], " "))
+		}
+	}
+
+	want := []string{"=the", "-quick", "+slow", "=brown fox", "-jumps", "+leaps high"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	tokens := func(s string) []string { return util.TokenizeHTML(s) }
+	base := "<p>One two three four</p>"
+
+	tests := []struct {
+		name   string
+		ours   string
+		theirs string
+		want   string
+		ok     bool
+	}{
+		{"Separate words", "<p>One 2 three four</p>", "<p>One two three 4</p>", "<p>One 2 three 4</p>", true},
+		{"Same change", "<p>One 2 three four</p>", "<p>One 2 three four</p>", "<p>One 2 three four</p>", true},
+		{"Same word", "<p>One 2 three four</p>", "<p>One deux three four</p>", "", false},
+		{"Only one side", base, "<p>One two three four five</p>", "<p>One two three four five</p>", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, ok := util.Merge3(tokens(base), tokens(tt.ours), tokens(tt.theirs))
+			if ok != tt.ok {
+				t.Fatalf("Merge3() ok = %v, want %v", ok, tt.ok)
+			}
+			if got := strings.Join(merged, ""); ok && got != tt.want {
+				t.Errorf("Merge3() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffArticles(t *testing.T) {
+	from := &model.Article{
+		Title:        "Old title",
+		Content:      "<p>Hello <b>world</b></p>",
+		Tags:         []string{"news", "sport"},
+		SEO:          model.SEO{Title: "SEO", Keywords: []string{"a"}},
+		CustomFields: map[string]interface{}{"price": 10, "meta": map[string]interface{}{"color": "red"}},
+		ContentBlocks: []model.ContentBlock{
+			{Type: "text", Content: "Intro"},
+			{Type: "image", Content: "a.jpg"},
+		},
+	}
+	to := &model.Article{
+		Title:        "Old title",
+		Content:      "<p>Hello <b>there</b></p>",
+		Tags:         []string{"news", "world"},
+		SEO:          model.SEO{Title: "New SEO", Keywords: []string{"a"}},
+		CustomFields: map[string]interface{}{"price": 12.0, "meta": map[string]interface{}{"color": "red", "size": "L"}},
+		ContentBlocks: []model.ContentBlock{
+			{Type: "text", Content: "Intro text"},
+			{Type: "image", Content: "a.jpg"},
+		},
+	}
+
+	changes := service.DiffArticles(from, to)
+	byField := make(map[string]model.FieldChange)
+	for _, change := range changes {
+		byField[change.Field] = change
+	}
+
+	wantFields := []string{"content", "contentBlocks", "tags", "seo.title", "customFields.meta.size", "customFields.price"}
+	if len(changes) != len(wantFields) {
+		t.Errorf("got %d changes, want %d: %+v", len(changes), len(wantFields), changes)
+	}
+	for _, field := range wantFields {
+		if _, ok := byField[field]; !ok {
+			t.Errorf("missing change for %s", field)
+		}
+	}
+
+	wantSegments := []model.TextSegment{
+		{Op: "equal", Text: "<p>Hello <b>"},
+		{Op: "delete", Text: "world"},
+		{Op: "insert", Text: "there"},
+		{Op: "equal", Text: "</b></p>"},
+	}
+	if got := byField["content"].Segments; !reflect.DeepEqual(got, wantSegments) {
+		t.Errorf("content segments = %+v, want %+v", got, wantSegments)
+	}
+
+	tags := byField["tags"]
+	if !reflect.DeepEqual(tags.Added, []string{"world"}) || !reflect.DeepEqual(tags.Removed, []string{"sport"}) {
+		t.Errorf("tags added %v removed %v", tags.Added, tags.Removed)
+	}
+
+	if size := byField["customFields.meta.size"]; size.Type != model.ChangeAdded || size.New != "L" {
+		t.Errorf("customFields.meta.size = %+v", size)
+	}
+
+	blocks := byField["contentBlocks"].Blocks
+	if len(blocks) != 1 || blocks[0].Type != model.ChangeModified || *blocks[0].OldIndex != 0 {
+		t.Errorf("contentBlocks = %+v", blocks)
+	}
+}
+
+func TestMergeArticles(t *testing.T) {
+	base := &model.Article{
+		Title:        "Title",
+		Summary:      "Summary",
+		Content:      "<p>One two three</p>",
+		Tags:         []string{"a", "b"},
+		CustomFields: map[string]interface{}{"price": 10, "stock": 5},
+	}
+	current := &model.Article{
+		Title:        "Title",
+		Summary:      "Summary by someone else",
+		Content:      "<p>One two 3</p>",
+		Tags:         []string{"a", "b", "c"},
+		CustomFields: map[string]interface{}{"price": 10, "stock": 4},
+	}
+
+	t.Run("Non-overlapping changes merge", func(t *testing.T) {
+		edited := &model.Article{
+			Title:        "New title",
+			Summary:      "Summary",
+			Content:      "<p>1 two three</p>",
+			Tags:         []string{"a"},
+			CustomFields: map[string]interface{}{"price": 12, "stock": 5},
+		}
+
+		merged, conflicts := service.MergeArticles(base, current, edited)
+		if len(conflicts) != 0 {
+			t.Fatalf("unexpected conflicts: %+v", conflicts)
+		}
+		if merged.Title != "New title" || merged.Summary != "Summary by someone else" {
+			t.Errorf("title %q summary %q", merged.Title, merged.Summary)
+		}
+		if merged.Content != "<p>1 two 3</p>" {
+			t.Errorf("content = %q", merged.Content)
+		}
+		if !reflect.DeepEqual(merged.Tags, []string{"a", "c"}) {
+			t.Errorf("tags = %v", merged.Tags)
+		}
+		if merged.CustomFields["price"] != 12 || merged.CustomFields["stock"] != 4 {
+			t.Errorf("customFields = %v", merged.CustomFields)
+		}
+	})
+
+	t.Run("Overlapping changes conflict", func(t *testing.T) {
+		edited := &model.Article{
+			Title:        "Title",
+			Summary:      "My summary",
+			Content:      "<p>One two three!</p>",
+			Tags:         []string{"a", "b"},
+			CustomFields: map[string]interface{}{"price": 10, "stock": 3},
+		}
+
+		merged, conflicts := service.MergeArticles(base, current, edited)
+		var fields []string
+		for _, conflict := range conflicts {
+			fields = append(fields, conflict.Field)
+		}
+		want := []string{"summary", "content", "customFields.stock"}
+		if !reflect.DeepEqual(fields, want) {
+			t.Errorf("conflicts = %v, want %v", fields, want)
+		}
+		if merged.Summary != current.Summary || merged.Content != current.Content {
+			t.Errorf("conflicting fields must keep the current value")
+		}
+	})
+}
+
+func TestVersionConflictError(t *testing.T) {
+	var err error = &service.VersionConflictError{BaseVersion: 3, CurrentVersion: 5}
+
+	if !errors.Is(err, repository.ErrConflict) {
+		t.Error("VersionConflictError should match repository.ErrConflict")
+	}
+	if got, want := err.Error(), "article has changed since version 3; the current version is 5"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestEditLockedError(t *testing.T) {
+	expiresAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	var err error = &service.EditLockedError{Lock: &model.EditLock{UserID: "u1", UserName: "Lan", ExpiresAt: expiresAt}}
+
+	if !errors.Is(err, repository.ErrConflict) {
+		t.Error("EditLockedError should match repository.ErrConflict")
+	}
+	if got, want := err.Error(), "article is being edited by Lan until 2024-05-01T09:30:00Z"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/util"
+)
+
+func TestViewFilter_IsBot(t *testing.T) {
+	filter := util.NewViewFilter(time.Minute, 1000, []string{"InternalChecker"})
+
+	tests := []struct {
+		userAgent string
+		want      bool
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36", false},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148", false},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", true},
+		{"facebookexternalhit/1.1", true},
+		{"curl/8.4.0", true},
+		{"internalchecker/2.0", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		if got := filter.IsBot(tt.userAgent); got != tt.want {
+			t.Errorf("IsBot(%q) = %v, want %v", tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestViewFilter_IsFirstView(t *testing.T) {
+	filter := util.NewViewFilter(30*time.Minute, 1000, nil)
+	start := time.Now()
+
+	if !filter.IsFirstView("a1", "visitor", start) {
+		t.Error("first view should be unique")
+	}
+	if filter.IsFirstView("a1", "visitor", start.Add(time.Minute)) {
+		t.Error("repeat view within the window should not be unique")
+	}
+	if !filter.IsFirstView("a2", "visitor", start.Add(time.Minute)) {
+		t.Error("view of another article should be unique")
+	}
+	if !filter.IsFirstView("a1", "other", start.Add(time.Minute)) {
+		t.Error("view by another visitor should be unique")
+	}
+
+	// Still remembered across one rotation
+	if filter.IsFirstView("a2", "visitor", start.Add(40*time.Minute)) {
+		t.Error("repeat view within the window should not be unique after a rotation")
+	}
+	if !filter.IsFirstView("a1", "visitor", start.Add(2*time.Hour)) {
+		t.Error("view after the window should be unique again")
+	}
+}
+
+func TestVisitor_Fingerprint(t *testing.T) {
+	anonymous := model.Visitor{IP: "203.0.113.7", UserAgent: "Mozilla/5.0"}
+	if anonymous.Fingerprint() != (model.Visitor{IP: "203.0.113.7", UserAgent: "Mozilla/5.0"}).Fingerprint() {
+		t.Error("the same IP and User-Agent should give the same fingerprint")
+	}
+	if anonymous.Fingerprint() == (model.Visitor{IP: "203.0.113.8", UserAgent: "Mozilla/5.0"}).Fingerprint() {
+		t.Error("different IPs should give different fingerprints")
+	}
+
+	signedIn := model.Visitor{UserID: "u1", IP: "203.0.113.7", UserAgent: "Mozilla/5.0"}
+	if signedIn.Fingerprint() != (model.Visitor{UserID: "u1", IP: "198.51.100.1"}).Fingerprint() {
+		t.Error("a signed-in visitor should be identified by user ID")
+	}
+}
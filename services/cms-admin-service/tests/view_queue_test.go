@@ -0,0 +1,26 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/worker"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestViewQueue_EnqueueAfterStop(t *testing.T) {
+	queue := worker.NewViewQueue(nil, nil, 10, 5, time.Minute)
+
+	if err := queue.Enqueue(model.ViewEvent{ArticleID: primitive.NewObjectID(), Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if got := queue.GetQueueSize(); got != 1 {
+		t.Errorf("GetQueueSize() = %d, want 1", got)
+	}
+
+	queue.Stop()
+	if err := queue.Enqueue(model.ViewEvent{ArticleID: primitive.NewObjectID(), Timestamp: time.Now()}); err != worker.ErrViewQueueStopped {
+		t.Errorf("Enqueue() after Stop error = %v, want ErrViewQueueStopped", err)
+	}
+}
@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/service"
+	"github.com/vhvplatform/go-cms-service/services/cms-admin-service/internal/validator"
+)
+
+func TestWorkflowValidator_Validate(t *testing.T) {
+	v := validator.NewWorkflowValidator()
+
+	if err := v.Validate(service.DefaultWorkflow()); err != nil {
+		t.Fatalf("Expected default workflow to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*model.Workflow)
+		field  string
+	}{
+		{
+			name:   "Missing name",
+			modify: func(w *model.Workflow) { w.Name = "" },
+			field:  "name",
+		},
+		{
+			name:   "Undeclared initial state",
+			modify: func(w *model.Workflow) { w.InitialState = "copy_edit" },
+			field:  "initialState",
+		},
+		{
+			name: "Duplicate state",
+			modify: func(w *model.Workflow) {
+				w.States = append(w.States, model.WorkflowState{Name: model.ArticleStatusDraft})
+			},
+			field: "states[4].name",
+		},
+		{
+			name: "Deleted is reserved",
+			modify: func(w *model.Workflow) {
+				w.States = append(w.States, model.WorkflowState{Name: model.ArticleStatusDeleted})
+			},
+			field: "states[4].name",
+		},
+		{
+			name:   "Duplicate transition",
+			modify: func(w *model.Workflow) { w.Transitions[1].Name = w.Transitions[0].Name },
+			field:  "transitions[1].name",
+		},
+		{
+			name:   "Undeclared target",
+			modify: func(w *model.Workflow) { w.Transitions[0].To = "legal_review" },
+			field:  "transitions[0].to",
+		},
+		{
+			name: "Nobody allowed",
+			modify: func(w *model.Workflow) {
+				w.Transitions[0].AllowedRoles = nil
+				w.Transitions[0].AllowAuthor = false
+			},
+			field: "transitions[0].allowedRoles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := service.DefaultWorkflow()
+			tt.modify(workflow)
+
+			err := v.Validate(workflow)
+			errs, ok := err.(validator.ValidationErrors)
+			if !ok {
+				t.Fatalf("Expected ValidationErrors, got %v", err)
+			}
+			for _, e := range errs {
+				if e.Field == tt.field {
+					return
+				}
+			}
+			t.Errorf("Expected error on %s, got %v", tt.field, errs)
+		})
+	}
+}
+
+func TestDefaultWorkflow_Permissions(t *testing.T) {
+	workflow := service.DefaultWorkflow()
+	author := model.Actor{UserID: "writer1", Role: model.RoleWriter}
+	otherWriter := model.Actor{UserID: "writer2", Role: model.RoleWriter}
+	editor := model.Actor{UserID: "editor1", Role: model.RoleEditor}
+
+	draft := &model.Article{Status: model.ArticleStatusDraft, CreatedBy: "writer1"}
+	pending := &model.Article{Status: model.ArticleStatusPendingReview, CreatedBy: "writer1"}
+
+	tests := []struct {
+		name       string
+		transition string
+		article    *model.Article
+		actor      model.Actor
+		want       bool
+	}{
+		{"Author submits own draft", "submit", draft, author, true},
+		{"Writer cannot submit another's draft", "submit", draft, otherWriter, false},
+		{"Author cannot publish", "publish", pending, author, false},
+		{"Editor publishes", "publish", pending, editor, true},
+		{"Editor rejects pending article", "reject", pending, editor, true},
+		{"Reject requires a reviewable state", "reject", draft, editor, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transition := service.FindTransition(workflow, tt.transition)
+			if transition == nil {
+				t.Fatalf("Transition %s not found", tt.transition)
+			}
+			if got := service.CanPerform(transition, tt.article, tt.actor); got != tt.want {
+				t.Errorf("CanPerform() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !service.FindTransition(workflow, "reject").RequireNote {
+		t.Error("Expected reject to require a note")
+	}
+	if service.CanEdit(workflow, model.ArticleStatusPublished, model.RoleWriter) {
+		t.Error("Expected writers not to edit published articles")
+	}
+	if !service.CanEdit(workflow, model.ArticleStatusDraft, model.RoleWriter) {
+		t.Error("Expected writers to edit drafts")
+	}
+}
+
+func TestCanPerform_AllowedGroups(t *testing.T) {
+	transition := &model.WorkflowTransition{
+		Name:          "legal_approve",
+		From:          []model.ArticleStatus{model.ArticleStatusPendingReview},
+		To:            model.ArticleStatusPublished,
+		AllowedGroups: []string{"legal"},
+	}
+	article := &model.Article{Status: model.ArticleStatusPendingReview}
+
+	if !service.CanPerform(transition, article, model.Actor{Role: model.RoleWriter, GroupIDs: []string{"legal"}}) {
+		t.Error("Expected group member to perform the transition")
+	}
+	if service.CanPerform(transition, article, model.Actor{Role: model.RoleModerator}) {
+		t.Error("Expected non-member to be denied")
+	}
+}
@@ -3,16 +3,20 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"io"
+	"errors"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/vhvplatform/go-cms-service/pkg/auth"
+	"github.com/vhvplatform/go-cms-service/pkg/config"
+	"github.com/vhvplatform/go-cms-service/pkg/middleware"
 	"github.com/vhvplatform/go-cms-service/services/cms-frontend-service/internal/client"
 )
 
@@ -25,6 +29,12 @@ func main() {
 	serverPort := getEnv("SERVER_PORT", "8082")
 	cacheTTL := getEnvInt("CACHE_TTL", 300)
 
+	// Forwarding headers are only believed from these proxies
+	trustedProxies, err := middleware.ParseTrustedProxies(getEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	log.Println("Starting CMS Frontend Service...")
 	log.Printf("CMS Service URL: %s", cmsServiceURL)
 	log.Printf("Stats Service URL: %s", statsServiceURL)
@@ -46,6 +56,12 @@ func main() {
 		log.Println("✓ Connected to Redis")
 	}
 
+	// Initialize token validator
+	tokenValidator, err := auth.NewValidator(config.NewJWTConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize token validator: %v", err)
+	}
+
 	// Initialize clients
 	cmsClient := client.NewCMSClient(cmsServiceURL)
 	statsClient := client.NewStatsClient(statsServiceURL)
@@ -77,13 +93,16 @@ func main() {
 
 		filters := make(map[string]string)
 		if category := r.URL.Query().Get("category"); category != "" {
-			filters["category"] = category
+			filters["categoryId"] = category
 		}
 		if tag := r.URL.Query().Get("tag"); tag != "" {
-			filters["tag"] = tag
+			filters["tags"] = tag
+		}
+		if r.URL.Query().Get("sort") == "trending" {
+			filters["sort"] = "trending"
 		}
 
-		articles, total, err := cmsClient.ListArticles(r.Context(), page, limit, filters)
+		articles, total, err := cmsClient.ListArticles(r.Context(), originOf(r), page, limit, filters)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -158,19 +177,24 @@ func main() {
 					return
 				}
 
-				// Get auth token from header (simplified)
-				authToken := r.Header.Get("Authorization")
-				if authToken == "" {
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				// Validate the bearer token before passing it on
+				_, err := tokenValidator.Authenticate(r)
+				if err != nil {
+					auth.Unauthorized(w, err)
 					return
 				}
+				authToken := r.Header.Get("Authorization")
 
-				// Extract user info from token (simplified - should validate JWT)
-				userID := "user123"    // TODO: Extract from JWT
-				userName := "User 123" // TODO: Extract from JWT
-
-				if err := statsClient.CreateComment(r.Context(), actualID, userID, userName, req.Content, req.ParentID, authToken); err != nil {
-					http.Error(w, err.Error(), http.StatusBadRequest)
+				err = statsClient.CreateComment(r.Context(), originOf(r), actualID, req.Content, req.ParentID, authToken)
+				var statusErr *client.StatusError
+				if errors.As(err, &statusErr) && statusErr.StatusCode < http.StatusInternalServerError {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(statusErr.StatusCode)
+					w.Write([]byte(statusErr.Body))
+					return
+				}
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadGateway)
 					return
 				}
 
@@ -186,10 +210,12 @@ func main() {
 			return
 		}
 
-		// Try cache first if Redis is available
+		// Try cache first if Redis is available; the same ID is cached per
+		// host and tenant, which the CMS resolves the article in
 		var article map[string]interface{}
 		var err error
-		cacheKey := "article:" + articleID
+		origin := originOf(r)
+		cacheKey := "article:" + origin.Host + ":" + origin.TenantID + ":" + articleID
 
 		if rdb != nil {
 			cached, err := rdb.Get(r.Context(), cacheKey).Result()
@@ -200,7 +226,7 @@ func main() {
 
 		// If not in cache, fetch from CMS service
 		if article == nil {
-			article, err = cmsClient.GetArticle(r.Context(), articleID)
+			article, err = cmsClient.GetArticle(r.Context(), origin, articleID)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
@@ -211,34 +237,150 @@ func main() {
 				data, _ := json.Marshal(article)
 				rdb.Set(r.Context(), cacheKey, data, time.Duration(cacheTTL)*time.Second)
 			}
+		}
+
+		// Record view asynchronously; the CMS ignores crawlers and repeat
+		// views for the unique count
+		go cmsClient.RecordView(context.Background(), origin, articleID)
 
-			// Record view asynchronously
-			go cmsClient.RecordView(context.Background(), articleID)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(article)
+	})
+
+	// Article preview; previews are not cached and do not count as views
+	mux.HandleFunc("/api/v1/preview/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := r.URL.Path[len("/api/v1/preview/"):]
+		if token == "" {
+			http.Error(w, "Preview token required", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "private, no-store")
+		w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+
+		article, err := cmsClient.GetPreview(r.Context(), token)
+		if errors.Is(err, client.ErrPreviewNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(article)
 	})
 
+	// Trending articles of a category
+	mux.HandleFunc("/api/v1/categories/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		categoryID, ok := strings.CutSuffix(r.URL.Path[len("/api/v1/categories/"):], "/trending")
+		if !ok || categoryID == "" || strings.Contains(categoryID, "/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		articles, err := cmsClient.GetCategoryTrending(r.Context(), originOf(r), categoryID, limit)
+		var statusErr *client.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode < http.StatusInternalServerError {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(statusErr.StatusCode)
+			w.Write([]byte(statusErr.Body))
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": articles})
+	})
+
+	// Poll votes; signed-in voters are identified by their token, anonymous
+	// voters by their address
+	mux.HandleFunc("/api/v1/polls/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		pollID, ok := strings.CutSuffix(r.URL.Path[len("/api/v1/polls/"):], "/vote")
+		if !ok || pollID == "" || strings.Contains(pollID, "/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		var req struct {
+			OptionIDs []string `json:"optionIds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// A token that is sent has to be valid
+		authToken := r.Header.Get("Authorization")
+		if authToken != "" {
+			if _, err := tokenValidator.Authenticate(r); err != nil {
+				auth.Unauthorized(w, err)
+				return
+			}
+		}
+
+		err := cmsClient.VoteOnPoll(r.Context(), originOf(r), pollID, req.OptionIDs, authToken)
+		var statusErr *client.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode < http.StatusInternalServerError {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(statusErr.StatusCode)
+			w.Write([]byte(statusErr.Body))
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"message":"Vote recorded"}`))
+	})
+
 	// RSS feed
 	mux.HandleFunc("/api/v1/rss", func(w http.ResponseWriter, r *http.Request) {
-		// Proxy to CMS service
-		resp, err := http.Get(cmsServiceURL + "/api/v1/rss?" + r.URL.RawQuery)
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		feed, err := cmsClient.GetRSSFeed(r.Context(), originOf(r), limit, r.URL.Query().Get("categoryId"))
+		var statusErr *client.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode < http.StatusInternalServerError {
+			http.Error(w, statusErr.Body, statusErr.StatusCode)
+			return
+		}
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		defer resp.Body.Close()
 
 		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
-		w.WriteHeader(resp.StatusCode)
-		io.Copy(w, resp.Body)
+		w.Write(feed)
 	})
 
 	// Start HTTP server
 	server := &http.Server{
 		Addr:         ":" + serverPort,
-		Handler:      mux,
+		Handler:      middleware.ClientIPMiddleware(trustedProxies)(mux),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -269,6 +411,17 @@ func main() {
 	log.Println("Server stopped")
 }
 
+// originOf describes a public request for the calls made to the CMS on its
+// behalf
+func originOf(r *http.Request) client.Origin {
+	return client.Origin{
+		Host:      r.Host,
+		TenantID:  r.Header.Get("X-Tenant-ID"),
+		ClientIP:  middleware.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -4,12 +4,55 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 )
 
+// ErrPreviewNotFound is returned for a preview token that is invalid, expired
+// or revoked
+var ErrPreviewNotFound = errors.New("preview link is invalid or has expired")
+
+// Origin describes the public request a call to the CMS is made for, so the
+// CMS resolves the same tenant and sees the same client
+type Origin struct {
+	Host      string // Host the request was made to, which the CMS maps to a tenant
+	TenantID  string // Tenant selected with the X-Tenant-ID header, if any
+	ClientIP  string // Client address as resolved from trusted proxies
+	UserAgent string
+}
+
+// apply sets the headers that pass the origin on to the CMS. The CMS has to
+// trust this service as a proxy to use the client address.
+func (o Origin) apply(req *http.Request) {
+	if o.Host != "" {
+		req.Host = o.Host
+	}
+	if o.TenantID != "" {
+		req.Header.Set("X-Tenant-ID", o.TenantID)
+	}
+	if o.ClientIP != "" {
+		req.Header.Set("X-Forwarded-For", o.ClientIP)
+	}
+	if o.UserAgent != "" {
+		req.Header.Set("User-Agent", o.UserAgent)
+	}
+}
+
+// StatusError is returned when a service answers a call with an error status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status %d, body: %s", e.StatusCode, e.Body)
+}
+
 // CMSClient handles communication with CMS Service
 type CMSClient struct {
 	baseURL    string
@@ -26,14 +69,16 @@ func NewCMSClient(baseURL string) *CMSClient {
 	}
 }
 
-// GetArticle fetches an article from CMS service
-func (c *CMSClient) GetArticle(ctx context.Context, articleID string) (map[string]interface{}, error) {
-	url := fmt.Sprintf("%s/api/v1/public/articles/%s", c.baseURL, articleID)
+// GetArticle fetches a published article of the origin's tenant from CMS
+// service
+func (c *CMSClient) GetArticle(ctx context.Context, origin Origin, articleID string) (map[string]interface{}, error) {
+	articleURL := fmt.Sprintf("%s/api/v1/public/articles/%s", c.baseURL, url.PathEscape(articleID))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, articleURL, nil)
 	if err != nil {
 		return nil, err
 	}
+	origin.apply(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -53,19 +98,52 @@ func (c *CMSClient) GetArticle(ctx context.Context, articleID string) (map[strin
 	return article, nil
 }
 
-// ListArticles fetches list of articles from CMS service
-func (c *CMSClient) ListArticles(ctx context.Context, page, limit int, filters map[string]string) ([]map[string]interface{}, int64, error) {
-	url := fmt.Sprintf("%s/api/v1/public/articles?page=%d&limit=%d", c.baseURL, page, limit)
+// GetPreview fetches the article version a preview token was minted for
+func (c *CMSClient) GetPreview(ctx context.Context, token string) (map[string]interface{}, error) {
+	previewURL := fmt.Sprintf("%s/api/v1/public/preview/%s", c.baseURL, url.PathEscape(token))
 
-	// Add filters to query
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, previewURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrPreviewNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get preview: status %d", resp.StatusCode)
+	}
+
+	var article map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&article); err != nil {
+		return nil, err
+	}
+
+	return article, nil
+}
+
+// ListArticles fetches a list of published articles of the origin's tenant
+// from CMS service
+func (c *CMSClient) ListArticles(ctx context.Context, origin Origin, page, limit int, filters map[string]string) ([]map[string]interface{}, int64, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("limit", strconv.Itoa(limit))
 	for k, v := range filters {
-		url += fmt.Sprintf("&%s=%s", k, v)
+		query.Set(k, v)
 	}
+	listURL := fmt.Sprintf("%s/api/v1/public/articles?%s", c.baseURL, query.Encode())
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL, nil)
 	if err != nil {
 		return nil, 0, err
 	}
+	origin.apply(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -78,25 +156,100 @@ func (c *CMSClient) ListArticles(ctx context.Context, page, limit int, filters m
 	}
 
 	var result struct {
-		Articles []map[string]interface{} `json:"articles"`
-		Total    int64                    `json:"total"`
+		Data  []map[string]interface{} `json:"data"`
+		Total int64                    `json:"total"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, 0, err
 	}
 
-	return result.Articles, result.Total, nil
+	return result.Data, result.Total, nil
 }
 
-// RecordView records a view on an article
-func (c *CMSClient) RecordView(ctx context.Context, articleID string) error {
-	url := fmt.Sprintf("%s/api/v1/public/articles/%s/view", c.baseURL, articleID)
+// GetCategoryTrending fetches the trending articles of a category of the
+// origin's tenant. A limit of 0 leaves the number of articles to the CMS.
+func (c *CMSClient) GetCategoryTrending(ctx context.Context, origin Origin, categoryID string, limit int) ([]map[string]interface{}, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	trendingURL := fmt.Sprintf("%s/api/v1/public/categories/%s/trending?%s", c.baseURL, url.PathEscape(categoryID), query.Encode())
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, trendingURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	origin.apply(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var result struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// GetRSSFeed fetches the RSS feed of the origin's tenant, optionally limited
+// to a category. A limit of 0 leaves the size of the feed to the CMS.
+func (c *CMSClient) GetRSSFeed(ctx context.Context, origin Origin, limit int, categoryID string) ([]byte, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if categoryID != "" {
+		query.Set("categoryId", categoryID)
+	}
+	feedURL := fmt.Sprintf("%s/api/v1/rss?%s", c.baseURL, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	origin.apply(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	return body, nil
+}
+
+// RecordView records a view on an article. The client address and user agent
+// of the origin identify the visitor, so the CMS can count unique views and
+// ignore crawlers.
+func (c *CMSClient) RecordView(ctx context.Context, origin Origin, articleID string) error {
+	viewURL := fmt.Sprintf("%s/api/v1/public/articles/%s/view", c.baseURL, url.PathEscape(articleID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, viewURL, nil)
 	if err != nil {
 		return err
 	}
 
+	origin.apply(req)
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return err
@@ -110,6 +263,45 @@ func (c *CMSClient) RecordView(ctx context.Context, articleID string) error {
 	return nil
 }
 
+// VoteOnPoll votes on a poll. Signed-in voters send their token; anonymous
+// voters are told apart by the client address of the origin.
+func (c *CMSClient) VoteOnPoll(ctx context.Context, origin Origin, pollID string, optionIDs []string, authToken string) error {
+	voteURL := fmt.Sprintf("%s/api/v1/polls/%s/vote", c.baseURL, url.PathEscape(pollID))
+
+	payload := map[string]interface{}{
+		"optionIds": optionIDs,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, voteURL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if authToken != "" {
+		req.Header.Set("Authorization", authToken)
+	}
+	origin.apply(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	return nil
+}
+
 // StatsClient handles communication with Stats Service
 type StatsClient struct {
 	baseURL    string
@@ -157,8 +349,9 @@ func (c *StatsClient) GetComments(ctx context.Context, articleID string, page, l
 	return result.Comments, result.Total, nil
 }
 
-// CreateComment posts a comment
-func (c *StatsClient) CreateComment(ctx context.Context, articleID, userID, userName, content string, parentID *string, authToken string) error {
+// CreateComment posts a comment. The origin is passed on so the stats service
+// can have the comment screened by the CMS for the right tenant.
+func (c *StatsClient) CreateComment(ctx context.Context, origin Origin, articleID, content string, parentID *string, authToken string) error {
 	url := fmt.Sprintf("%s/api/v1/articles/%s/comments", c.baseURL, articleID)
 
 	payload := map[string]interface{}{
@@ -179,7 +372,8 @@ func (c *StatsClient) CreateComment(ctx context.Context, articleID, userID, user
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+authToken)
+	req.Header.Set("Authorization", authToken)
+	origin.apply(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -189,7 +383,7 @@ func (c *StatsClient) CreateComment(ctx context.Context, articleID, userID, user
 
 	if resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to create comment: status %d, body: %s", resp.StatusCode, string(body))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return nil
@@ -242,38 +436,3 @@ func (c *StatsClient) AddFavorite(ctx context.Context, articleID, authToken stri
 
 	return nil
 }
-
-// VoteOnPoll votes on a poll
-func (c *StatsClient) VoteOnPoll(ctx context.Context, pollID string, optionIDs []string, authToken string) error {
-	url := fmt.Sprintf("%s/api/v1/polls/%s/vote", c.baseURL, pollID)
-
-	payload := map[string]interface{}{
-		"optionIds": optionIDs,
-	}
-
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+authToken)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to vote: status %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	return nil
-}
@@ -6,12 +6,22 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/vhvplatform/go-cms-service/pkg/auth"
+	"github.com/vhvplatform/go-cms-service/pkg/config"
 	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/handler"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/inspect"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/repository"
 	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/service"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/storage"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/transform"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/worker"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -23,12 +33,37 @@ func main() {
 	serverPort := getEnv("SERVER_PORT", "8083")
 	uploadDir := getEnv("UPLOAD_DIR", "./uploads")
 	baseURL := getEnv("BASE_URL", "http://localhost:"+serverPort)
+	storageDriver := getEnv("STORAGE_DRIVER", "local")
+	publicURL := getEnv("STORAGE_PUBLIC_URL", "")
+	workDir := getEnv("WORK_DIR", os.TempDir())
+	uploadExpiry := config.GetEnvDuration("UPLOAD_EXPIRY", 24*time.Hour)
+	uploadExpiryInterval := config.GetEnvDuration("UPLOAD_EXPIRY_INTERVAL", 15*time.Minute)
+	defaultQuota := int64(config.GetEnvInt("TENANT_STORAGE_QUOTA", 0))
+	clamdAddress := getEnv("CLAMD_ADDRESS", "")
+	archiveLimits := inspect.ArchiveLimits{
+		MaxEntries: config.GetEnvInt("ARCHIVE_MAX_ENTRIES", 10000),
+		MaxSize:    int64(config.GetEnvInt("ARCHIVE_MAX_SIZE", 1<<30)),
+		MaxRatio:   float64(config.GetEnvInt("ARCHIVE_MAX_RATIO", 100)),
+	}
+	jobWorkers := map[model.JobType]int{
+		model.JobTypeImage:    config.GetEnvInt("JOB_WORKERS_IMAGE", 4),
+		model.JobTypeVideo:    config.GetEnvInt("JOB_WORKERS_VIDEO", 1),
+		model.JobTypeDocument: config.GetEnvInt("JOB_WORKERS_DOCUMENT", 2),
+	}
+	jobAttempts := config.GetEnvInt("JOB_MAX_ATTEMPTS", 5)
+	jobRetryDelay := config.GetEnvDuration("JOB_RETRY_DELAY", 30*time.Second)
+	jobLease := config.GetEnvDuration("JOB_LEASE", 2*time.Minute)
+	jobPollInterval := config.GetEnvDuration("JOB_POLL_INTERVAL", 2*time.Second)
+	imageURLSecret := getEnv("IMAGE_URL_SECRET", "")
+	imageCacheDir := getEnv("IMAGE_CACHE_DIR", filepath.Join(workDir, "image-cache"))
+	imageCacheMaxSize := int64(config.GetEnvInt("IMAGE_CACHE_MAX_SIZE", 1<<30))
+	imageTransformConcurrency := config.GetEnvInt("IMAGE_TRANSFORM_CONCURRENCY", runtime.NumCPU())
 
 	log.Println("Starting CMS Media Service...")
 	log.Printf("MongoDB URI: %s", mongoURI)
 	log.Printf("Database: %s", dbName)
 	log.Printf("Server Port: %s", serverPort)
-	log.Printf("Upload Directory: %s", uploadDir)
+	log.Printf("Storage: %s", storageDriver)
 
 	// Connect to MongoDB
 	ctx := context.Background()
@@ -46,19 +81,84 @@ func main() {
 
 	db := client.Database(dbName)
 
-	// Create upload directory if not exists
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		log.Fatalf("Failed to create upload directory: %v", err)
+	// Initialize storage
+	var store storage.Storage
+	switch storageDriver {
+	case "local":
+		log.Printf("Upload Directory: %s", uploadDir)
+		store, err = storage.NewLocalStorage(uploadDir, baseURL+"/uploads")
+	case "s3":
+		store, err = storage.NewS3Storage(storage.S3Config{
+			Endpoint:        getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:          getEnv("S3_REGION", "us-east-1"),
+			Bucket:          getEnv("S3_BUCKET", ""),
+			AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+			SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+			PathStyle:       config.GetEnvBool("S3_FORCE_PATH_STYLE", false),
+		})
+	default:
+		log.Fatalf("Unknown storage driver %q, must be local or s3", storageDriver)
+	}
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		log.Fatalf("Failed to create work directory: %v", err)
+	}
+
+	// Initialize the malware scanner
+	var scanner inspect.Scanner
+	if clamdAddress != "" {
+		clamd, err := inspect.NewClamdScanner(clamdAddress, config.GetEnvDuration("CLAMD_TIMEOUT", time.Minute))
+		if err != nil {
+			log.Fatalf("Failed to initialize malware scanner: %v", err)
+		}
+		if err := clamd.Ping(ctx); err != nil {
+			log.Printf("Warning: clamd is not answering, uploads will fail until it does: %v", err)
+		} else {
+			log.Println("✓ Connected to clamd")
+		}
+		scanner = clamd
+	}
+
+	// Initialize on-demand image transforms, which need a secret shared by
+	// every instance to sign their URLs
+	var imageSigner *transform.Signer
+	if imageURLSecret != "" {
+		imageSigner = transform.NewSigner(imageURLSecret)
+	} else {
+		log.Println("Warning: IMAGE_URL_SECRET is not set, on-demand image transforms are disabled")
+	}
+	imageCache, err := transform.NewCache(imageCacheDir, imageCacheMaxSize)
+	if err != nil {
+		log.Fatalf("Failed to create image cache: %v", err)
 	}
 
 	// Initialize repositories
 	mediaRepo := repository.NewMediaRepository(db)
+	uploadRepo := repository.NewUploadRepository(db)
+	if err := uploadRepo.CreateIndexes(ctx); err != nil {
+		log.Printf("Failed to create resumable upload indexes: %v", err)
+	}
+	jobRepo := repository.NewJobRepository(db)
+	if err := jobRepo.CreateIndexes(ctx); err != nil {
+		log.Printf("Failed to create processing job indexes: %v", err)
+	}
 
 	// Initialize services
-	mediaService := service.NewMediaService(mediaRepo, uploadDir, baseURL)
+	mediaService := service.NewMediaService(mediaRepo, uploadRepo, jobRepo, store, workDir, baseURL, publicURL, uploadExpiry, defaultQuota, scanner, archiveLimits, jobAttempts, jobRetryDelay)
+	imageService := service.NewImageService(mediaRepo, store, imageSigner, imageCache, workDir, baseURL, imageTransformConcurrency)
 
 	// Initialize handlers
 	mediaHandler := handler.NewMediaHandler(mediaService)
+	imageHandler := handler.NewImageHandler(imageService)
+
+	// Initialize token validator
+	tokenValidator, err := auth.NewValidator(config.NewJWTConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize token validator: %v", err)
+	}
+	authenticate := auth.Middleware(tokenValidator)
 
 	// Setup router
 	mux := http.NewServeMux()
@@ -70,42 +170,94 @@ func main() {
 	})
 
 	// Media routes
-	mux.HandleFunc("/api/v1/media/upload", func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/api/v1/media/upload", authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
 			mediaHandler.UploadFile(w, r)
 		} else {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	})
+	})))
+
+	// Resumable uploads (tus). OPTIONS is protocol discovery, which clients
+	// may do before they have a token.
+	uploads := authenticate(http.HandlerFunc(mediaHandler.ServeUpload))
+	serveUploads := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			mediaHandler.ServeUpload(w, r)
+			return
+		}
+		uploads.ServeHTTP(w, r)
+	}
+	mux.HandleFunc("/api/v1/media/uploads", serveUploads)
+	mux.HandleFunc("/api/v1/media/uploads/", serveUploads)
 
-	mux.HandleFunc("/api/v1/media/files", func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/api/v1/media/files", authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
 			mediaHandler.ListFiles(w, r)
 		} else {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	})
+	})))
 
-	mux.HandleFunc("/api/v1/media/", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
+	mux.Handle("/api/v1/media/", authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/download-url"):
+			mediaHandler.GetDownloadURL(w, r)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/image-url"):
+			imageHandler.GetImageURL(w, r)
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/focal-point"):
+			mediaHandler.SetFocalPoint(w, r)
+		case r.Method == http.MethodGet:
 			mediaHandler.GetFile(w, r)
-		case http.MethodDelete:
+		case r.Method == http.MethodDelete:
 			mediaHandler.DeleteFile(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	})
+	})))
+
+	mux.Handle("/api/v1/media/image-presets", authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			mediaHandler.GetImagePresets(w, r)
+		case http.MethodPut:
+			mediaHandler.SaveImagePresets(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
 
-	mux.HandleFunc("/api/v1/media/storage/", func(w http.ResponseWriter, r *http.Request) {
+	// Processing jobs
+	mux.Handle("/api/v1/media/jobs", authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			mediaHandler.ListJobs(w, r)
+		} else {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/api/v1/media/jobs/", authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/retry"):
+			mediaHandler.RetryJob(w, r)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/cancel"):
+			mediaHandler.CancelJob(w, r)
+		case r.Method == http.MethodGet:
+			mediaHandler.GetJob(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/api/v1/media/storage/", authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
 			mediaHandler.GetStorageUsage(w, r)
 		} else {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	})
+	})))
 
-	mux.HandleFunc("/api/v1/media/folders", func(w http.ResponseWriter, r *http.Request) {
+	mux.Handle("/api/v1/media/folders", authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			mediaHandler.ListFolders(w, r)
@@ -114,11 +266,14 @@ func main() {
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	})
+	})))
+
+	// On-demand image transforms; their URLs are signed, so they need no
+	// token and can be used in img tags
+	mux.HandleFunc("/img/", imageHandler.ServeImage)
 
-	// Serve uploaded files
-	fs := http.FileServer(http.Dir(uploadDir))
-	mux.Handle("/uploads/", http.StripPrefix("/uploads/", fs))
+	// Serve stored files, whichever store holds them
+	mux.Handle("/uploads/", http.StripPrefix("/uploads/", storage.FileServer(store)))
 
 	// Start HTTP server
 	server := &http.Server{
@@ -129,6 +284,15 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Start background workers
+	uploadExpiryWorker := worker.NewUploadExpiryWorker(mediaService, uploadExpiryInterval)
+	go uploadExpiryWorker.Start(ctx)
+	defer uploadExpiryWorker.Stop()
+
+	processingWorker := worker.NewProcessingWorker(mediaService, jobWorkers, jobPollInterval, jobLease)
+	go processingWorker.Start(ctx)
+	defer processingWorker.Stop()
+
 	// Graceful shutdown
 	go func() {
 		log.Printf("CMS Media Service starting on port %s", serverPort)
@@ -1,18 +1,23 @@
 module github.com/vhvplatform/go-cms-service/services/cms-media-service
 
-go 1.21
+go 1.24.11
 
-require go.mongodb.org/mongo-driver v1.17.6
+require (
+	github.com/vhvplatform/go-cms-service v0.0.0-00010101000000-000000000000
+	go.mongodb.org/mongo-driver v1.17.6
+)
 
 require (
-	github.com/golang/snappy v0.0.4 // indirect
-	github.com/klauspost/compress v1.16.7 // indirect
+	github.com/golang/snappy v1.0.0 // indirect
+	github.com/klauspost/compress v1.18.2 // indirect
 	github.com/montanaflynn/stats v0.7.1 // indirect
 	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
-	github.com/xdg-go/scram v1.1.2 // indirect
+	github.com/xdg-go/scram v1.2.0 // indirect
 	github.com/xdg-go/stringprep v1.0.4 // indirect
 	github.com/youmark/pkcs8 v0.0.0-20240726163527-a2c0da244d78 // indirect
-	golang.org/x/crypto v0.26.0 // indirect
-	golang.org/x/sync v0.8.0 // indirect
-	golang.org/x/text v0.17.0 // indirect
+	golang.org/x/crypto v0.46.0 // indirect
+	golang.org/x/sync v0.19.0 // indirect
+	golang.org/x/text v0.32.0 // indirect
 )
+
+replace github.com/vhvplatform/go-cms-service => ../..
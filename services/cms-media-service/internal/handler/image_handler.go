@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/processor"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/service"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/transform"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ImageHandler handles HTTP requests for on-demand image transforms
+type ImageHandler struct {
+	service *service.ImageService
+}
+
+// NewImageHandler creates a new image handler
+func NewImageHandler(service *service.ImageService) *ImageHandler {
+	return &ImageHandler{
+		service: service,
+	}
+}
+
+// ServeImage handles GET /img/{id}?w=&h=&fit=&fmt=&q=&s=. The URL is
+// signed, so it needs no token and can be used in img tags.
+func (h *ImageHandler) ServeImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	options, err := transform.ParseOptions(r.URL.Query())
+	if err != nil {
+		respondImageError(w, err)
+		return
+	}
+
+	image, err := h.service.Transform(r.Context(), getIDFromPath(r.URL.Path), options, r.URL.Query().Get("s"), r.Header.Get("Accept"))
+	if err != nil {
+		respondImageError(w, err)
+		return
+	}
+	defer image.File.Close()
+
+	w.Header().Set("Content-Type", image.MimeType)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("ETag", `"`+image.Name+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if options.Format == "auto" {
+		w.Header().Set("Vary", "Accept")
+	}
+	http.ServeContent(w, r, image.Name, image.ModTime, image.File)
+}
+
+// GetImageURL handles GET /api/v1/media/{id}/image-url?w=&h=&fit=&fmt=&q=
+func (h *ImageHandler) GetImageURL(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := primitive.ObjectIDFromHex(getTenantID(r))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(getIDFromPath(strings.TrimSuffix(r.URL.Path, "/image-url")))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid file ID")
+		return
+	}
+
+	options, err := transform.ParseOptions(r.URL.Query())
+	if err != nil {
+		respondImageError(w, err)
+		return
+	}
+
+	url, err := h.service.TransformURL(r.Context(), tenantID, id, options)
+	if err != nil {
+		respondImageError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"url": url})
+}
+
+// GetImagePresets handles GET /api/v1/media/image-presets
+func (h *MediaHandler) GetImagePresets(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := primitive.ObjectIDFromHex(getTenantID(r))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
+		return
+	}
+
+	config, err := h.service.GetImagePresets(r.Context(), tenantID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, config)
+}
+
+// SaveImagePresets handles PUT /api/v1/media/image-presets
+func (h *MediaHandler) SaveImagePresets(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := primitive.ObjectIDFromHex(getTenantID(r))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
+		return
+	}
+
+	var request struct {
+		Presets []model.ImagePreset `json:"presets"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	config, err := h.service.SaveImagePresets(r.Context(), tenantID, request.Presets)
+	if err != nil {
+		respondImageError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, config)
+}
+
+// SetFocalPoint handles PUT /api/v1/media/{id}/focal-point
+func (h *MediaHandler) SetFocalPoint(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := primitive.ObjectIDFromHex(getTenantID(r))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(getIDFromPath(strings.TrimSuffix(r.URL.Path, "/focal-point")))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid file ID")
+		return
+	}
+
+	var focalPoint model.FocalPoint
+	if err := json.NewDecoder(r.Body).Decode(&focalPoint); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID, role := getUser(r)
+	file, err := h.service.SetFocalPoint(r.Context(), tenantID, id, userID, role, focalPoint)
+	if err != nil {
+		respondImageError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, file)
+}
+
+// respondImageError responds with the status that matches an image error
+func respondImageError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, service.ErrTransformsDisabled):
+		respondError(w, http.StatusNotFound, "Image not found")
+	case errors.Is(err, transform.ErrInvalidOptions), errors.Is(err, service.ErrInvalidPresets),
+		errors.Is(err, service.ErrInvalidFocalPoint):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidSignature), errors.Is(err, service.ErrPermissionDenied):
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrNotAnImage):
+		respondError(w, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, processor.ErrNoImageTool):
+		respondError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
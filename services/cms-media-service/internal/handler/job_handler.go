@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ListJobs handles GET /api/v1/media/jobs
+func (h *MediaHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := primitive.ObjectIDFromHex(getTenantID(r))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := model.JobFilter{
+		Status: model.JobStatus(query.Get("status")),
+		Type:   model.JobType(query.Get("type")),
+	}
+	if fileID := query.Get("fileId"); fileID != "" {
+		id, err := primitive.ObjectIDFromHex(fileID)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid file ID")
+			return
+		}
+		filter.FileID = &id
+	}
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	jobs, total, err := h.service.ListJobs(r.Context(), tenantID, filter, page, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := map[string]interface{}{
+		"jobs":  jobs,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// GetJob handles GET /api/v1/media/jobs/{id}
+func (h *MediaHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := jobIDs(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.GetJob(r.Context(), tenantID, id)
+	if err != nil {
+		respondJobError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, job)
+}
+
+// RetryJob handles POST /api/v1/media/jobs/{id}/retry
+func (h *MediaHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := jobIDs(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.RetryJob(r.Context(), tenantID, id)
+	if err != nil {
+		respondJobError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, job)
+}
+
+// CancelJob handles POST /api/v1/media/jobs/{id}/cancel
+func (h *MediaHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := jobIDs(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.CancelJob(r.Context(), tenantID, id)
+	if err != nil {
+		respondJobError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, job)
+}
+
+// jobIDs parses the tenant and the job of a request to
+// /api/v1/media/jobs/{id}[/action], responding with an error if either is
+// invalid
+func jobIDs(w http.ResponseWriter, r *http.Request) (primitive.ObjectID, primitive.ObjectID, bool) {
+	tenantID, err := primitive.ObjectIDFromHex(getTenantID(r))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
+		return tenantID, primitive.NilObjectID, false
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/api/v1/media/jobs/")
+	id, err := primitive.ObjectIDFromHex(strings.Split(rest, "/")[0])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid job ID")
+		return tenantID, id, false
+	}
+	return tenantID, id, true
+}
+
+// respondJobError responds with the status that matches a job error
+func respondJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		respondError(w, http.StatusNotFound, "Job not found")
+	case errors.Is(err, repository.ErrConflict):
+		respondError(w, http.StatusConflict, "Job is not in a state that allows this")
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
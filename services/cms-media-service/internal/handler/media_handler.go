@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/vhvplatform/go-cms-service/pkg/auth"
 	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/service"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -38,8 +40,8 @@ func (h *MediaHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	// Get tenant ID from context/header
-	tenantIDStr := r.Header.Get("X-Tenant-ID")
+	// Get tenant ID from the token, falling back to the header
+	tenantIDStr := getTenantID(r)
 	tenantID, err := primitive.ObjectIDFromHex(tenantIDStr)
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
@@ -47,10 +49,7 @@ func (h *MediaHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user info
-	userID := r.Header.Get("X-User-ID")
-	if userID == "" {
-		userID = "system"
-	}
+	userID, _ := getUser(r)
 
 	folder := r.FormValue("folder")
 	if folder == "" {
@@ -69,7 +68,7 @@ func (h *MediaHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 		r.UserAgent(),
 	)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondUploadError(w, err)
 		return
 	}
 
@@ -94,6 +93,34 @@ func (h *MediaHandler) GetFile(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, file)
 }
 
+// GetDownloadURL handles GET /api/v1/media/{id}/download-url
+func (h *MediaHandler) GetDownloadURL(w http.ResponseWriter, r *http.Request) {
+	idStr := getIDFromPath(strings.TrimSuffix(r.URL.Path, "/download-url"))
+	id, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid file ID")
+		return
+	}
+
+	// Expiry in seconds, at most 7 days
+	expires, _ := strconv.Atoi(r.URL.Query().Get("expires"))
+	if expires < 1 || expires > 7*24*60*60 {
+		expires = 60 * 60
+	}
+	expiry := time.Duration(expires) * time.Second
+
+	url, err := h.service.GetDownloadURL(r.Context(), id, expiry)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "File not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"url":       url,
+		"expiresAt": time.Now().Add(expiry),
+	})
+}
+
 // ListFiles handles GET /api/v1/media/files
 func (h *MediaHandler) ListFiles(w http.ResponseWriter, r *http.Request) {
 	tenantIDStr := r.URL.Query().Get("tenantId")
@@ -143,8 +170,7 @@ func (h *MediaHandler) DeleteFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID := r.Header.Get("X-User-ID")
-	role := r.Header.Get("X-User-Role")
+	userID, role := getUser(r)
 
 	if err := h.service.DeleteFile(r.Context(), id, userID, role); err != nil {
 		respondError(w, http.StatusForbidden, err.Error())
@@ -217,6 +243,23 @@ func respondError(w http.ResponseWriter, status int, message string) {
 	respondJSON(w, status, map[string]string{"error": message})
 }
 
+// getUser returns the user ID and role of the authenticated principal
+func getUser(r *http.Request) (string, string) {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		return principal.UserID, principal.Role
+	}
+	return "", ""
+}
+
+// getTenantID returns the tenant of the authenticated principal, or the
+// X-Tenant-ID header for tokens that carry no tenant claim
+func getTenantID(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.TenantID != "" {
+		return principal.TenantID
+	}
+	return r.Header.Get("X-Tenant-ID")
+}
+
 func getIDFromPath(path string) string {
 	parts := strings.Split(path, "/")
 	if len(parts) > 0 {
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// tus protocol constants, see https://tus.io/protocols/resumable-upload
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination,expiration"
+	tusChunkType  = "application/offset+octet-stream"
+	uploadsPath   = "/api/v1/media/uploads"
+)
+
+// ServeUpload serves resumable uploads with the tus 1.0 protocol:
+//
+//	OPTIONS /api/v1/media/uploads       protocol discovery
+//	POST    /api/v1/media/uploads       create an upload
+//	HEAD    /api/v1/media/uploads/{id}  get the upload offset
+//	PATCH   /api/v1/media/uploads/{id}  append a chunk
+//	DELETE  /api/v1/media/uploads/{id}  terminate an upload
+func (h *MediaHandler) ServeUpload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+
+	method := r.Method
+	if override := r.Header.Get("X-HTTP-Method-Override"); override != "" && method == http.MethodPost {
+		method = strings.ToUpper(override)
+	}
+
+	if method == http.MethodOptions {
+		w.Header().Set("Tus-Version", tusVersion)
+		w.Header().Set("Tus-Extension", tusExtensions)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		respondError(w, http.StatusPreconditionFailed, "Unsupported tus version")
+		return
+	}
+
+	isCollection := strings.TrimSuffix(r.URL.Path, "/") == uploadsPath
+	switch {
+	case isCollection && method == http.MethodPost:
+		h.CreateUpload(w, r)
+	case !isCollection && method == http.MethodHead:
+		h.GetUploadOffset(w, r)
+	case !isCollection && method == http.MethodPatch:
+		h.WriteUploadChunk(w, r)
+	case !isCollection && method == http.MethodDelete:
+		h.TerminateUpload(w, r)
+	default:
+		respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// CreateUpload handles POST /api/v1/media/uploads
+func (h *MediaHandler) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := primitive.ObjectIDFromHex(getTenantID(r))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
+		return
+	}
+
+	if r.Header.Get("Upload-Defer-Length") != "" {
+		respondError(w, http.StatusBadRequest, "Upload-Defer-Length is not supported")
+		return
+	}
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		respondError(w, http.StatusBadRequest, "Invalid Upload-Length")
+		return
+	}
+
+	metadata, err := parseUploadMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid Upload-Metadata")
+		return
+	}
+
+	userID, _ := getUser(r)
+	upload := &model.ResumableUpload{
+		TenantID:   tenantID,
+		UploadedBy: userID,
+		FileName:   firstNonEmpty(metadata["filename"], metadata["name"]),
+		MimeType:   firstNonEmpty(metadata["filetype"], metadata["type"]),
+		Folder:     metadata["folder"],
+		Length:     length,
+		Metadata:   metadata,
+		IPAddress:  r.RemoteAddr,
+		UserAgent:  r.UserAgent(),
+	}
+	if upload.FileName == "" {
+		respondError(w, http.StatusBadRequest, "Upload-Metadata must contain a filename")
+		return
+	}
+
+	if err := h.service.CreateUpload(r.Context(), upload); err != nil {
+		respondUploadError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", uploadsPath+"/"+upload.ID.Hex())
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// GetUploadOffset handles HEAD /api/v1/media/uploads/{id}
+func (h *MediaHandler) GetUploadOffset(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := uploadIDs(w, r)
+	if !ok {
+		return
+	}
+
+	upload, err := h.service.GetUpload(r.Context(), tenantID, id)
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	setUploadHeaders(w, upload)
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+// WriteUploadChunk handles PATCH /api/v1/media/uploads/{id}
+func (h *MediaHandler) WriteUploadChunk(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := uploadIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != tusChunkType {
+		respondError(w, http.StatusUnsupportedMediaType, "Content-Type must be "+tusChunkType)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		respondError(w, http.StatusBadRequest, "Invalid Upload-Offset")
+		return
+	}
+
+	// Chunks may take longer than the server's timeouts allow a request
+	controller := http.NewResponseController(w)
+	controller.SetReadDeadline(time.Time{})
+	controller.SetWriteDeadline(time.Time{})
+
+	upload, _, err := h.service.WriteUploadChunk(r.Context(), tenantID, id, offset, r.Body)
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+
+	setUploadHeaders(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TerminateUpload handles DELETE /api/v1/media/uploads/{id}
+func (h *MediaHandler) TerminateUpload(w http.ResponseWriter, r *http.Request) {
+	tenantID, id, ok := uploadIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.TerminateUpload(r.Context(), tenantID, id); err != nil {
+		respondUploadError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// uploadIDs parses the tenant and upload IDs of a request, responding with
+// an error if either is invalid
+func uploadIDs(w http.ResponseWriter, r *http.Request) (primitive.ObjectID, primitive.ObjectID, bool) {
+	tenantID, err := primitive.ObjectIDFromHex(getTenantID(r))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
+		return tenantID, primitive.NilObjectID, false
+	}
+	id, err := primitive.ObjectIDFromHex(getIDFromPath(r.URL.Path))
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Upload not found")
+		return tenantID, id, false
+	}
+	return tenantID, id, true
+}
+
+// setUploadHeaders sets the offset and expiry of an upload, and the media
+// file it was assembled into once it is complete
+func setUploadHeaders(w http.ResponseWriter, upload *model.ResumableUpload) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	if upload.FileID != nil {
+		w.Header().Set("Media-File-Id", upload.FileID.Hex())
+	}
+}
+
+// respondUploadError responds with the status matching an upload error
+func respondUploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		respondError(w, http.StatusNotFound, "Upload not found")
+	case errors.Is(err, service.ErrOffsetMismatch):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrFileTooLarge), errors.Is(err, service.ErrQuotaExceeded):
+		respondError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, service.ErrFileTypeNotAllowed), errors.Is(err, service.ErrContentMismatch):
+		respondError(w, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, service.ErrInvalidContent), errors.Is(err, service.ErrUnsafeArchive),
+		errors.Is(err, service.ErrMalwareDetected):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, service.ErrScanFailed):
+		respondError(w, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, service.ErrUploadBusy):
+		respondError(w, http.StatusLocked, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// parseUploadMetadata parses an Upload-Metadata header, a comma separated
+// list of keys each followed by an optional base64 encoded value
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
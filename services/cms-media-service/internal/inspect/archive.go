@@ -0,0 +1,191 @@
+package inspect
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrUnsafeArchive is returned for archives that would overwhelm or escape
+// whoever extracts them
+var ErrUnsafeArchive = errors.New("unsafe archive")
+
+// ArchiveLimits bounds what an archive may expand to
+type ArchiveLimits struct {
+	MaxEntries int     // files and directories in the archive
+	MaxSize    int64   // bytes of all files once extracted
+	MaxRatio   float64 // extracted size per compressed byte
+}
+
+// ratioThreshold is the extracted size below which the ratio is not
+// checked, as small files of repeated content compress very well
+const ratioThreshold = 1 << 20
+
+// Archive reports whether files of a type are archives CheckArchive can
+// inspect. Office Open XML, OpenDocument and EPUB packages are zip archives.
+func Archive(mimeType string) bool {
+	switch mimeType {
+	case "application/zip", "application/epub+zip", "application/x-gzip", "application/x-bzip2", "application/x-tar":
+		return true
+	}
+	return strings.HasPrefix(mimeType, "application/vnd.openxmlformats-officedocument.") ||
+		strings.HasPrefix(mimeType, "application/vnd.oasis.opendocument.")
+}
+
+// CheckArchive extracts an archive without writing it anywhere, and returns
+// ErrUnsafeArchive if it has more entries, expands to more bytes or
+// compresses better than the limits allow, or if any entry is a link or
+// has a path that leaves the directory it is extracted to. The sizes an
+// archive declares are not trusted; every entry is decompressed.
+func CheckArchive(r io.ReaderAt, size int64, mimeType string, limits ArchiveLimits) error {
+	switch mimeType {
+	case "application/x-gzip":
+		reader, err := gzip.NewReader(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnsafeArchive, err)
+		}
+		return checkStream(reader, size, limits)
+	case "application/x-bzip2":
+		return checkStream(bzip2.NewReader(io.NewSectionReader(r, 0, size)), size, limits)
+	case "application/x-tar":
+		return checkTar(io.NewSectionReader(r, 0, size), size, limits)
+	default:
+		return checkZip(r, size, limits)
+	}
+}
+
+// archiveCounter counts what an archive expands to against the limits
+type archiveCounter struct {
+	limits     ArchiveLimits
+	compressed int64
+	entries    int
+	size       int64
+}
+
+func (c *archiveCounter) entry(name string) error {
+	c.entries++
+	if c.limits.MaxEntries > 0 && c.entries > c.limits.MaxEntries {
+		return fmt.Errorf("%w: more than %d entries", ErrUnsafeArchive, c.limits.MaxEntries)
+	}
+	if !safePath(name) {
+		return fmt.Errorf("%w: entry %q leaves the extraction directory", ErrUnsafeArchive, name)
+	}
+	return nil
+}
+
+// extract reads r, which is one entry or a whole decompressed stream,
+// counting its bytes
+func (c *archiveCounter) extract(r io.Reader) error {
+	remaining := int64(1<<62) - c.size
+	if c.limits.MaxSize > 0 {
+		remaining = c.limits.MaxSize - c.size
+	}
+	n, err := io.Copy(io.Discard, io.LimitReader(r, remaining+1))
+	c.size += n
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsafeArchive, err)
+	}
+	if n > remaining {
+		return fmt.Errorf("%w: expands to more than %d bytes", ErrUnsafeArchive, c.limits.MaxSize)
+	}
+	return c.checkRatio()
+}
+
+func (c *archiveCounter) checkRatio() error {
+	if c.limits.MaxRatio <= 0 || c.size < ratioThreshold || c.compressed <= 0 {
+		return nil
+	}
+	if ratio := float64(c.size) / float64(c.compressed); ratio > c.limits.MaxRatio {
+		return fmt.Errorf("%w: compression ratio %.0f exceeds %.0f", ErrUnsafeArchive, ratio, c.limits.MaxRatio)
+	}
+	return nil
+}
+
+func checkZip(r io.ReaderAt, size int64, limits ArchiveLimits) error {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsafeArchive, err)
+	}
+	counter := &archiveCounter{limits: limits, compressed: size}
+	for _, file := range archive.File {
+		if err := counter.entry(file.Name); err != nil {
+			return err
+		}
+		mode := file.Mode()
+		if !mode.IsRegular() && !mode.IsDir() { // links, devices and pipes
+			return fmt.Errorf("%w: entry %q is not a regular file", ErrUnsafeArchive, file.Name)
+		}
+		if mode.IsDir() {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnsafeArchive, err)
+		}
+		err = counter.extract(reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStream checks a decompressed stream, which is checked as a tar
+// archive if it is one
+func checkStream(r io.Reader, compressed int64, limits ArchiveLimits) error {
+	buffered := bufio.NewReaderSize(r, 512)
+	head, _ := buffered.Peek(512)
+	if len(head) >= 262 && string(head[257:262]) == "ustar" {
+		return checkTar(buffered, compressed, limits)
+	}
+
+	counter := &archiveCounter{limits: limits, compressed: compressed}
+	return counter.extract(buffered)
+}
+
+func checkTar(r io.Reader, compressed int64, limits ArchiveLimits) error {
+	archive := tar.NewReader(r)
+	counter := &archiveCounter{limits: limits, compressed: compressed}
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnsafeArchive, err)
+		}
+		if err := counter.entry(header.Name); err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeReg, tar.TypeDir:
+		default:
+			return fmt.Errorf("%w: entry %q is not a regular file", ErrUnsafeArchive, header.Name)
+		}
+		if err := counter.extract(archive); err != nil {
+			return err
+		}
+	}
+}
+
+// safePath reports whether an entry extracts inside the extraction
+// directory
+func safePath(name string) bool {
+	if name == "" || strings.ContainsAny(name, "\\\x00") || strings.HasPrefix(name, "/") {
+		return false
+	}
+	if len(name) >= 2 && name[1] == ':' { // Windows drive letter
+		return false
+	}
+	clean := path.Clean(name)
+	return clean != ".." && !strings.HasPrefix(clean, "../")
+}
@@ -0,0 +1,146 @@
+package inspect
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Scanner scans files for malware
+type Scanner interface {
+	// Scan reads a file and returns the name of the malware found in it, or
+	// "" if it is clean
+	Scan(ctx context.Context, r io.Reader) (string, error)
+}
+
+// clamdChunkSize is the size of the chunks a stream is sent to clamd in
+const clamdChunkSize = 64 << 10
+
+// ClamdScanner scans files with a clamd daemon, streaming them with the
+// INSTREAM command so the daemon needs no access to the files
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner creates a scanner for the clamd daemon at address, either
+// "tcp://host:port", "unix:///path/to/clamd.sock" or "host:port". Each scan
+// must finish within timeout.
+func NewClamdScanner(address string, timeout time.Duration) (*ClamdScanner, error) {
+	network, addr := "tcp", address
+	if scheme, rest, ok := strings.Cut(address, "://"); ok {
+		network, addr = scheme, rest
+	}
+	if network != "tcp" && network != "unix" {
+		return nil, fmt.Errorf("unsupported clamd address %q", address)
+	}
+	if addr == "" {
+		return nil, errors.New("clamd address is empty")
+	}
+	return &ClamdScanner{network: network, address: addr, timeout: timeout}, nil
+}
+
+// Ping checks that the daemon answers
+func (c *ClamdScanner) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "PING", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("clamd: unexpected reply %q", reply)
+	}
+	return nil
+}
+
+// Scan streams a file to the daemon and returns the name of the malware it
+// found, or "" if it found none
+func (c *ClamdScanner) Scan(ctx context.Context, r io.Reader) (string, error) {
+	reply, err := c.command(ctx, "INSTREAM", r)
+	if err != nil {
+		return "", err
+	}
+
+	// "stream: OK", "stream: Eicar-Signature FOUND" or "<reason> ERROR"
+	result := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case result == "OK":
+		return "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return strings.TrimSuffix(result, " FOUND"), nil
+	default:
+		return "", fmt.Errorf("clamd: %s", strings.TrimSuffix(result, " ERROR"))
+	}
+}
+
+// command sends a command, followed by the stream read from body if there
+// is one, and returns the daemon's reply
+func (c *ClamdScanner) command(ctx context.Context, name string, body io.Reader) (string, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return "", fmt.Errorf("clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// Commands prefixed with z are terminated by NUL, and so are replies
+	writer := bufio.NewWriterSize(conn, clamdChunkSize+4)
+	writer.WriteString("z" + name + "\x00")
+	if body != nil {
+		if err := writeChunks(writer, body); err != nil {
+			return "", err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return "", fmt.Errorf("clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(err == io.EOF && reply != "") {
+		return "", fmt.Errorf("clamd: %w", err)
+	}
+	return strings.TrimSpace(strings.TrimRight(reply, "\x00")), nil
+}
+
+// writeChunks sends a stream as chunks prefixed by their length, ended by a
+// chunk of length 0
+func writeChunks(w io.Writer, r io.Reader) error {
+	buffer := make([]byte, clamdChunkSize)
+	length := make([]byte, 4)
+	for {
+		n, err := io.ReadFull(r, buffer)
+		if n > 0 {
+			binary.BigEndian.PutUint32(length, uint32(n))
+			if _, err := w.Write(length); err != nil {
+				return fmt.Errorf("clamd: %w", err)
+			}
+			if _, err := w.Write(buffer[:n]); err != nil {
+				return fmt.Errorf("clamd: %w", err)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(bytes.Repeat([]byte{0}, 4))
+	return err
+}
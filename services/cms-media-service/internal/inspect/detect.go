@@ -0,0 +1,289 @@
+// Package inspect checks what uploaded files really contain before they are
+// stored: it detects their type from their content, sanitises SVGs, guards
+// archives against zip bombs and path traversal, strips the location from
+// image metadata and scans files for malware with clamd.
+package inspect
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// sniffLen is how many leading bytes are read to detect a type
+const sniffLen = 4096
+
+// signature is a magic number at an offset that identifies a type
+type signature struct {
+	offset   int
+	magic    []byte
+	mimeType string
+}
+
+// signatures identifies types that http.DetectContentType does not know or
+// that must be told apart from the type it would report
+var signatures = []signature{
+	{0, []byte("II*\x00"), "image/tiff"},
+	{0, []byte("MM\x00*"), "image/tiff"},
+	{0, []byte("7z\xbc\xaf\x27\x1c"), "application/x-7z-compressed"},
+	{0, []byte("BZh"), "application/x-bzip2"},
+	{0, []byte("fLaC"), "audio/flac"},
+	{0, []byte("\x7fELF"), "application/x-executable"},
+	{0, []byte("\xfe\xed\xfa\xce"), "application/x-mach-binary"},
+	{0, []byte("\xfe\xed\xfa\xcf"), "application/x-mach-binary"},
+	{0, []byte("\xcf\xfa\xed\xfe"), "application/x-mach-binary"},
+	{0, []byte("\xce\xfa\xed\xfe"), "application/x-mach-binary"},
+	{257, []byte("ustar"), "application/x-tar"},
+}
+
+// oleMagic starts legacy Office documents, which are told apart by name
+var oleMagic = []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")
+
+var oleTypes = map[string]string{
+	".doc": "application/msword",
+	".dot": "application/msword",
+	".xls": "application/vnd.ms-excel",
+	".xlt": "application/vnd.ms-excel",
+	".ppt": "application/vnd.ms-powerpoint",
+	".pps": "application/vnd.ms-powerpoint",
+}
+
+// ftypBrands maps the major brand of ISO base media files to their type
+var ftypBrands = map[string]string{
+	"heic": "image/heic",
+	"heix": "image/heic",
+	"mif1": "image/heif",
+	"msf1": "image/heif",
+	"avif": "image/avif",
+	"qt  ": "video/quicktime",
+	"M4A ": "audio/mp4",
+	"M4B ": "audio/mp4",
+	"3gp4": "video/3gpp",
+	"3gp5": "video/3gpp",
+	"3g2a": "video/3gpp2",
+}
+
+// zipTypes maps the top level directory of Office Open XML packages to
+// their type
+var zipTypes = map[string]string{
+	"word/": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"xl/":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"ppt/":  "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+// extensions are the preferred file extensions of detected types
+var extensions = map[string]string{
+	"image/jpeg":                    ".jpg",
+	"image/png":                     ".png",
+	"image/gif":                     ".gif",
+	"image/webp":                    ".webp",
+	"image/bmp":                     ".bmp",
+	"image/tiff":                    ".tiff",
+	"image/heic":                    ".heic",
+	"image/heif":                    ".heif",
+	"image/avif":                    ".avif",
+	"image/svg+xml":                 ".svg",
+	"image/x-icon":                  ".ico",
+	"video/mp4":                     ".mp4",
+	"video/quicktime":               ".mov",
+	"video/webm":                    ".webm",
+	"video/avi":                     ".avi",
+	"video/3gpp":                    ".3gp",
+	"video/3gpp2":                   ".3g2",
+	"audio/mpeg":                    ".mp3",
+	"audio/mp4":                     ".m4a",
+	"audio/wave":                    ".wav",
+	"audio/flac":                    ".flac",
+	"audio/aiff":                    ".aiff",
+	"audio/midi":                    ".mid",
+	"application/ogg":               ".ogg",
+	"application/pdf":               ".pdf",
+	"application/zip":               ".zip",
+	"application/x-gzip":            ".gz",
+	"application/x-bzip2":           ".bz2",
+	"application/x-tar":             ".tar",
+	"application/x-7z-compressed":   ".7z",
+	"application/x-rar-compressed":  ".rar",
+	"application/msword":            ".doc",
+	"application/vnd.ms-excel":      ".xls",
+	"application/vnd.ms-powerpoint": ".ppt",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   ".docx",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         ".xlsx",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": ".pptx",
+	"application/vnd.oasis.opendocument.text":                                   ".odt",
+	"application/vnd.oasis.opendocument.spreadsheet":                            ".ods",
+	"application/vnd.oasis.opendocument.presentation":                           ".odp",
+	"application/epub+zip": ".epub",
+	"text/plain":           ".txt",
+	"text/csv":             ".csv",
+}
+
+// blockedTypes are types that browsers run, or that run on the machines of
+// those who download them, and are never accepted
+var blockedTypes = map[string]bool{
+	"text/html":                 true,
+	"application/xhtml+xml":     true,
+	"text/javascript":           true,
+	"application/javascript":    true,
+	"application/x-msdownload":  true,
+	"application/x-executable":  true,
+	"application/x-mach-binary": true,
+}
+
+// DetectContentType detects the type of a file from its content. The name is
+// only used to tell apart types that share a container, such as legacy
+// Office documents. Content of no known type is "application/octet-stream".
+func DetectContentType(r io.ReaderAt, size int64, name string) (string, error) {
+	head := make([]byte, sniffLen)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	head = head[:n]
+	ext := strings.ToLower(filepath.Ext(name))
+
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return detectZip(r, size), nil
+	case bytes.HasPrefix(head, oleMagic):
+		if mimeType, ok := oleTypes[ext]; ok {
+			return mimeType, nil
+		}
+		return "application/x-ole-storage", nil
+	case isPE(head):
+		return "application/x-msdownload", nil
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		if mimeType, ok := ftypBrands[string(head[8:12])]; ok {
+			return mimeType, nil
+		}
+		return "video/mp4", nil
+	}
+	for _, sig := range signatures {
+		if len(head) >= sig.offset+len(sig.magic) && bytes.Equal(head[sig.offset:sig.offset+len(sig.magic)], sig.magic) {
+			return sig.mimeType, nil
+		}
+	}
+
+	mimeType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	switch mimeType {
+	case "text/xml", "text/plain":
+		// Browsers run the scripts of SVG and XHTML documents
+		switch strings.ToLower(rootElement(head)) {
+		case "svg":
+			return "image/svg+xml", nil
+		case "html":
+			return "application/xhtml+xml", nil
+		}
+		if mimeType == "text/plain" && ext == ".csv" {
+			return "text/csv", nil
+		}
+	case "":
+		mimeType = "application/octet-stream"
+	}
+	return mimeType, nil
+}
+
+// detectZip tells Office Open XML, OpenDocument and EPUB packages apart from
+// other zip archives
+func detectZip(r io.ReaderAt, size int64) string {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return "application/zip"
+	}
+
+	contentTypes := false
+	for _, file := range archive.File {
+		switch {
+		case file.Name == "mimetype":
+			if mimeType := readMimetype(file); mimeType != "" {
+				return mimeType
+			}
+		case file.Name == "[Content_Types].xml":
+			contentTypes = true
+		}
+	}
+	if contentTypes {
+		for _, file := range archive.File {
+			for dir, mimeType := range zipTypes {
+				if strings.HasPrefix(file.Name, dir) {
+					return mimeType
+				}
+			}
+		}
+	}
+	return "application/zip"
+}
+
+// readMimetype reads the mimetype entry of OpenDocument and EPUB packages
+func readMimetype(file *zip.File) string {
+	if file.UncompressedSize64 > 128 {
+		return ""
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return ""
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, 128))
+	if err != nil {
+		return ""
+	}
+	mimeType := strings.TrimSpace(string(content))
+	if strings.HasPrefix(mimeType, "application/vnd.oasis.opendocument.") || mimeType == "application/epub+zip" {
+		return mimeType
+	}
+	return ""
+}
+
+// isPE reports whether a file is a Windows executable: an MZ header that
+// points to a PE header
+func isPE(head []byte) bool {
+	if len(head) < 0x40 || !bytes.HasPrefix(head, []byte("MZ")) {
+		return false
+	}
+	offset := int(binary.LittleEndian.Uint32(head[0x3c:]))
+	return offset+4 <= len(head) && bytes.Equal(head[offset:offset+4], []byte("PE\x00\x00"))
+}
+
+// rootElement returns the name of the first element of an XML document, or
+// "" if the document has none
+func rootElement(head []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(head))
+	decoder.Strict = false
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return ""
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+}
+
+// Blocked reports whether files of a type are never accepted
+func Blocked(mimeType string) bool {
+	return blockedTypes[mimeType]
+}
+
+// Extension returns the file extension to store a file of a detected type
+// with. The extension of the file's name is kept if it matches the type.
+func Extension(mimeType, name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext != "" {
+		if extType, _, _ := mime.ParseMediaType(mime.TypeByExtension(ext)); extType == mimeType {
+			return ext
+		}
+	}
+	if ext, ok := extensions[mimeType]; ok {
+		return ext
+	}
+	return ""
+}
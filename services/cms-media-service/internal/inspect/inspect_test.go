@@ -0,0 +1,356 @@
+package inspect
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{"photo.png", encodePNG(t), "image/png"},
+		{"photo.png", encodeJPEG(t), "image/jpeg"},
+		{"logo.png", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`), "image/svg+xml"},
+		{"logo.svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), "image/svg+xml"},
+		{"page.png", []byte(`<!DOCTYPE html><html><script>alert(1)</script></html>`), "text/html"},
+		{"page.xml", []byte(`<?xml version="1.0"?><html xmlns="http://www.w3.org/1999/xhtml"></html>`), "application/xhtml+xml"},
+		{"report.pdf", []byte("%PDF-1.7\n"), "application/pdf"},
+		{"report.zip", buildZip(t, map[string]string{"[Content_Types].xml": "<Types/>", "word/document.xml": "<w/>"}),
+			"application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"notes.docx", buildZip(t, map[string]string{"notes.txt": "hello"}), "application/zip"},
+		{"notes.doc", append(append([]byte{}, oleMagic...), make([]byte, 504)...), "application/msword"},
+		{"clip.mov", append([]byte("\x00\x00\x00\x14ftypqt  "), make([]byte, 8)...), "video/quicktime"},
+		{"setup.jpg", peHeader(), "application/x-msdownload"},
+		{"data.csv", []byte("a,b\n1,2\n"), "text/csv"},
+	}
+	for _, tt := range tests {
+		got, err := DetectContentType(bytes.NewReader(tt.content), int64(len(tt.content)), tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("DetectContentType(%s) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	if !Blocked("text/html") || !Blocked("application/x-msdownload") || Blocked("image/png") {
+		t.Error("Blocked() does not block pages and executables only")
+	}
+	if got := Extension("image/jpeg", "photo.JPEG"); got != ".jpeg" {
+		t.Errorf("Extension() = %q, want the matching extension kept", got)
+	}
+	if got := Extension("image/svg+xml", "logo.png"); got != ".svg" {
+		t.Errorf("Extension() = %q, want the extension of the detected type", got)
+	}
+}
+
+func TestSanitizeSVG(t *testing.T) {
+	input := `<?xml version="1.0"?>
+<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd">
+<?xml-stylesheet href="evil.css"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" onload="alert(1)">
+  <!-- comment -->
+  <style><![CDATA[@im]]>port url(evil.css);</style>
+  <style>circle { fill: red; } g > circle { fill: blue; }</style>
+  <script>alert(2)</script>
+  <foreignObject><div xmlns="http://www.w3.org/1999/xhtml"><script>alert(3)</script></div></foreignObject>
+  <a xlink:href="jav&#x09;ascript:alert(4)"><circle cx="5" cy="5" r="4" onclick="alert(5)"/></a>
+  <image href="data:image/svg+xml;base64,PHN2Zz4=" width="1" height="1"/>
+  <image href="data:image/png;base64,iVBORw0KGgo=" width="1" height="1"/>
+  <animate attributeName="href" values="javascript:alert(6)"/>
+  <text x="1" y="2">5 &lt; 6 &amp; safe</text>
+</svg>`
+	var output bytes.Buffer
+	if err := SanitizeSVG(strings.NewReader(input), &output); err != nil {
+		t.Fatalf("SanitizeSVG() error = %v", err)
+	}
+	sanitized := output.String()
+
+	for _, unsafe := range []string{"alert", "script", "foreignObject", "@im", "onload", "onclick", "svg+xml", "DOCTYPE", "stylesheet", "comment"} {
+		if strings.Contains(sanitized, unsafe) {
+			t.Errorf("sanitized SVG contains %q:\n%s", unsafe, sanitized)
+		}
+	}
+	for _, safe := range []string{`<circle cx="5" cy="5" r="4">`, "g &gt; circle", "data:image/png", "5 &lt; 6 &amp; safe", `xmlns:xlink="http://www.w3.org/1999/xlink"`} {
+		if !strings.Contains(sanitized, safe) {
+			t.Errorf("sanitized SVG lost %q:\n%s", safe, sanitized)
+		}
+	}
+	if err := xml.Unmarshal(output.Bytes(), new(struct{})); err != nil {
+		t.Errorf("sanitized SVG is not well formed: %v", err)
+	}
+
+	for name, input := range map[string]string{
+		"entity":    `<!DOCTYPE svg [<!ENTITY lol "lol">]><svg>&lol;</svg>`,
+		"not svg":   `<html><body/></html>`,
+		"truncated": `<svg><g>`,
+		"mismatch":  `<svg><g></a></svg>`,
+	} {
+		if err := SanitizeSVG(strings.NewReader(input), io.Discard); !errors.Is(err, ErrInvalidSVG) {
+			t.Errorf("SanitizeSVG(%s) error = %v, want ErrInvalidSVG", name, err)
+		}
+	}
+}
+
+func TestCheckArchive(t *testing.T) {
+	limits := ArchiveLimits{MaxEntries: 3, MaxSize: 4 << 20, MaxRatio: 100}
+	check := func(content []byte, mimeType string) error {
+		return CheckArchive(bytes.NewReader(content), int64(len(content)), mimeType, limits)
+	}
+
+	if err := check(buildZip(t, map[string]string{"docs/readme.txt": "hello", "image.png": "png"}), "application/zip"); err != nil {
+		t.Errorf("CheckArchive(safe zip) error = %v", err)
+	}
+
+	unsafe := map[string][]byte{
+		"traversal": buildZip(t, map[string]string{"../../etc/cron.d/job": "x"}),
+		"absolute":  buildZip(t, map[string]string{"/etc/passwd": "x"}),
+		"backslash": buildZip(t, map[string]string{"..\\evil.exe": "x"}),
+		"entries":   buildZip(t, map[string]string{"a": "", "b": "", "c": "", "d": ""}),
+		"ratio":     buildZip(t, map[string]string{"zeros": string(make([]byte, 2<<20))}),
+		"size":      buildZip(t, map[string]string{"a": string(make([]byte, 3<<20)), "b": string(make([]byte, 3<<20))}),
+	}
+	for name, content := range unsafe {
+		if err := check(content, "application/zip"); !errors.Is(err, ErrUnsafeArchive) {
+			t.Errorf("CheckArchive(%s) error = %v, want ErrUnsafeArchive", name, err)
+		}
+	}
+
+	var bomb bytes.Buffer
+	writer := gzip.NewWriter(&bomb)
+	writer.Write(make([]byte, 8<<20))
+	writer.Close()
+	if err := check(bomb.Bytes(), "application/x-gzip"); !errors.Is(err, ErrUnsafeArchive) {
+		t.Errorf("CheckArchive(gzip bomb) error = %v, want ErrUnsafeArchive", err)
+	}
+}
+
+func TestStripLocation(t *testing.T) {
+	tiff, latitude := gpsTIFF()
+
+	// JPEG with the EXIF segment after the start of image
+	original := encodeJPEG(t)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	withGPS := append(append(append([]byte{}, original[:2]...), append(app1, segment...)...), original[2:]...)
+
+	var output bytes.Buffer
+	stripped, err := StripLocation("image/jpeg", bytes.NewReader(withGPS), &output)
+	if err != nil || !stripped {
+		t.Fatalf("StripLocation(jpeg) = %v, %v", stripped, err)
+	}
+	if bytes.Contains(output.Bytes(), latitude) || output.Len() != len(withGPS) {
+		t.Error("StripLocation(jpeg) kept the latitude or changed offsets")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(output.Bytes())); err != nil {
+		t.Errorf("stripped jpeg does not decode: %v", err)
+	}
+
+	// PNG with an eXIf chunk after the header, whose CRC must be updated
+	original = encodePNG(t)
+	chunk := make([]byte, 8, 12+len(tiff))
+	binary.BigEndian.PutUint32(chunk, uint32(len(tiff)))
+	copy(chunk[4:], "eXIf")
+	chunk = append(chunk, tiff...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	headerEnd := 8 + 8 + 13 + 4
+	withGPS = append(append(append([]byte{}, original[:headerEnd]...), chunk...), original[headerEnd:]...)
+
+	output.Reset()
+	stripped, err = StripLocation("image/png", bytes.NewReader(withGPS), &output)
+	if err != nil || !stripped {
+		t.Fatalf("StripLocation(png) = %v, %v", stripped, err)
+	}
+	if bytes.Contains(output.Bytes(), latitude) {
+		t.Error("StripLocation(png) kept the latitude")
+	}
+	if _, err := png.Decode(bytes.NewReader(output.Bytes())); err != nil {
+		t.Errorf("stripped png does not decode: %v", err)
+	}
+
+	// Images without a location are copied as they are
+	output.Reset()
+	stripped, err = StripLocation("image/png", bytes.NewReader(original), &output)
+	if err != nil || stripped || !bytes.Equal(output.Bytes(), original) {
+		t.Errorf("StripLocation(png without location) = %v, %v, changed %v", stripped, err, !bytes.Equal(output.Bytes(), original))
+	}
+}
+
+func TestClamdScanner(t *testing.T) {
+	address := startClamdStub(t)
+	scanner, err := NewClamdScanner("tcp://"+address, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := scanner.Ping(ctx); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+	if malware, err := scanner.Scan(ctx, bytes.NewReader(make([]byte, 200<<10))); err != nil || malware != "" {
+		t.Errorf("Scan(clean) = %q, %v", malware, err)
+	}
+	eicar := strings.NewReader(`X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`)
+	if malware, err := scanner.Scan(ctx, eicar); err != nil || malware != "Eicar-Test-Signature" {
+		t.Errorf("Scan(eicar) = %q, %v, want Eicar-Test-Signature", malware, err)
+	}
+	if _, err := scanner.Scan(ctx, bytes.NewReader(make([]byte, 2<<20))); err == nil || !strings.Contains(err.Error(), "size limit exceeded") {
+		t.Errorf("Scan(too large) error = %v, want the daemon's error", err)
+	}
+
+	if _, err := NewClamdScanner("http://clamav:3310", time.Second); err == nil {
+		t.Error("NewClamdScanner(http) error = nil")
+	}
+}
+
+// startClamdStub serves PING and INSTREAM like clamd, finding the EICAR test
+// signature and refusing streams of more than 1 MB
+func startClamdStub(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				command, _ := reader.ReadString(0)
+				switch command {
+				case "zPING\x00":
+					conn.Write([]byte("PONG\x00"))
+				case "zINSTREAM\x00":
+					var stream []byte
+					length := make([]byte, 4)
+					for {
+						if _, err := io.ReadFull(reader, length); err != nil {
+							return
+						}
+						size := binary.BigEndian.Uint32(length)
+						if size == 0 {
+							break
+						}
+						chunk := make([]byte, size)
+						if _, err := io.ReadFull(reader, chunk); err != nil {
+							return
+						}
+						stream = append(stream, chunk...)
+					}
+					switch {
+					case len(stream) > 1<<20:
+						conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+					case bytes.Contains(stream, []byte("EICAR-STANDARD-ANTIVIRUS-TEST-FILE")):
+						conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+					default:
+						conn.Write([]byte("stream: OK\x00"))
+					}
+				default:
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// gpsTIFF builds little endian TIFF metadata whose GPS IFD holds a latitude,
+// and returns it with the latitude's bytes
+func gpsTIFF() ([]byte, []byte) {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1) // IFD0: the GPS IFD pointer
+	tiff = appendEntry(tiff, gpsIFDTag, 4, 1, 26)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2) // GPS IFD at 26
+	tiff = appendEntry(tiff, 1, 2, 2, uint32('N'))   // GPSLatitudeRef
+	tiff = appendEntry(tiff, 2, 5, 3, 56)            // GPSLatitude, 3 rationals at 56
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	latitude := []byte{}
+	for _, value := range []uint32{48, 1, 51, 1, 2957, 100} {
+		latitude = binary.LittleEndian.AppendUint32(latitude, value)
+	}
+	return append(tiff, latitude...), latitude
+}
+
+func appendEntry(tiff []byte, tag, fieldType uint16, count, value uint32) []byte {
+	tiff = binary.LittleEndian.AppendUint16(tiff, tag)
+	tiff = binary.LittleEndian.AppendUint16(tiff, fieldType)
+	tiff = binary.LittleEndian.AppendUint32(tiff, count)
+	return binary.LittleEndian.AppendUint32(tiff, value)
+}
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i)
+	}
+	img.Set(0, 0, color.White)
+	return img
+}
+
+func encodePNG(t *testing.T) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func encodeJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	if err := jpeg.Encode(&buffer, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Write([]byte(content))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+// peHeader builds the headers of a Windows executable
+func peHeader() []byte {
+	header := make([]byte, 0x100)
+	copy(header, "MZ")
+	binary.LittleEndian.PutUint32(header[0x3c:], 0x80)
+	copy(header[0x80:], "PE\x00\x00")
+	return header
+}
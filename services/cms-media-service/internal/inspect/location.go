@@ -0,0 +1,320 @@
+package inspect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// ErrInvalidImage is returned for images whose metadata cannot be parsed
+var ErrInvalidImage = errors.New("invalid image")
+
+// gpsIFDTag is the EXIF tag that points to the GPS fields
+const gpsIFDTag = 0x8825
+
+// xmpLocation marks XMP packets that hold a location
+var xmpLocation = []byte("exif:GPS")
+
+// StripLocation copies an image from r to w without the location its
+// metadata records: the values of the EXIF GPS fields are erased and XMP
+// packets holding GPS fields are dropped. The rest of the image and its
+// metadata is copied unchanged. It supports JPEG, PNG, WebP and TIFF
+// images, and reports whether it removed anything; other images are copied
+// as they are.
+func StripLocation(mimeType string, r io.Reader, w io.Writer) (bool, error) {
+	switch mimeType {
+	case "image/jpeg":
+		return stripJPEG(r, w)
+	case "image/png":
+		return stripPNG(r, w)
+	case "image/webp":
+		return stripWebP(r, w)
+	case "image/tiff":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return false, err
+		}
+		stripped := eraseGPS(data)
+		_, err = w.Write(data)
+		return stripped, err
+	default:
+		_, err := io.Copy(w, r)
+		return false, err
+	}
+}
+
+// stripJPEG erases the GPS fields of the EXIF segment and drops XMP
+// segments with a location. Segments are copied up to the start of the scan,
+// then the rest of the image as it is.
+func stripJPEG(r io.Reader, w io.Writer) (bool, error) {
+	reader := bufio.NewReader(r)
+	marker := make([]byte, 2)
+	if _, err := io.ReadFull(reader, marker); err != nil || marker[0] != 0xff || marker[1] != 0xd8 {
+		return false, fmt.Errorf("%w: not a jpeg", ErrInvalidImage)
+	}
+	if _, err := w.Write(marker); err != nil {
+		return false, err
+	}
+
+	stripped := false
+	for {
+		if _, err := io.ReadFull(reader, marker); err != nil {
+			return false, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		if marker[0] != 0xff {
+			return false, fmt.Errorf("%w: bad marker", ErrInvalidImage)
+		}
+		for marker[1] == 0xff { // fill bytes
+			b, err := reader.ReadByte()
+			if err != nil {
+				return false, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+			}
+			marker[1] = b
+		}
+		// Markers without a segment, and the start of the scan after which
+		// only image data follows
+		if marker[1] == 0xd9 || marker[1] == 0xda || marker[1] >= 0xd0 && marker[1] <= 0xd7 || marker[1] == 0x01 {
+			if _, err := w.Write(marker); err != nil {
+				return false, err
+			}
+			if marker[1] == 0xd9 {
+				return stripped, nil
+			}
+			if marker[1] == 0xda {
+				_, err := io.Copy(w, reader)
+				return stripped, err
+			}
+			continue
+		}
+
+		length := make([]byte, 2)
+		if _, err := io.ReadFull(reader, length); err != nil {
+			return false, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		size := int(binary.BigEndian.Uint16(length))
+		if size < 2 {
+			return false, fmt.Errorf("%w: bad segment length", ErrInvalidImage)
+		}
+		segment := make([]byte, size-2)
+		if _, err := io.ReadFull(reader, segment); err != nil {
+			return false, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+
+		if marker[1] == 0xe1 { // APP1: EXIF or XMP
+			if tiff, ok := bytes.CutPrefix(segment, []byte("Exif\x00\x00")); ok && eraseGPS(tiff) {
+				stripped = true
+			}
+			if bytes.HasPrefix(segment, []byte("http://ns.adobe.com/xap/1.0/")) && bytes.Contains(segment, xmpLocation) {
+				stripped = true
+				continue
+			}
+		}
+		for _, part := range [][]byte{marker, length, segment} {
+			if _, err := w.Write(part); err != nil {
+				return false, err
+			}
+		}
+	}
+}
+
+// stripPNG erases the GPS fields of the eXIf chunk and drops XMP text chunks
+// with a location, or compressed ones that cannot be checked cheaply
+func stripPNG(r io.Reader, w io.Writer) (bool, error) {
+	signature := make([]byte, 8)
+	if _, err := io.ReadFull(r, signature); err != nil || string(signature) != "\x89PNG\r\n\x1a\n" {
+		return false, fmt.Errorf("%w: not a png", ErrInvalidImage)
+	}
+	if _, err := w.Write(signature); err != nil {
+		return false, err
+	}
+
+	stripped := false
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return stripped, nil
+			}
+			return false, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		size := binary.BigEndian.Uint32(header)
+		chunkType := string(header[4:8])
+
+		// Copy other chunks, such as image data, without holding them in
+		// memory
+		if chunkType != "eXIf" && chunkType != "iTXt" {
+			if _, err := w.Write(header); err != nil {
+				return false, err
+			}
+			if _, err := io.CopyN(w, r, int64(size)+4); err != nil {
+				return false, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+			}
+			if chunkType == "IEND" {
+				return stripped, nil
+			}
+			continue
+		}
+
+		if size > 1<<24 {
+			return false, fmt.Errorf("%w: %s chunk too large", ErrInvalidImage, chunkType)
+		}
+		chunk := make([]byte, size+4) // data and CRC
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return false, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		data := chunk[:size]
+		switch chunkType {
+		case "eXIf":
+			if eraseGPS(data) {
+				stripped = true
+				crc := crc32.NewIEEE()
+				crc.Write(header[4:8])
+				crc.Write(data)
+				binary.BigEndian.PutUint32(chunk[size:], crc.Sum32())
+			}
+		case "iTXt":
+			// keyword, NUL, compression flag
+			if keyword, rest, ok := bytes.Cut(data, []byte{0}); ok && string(keyword) == "XML:com.adobe.xmp" &&
+				(len(rest) > 0 && rest[0] != 0 || bytes.Contains(rest, xmpLocation)) {
+				stripped = true
+				continue
+			}
+		}
+		if _, err := w.Write(header); err != nil {
+			return false, err
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return false, err
+		}
+	}
+}
+
+// stripWebP erases the GPS fields of the EXIF chunk and drops an XMP chunk
+// with a location, which changes the size in the RIFF header
+func stripWebP(r io.Reader, w io.Writer) (bool, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return false, err
+	}
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return false, fmt.Errorf("%w: not a webp", ErrInvalidImage)
+	}
+
+	stripped := false
+	output := append([]byte{}, data[:12]...)
+	vp8x := -1 // offset of the VP8X chunk in output
+	for offset := 12; offset+8 <= len(data); {
+		chunkType := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		end := offset + 8 + size + size%2 // chunks are padded to even sizes
+		if size < 0 || end > len(data) {
+			return false, fmt.Errorf("%w: bad chunk size", ErrInvalidImage)
+		}
+		chunk := data[offset:end]
+		offset = end
+
+		switch chunkType {
+		case "VP8X":
+			vp8x = len(output)
+		case "EXIF":
+			payload := chunk[8 : 8+size]
+			payload = bytes.TrimPrefix(payload, []byte("Exif\x00\x00"))
+			if eraseGPS(payload) {
+				stripped = true
+			}
+		case "XMP ":
+			if bytes.Contains(chunk, xmpLocation) {
+				stripped = true
+				if vp8x >= 0 {
+					output[vp8x+8] &^= 0x04 // XMP flag
+				}
+				continue
+			}
+		}
+		output = append(output, chunk...)
+	}
+
+	binary.LittleEndian.PutUint32(output[4:], uint32(len(output)-8))
+	_, err = w.Write(output)
+	return stripped, err
+}
+
+// eraseGPS overwrites the fields of the GPS IFD of TIFF structured metadata
+// with zeros and empties the IFD, in place, so that no offset changes. It
+// reports whether there were fields to erase.
+func eraseGPS(tiff []byte) bool {
+	if len(tiff) < 8 {
+		return false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return false
+	}
+
+	gpsOffset := -1
+	ifd := int(order.Uint32(tiff[4:]))
+	eachEntry(tiff, order, ifd, func(entry []byte) {
+		if order.Uint16(entry) == gpsIFDTag {
+			gpsOffset = int(order.Uint32(entry[8:]))
+		}
+	})
+	if gpsOffset < 0 || gpsOffset+2 > len(tiff) {
+		return false
+	}
+
+	count := eachEntry(tiff, order, gpsOffset, func(entry []byte) {
+		// Values of more than 4 bytes are stored at an offset
+		if size := fieldSize(order.Uint16(entry[2:])) * int(order.Uint32(entry[4:])); size > 4 {
+			if offset := int(order.Uint32(entry[8:])); offset >= 0 && offset+size <= len(tiff) && size > 0 {
+				clear(tiff[offset : offset+size])
+			}
+		}
+		clear(entry)
+	})
+	if count == 0 {
+		return false
+	}
+	order.PutUint16(tiff[gpsOffset:], 0)
+	return true
+}
+
+// eachEntry calls fn with each 12 byte entry of the IFD at offset, and
+// returns how many there were
+func eachEntry(tiff []byte, order binary.ByteOrder, offset int, fn func(entry []byte)) int {
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		start := offset + 2 + i*12
+		if start+12 > len(tiff) {
+			return i
+		}
+		fn(tiff[start : start+12])
+	}
+	return count
+}
+
+// fieldSize returns the size in bytes of a TIFF field type
+func fieldSize(fieldType uint16) int {
+	switch fieldType {
+	case 1, 2, 6, 7: // BYTE, ASCII, SBYTE, UNDEFINED
+		return 1
+	case 3, 8: // SHORT, SSHORT
+		return 2
+	case 4, 9, 11: // LONG, SLONG, FLOAT
+		return 4
+	case 5, 10, 12: // RATIONAL, SRATIONAL, DOUBLE
+		return 8
+	}
+	return 0
+}
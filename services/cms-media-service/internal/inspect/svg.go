@@ -0,0 +1,209 @@
+package inspect
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidSVG is returned for SVGs that cannot be sanitised
+var ErrInvalidSVG = errors.New("invalid svg")
+
+// svgBlockedElements are elements that run scripts or embed other documents
+var svgBlockedElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+	"handler":       true,
+	"listener":      true,
+}
+
+// svgURLAttributes are attributes whose value is a URL
+var svgURLAttributes = map[string]bool{
+	"href":       true,
+	"src":        true,
+	"action":     true,
+	"formaction": true,
+}
+
+// SanitizeSVG copies an SVG from r to w without what could run scripts:
+// script, foreignObject and embedding elements, event handler attributes,
+// javascript: and data: URLs other than raster images, style sheets that
+// import or run code, document type declarations and processing
+// instructions. Comments are dropped too.
+func SanitizeSVG(r io.Reader, w io.Writer) error {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = true
+	encoder := &svgWriter{w: w}
+
+	var stack []string // names of the open elements that are kept
+	var style strings.Builder
+	skip := 0 // depth inside a dropped element
+	root := false
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSVG, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skip > 0 {
+				skip++
+				continue
+			}
+			if !root {
+				if !strings.EqualFold(t.Name.Local, "svg") {
+					return fmt.Errorf("%w: root element is %s", ErrInvalidSVG, t.Name.Local)
+				}
+				root = true
+			} else if len(stack) == 0 {
+				return fmt.Errorf("%w: more than one root element", ErrInvalidSVG)
+			}
+			if svgBlockedElements[strings.ToLower(t.Name.Local)] {
+				skip = 1
+				continue
+			}
+			stack = append(stack, qualifiedName(t.Name))
+			encoder.start(t)
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if len(stack) == 0 || stack[len(stack)-1] != qualifiedName(t.Name) {
+				return fmt.Errorf("%w: unexpected end element %s", ErrInvalidSVG, qualifiedName(t.Name))
+			}
+			stack = stack[:len(stack)-1]
+			// Style sheets are checked whole, as CDATA sections may split them
+			if strings.EqualFold(t.Name.Local, "style") {
+				if !unsafeStyle(style.String()) {
+					encoder.text(xml.CharData(style.String()))
+				}
+				style.Reset()
+			}
+			encoder.end(t)
+		case xml.CharData:
+			if skip > 0 || len(stack) == 0 {
+				continue
+			}
+			if strings.EqualFold(stack[len(stack)-1], "style") {
+				style.Write(t)
+				continue
+			}
+			encoder.text(t)
+		case xml.ProcInst:
+			if t.Target == "xml" && !root {
+				encoder.procInst(t)
+			}
+		}
+		if encoder.err != nil {
+			return encoder.err
+		}
+	}
+
+	if !root || len(stack) > 0 {
+		return fmt.Errorf("%w: unexpected end of document", ErrInvalidSVG)
+	}
+	return nil
+}
+
+// safeAttr reports whether an attribute is kept
+func safeAttr(attr xml.Attr) bool {
+	name := strings.ToLower(attr.Name.Local)
+	if strings.HasPrefix(name, "on") {
+		return false
+	}
+	if name == "style" && unsafeStyle(attr.Value) {
+		return false
+	}
+
+	// Browsers ignore whitespace and control characters in URL schemes
+	value := strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(attr.Value))
+	if strings.Contains(value, "javascript:") || strings.Contains(value, "vbscript:") {
+		return false
+	}
+	if svgURLAttributes[name] && strings.HasPrefix(value, "data:") {
+		return strings.HasPrefix(value, "data:image/png") ||
+			strings.HasPrefix(value, "data:image/jpeg") ||
+			strings.HasPrefix(value, "data:image/gif") ||
+			strings.HasPrefix(value, "data:image/webp")
+	}
+	return true
+}
+
+// unsafeStyle reports whether CSS imports other style sheets or runs code
+func unsafeStyle(css string) bool {
+	css = strings.ToLower(css)
+	return strings.Contains(css, "@import") ||
+		strings.Contains(css, "javascript:") ||
+		strings.Contains(css, "expression(") ||
+		strings.Contains(css, "-moz-binding") ||
+		strings.Contains(css, "behavior:")
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// svgWriter writes raw tokens, keeping the namespace prefixes they were read
+// with. It remembers the first error so the caller checks once per token.
+type svgWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *svgWriter) write(s string) {
+	if e.err == nil {
+		_, e.err = io.WriteString(e.w, s)
+	}
+}
+
+func (e *svgWriter) escape(s string) {
+	if e.err == nil {
+		e.err = xml.EscapeText(e.w, []byte(s))
+	}
+}
+
+func (e *svgWriter) start(t xml.StartElement) {
+	e.write("<" + qualifiedName(t.Name))
+	for _, attr := range t.Attr {
+		if !safeAttr(attr) {
+			continue
+		}
+		e.write(" " + qualifiedName(attr.Name) + `="`)
+		e.escape(attr.Value)
+		e.write(`"`)
+	}
+	e.write(">")
+}
+
+func (e *svgWriter) end(t xml.EndElement) {
+	e.write("</" + qualifiedName(t.Name) + ">")
+}
+
+// textEscaper escapes character data, keeping its whitespace as it is
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func (e *svgWriter) text(t xml.CharData) {
+	e.write(textEscaper.Replace(string(t)))
+}
+
+func (e *svgWriter) procInst(t xml.ProcInst) {
+	e.write("<?" + t.Target + " " + string(t.Inst) + "?>\n")
+}
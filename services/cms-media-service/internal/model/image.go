@@ -0,0 +1,119 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ImageFit is how an image is fitted to the size of a derivative
+type ImageFit string
+
+const (
+	ImageFitContain ImageFit = "contain" // scaled down to fit within the size
+	ImageFitCover   ImageFit = "cover"   // scaled to cover the size and cropped around the focal point
+)
+
+// ImageFormats lists the formats derivatives can be generated in
+var ImageFormats = []string{"jpeg", "png", "webp", "avif"}
+
+// ImagePreset describes derivatives generated for every image of a tenant,
+// one per width and format, for use in srcset
+type ImagePreset struct {
+	Name        string   `json:"name" bson:"name"`
+	Widths      []int    `json:"widths" bson:"widths"`
+	AspectRatio float64  `json:"aspectRatio,omitempty" bson:"aspectRatio,omitempty"` // width / height of cover crops
+	Fit         ImageFit `json:"fit" bson:"fit"`
+	Formats     []string `json:"formats" bson:"formats"`
+	Quality     int      `json:"quality" bson:"quality"` // 1-100
+}
+
+// ImagePresetConfig holds a tenant's image derivative presets
+type ImagePresetConfig struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TenantID  primitive.ObjectID `json:"tenantId" bson:"tenantId"`
+	Presets   []ImagePreset      `json:"presets" bson:"presets"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// DefaultImagePresets are the presets of tenants without presets of their
+// own
+var DefaultImagePresets = []ImagePreset{
+	{
+		Name:    "responsive",
+		Widths:  []int{320, 640, 1024, 1600},
+		Fit:     ImageFitContain,
+		Formats: []string{"webp", "avif"},
+		Quality: 80,
+	},
+}
+
+// ImageDerivative is a copy of an image generated for a preset
+type ImageDerivative struct {
+	Preset   string `json:"preset" bson:"preset"`
+	Width    int    `json:"width" bson:"width"`
+	Height   int    `json:"height" bson:"height"`
+	Format   string `json:"format" bson:"format"`
+	MimeType string `json:"mimeType" bson:"mimeType"`
+	Path     string `json:"path" bson:"path"` // storage key
+	URL      string `json:"url" bson:"url"`
+	Size     int64  `json:"size" bson:"size"`
+}
+
+// FocalPoint is the point of an image that cover crops keep in view, as
+// fractions of its width and height from the top left corner. It is stored
+// in the metadata of a media file under "focalPoint".
+type FocalPoint struct {
+	X float64 `json:"x" bson:"x"`
+	Y float64 `json:"y" bson:"y"`
+}
+
+// DefaultFocalPoint is the centre of an image
+var DefaultFocalPoint = FocalPoint{X: 0.5, Y: 0.5}
+
+// FocalPoint returns the focal point of a file, or the centre if none is
+// set
+func (f *MediaFile) FocalPoint() FocalPoint {
+	var fields map[string]interface{}
+	switch value := f.Metadata["focalPoint"].(type) {
+	case FocalPoint:
+		return value
+	case map[string]interface{}:
+		fields = value
+	case primitive.M:
+		fields = value
+	case primitive.D:
+		fields = make(map[string]interface{}, len(value))
+		for _, element := range value {
+			fields[element.Key] = element.Value
+		}
+	default:
+		return DefaultFocalPoint
+	}
+
+	x, okX := fraction(fields["x"])
+	y, okY := fraction(fields["y"])
+	if !okX || !okY {
+		return DefaultFocalPoint
+	}
+	return FocalPoint{X: x, Y: y}
+}
+
+// fraction converts a decoded number to a fraction between 0 and 1
+func fraction(value interface{}) (float64, bool) {
+	var f float64
+	switch n := value.(type) {
+	case float64:
+		f = n
+	case int32:
+		f = float64(n)
+	case int64:
+		f = float64(n)
+	case int:
+		f = float64(n)
+	default:
+		return 0, false
+	}
+	return f, f >= 0 && f <= 1
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JobType is the kind of processing a job does, each run by its own pool of
+// workers
+type JobType string
+
+const (
+	JobTypeImage    JobType = "image"    // compression
+	JobTypeVideo    JobType = "video"    // HLS transcoding and thumbnail
+	JobTypeDocument JobType = "document" // thumbnail and page count
+)
+
+// JobTypes lists every job type
+var JobTypes = []JobType{JobTypeImage, JobTypeVideo, JobTypeDocument}
+
+// JobStatus is the state of a processing job
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"    // waiting for a worker, or for its next attempt
+	JobStatusRunning   JobStatus = "running"   // leased by a worker
+	JobStatusCompleted JobStatus = "completed" // processed
+	JobStatusDead      JobStatus = "dead"      // failed every attempt
+	JobStatusCancelled JobStatus = "cancelled" // cancelled before it completed
+)
+
+// ProcessingJob processes an uploaded media file. A worker leases the job
+// and renews the lease while it runs, so that the job of a worker that
+// crashed is queued again once its lease expires. Failed attempts are
+// retried with exponential backoff until MaxAttempts, then the job is dead.
+type ProcessingJob struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TenantID       primitive.ObjectID `json:"tenantId" bson:"tenantId"`
+	FileID         primitive.ObjectID `json:"fileId" bson:"fileId"`
+	Type           JobType            `json:"type" bson:"type"`
+	Status         JobStatus          `json:"status" bson:"status"`
+	Progress       float64            `json:"progress" bson:"progress"`               // percent of the current attempt
+	Stage          string             `json:"stage,omitempty" bson:"stage,omitempty"` // step the current attempt is at
+	Attempts       int                `json:"attempts" bson:"attempts"`               // attempts started so far
+	MaxAttempts    int                `json:"maxAttempts" bson:"maxAttempts"`
+	LastError      string             `json:"lastError,omitempty" bson:"lastError,omitempty"`
+	RunAt          time.Time          `json:"runAt" bson:"runAt"` // earliest time of the next attempt
+	LeaseOwner     string             `json:"leaseOwner,omitempty" bson:"leaseOwner,omitempty"`
+	LeaseExpiresAt *time.Time         `json:"leaseExpiresAt,omitempty" bson:"leaseExpiresAt,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt" bson:"updatedAt"`
+	StartedAt      *time.Time         `json:"startedAt,omitempty" bson:"startedAt,omitempty"`   // of the current attempt
+	FinishedAt     *time.Time         `json:"finishedAt,omitempty" bson:"finishedAt,omitempty"` // once completed, dead or cancelled
+}
+
+// JobFilter filters the jobs of a tenant
+type JobFilter struct {
+	Status JobStatus
+	Type   JobType
+	FileID *primitive.ObjectID
+}
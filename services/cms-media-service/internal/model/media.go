@@ -25,7 +25,8 @@ type MediaFile struct {
 	TenantID       primitive.ObjectID     `json:"tenantId" bson:"tenantId"`
 	FileName       string                 `json:"fileName" bson:"fileName"`
 	OriginalName   string                 `json:"originalName" bson:"originalName"`
-	FilePath       string                 `json:"filePath" bson:"filePath"`
+	FilePath       string                 `json:"filePath" bson:"filePath"`                           // storage key
+	ContentHash    string                 `json:"contentHash,omitempty" bson:"contentHash,omitempty"` // SHA-256 of the uploaded content
 	FileType       FileType               `json:"fileType" bson:"fileType"`
 	MimeType       string                 `json:"mimeType" bson:"mimeType"`
 	FileSize       int64                  `json:"fileSize" bson:"fileSize"`             // in bytes
@@ -46,7 +47,10 @@ type MediaFile struct {
 
 	// Video specific
 	VideoFormats []VideoFormat `json:"videoFormats,omitempty" bson:"videoFormats,omitempty"`
-	M3U8Path     string        `json:"m3u8Path,omitempty" bson:"m3u8Path,omitempty"`
+	M3U8Path     string        `json:"m3u8Path,omitempty" bson:"m3u8Path,omitempty"` // storage key of the master playlist
+
+	// Image specific
+	Derivatives []ImageDerivative `json:"derivatives,omitempty" bson:"derivatives,omitempty"` // resized copies for srcset
 
 	// Processing status
 	ProcessingStatus string `json:"processingStatus" bson:"processingStatus"` // pending, processing, completed, failed
@@ -56,7 +60,7 @@ type MediaFile struct {
 // VideoFormat represents different video format outputs
 type VideoFormat struct {
 	Resolution string `json:"resolution" bson:"resolution"` // 720p, 1080p, etc.
-	Path       string `json:"path" bson:"path"`
+	Path       string `json:"path" bson:"path"`             // storage key of the playlist
 	Bitrate    int    `json:"bitrate" bson:"bitrate"`
 	Size       int64  `json:"size" bson:"size"`
 }
@@ -85,6 +89,7 @@ type TenantStorageUsage struct {
 	ImageSize    int64              `json:"imageSize" bson:"imageSize"`
 	VideoSize    int64              `json:"videoSize" bson:"videoSize"`
 	DocumentSize int64              `json:"documentSize" bson:"documentSize"`
+	Quota        int64              `json:"quota,omitempty" bson:"quota,omitempty"` // Bytes the tenant may store; 0 uses the service default
 	LastUpdated  time.Time          `json:"lastUpdated" bson:"lastUpdated"`
 }
 
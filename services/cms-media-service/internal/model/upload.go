@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ResumableUpload is a file being uploaded in chunks with the tus protocol.
+// Each chunk is stored as a part until the upload is complete, then the
+// parts are assembled into a MediaFile.
+type ResumableUpload struct {
+	ID         primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	TenantID   primitive.ObjectID  `json:"tenantId" bson:"tenantId"`
+	UploadedBy string              `json:"uploadedBy" bson:"uploadedBy"`
+	FileName   string              `json:"fileName" bson:"fileName"`
+	MimeType   string              `json:"mimeType" bson:"mimeType"`
+	FileType   FileType            `json:"fileType" bson:"fileType"`
+	Folder     string              `json:"folder" bson:"folder"`
+	Length     int64               `json:"length" bson:"length"` // in bytes, announced by Upload-Length
+	Offset     int64               `json:"offset" bson:"offset"` // bytes received so far
+	Parts      []UploadPart        `json:"parts" bson:"parts"`
+	Metadata   map[string]string   `json:"metadata,omitempty" bson:"metadata,omitempty"` // Upload-Metadata
+	IPAddress  string              `json:"ipAddress,omitempty" bson:"ipAddress,omitempty"`
+	UserAgent  string              `json:"userAgent,omitempty" bson:"userAgent,omitempty"`
+	Completing bool                `json:"completing" bson:"completing"` // parts are being assembled
+	FileID     *primitive.ObjectID `json:"fileId,omitempty" bson:"fileId,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt  time.Time           `json:"updatedAt" bson:"updatedAt"`
+	ExpiresAt  time.Time           `json:"expiresAt" bson:"expiresAt"`
+}
+
+// UploadPart is a stored chunk of a resumable upload
+type UploadPart struct {
+	Key    string `json:"key" bson:"key"` // storage key
+	Offset int64  `json:"offset" bson:"offset"`
+	Size   int64  `json:"size" bson:"size"`
+}
+
+// Complete reports whether every byte of the upload was received
+func (u *ResumableUpload) Complete() bool {
+	return u.Offset == u.Length
+}
@@ -10,13 +10,14 @@ import (
 
 // DocumentProcessor handles document processing operations
 type DocumentProcessor struct {
-	outputDir string
+	workDir string
 }
 
-// NewDocumentProcessor creates a new document processor
-func NewDocumentProcessor(outputDir string) *DocumentProcessor {
+// NewDocumentProcessor creates a new document processor that converts
+// documents in temporary directories under workDir
+func NewDocumentProcessor(workDir string) *DocumentProcessor {
 	return &DocumentProcessor{
-		outputDir: outputDir,
+		workDir: workDir,
 	}
 }
 
@@ -39,8 +40,10 @@ func (dp *DocumentProcessor) ExtractPDFThumbnail(pdfPath, outputPath string) err
 // ExtractDocxThumbnail extracts thumbnail from DOCX file
 func (dp *DocumentProcessor) ExtractDocxThumbnail(docxPath, outputPath string) error {
 	// First convert DOCX to PDF using LibreOffice
-	tmpDir := filepath.Join(dp.outputDir, "tmp")
-	os.MkdirAll(tmpDir, 0755)
+	tmpDir, err := os.MkdirTemp(dp.workDir, "convert-")
+	if err != nil {
+		return err
+	}
 	defer os.RemoveAll(tmpDir)
 
 	// Convert to PDF
@@ -66,8 +69,10 @@ func (dp *DocumentProcessor) ExtractDocxThumbnail(docxPath, outputPath string) e
 // ExtractPPTXThumbnail extracts thumbnail from PPTX file
 func (dp *DocumentProcessor) ExtractPPTXThumbnail(pptxPath, outputPath string) error {
 	// Similar to DOCX - convert to PDF first then extract thumbnail
-	tmpDir := filepath.Join(dp.outputDir, "tmp")
-	os.MkdirAll(tmpDir, 0755)
+	tmpDir, err := os.MkdirTemp(dp.workDir, "convert-")
+	if err != nil {
+		return err
+	}
 	defer os.RemoveAll(tmpDir)
 
 	// Convert to PDF
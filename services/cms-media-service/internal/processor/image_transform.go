@@ -0,0 +1,157 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// ErrNoImageTool is returned by transforms when neither ImageMagick nor
+// FFmpeg is installed
+var ErrNoImageTool = errors.New("no image processing tool is installed")
+
+// ImageTransform describes a resized copy of an image. Images are never
+// scaled up.
+type ImageTransform struct {
+	Width   int     // 0 scales with Height
+	Height  int     // 0 scales with Width
+	Crop    bool    // cover Width x Height and crop around the focus, instead of fitting within it
+	FocusX  float64 // focus of crops, as fractions of the width and height
+	FocusY  float64
+	Format  string // jpeg, png, webp or avif
+	Quality int    // 1-100
+}
+
+// imageGeometry is how a transform scales an image, then which area of
+// the scaled image it keeps
+type imageGeometry struct {
+	scaleWidth, scaleHeight int
+	cropX, cropY            int
+	width, height           int
+}
+
+// cropped reports whether the geometry keeps only part of the scaled image
+func (g imageGeometry) cropped() bool {
+	return g.width != g.scaleWidth || g.height != g.scaleHeight
+}
+
+// geometry computes the geometry of a transform of an image of a size
+func (t ImageTransform) geometry(srcWidth, srcHeight int) imageGeometry {
+	width, height := float64(t.Width), float64(t.Height)
+	sw, sh := float64(srcWidth), float64(srcHeight)
+
+	if !t.Crop || t.Width <= 0 || t.Height <= 0 {
+		scale := 1.0
+		if t.Width > 0 {
+			scale = math.Min(scale, width/sw)
+		}
+		if t.Height > 0 {
+			scale = math.Min(scale, height/sh)
+		}
+		w, h := scaled(sw, scale), scaled(sh, scale)
+		return imageGeometry{scaleWidth: w, scaleHeight: h, width: w, height: h}
+	}
+
+	// A box larger than the image shrinks to fit it, keeping its shape
+	shrink := math.Min(1, math.Min(sw/width, sh/height))
+	w, h := scaled(width, shrink), scaled(height, shrink)
+
+	scale := math.Max(float64(w)/sw, float64(h)/sh)
+	g := imageGeometry{
+		scaleWidth:  max(scaled(sw, scale), w),
+		scaleHeight: max(scaled(sh, scale), h),
+		width:       w,
+		height:      h,
+	}
+	g.cropX = cropOffset(t.FocusX, g.scaleWidth, w)
+	g.cropY = cropOffset(t.FocusY, g.scaleHeight, h)
+	return g
+}
+
+// Size returns the size of the copy a transform makes of an image of a size
+func (t ImageTransform) Size(srcWidth, srcHeight int) (int, int) {
+	g := t.geometry(srcWidth, srcHeight)
+	return g.width, g.height
+}
+
+// scaled scales a length, to at least 1 pixel
+func scaled(length, scale float64) int {
+	return max(int(math.Round(length*scale)), 1)
+}
+
+// cropOffset centres a crop of a length on a focus as far as the scaled
+// length allows
+func cropOffset(focus float64, scaledLength, length int) int {
+	offset := int(math.Round(focus*float64(scaledLength) - float64(length)/2))
+	return min(max(offset, 0), scaledLength-length)
+}
+
+// Transform writes a resized copy of an image of a size to outputPath, in
+// the format of the transform, and returns the size of the copy
+func (p *ImageProcessor) Transform(ctx context.Context, inputPath, outputPath string, srcWidth, srcHeight int, t ImageTransform) (int, int, error) {
+	if srcWidth <= 0 || srcHeight <= 0 {
+		return 0, 0, fmt.Errorf("invalid image size %dx%d", srcWidth, srcHeight)
+	}
+	g := t.geometry(srcWidth, srcHeight)
+	if t.Quality < 1 || t.Quality > 100 {
+		t.Quality = p.quality
+	}
+
+	var cmd *exec.Cmd
+	if _, err := exec.LookPath("convert"); err == nil {
+		cmd = exec.CommandContext(ctx, "convert", imageMagickArgs(inputPath, outputPath, g, t)...)
+	} else if _, err := exec.LookPath("ffmpeg"); err == nil {
+		cmd = exec.CommandContext(ctx, "ffmpeg", ffmpegImageArgs(inputPath, outputPath, g, t)...)
+	} else {
+		return 0, 0, ErrNoImageTool
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(outputPath)
+		return 0, 0, fmt.Errorf("%s %s conversion failed: %w: %s", cmd.Args[0], t.Format, err, output)
+	}
+	return g.width, g.height, nil
+}
+
+// imageMagickArgs returns the arguments of a transform with ImageMagick.
+// Only the first frame of animations is kept.
+func imageMagickArgs(inputPath, outputPath string, g imageGeometry, t ImageTransform) []string {
+	args := []string{
+		inputPath + "[0]",
+		"-resize", fmt.Sprintf("%dx%d!", g.scaleWidth, g.scaleHeight),
+	}
+	if g.cropped() {
+		args = append(args, "-crop", fmt.Sprintf("%dx%d+%d+%d", g.width, g.height, g.cropX, g.cropY), "+repage")
+	}
+	return append(args,
+		"-strip",
+		"-quality", strconv.Itoa(t.Quality),
+		t.Format+":"+outputPath,
+	)
+}
+
+// ffmpegImageArgs returns the arguments of a transform with FFmpeg
+func ffmpegImageArgs(inputPath, outputPath string, g imageGeometry, t ImageTransform) []string {
+	filter := fmt.Sprintf("scale=%d:%d", g.scaleWidth, g.scaleHeight)
+	if g.cropped() {
+		filter += fmt.Sprintf(",crop=%d:%d:%d:%d", g.width, g.height, g.cropX, g.cropY)
+	}
+	args := []string{"-i", inputPath, "-frames:v", "1", "-vf", filter, "-map_metadata", "-1"}
+
+	switch t.Format {
+	case "jpeg":
+		// 2 is the best quality and 31 the worst
+		args = append(args, "-q:v", strconv.Itoa(31-t.Quality*29/100), "-f", "image2")
+	case "png":
+		args = append(args, "-f", "image2")
+	case "webp":
+		args = append(args, "-c:v", "libwebp", "-quality", strconv.Itoa(t.Quality), "-f", "webp")
+	case "avif":
+		args = append(args, "-c:v", "libaom-av1", "-still-picture", "1", "-crf", strconv.Itoa(63-t.Quality*63/100), "-f", "avif")
+	}
+	return append(args, "-y", outputPath)
+}
@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImageTransformGeometry(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform ImageTransform
+		width     int
+		height    int
+		want      imageGeometry
+	}{
+		{
+			name:      "contain by width",
+			transform: ImageTransform{Width: 800},
+			width:     2000, height: 1000,
+			want: imageGeometry{scaleWidth: 800, scaleHeight: 400, width: 800, height: 400},
+		},
+		{
+			name:      "contain within box",
+			transform: ImageTransform{Width: 800, Height: 800},
+			width:     1000, height: 2000,
+			want: imageGeometry{scaleWidth: 400, scaleHeight: 800, width: 400, height: 800},
+		},
+		{
+			name:      "contain never scales up",
+			transform: ImageTransform{Width: 1600},
+			width:     800, height: 600,
+			want: imageGeometry{scaleWidth: 800, scaleHeight: 600, width: 800, height: 600},
+		},
+		{
+			name:      "cover centred",
+			transform: ImageTransform{Width: 400, Height: 400, Crop: true, FocusX: 0.5, FocusY: 0.5},
+			width:     2000, height: 1000,
+			want: imageGeometry{scaleWidth: 800, scaleHeight: 400, cropX: 200, width: 400, height: 400},
+		},
+		{
+			name:      "cover on focal point",
+			transform: ImageTransform{Width: 400, Height: 400, Crop: true, FocusX: 0.3, FocusY: 0.5},
+			width:     2000, height: 1000,
+			want: imageGeometry{scaleWidth: 800, scaleHeight: 400, cropX: 40, width: 400, height: 400},
+		},
+		{
+			name:      "cover clamps focal point to the edge",
+			transform: ImageTransform{Width: 400, Height: 400, Crop: true, FocusX: 0.95, FocusY: 0.5},
+			width:     2000, height: 1000,
+			want: imageGeometry{scaleWidth: 800, scaleHeight: 400, cropX: 400, width: 400, height: 400},
+		},
+		{
+			name:      "cover shrinks a box larger than the image",
+			transform: ImageTransform{Width: 1600, Height: 900, Crop: true, FocusX: 0.5, FocusY: 0},
+			width:     800, height: 800,
+			want: imageGeometry{scaleWidth: 800, scaleHeight: 800, width: 800, height: 450},
+		},
+		{
+			name:      "cover with one side contains",
+			transform: ImageTransform{Height: 500, Crop: true},
+			width:     2000, height: 1000,
+			want: imageGeometry{scaleWidth: 1000, scaleHeight: 500, width: 1000, height: 500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.transform.geometry(tt.width, tt.height)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("geometry(%d, %d) = %+v, want %+v", tt.width, tt.height, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageMagickArgs(t *testing.T) {
+	transform := ImageTransform{Width: 400, Height: 400, Crop: true, FocusX: 0.5, FocusY: 0.5, Format: "webp", Quality: 80}
+	got := imageMagickArgs("in.jpg", "out.webp", transform.geometry(2000, 1000), transform)
+	want := []string{"in.jpg[0]", "-resize", "800x400!", "-crop", "400x400+200+0", "+repage", "-strip", "-quality", "80", "webp:out.webp"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("imageMagickArgs() = %q, want %q", got, want)
+	}
+}
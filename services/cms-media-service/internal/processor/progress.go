@@ -0,0 +1,37 @@
+package processor
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// readProgress reads the key=value lines ffmpeg writes with -progress and
+// reports the fraction of duration, in seconds, processed so far. It
+// reports 1 when ffmpeg ends, and returns once r is exhausted.
+func readProgress(r io.Reader, duration float64, report func(float64)) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		// out_time_ms is in microseconds too, despite its name
+		case "out_time_us", "out_time_ms":
+			microseconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || microseconds < 0 || duration <= 0 {
+				continue
+			}
+			report(min(float64(microseconds)/1e6/duration, 1))
+		case "progress":
+			if value == "end" {
+				report(1)
+			}
+		}
+	}
+	// Drain the rest so ffmpeg never blocks on a full pipe
+	io.Copy(io.Discard, r)
+}
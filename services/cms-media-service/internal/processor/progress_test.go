@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadProgress(t *testing.T) {
+	output := `frame=120
+fps=48.00
+out_time_us=2500000
+out_time_ms=2500000
+out_time=00:00:02.500000
+progress=continue
+frame=240
+out_time_us=N/A
+out_time_us=7500000
+progress=continue
+out_time_us=12000000
+progress=end
+`
+	var reports []float64
+	readProgress(strings.NewReader(output), 10, func(fraction float64) {
+		reports = append(reports, fraction)
+	})
+
+	want := []float64{0.25, 0.25, 0.75, 1, 1}
+	if !reflect.DeepEqual(reports, want) {
+		t.Errorf("readProgress() reported %v, want %v", reports, want)
+	}
+
+	reports = nil
+	readProgress(strings.NewReader("out_time_us=5000000\nprogress=end\n"), 0, func(fraction float64) {
+		reports = append(reports, fraction)
+	})
+	if want := []float64{1}; !reflect.DeepEqual(reports, want) {
+		t.Errorf("readProgress() without a duration reported %v, want %v", reports, want)
+	}
+}
@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,20 +11,18 @@ import (
 )
 
 // VideoProcessor handles video processing operations
-type VideoProcessor struct {
-	outputDir string
-}
+type VideoProcessor struct{}
 
 // NewVideoProcessor creates a new video processor
-func NewVideoProcessor(outputDir string) *VideoProcessor {
-	return &VideoProcessor{
-		outputDir: outputDir,
-	}
+func NewVideoProcessor() *VideoProcessor {
+	return &VideoProcessor{}
 }
 
-// ConvertToHLS converts video to HLS format (m3u8)
-func (vp *VideoProcessor) ConvertToHLS(inputPath string, outputName string) (string, []VideoResolution, error) {
-	baseDir := filepath.Join(vp.outputDir, outputName)
+// ConvertToHLS converts video to HLS format (m3u8), writing the playlists and
+// segments to baseDir. The video's duration, in seconds, is used to report
+// the fraction converted so far to progress, if it is not nil. Conversion
+// stops when ctx is cancelled.
+func (vp *VideoProcessor) ConvertToHLS(ctx context.Context, inputPath string, baseDir string, duration float64, progress func(float64)) (string, []VideoResolution, error) {
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
 		return "", nil, err
 	}
@@ -38,8 +37,9 @@ func (vp *VideoProcessor) ConvertToHLS(inputPath string, outputName string) (str
 	}
 
 	var generatedResolutions []VideoResolution
+	var lastErr error
 
-	for _, res := range resolutions {
+	for i, res := range resolutions {
 		outputPath := filepath.Join(baseDir, fmt.Sprintf("%s.m3u8", res.Name))
 
 		args := []string{
@@ -53,17 +53,32 @@ func (vp *VideoProcessor) ConvertToHLS(inputPath string, outputName string) (str
 			"-hls_list_size", "0",
 			"-hls_segment_filename", filepath.Join(baseDir, fmt.Sprintf("%s_%%03d.ts", res.Name)),
 			"-f", "hls",
+			"-progress", "pipe:1",
+			"-nostats",
+			"-y",
 			outputPath,
 		}
 
-		cmd := exec.Command("ffmpeg", args...)
-		if err := cmd.Run(); err != nil {
+		// Each resolution is an equal share of the conversion
+		report := func(fraction float64) {
+			if progress != nil {
+				progress((float64(i) + fraction) / float64(len(resolutions)))
+			}
+		}
+		if err := vp.runFFmpeg(ctx, args, duration, report); err != nil {
+			if ctx.Err() != nil {
+				return "", nil, ctx.Err()
+			}
 			// Skip this resolution if conversion fails
+			lastErr = err
 			continue
 		}
 
 		generatedResolutions = append(generatedResolutions, res)
 	}
+	if len(generatedResolutions) == 0 {
+		return "", nil, fmt.Errorf("hls conversion failed: %w", lastErr)
+	}
 
 	// Create master playlist
 	if err := vp.createMasterPlaylist(m3u8Path, generatedResolutions); err != nil {
@@ -73,6 +88,21 @@ func (vp *VideoProcessor) ConvertToHLS(inputPath string, outputName string) (str
 	return m3u8Path, generatedResolutions, nil
 }
 
+// runFFmpeg runs ffmpeg with -progress output on stdout, reporting the
+// fraction of duration converted
+func (vp *VideoProcessor) runFFmpeg(ctx context.Context, args []string, duration float64, report func(float64)) error {
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	readProgress(stdout, duration, report)
+	return cmd.Wait()
+}
+
 // createMasterPlaylist creates an HLS master playlist
 func (vp *VideoProcessor) createMasterPlaylist(masterPath string, resolutions []VideoResolution) error {
 	var content strings.Builder
@@ -0,0 +1,58 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/storage"
+)
+
+// Workspace is a temporary local directory in which a stored file is
+// processed. The command line tools the processors run need local files, so
+// the input is fetched from storage and the outputs are stored back.
+type Workspace struct {
+	store storage.Storage
+	dir   string
+}
+
+// NewWorkspace creates a workspace under parentDir, or under the system
+// temporary directory if parentDir is empty
+func NewWorkspace(store storage.Storage, parentDir string) (*Workspace, error) {
+	if parentDir != "" {
+		if err := os.MkdirAll(parentDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	dir, err := os.MkdirTemp(parentDir, "media-")
+	if err != nil {
+		return nil, err
+	}
+	return &Workspace{store: store, dir: dir}, nil
+}
+
+// Path returns the local path of a file in the workspace
+func (w *Workspace) Path(name string) string {
+	return filepath.Join(w.dir, name)
+}
+
+// Fetch copies a stored file into the workspace and returns its local path
+func (w *Workspace) Fetch(ctx context.Context, key, name string) (string, error) {
+	localPath := w.Path(name)
+	return localPath, storage.FetchFile(ctx, w.store, key, localPath)
+}
+
+// Store stores a file of the workspace under key and returns its size
+func (w *Workspace) Store(ctx context.Context, name, key string) (int64, error) {
+	return storage.PutFile(ctx, w.store, key, w.Path(name), "")
+}
+
+// StoreDir stores every file of a workspace directory under prefix
+func (w *Workspace) StoreDir(ctx context.Context, name, prefix string) error {
+	return storage.PutDir(ctx, w.store, prefix, w.Path(name))
+}
+
+// Close removes the workspace and everything in it
+func (w *Workspace) Close() error {
+	return os.RemoveAll(w.dir)
+}
@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// JobRepository handles processing job data operations
+type JobRepository struct {
+	collection *mongo.Collection
+}
+
+// NewJobRepository creates a new processing job repository
+func NewJobRepository(db *mongo.Database) *JobRepository {
+	return &JobRepository{
+		collection: db.Collection("processing_jobs"),
+	}
+}
+
+// CreateIndexes creates the indexes of processing jobs
+func (r *JobRepository) CreateIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "type", Value: 1}, {Key: "runAt", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "leaseExpiresAt", Value: 1}}},
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "fileId", Value: 1}}},
+	})
+	return err
+}
+
+// Create queues a new job
+func (r *JobRepository) Create(ctx context.Context, job *model.ProcessingJob) error {
+	job.ID = primitive.NewObjectID()
+	job.Status = model.JobStatusQueued
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	if job.RunAt.IsZero() {
+		job.RunAt = job.CreatedAt
+	}
+
+	_, err := r.collection.InsertOne(ctx, job)
+	return err
+}
+
+// FindByID finds a tenant's job
+func (r *JobRepository) FindByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.ProcessingJob, error) {
+	var job model.ProcessingJob
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "tenantId": tenantID}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List lists a tenant's jobs, newest first
+func (r *JobRepository) List(ctx context.Context, tenantID primitive.ObjectID, filter model.JobFilter, page, limit int) ([]*model.ProcessingJob, int64, error) {
+	query := bson.M{"tenantId": tenantID}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Type != "" {
+		query["type"] = filter.Type
+	}
+	if filter.FileID != nil {
+		query["fileId"] = *filter.FileID
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []*model.ProcessingJob
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// Claim leases the queued job of a type that is due first to owner, and
+// starts its next attempt. It returns ErrNotFound if no job is due.
+func (r *JobRepository) Claim(ctx context.Context, jobType model.JobType, owner string, lease time.Duration) (*model.ProcessingJob, error) {
+	now := time.Now()
+	leaseExpiresAt := now.Add(lease)
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "runAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job model.ProcessingJob
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"status": model.JobStatusQueued, "type": jobType, "runAt": bson.M{"$lte": now}},
+		bson.M{
+			"$set": bson.M{
+				"status":         model.JobStatusRunning,
+				"leaseOwner":     owner,
+				"leaseExpiresAt": leaseExpiresAt,
+				"progress":       0,
+				"stage":          "",
+				"startedAt":      now,
+				"updatedAt":      now,
+			},
+			"$inc": bson.M{"attempts": 1},
+		},
+		opts,
+	).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Heartbeat renews the lease of a running job and records its progress. It
+// returns ErrConflict if the job is no longer leased to owner, because it
+// was cancelled or its lease expired.
+func (r *JobRepository) Heartbeat(ctx context.Context, id primitive.ObjectID, owner string, lease time.Duration, progress float64, stage string) error {
+	now := time.Now()
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": model.JobStatusRunning, "leaseOwner": owner},
+		bson.M{"$set": bson.M{
+			"leaseExpiresAt": now.Add(lease),
+			"progress":       progress,
+			"stage":          stage,
+			"updatedAt":      now,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// Complete marks a job leased to owner as completed
+func (r *JobRepository) Complete(ctx context.Context, id primitive.ObjectID, owner string) error {
+	now := time.Now()
+	return r.finish(ctx, id, owner, bson.M{
+		"status":     model.JobStatusCompleted,
+		"progress":   100,
+		"stage":      "",
+		"lastError":  "",
+		"finishedAt": now,
+		"updatedAt":  now,
+	})
+}
+
+// Fail records the failed attempt of a job leased to owner. The job is
+// queued again to run at runAt, or is dead if runAt is nil.
+func (r *JobRepository) Fail(ctx context.Context, id primitive.ObjectID, owner string, message string, runAt *time.Time) error {
+	now := time.Now()
+	set := bson.M{"lastError": message, "updatedAt": now}
+	if runAt != nil {
+		set["status"] = model.JobStatusQueued
+		set["runAt"] = *runAt
+	} else {
+		set["status"] = model.JobStatusDead
+		set["finishedAt"] = now
+	}
+	return r.finish(ctx, id, owner, set)
+}
+
+// Release queues a job leased to owner again without counting its attempt,
+// for workers that stop before the job finished
+func (r *JobRepository) Release(ctx context.Context, id primitive.ObjectID, owner string) error {
+	now := time.Now()
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": model.JobStatusRunning, "leaseOwner": owner},
+		bson.M{
+			"$set":   bson.M{"status": model.JobStatusQueued, "runAt": now, "updatedAt": now},
+			"$unset": bson.M{"leaseOwner": "", "leaseExpiresAt": ""},
+			"$inc":   bson.M{"attempts": -1},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// finish ends the attempt of a job leased to owner, returning ErrConflict if
+// it is no longer leased to owner
+func (r *JobRepository) finish(ctx context.Context, id primitive.ObjectID, owner string, set bson.M) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": model.JobStatusRunning, "leaseOwner": owner},
+		bson.M{"$set": set, "$unset": bson.M{"leaseOwner": "", "leaseExpiresAt": ""}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// RequeueExpired queues the running jobs whose lease expired before now
+// again, or marks them dead if they have no attempts left. It returns the
+// jobs that are now dead, whose files failed.
+func (r *JobRepository) RequeueExpired(ctx context.Context, now time.Time) (int64, []*model.ProcessingJob, error) {
+	expired := bson.M{"status": model.JobStatusRunning, "leaseExpiresAt": bson.M{"$lt": now}}
+	message := "worker stopped responding"
+
+	// Jobs without attempts left
+	deadFilter := bson.M{"$and": bson.A{expired, bson.M{"$expr": bson.M{"$gte": bson.A{"$attempts", "$maxAttempts"}}}}}
+	cursor, err := r.collection.Find(ctx, deadFilter)
+	if err != nil {
+		return 0, nil, err
+	}
+	var dead []*model.ProcessingJob
+	if err := cursor.All(ctx, &dead); err != nil {
+		return 0, nil, err
+	}
+	var deadJobs []*model.ProcessingJob
+	for _, job := range dead {
+		result, err := r.collection.UpdateOne(ctx,
+			bson.M{"_id": job.ID, "status": model.JobStatusRunning, "leaseOwner": job.LeaseOwner, "leaseExpiresAt": bson.M{"$lt": now}},
+			bson.M{
+				"$set":   bson.M{"status": model.JobStatusDead, "lastError": message, "finishedAt": now, "updatedAt": now},
+				"$unset": bson.M{"leaseOwner": "", "leaseExpiresAt": ""},
+			},
+		)
+		if err != nil {
+			return 0, deadJobs, err
+		}
+		if result.ModifiedCount > 0 {
+			deadJobs = append(deadJobs, job)
+		}
+	}
+
+	result, err := r.collection.UpdateMany(ctx, expired, bson.M{
+		"$set":   bson.M{"status": model.JobStatusQueued, "runAt": now, "lastError": message, "updatedAt": now},
+		"$unset": bson.M{"leaseOwner": "", "leaseExpiresAt": ""},
+	})
+	if err != nil {
+		return 0, deadJobs, err
+	}
+	return result.ModifiedCount, deadJobs, nil
+}
+
+// Cancel cancels a tenant's queued or running job. It returns ErrConflict if
+// the job already finished.
+func (r *JobRepository) Cancel(ctx context.Context, tenantID, id primitive.ObjectID) (*model.ProcessingJob, error) {
+	now := time.Now()
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var job model.ProcessingJob
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":      id,
+			"tenantId": tenantID,
+			"status":   bson.M{"$in": bson.A{model.JobStatusQueued, model.JobStatusRunning}},
+		},
+		bson.M{
+			"$set":   bson.M{"status": model.JobStatusCancelled, "finishedAt": now, "updatedAt": now},
+			"$unset": bson.M{"leaseOwner": "", "leaseExpiresAt": ""},
+		},
+		opts,
+	).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, r.missingOrConflict(ctx, tenantID, id)
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CancelByFile cancels the queued and running jobs of a file
+func (r *JobRepository) CancelByFile(ctx context.Context, fileID primitive.ObjectID) error {
+	now := time.Now()
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"fileId": fileID, "status": bson.M{"$in": bson.A{model.JobStatusQueued, model.JobStatusRunning}}},
+		bson.M{
+			"$set":   bson.M{"status": model.JobStatusCancelled, "finishedAt": now, "updatedAt": now},
+			"$unset": bson.M{"leaseOwner": "", "leaseExpiresAt": ""},
+		},
+	)
+	return err
+}
+
+// Retry queues a tenant's dead or cancelled job again with all its attempts.
+// It returns ErrConflict if the job is queued, running or completed.
+func (r *JobRepository) Retry(ctx context.Context, tenantID, id primitive.ObjectID) (*model.ProcessingJob, error) {
+	now := time.Now()
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var job model.ProcessingJob
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":      id,
+			"tenantId": tenantID,
+			"status":   bson.M{"$in": bson.A{model.JobStatusDead, model.JobStatusCancelled}},
+		},
+		bson.M{
+			"$set": bson.M{
+				"status":    model.JobStatusQueued,
+				"attempts":  0,
+				"progress":  0,
+				"stage":     "",
+				"runAt":     now,
+				"updatedAt": now,
+			},
+			"$unset": bson.M{"finishedAt": ""},
+		},
+		opts,
+	).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, r.missingOrConflict(ctx, tenantID, id)
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// missingOrConflict tells apart a job that does not exist from one whose
+// status did not allow an update
+func (r *JobRepository) missingOrConflict(ctx context.Context, tenantID, id primitive.ObjectID) error {
+	if _, err := r.FindByID(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return ErrConflict
+}
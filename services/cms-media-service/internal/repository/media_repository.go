@@ -24,6 +24,7 @@ type MediaRepository struct {
 	configCollection     *mongo.Collection
 	permissionCollection *mongo.Collection
 	folderCollection     *mongo.Collection
+	presetCollection     *mongo.Collection
 }
 
 // NewMediaRepository creates a new media repository
@@ -35,6 +36,7 @@ func NewMediaRepository(db *mongo.Database) *MediaRepository {
 		configCollection:     db.Collection("file_type_configs"),
 		permissionCollection: db.Collection("file_permissions"),
 		folderCollection:     db.Collection("folders"),
+		presetCollection:     db.Collection("image_presets"),
 	}
 }
 
@@ -43,7 +45,9 @@ func (r *MediaRepository) CreateFile(ctx context.Context, file *model.MediaFile)
 	file.ID = primitive.NewObjectID()
 	file.CreatedAt = time.Now()
 	file.UpdatedAt = time.Now()
-	file.ProcessingStatus = "completed"
+	if file.ProcessingStatus == "" {
+		file.ProcessingStatus = "completed"
+	}
 
 	_, err := r.mediaCollection.InsertOne(ctx, file)
 	return err
@@ -65,6 +69,34 @@ func (r *MediaRepository) FindFileByID(ctx context.Context, id primitive.ObjectI
 	return &file, nil
 }
 
+// FindProcessedFileByContentHash finds a tenant's processed file with the
+// given content, whose stored objects an upload of the same content can reuse
+func (r *MediaRepository) FindProcessedFileByContentHash(ctx context.Context, tenantID primitive.ObjectID, hash string) (*model.MediaFile, error) {
+	var file model.MediaFile
+	err := r.mediaCollection.FindOne(ctx, bson.M{
+		"tenantId":         tenantID,
+		"contentHash":      hash,
+		"processingStatus": "completed",
+		"deletedAt":        nil,
+	}).Decode(&file)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &file, nil
+}
+
+// CountFilesByContentHash counts a tenant's files with the given content
+func (r *MediaRepository) CountFilesByContentHash(ctx context.Context, tenantID primitive.ObjectID, hash string) (int64, error) {
+	return r.mediaCollection.CountDocuments(ctx, bson.M{
+		"tenantId":    tenantID,
+		"contentHash": hash,
+		"deletedAt":   nil,
+	})
+}
+
 // FindFilesByFolder finds all files in a folder
 func (r *MediaRepository) FindFilesByFolder(ctx context.Context, tenantID primitive.ObjectID, folder string, page, limit int) ([]*model.MediaFile, int64, error) {
 	filter := bson.M{
@@ -109,6 +141,62 @@ func (r *MediaRepository) UpdateFile(ctx context.Context, file *model.MediaFile)
 	return err
 }
 
+// UpdateProcessingStatus sets the processing status of a media file and the
+// error of its last processing attempt
+func (r *MediaRepository) UpdateProcessingStatus(ctx context.Context, id primitive.ObjectID, status, processingError string) error {
+	_, err := r.mediaCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{
+			"processingStatus": status,
+			"processingError":  processingError,
+			"updatedAt":        time.Now(),
+		}},
+	)
+	return err
+}
+
+// UpdateProcessingResult records the outputs of processing a media file
+// and its processing status, leaving the fields users edit untouched
+func (r *MediaRepository) UpdateProcessingResult(ctx context.Context, file *model.MediaFile) error {
+	set := bson.M{
+		"fileSize":         file.FileSize,
+		"compressedSize":   file.CompressedSize,
+		"width":            file.Width,
+		"height":           file.Height,
+		"duration":         file.Duration,
+		"thumbnail":        file.Thumbnail,
+		"videoFormats":     file.VideoFormats,
+		"m3u8Path":         file.M3U8Path,
+		"derivatives":      file.Derivatives,
+		"processingStatus": file.ProcessingStatus,
+		"processingError":  file.ProcessingError,
+		"updatedAt":        time.Now(),
+	}
+	if pageCount, ok := file.Metadata["pageCount"]; ok {
+		set["metadata.pageCount"] = pageCount
+	}
+
+	_, err := r.mediaCollection.UpdateOne(ctx, bson.M{"_id": file.ID}, bson.M{"$set": set})
+	return err
+}
+
+// SetFocalPoint sets the focal point of an image
+func (r *MediaRepository) SetFocalPoint(ctx context.Context, id primitive.ObjectID, focalPoint model.FocalPoint) error {
+	result, err := r.mediaCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "deletedAt": nil},
+		bson.M{"$set": bson.M{"metadata.focalPoint": focalPoint, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 // DeleteFile soft deletes a media file
 func (r *MediaRepository) DeleteFile(ctx context.Context, id primitive.ObjectID) error {
 	now := time.Now()
@@ -205,6 +293,40 @@ func (r *MediaRepository) GetFileTypeConfig(ctx context.Context, tenantID primit
 	return &config, nil
 }
 
+// GetImagePresets gets the image derivative presets of a tenant
+func (r *MediaRepository) GetImagePresets(ctx context.Context, tenantID primitive.ObjectID) (*model.ImagePresetConfig, error) {
+	var config model.ImagePresetConfig
+	err := r.presetCollection.FindOne(ctx, bson.M{"tenantId": tenantID}).Decode(&config)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			// Return default presets
+			return &model.ImagePresetConfig{
+				TenantID: tenantID,
+				Presets:  model.DefaultImagePresets,
+			}, nil
+		}
+		return nil, err
+	}
+	return &config, nil
+}
+
+// SaveImagePresets replaces the image derivative presets of a tenant
+func (r *MediaRepository) SaveImagePresets(ctx context.Context, config *model.ImagePresetConfig) error {
+	now := time.Now()
+	config.UpdatedAt = now
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	return r.presetCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{"tenantId": config.TenantID},
+		bson.M{
+			"$set":         bson.M{"presets": config.Presets, "updatedAt": now},
+			"$setOnInsert": bson.M{"createdAt": now},
+		},
+		opts,
+	).Decode(config)
+}
+
 // CheckPermission checks if user has permission for operation
 func (r *MediaRepository) CheckPermission(ctx context.Context, tenantID primitive.ObjectID, folder, userID, role string, operation string) (bool, error) {
 	filter := bson.M{
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrConflict is returned when a resumable upload changed since it was read
+var ErrConflict = errors.New("conflict")
+
+// UploadRepository handles resumable upload data operations
+type UploadRepository struct {
+	collection *mongo.Collection
+}
+
+// NewUploadRepository creates a new resumable upload repository
+func NewUploadRepository(db *mongo.Database) *UploadRepository {
+	return &UploadRepository{
+		collection: db.Collection("resumable_uploads"),
+	}
+}
+
+// CreateIndexes creates the indexes of resumable uploads
+func (r *UploadRepository) CreateIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tenantId", Value: 1}, {Key: "fileId", Value: 1}}},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}},
+	})
+	return err
+}
+
+// Create creates a new resumable upload
+func (r *UploadRepository) Create(ctx context.Context, upload *model.ResumableUpload) error {
+	upload.ID = primitive.NewObjectID()
+	upload.CreatedAt = time.Now()
+	upload.UpdatedAt = upload.CreatedAt
+	if upload.Parts == nil {
+		upload.Parts = []model.UploadPart{}
+	}
+
+	_, err := r.collection.InsertOne(ctx, upload)
+	return err
+}
+
+// FindByID finds a tenant's resumable upload that has not expired
+func (r *UploadRepository) FindByID(ctx context.Context, tenantID, id primitive.ObjectID) (*model.ResumableUpload, error) {
+	var upload model.ResumableUpload
+	err := r.collection.FindOne(ctx, bson.M{
+		"_id":       id,
+		"tenantId":  tenantID,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}).Decode(&upload)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// AppendPart records a received part if the upload is still at the part's
+// offset, and pushes back its expiry. It returns ErrConflict if another part
+// was appended first.
+func (r *UploadRepository) AppendPart(ctx context.Context, id primitive.ObjectID, part model.UploadPart, expiresAt time.Time) (*model.ResumableUpload, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var upload model.ResumableUpload
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "offset": part.Offset, "completing": false, "fileId": nil},
+		bson.M{
+			"$inc":  bson.M{"offset": part.Size},
+			"$push": bson.M{"parts": part},
+			"$set":  bson.M{"updatedAt": time.Now(), "expiresAt": expiresAt},
+		},
+		opts,
+	).Decode(&upload)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrConflict
+		}
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// ClaimCompletion marks a fully received upload as being assembled, so that
+// only one request assembles it. It returns ErrConflict if the upload is
+// incomplete, already being assembled or already assembled.
+func (r *UploadRepository) ClaimCompletion(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{
+			"_id":        id,
+			"completing": false,
+			"fileId":     nil,
+			"$expr":      bson.M{"$eq": bson.A{"$offset", "$length"}},
+		},
+		bson.M{"$set": bson.M{"completing": true, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// ReleaseCompletion clears the mark of a failed assembly so it can be retried
+func (r *UploadRepository) ReleaseCompletion(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"completing": false, "updatedAt": time.Now()}},
+	)
+	return err
+}
+
+// Complete records the file an upload was assembled into. Its parts are
+// deleted, so the record no longer lists them.
+func (r *UploadRepository) Complete(ctx context.Context, id, fileID primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{
+			"completing": false,
+			"fileId":     fileID,
+			"parts":      []model.UploadPart{},
+			"updatedAt":  time.Now(),
+		}},
+	)
+	return err
+}
+
+// Delete deletes a resumable upload
+func (r *UploadRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// FindExpired finds uploads that expired before now
+func (r *UploadRepository) FindExpired(ctx context.Context, now time.Time, limit int) ([]*model.ResumableUpload, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "expiresAt", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{"expiresAt": bson.M{"$lte": now}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var uploads []*model.ResumableUpload
+	if err := cursor.All(ctx, &uploads); err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+// SumPendingLength sums the announced lengths of a tenant's uploads that are
+// neither assembled nor expired, which the tenant's quota reserves
+func (r *UploadRepository) SumPendingLength(ctx context.Context, tenantID primitive.ObjectID) (int64, error) {
+	cursor, err := r.collection.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"tenantId": tenantID, "fileId": nil, "expiresAt": bson.M{"$gt": time.Now()}}},
+		{"$group": bson.M{"_id": nil, "total": bson.M{"$sum": "$length"}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Total int64 `bson:"total"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	return result.Total, cursor.Err()
+}
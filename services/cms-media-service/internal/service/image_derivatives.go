@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"path"
+	"regexp"
+	"slices"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/processor"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/transform"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrInvalidPresets    = errors.New("invalid image presets")
+	ErrInvalidFocalPoint = errors.New("focal point must be within the image")
+	ErrNotAnImage        = errors.New("file is not a raster image")
+	ErrPermissionDenied  = errors.New("insufficient permissions")
+)
+
+const (
+	maxImagePresets      = 10
+	maxPresetWidths      = 20
+	maxAspectRatio       = 10
+	defaultImageQuality  = 80
+	derivativesDirectory = "derivatives"
+)
+
+// presetName matches the names of presets, which are part of storage keys
+var presetName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// plannedDerivative is a derivative of an image to generate
+type plannedDerivative struct {
+	preset    string
+	name      string
+	width     int
+	height    int
+	transform processor.ImageTransform
+}
+
+// planDerivatives lists the derivatives of an image of a size for a set of
+// presets. Widths beyond the image's are replaced by the image's own width,
+// as images are never scaled up, and derivatives of the same size are
+// generated once.
+func planDerivatives(presets []model.ImagePreset, width, height int, focalPoint model.FocalPoint) []plannedDerivative {
+	var planned []plannedDerivative
+	for _, preset := range presets {
+		quality := preset.Quality
+		if quality == 0 {
+			quality = defaultImageQuality
+		}
+
+		seen := make(map[[2]int]bool)
+		for _, presetWidth := range preset.Widths {
+			t := processor.ImageTransform{Width: presetWidth, Quality: quality}
+			if preset.Fit == model.ImageFitCover && preset.AspectRatio > 0 {
+				t.Height = int(math.Round(float64(presetWidth) / preset.AspectRatio))
+				t.Crop = true
+				t.FocusX, t.FocusY = focalPoint.X, focalPoint.Y
+			}
+			w, h := t.Size(width, height)
+			if seen[[2]int{w, h}] {
+				continue
+			}
+			seen[[2]int{w, h}] = true
+
+			// Crops are named after the focal point they keep in view
+			base := fmt.Sprintf("%s-%dx%d", preset.Name, w, h)
+			if t.Crop {
+				base += fmt.Sprintf("-f%.0fx%.0f", focalPoint.X*100, focalPoint.Y*100)
+			}
+			for _, format := range preset.Formats {
+				t.Format = format
+				planned = append(planned, plannedDerivative{
+					preset:    preset.Name,
+					name:      base + formatExtension(format),
+					width:     w,
+					height:    h,
+					transform: t,
+				})
+			}
+		}
+	}
+	return planned
+}
+
+// generateDerivatives generates the derivatives of the tenant's image
+// presets from the image at sourcePath and stores them next to the file.
+// Derivatives that cannot be generated, for instance in a format the
+// installed tools do not support, are left out.
+func (s *MediaService) generateDerivatives(ctx context.Context, workspace *processor.Workspace, mediaFile *model.MediaFile, sourcePath string, report reportFunc) error {
+	if mediaFile.Width == 0 || mediaFile.Height == 0 {
+		log.Printf("Skipped the derivatives of %s, whose dimensions are unknown", mediaFile.ID.Hex())
+		return nil
+	}
+
+	config, err := s.repo.GetImagePresets(ctx, mediaFile.TenantID)
+	if err != nil {
+		return err
+	}
+
+	planned := planDerivatives(config.Presets, mediaFile.Width, mediaFile.Height, mediaFile.FocalPoint())
+	derivatives := make([]model.ImageDerivative, 0, len(planned))
+	for i, derivative := range planned {
+		report(30+60*float64(i)/float64(len(planned)), "derivatives")
+
+		_, _, err := s.imageProcessor.Transform(ctx, sourcePath, workspace.Path(derivative.name), mediaFile.Width, mediaFile.Height, derivative.transform)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, processor.ErrNoImageTool) {
+				log.Printf("Skipped the derivatives of %s: %v", mediaFile.ID.Hex(), err)
+				break
+			}
+			log.Printf("Failed to generate derivative %s of %s: %v", derivative.name, mediaFile.ID.Hex(), err)
+			continue
+		}
+
+		key := path.Join(path.Dir(mediaFile.FilePath), derivativesDirectory, derivative.name)
+		size, err := workspace.Store(ctx, derivative.name, key)
+		if err != nil {
+			return err
+		}
+		url, cdnURL := s.fileURLs(key)
+		if cdnURL != "" {
+			url = cdnURL
+		}
+		derivatives = append(derivatives, model.ImageDerivative{
+			Preset:   derivative.preset,
+			Width:    derivative.width,
+			Height:   derivative.height,
+			Format:   derivative.transform.Format,
+			MimeType: formatMimeType(derivative.transform.Format),
+			Path:     key,
+			URL:      url,
+			Size:     size,
+		})
+	}
+
+	mediaFile.Derivatives = derivatives
+	return nil
+}
+
+// formatExtension returns the file extension of an image format
+func formatExtension(format string) string {
+	if format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+// formatMimeType returns the MIME type of an image format
+func formatMimeType(format string) string {
+	return "image/" + format
+}
+
+// GetImagePresets gets the image derivative presets of a tenant
+func (s *MediaService) GetImagePresets(ctx context.Context, tenantID primitive.ObjectID) (*model.ImagePresetConfig, error) {
+	return s.repo.GetImagePresets(ctx, tenantID)
+}
+
+// SaveImagePresets replaces the image derivative presets of a tenant. They
+// apply to images processed from then on.
+func (s *MediaService) SaveImagePresets(ctx context.Context, tenantID primitive.ObjectID, presets []model.ImagePreset) (*model.ImagePresetConfig, error) {
+	if err := normalizePresets(presets); err != nil {
+		return nil, err
+	}
+	config := &model.ImagePresetConfig{TenantID: tenantID, Presets: presets}
+	if err := s.repo.SaveImagePresets(ctx, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// normalizePresets checks a set of presets and fills in their defaults
+func normalizePresets(presets []model.ImagePreset) error {
+	if len(presets) > maxImagePresets {
+		return fmt.Errorf("%w: at most %d presets", ErrInvalidPresets, maxImagePresets)
+	}
+
+	names := make(map[string]bool)
+	for i := range presets {
+		preset := &presets[i]
+		switch {
+		case !presetName.MatchString(preset.Name):
+			return fmt.Errorf("%w: name %q must be lower case letters, digits, - and _", ErrInvalidPresets, preset.Name)
+		case names[preset.Name]:
+			return fmt.Errorf("%w: duplicate name %q", ErrInvalidPresets, preset.Name)
+		case len(preset.Widths) == 0 || len(preset.Widths) > maxPresetWidths:
+			return fmt.Errorf("%w: %s needs 1 to %d widths", ErrInvalidPresets, preset.Name, maxPresetWidths)
+		case len(preset.Formats) == 0:
+			return fmt.Errorf("%w: %s needs a format", ErrInvalidPresets, preset.Name)
+		case preset.Quality < 0 || preset.Quality > 100:
+			return fmt.Errorf("%w: %s quality must be between 1 and 100", ErrInvalidPresets, preset.Name)
+		}
+		names[preset.Name] = true
+
+		for _, width := range preset.Widths {
+			if width < 1 || width > transform.MaxDimension {
+				return fmt.Errorf("%w: %s widths must be between 1 and %d", ErrInvalidPresets, preset.Name, transform.MaxDimension)
+			}
+		}
+		slices.Sort(preset.Widths)
+		preset.Widths = slices.Compact(preset.Widths)
+
+		for _, format := range preset.Formats {
+			if !slices.Contains(model.ImageFormats, format) {
+				return fmt.Errorf("%w: %s format %q is not one of %v", ErrInvalidPresets, preset.Name, format, model.ImageFormats)
+			}
+		}
+		slices.Sort(preset.Formats)
+		preset.Formats = slices.Compact(preset.Formats)
+
+		switch preset.Fit {
+		case "", model.ImageFitContain:
+			preset.Fit = model.ImageFitContain
+			preset.AspectRatio = 0
+		case model.ImageFitCover:
+			if preset.AspectRatio <= 0 || preset.AspectRatio > maxAspectRatio {
+				return fmt.Errorf("%w: %s needs an aspect ratio up to %d to cover", ErrInvalidPresets, preset.Name, maxAspectRatio)
+			}
+		default:
+			return fmt.Errorf("%w: %s fit must be contain or cover", ErrInvalidPresets, preset.Name)
+		}
+
+		if preset.Quality == 0 {
+			preset.Quality = defaultImageQuality
+		}
+	}
+	return nil
+}
+
+// SetFocalPoint sets the focal point of a tenant's image, and queues the
+// image to be processed again if the tenant's presets crop around it
+func (s *MediaService) SetFocalPoint(ctx context.Context, tenantID, id primitive.ObjectID, userID, role string, focalPoint model.FocalPoint) (*model.MediaFile, error) {
+	if focalPoint.X < 0 || focalPoint.X > 1 || focalPoint.Y < 0 || focalPoint.Y > 1 {
+		return nil, ErrInvalidFocalPoint
+	}
+
+	mediaFile, err := s.repo.FindFileByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if mediaFile.TenantID != tenantID {
+		return nil, repository.ErrNotFound
+	}
+	if mediaFile.FileType != model.FileTypeImage || mediaFile.MimeType == "image/svg+xml" {
+		return nil, ErrNotAnImage
+	}
+
+	canWrite, err := s.repo.CheckPermission(ctx, mediaFile.TenantID, mediaFile.Folder, userID, role, "write")
+	if err != nil {
+		return nil, err
+	}
+	if !canWrite {
+		return nil, ErrPermissionDenied
+	}
+
+	if err := s.repo.SetFocalPoint(ctx, id, focalPoint); err != nil {
+		return nil, err
+	}
+	if mediaFile.Metadata == nil {
+		mediaFile.Metadata = make(map[string]interface{})
+	}
+	mediaFile.Metadata["focalPoint"] = focalPoint
+
+	config, err := s.repo.GetImagePresets(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if slices.ContainsFunc(config.Presets, func(preset model.ImagePreset) bool { return preset.Fit == model.ImageFitCover }) {
+		mediaFile.ProcessingStatus = "pending"
+		mediaFile.ProcessingError = ""
+		if err := s.repo.UpdateProcessingStatus(ctx, id, mediaFile.ProcessingStatus, mediaFile.ProcessingError); err != nil {
+			return nil, err
+		}
+		s.enqueueProcessing(ctx, mediaFile)
+	}
+	return mediaFile, nil
+}
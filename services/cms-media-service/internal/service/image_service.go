@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/processor"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/storage"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/transform"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrTransformsDisabled = errors.New("image transforms are not configured")
+	ErrInvalidSignature   = errors.New("invalid image transform signature")
+)
+
+// transformTimeout bounds the generation of an image on demand
+const transformTimeout = time.Minute
+
+// ImageService generates resized copies of images on demand, for the
+// signed transform URLs it hands out
+type ImageService struct {
+	repo           *repository.MediaRepository
+	store          storage.Storage
+	imageProcessor *processor.ImageProcessor
+	signer         *transform.Signer
+	cache          *transform.Cache
+	workDir        string
+	baseURL        string
+	slots          chan struct{}
+
+	mu       sync.Mutex
+	inFlight map[string]*generation
+}
+
+// generation is an image being generated, which concurrent requests for
+// the same image wait for
+type generation struct {
+	done chan struct{}
+	err  error
+}
+
+// NewImageService creates a new image service. Transform URLs are signed
+// by signer and served under baseURL/img; transforms are disabled if
+// signer is nil. Generated images are kept in cache, and at most
+// concurrency of them are generated at a time, in temporary directories
+// under workDir.
+func NewImageService(
+	repo *repository.MediaRepository,
+	store storage.Storage,
+	signer *transform.Signer,
+	cache *transform.Cache,
+	workDir string,
+	baseURL string,
+	concurrency int,
+) *ImageService {
+	return &ImageService{
+		repo:           repo,
+		store:          store,
+		imageProcessor: processor.NewImageProcessor(transform.MaxDimension, transform.MaxDimension, defaultImageQuality),
+		signer:         signer,
+		cache:          cache,
+		workDir:        workDir,
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		slots:          make(chan struct{}, max(concurrency, 1)),
+		inFlight:       make(map[string]*generation),
+	}
+}
+
+// TransformURL returns the signed URL of a transform of a tenant's image
+func (s *ImageService) TransformURL(ctx context.Context, tenantID, id primitive.ObjectID, options transform.Options) (string, error) {
+	if s.signer == nil {
+		return "", ErrTransformsDisabled
+	}
+	if err := options.Validate(); err != nil {
+		return "", err
+	}
+
+	file, err := s.repo.FindFileByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if file.TenantID != tenantID {
+		return "", repository.ErrNotFound
+	}
+	if !rasterImage(file) {
+		return "", ErrNotAnImage
+	}
+	return s.signer.URL(s.baseURL, id.Hex(), options), nil
+}
+
+// TransformedImage is a generated image, open in the cache
+type TransformedImage struct {
+	File     *os.File
+	Name     string
+	MimeType string
+	ModTime  time.Time
+}
+
+// Transform returns the image a signed transform URL asks for, generating
+// it unless it is cached. Images asked for in the auto format are in the
+// best format the client accepts.
+func (s *ImageService) Transform(ctx context.Context, id string, options transform.Options, signature, accept string) (*TransformedImage, error) {
+	if s.signer == nil {
+		return nil, ErrTransformsDisabled
+	}
+	if !s.signer.Verify(id, options, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	fileID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrNotFound
+	}
+	file, err := s.repo.FindFileByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if !rasterImage(file) {
+		return nil, ErrNotAnImage
+	}
+
+	format := resolveFormat(options.Format, file.MimeType, accept)
+	focalPoint := file.FocalPoint()
+	t := processor.ImageTransform{
+		Width:   options.Width,
+		Height:  options.Height,
+		Crop:    options.Fit == string(model.ImageFitCover),
+		FocusX:  focalPoint.X,
+		FocusY:  focalPoint.Y,
+		Format:  format,
+		Quality: options.Quality,
+	}
+
+	// The stored content and the focal point identify the image, so the
+	// cached copy is replaced when either changes
+	key := transform.Key(formatExtension(format),
+		file.FilePath,
+		strconv.FormatInt(file.FileSize, 10),
+		fmt.Sprintf("%g,%g", focalPoint.X, focalPoint.Y),
+		options.Query().Encode(),
+		format,
+	)
+
+	cached, err := s.cache.Open(key)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := s.generateOnce(key, func() error { return s.generate(file, key, t) }); err != nil {
+			return nil, err
+		}
+		cached, err = s.cache.Open(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransformedImage{
+		File:     cached,
+		Name:     key,
+		MimeType: formatMimeType(format),
+		ModTime:  file.UpdatedAt,
+	}, nil
+}
+
+// generateOnce runs generate unless the same image is being generated
+// already, in which case it waits for that generation instead
+func (s *ImageService) generateOnce(key string, generate func() error) error {
+	s.mu.Lock()
+	if running, ok := s.inFlight[key]; ok {
+		s.mu.Unlock()
+		<-running.done
+		return running.err
+	}
+	running := &generation{done: make(chan struct{})}
+	s.inFlight[key] = running
+	s.mu.Unlock()
+
+	running.err = generate()
+
+	s.mu.Lock()
+	delete(s.inFlight, key)
+	s.mu.Unlock()
+	close(running.done)
+	return running.err
+}
+
+// generate generates a transform of an image into the cache. Requests
+// that go away do not stop it, as others may be waiting for the image.
+func (s *ImageService) generate(file *model.MediaFile, key string, t processor.ImageTransform) error {
+	ctx, cancel := context.WithTimeout(context.Background(), transformTimeout)
+	defer cancel()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	workspace, err := processor.NewWorkspace(s.store, s.workDir)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+
+	sourcePath, err := workspace.Fetch(ctx, file.FilePath, path.Base(file.FilePath))
+	if err != nil {
+		return err
+	}
+	width, height := file.Width, file.Height
+	if width == 0 || height == 0 {
+		if width, height, err = s.imageProcessor.GetImageDimensions(sourcePath); err != nil {
+			return err
+		}
+	}
+
+	outputPath := workspace.Path("transformed" + formatExtension(t.Format))
+	if _, _, err := s.imageProcessor.Transform(ctx, sourcePath, outputPath, width, height, t); err != nil {
+		return err
+	}
+	return s.cache.Add(key, outputPath)
+}
+
+// rasterImage reports whether a file is an image transforms can resize
+func rasterImage(file *model.MediaFile) bool {
+	return file.FileType == model.FileTypeImage && file.MimeType != "image/svg+xml"
+}
+
+// resolveFormat returns the format to generate for a requested format: the
+// image's own if none was requested, or the best one the client accepts for
+// auto
+func resolveFormat(requested, mimeType, accept string) string {
+	switch requested {
+	case "":
+		return sourceFormat(mimeType)
+	case "auto":
+		switch {
+		case strings.Contains(accept, "image/avif"):
+			return "avif"
+		case strings.Contains(accept, "image/webp"):
+			return "webp"
+		default:
+			return sourceFormat(mimeType)
+		}
+	default:
+		return requested
+	}
+}
+
+// sourceFormat returns the format copies of an image keep: its own if it
+// can be generated, PNG for GIFs whose transparency JPEG would lose, and
+// JPEG otherwise
+func sourceFormat(mimeType string) string {
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/webp", "image/avif":
+		return strings.TrimPrefix(mimeType, "image/")
+	case "image/gif":
+		return "png"
+	default:
+		return "jpeg"
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/inspect"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
+)
+
+var (
+	ErrContentMismatch = errors.New("file content does not match its type")
+	ErrInvalidContent  = errors.New("file content is invalid")
+	ErrUnsafeArchive   = inspect.ErrUnsafeArchive
+	ErrMalwareDetected = errors.New("malware detected")
+	ErrScanFailed      = errors.New("malware scan failed")
+)
+
+// inspectFile checks what a received file really contains before it is
+// stored. Its type is detected from its content, which must be of the type
+// the client claimed, and is checked against the tenant's config of that
+// type. The file is then scanned for malware, archives are checked against
+// the archive limits, SVGs are sanitised and the location is stripped from
+// the metadata of other images.
+func (s *MediaService) inspectFile(ctx context.Context, received *receivedFile) error {
+	mimeType, err := inspect.DetectContentType(received.file, received.size, received.name)
+	if err != nil {
+		return err
+	}
+	if inspect.Blocked(mimeType) {
+		return fmt.Errorf("%w: %s", ErrFileTypeNotAllowed, mimeType)
+	}
+
+	fileType := s.determineFileType(mimeType)
+	claimed, _, _ := mime.ParseMediaType(received.mimeType)
+	if claimed != "" && claimed != "application/octet-stream" && s.determineFileType(claimed) != fileType {
+		return fmt.Errorf("%w: claimed %s, content is %s", ErrContentMismatch, claimed, mimeType)
+	}
+	received.mimeType = mimeType
+	received.fileType = fileType
+
+	if err := s.checkFileType(ctx, received.tenantID, fileType, mimeType, received.size); err != nil {
+		return err
+	}
+
+	if s.scanner != nil {
+		if _, err := received.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		malware, err := s.scanner.Scan(ctx, received.file)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrScanFailed, err)
+		}
+		if malware != "" {
+			return fmt.Errorf("%w: %s", ErrMalwareDetected, malware)
+		}
+	}
+
+	switch {
+	case inspect.Archive(mimeType):
+		return inspect.CheckArchive(received.file, received.size, mimeType, s.archiveLimits)
+	case mimeType == "image/svg+xml":
+		return s.rewrite(received, func(r io.Reader, w io.Writer) (bool, error) {
+			return true, inspect.SanitizeSVG(r, w)
+		})
+	case fileType == model.FileTypeImage:
+		return s.rewrite(received, func(r io.Reader, w io.Writer) (bool, error) {
+			return inspect.StripLocation(mimeType, r, w)
+		})
+	}
+	return nil
+}
+
+// rewrite replaces the content of a received file with what transform
+// writes from it, if transform reports that it changed anything
+func (s *MediaService) rewrite(received *receivedFile, transform func(io.Reader, io.Writer) (bool, error)) error {
+	if _, err := received.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.workDir, "inspect-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	changed, err := transform(received.file, tmp)
+	if err != nil {
+		if errors.Is(err, inspect.ErrInvalidSVG) || errors.Is(err, inspect.ErrInvalidImage) {
+			return fmt.Errorf("%w: %v", ErrInvalidContent, err)
+		}
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := received.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := received.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	size, err := io.Copy(received.file, tmp)
+	if err != nil {
+		return err
+	}
+	received.size = size
+	return nil
+}
+
+// rejected reports whether an upload failed because of what the file is,
+// so that uploading it again would fail too
+func rejected(err error) bool {
+	for _, target := range []error{
+		ErrFileTypeNotAllowed, ErrFileTooLarge, ErrContentMismatch,
+		ErrInvalidContent, ErrUnsafeArchive, ErrMalwareDetected,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,47 +2,112 @@ package service
 
 import (
 	"context"
-	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"mime/multipart"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/inspect"
 	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/processor"
 	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/storage"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var (
+	ErrFileTypeNotAllowed = errors.New("file type is not allowed")
+	ErrFileTooLarge       = errors.New("file is too large")
+	ErrQuotaExceeded      = errors.New("storage quota exceeded")
+)
+
 // MediaService handles media processing business logic
 type MediaService struct {
 	repo              *repository.MediaRepository
+	uploadRepo        *repository.UploadRepository
+	jobRepo           *repository.JobRepository
+	store             storage.Storage
 	imageProcessor    *processor.ImageProcessor
 	videoProcessor    *processor.VideoProcessor
 	documentProcessor *processor.DocumentProcessor
-	uploadDir         string
+	workDir           string
 	baseURL           string
+	publicURL         string
+	uploadExpiry      time.Duration
+	defaultQuota      int64
+	scanner           inspect.Scanner
+	archiveLimits     inspect.ArchiveLimits
+	jobAttempts       int
+	jobRetryDelay     time.Duration
 }
 
-// NewMediaService creates a new media service
+// NewMediaService creates a new media service. Files are processed in
+// temporary directories under workDir, served by this service under
+// baseURL/uploads and, if publicURL is set, by a CDN in front of the store.
+// Resumable uploads expire uploadExpiry after their last chunk; tenants
+// without a quota of their own may store defaultQuota bytes, or any amount
+// if it is 0. Uploads are scanned for malware if scanner is not nil, and
+// archives must stay within archiveLimits. Files are processed by jobs
+// that are attempted jobAttempts times, waiting jobRetryDelay after the
+// first failed attempt and twice as long after each further one.
 func NewMediaService(
 	repo *repository.MediaRepository,
-	uploadDir string,
+	uploadRepo *repository.UploadRepository,
+	jobRepo *repository.JobRepository,
+	store storage.Storage,
+	workDir string,
 	baseURL string,
+	publicURL string,
+	uploadExpiry time.Duration,
+	defaultQuota int64,
+	scanner inspect.Scanner,
+	archiveLimits inspect.ArchiveLimits,
+	jobAttempts int,
+	jobRetryDelay time.Duration,
 ) *MediaService {
 	return &MediaService{
 		repo:              repo,
+		uploadRepo:        uploadRepo,
+		jobRepo:           jobRepo,
+		store:             store,
 		imageProcessor:    processor.NewImageProcessor(2048, 2048, 85),
-		videoProcessor:    processor.NewVideoProcessor(uploadDir),
-		documentProcessor: processor.NewDocumentProcessor(uploadDir),
-		uploadDir:         uploadDir,
-		baseURL:           baseURL,
+		videoProcessor:    processor.NewVideoProcessor(),
+		documentProcessor: processor.NewDocumentProcessor(workDir),
+		workDir:           workDir,
+		baseURL:           strings.TrimSuffix(baseURL, "/"),
+		publicURL:         strings.TrimSuffix(publicURL, "/"),
+		uploadExpiry:      uploadExpiry,
+		defaultQuota:      defaultQuota,
+		scanner:           scanner,
+		archiveLimits:     archiveLimits,
+		jobAttempts:       jobAttempts,
+		jobRetryDelay:     jobRetryDelay,
 	}
 }
 
+// receivedFile is an uploaded file spooled to a temporary file. Its type is
+// the one the client claimed until the file is inspected.
+type receivedFile struct {
+	file      *os.File
+	size      int64
+	name      string
+	mimeType  string
+	fileType  model.FileType
+	tenantID  primitive.ObjectID
+	userID    string
+	folder    string
+	ipAddress string
+	userAgent string
+}
+
 // UploadFile handles file upload with processing
 func (s *MediaService) UploadFile(
 	ctx context.Context,
@@ -54,176 +119,299 @@ func (s *MediaService) UploadFile(
 	ipAddress string,
 	userAgent string,
 ) (*model.MediaFile, error) {
-	// Validate file type and size
-	fileType := s.determineFileType(fileHeader.Header.Get("Content-Type"))
+	// Validate the claimed file type and size; the file is inspected once
+	// received
+	mimeType := fileHeader.Header.Get("Content-Type")
+	fileType := s.determineFileType(mimeType)
+	if err := s.checkUpload(ctx, tenantID, fileType, fileHeader.Size); err != nil {
+		return nil, err
+	}
 
-	// Check file type config
-	config, err := s.repo.GetFileTypeConfig(ctx, tenantID, fileType)
+	// Spool the upload to a temporary file
+	tmp, err := os.CreateTemp(s.workDir, "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, file)
 	if err != nil {
 		return nil, err
 	}
 
+	return s.createFile(ctx, &receivedFile{
+		file:      tmp,
+		size:      size,
+		name:      fileHeader.Filename,
+		mimeType:  mimeType,
+		fileType:  fileType,
+		tenantID:  tenantID,
+		userID:    userID,
+		folder:    folder,
+		ipAddress: ipAddress,
+		userAgent: userAgent,
+	})
+}
+
+// checkUpload checks that a tenant may upload a file of a type and size
+func (s *MediaService) checkUpload(ctx context.Context, tenantID primitive.ObjectID, fileType model.FileType, size int64) error {
+	if err := s.checkFileType(ctx, tenantID, fileType, "", size); err != nil {
+		return err
+	}
+
+	// Check quota, counting the space unfinished resumable uploads reserve
+	usage, err := s.repo.GetTenantStorage(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	quota := usage.Quota
+	if quota == 0 {
+		quota = s.defaultQuota
+	}
+	if quota <= 0 {
+		return nil
+	}
+	pending, err := s.uploadRepo.SumPendingLength(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if usage.TotalSize+pending+size > quota {
+		return fmt.Errorf("%w: %d of %d bytes used, %d reserved by uploads in progress", ErrQuotaExceeded, usage.TotalSize, quota, pending)
+	}
+	return nil
+}
+
+// checkFileType checks a file against the tenant's config of its type. The
+// MIME type is only checked if it is known.
+func (s *MediaService) checkFileType(ctx context.Context, tenantID primitive.ObjectID, fileType model.FileType, mimeType string, size int64) error {
+	config, err := s.repo.GetFileTypeConfig(ctx, tenantID, fileType)
+	if err != nil {
+		return err
+	}
+
 	if !config.Enabled {
-		return nil, fmt.Errorf("file type %s is not allowed", fileType)
+		return fmt.Errorf("%w: %s", ErrFileTypeNotAllowed, fileType)
 	}
 
-	if fileHeader.Size > config.MaxFileSize {
-		return nil, fmt.Errorf("file size %d exceeds maximum allowed size %d", fileHeader.Size, config.MaxFileSize)
+	if size > config.MaxFileSize {
+		return fmt.Errorf("%w: file size %d exceeds maximum allowed size %d", ErrFileTooLarge, size, config.MaxFileSize)
 	}
 
-	// Generate unique filename
-	filename := s.generateFilename(fileHeader.Filename)
+	if mimeType != "" && len(config.AllowedMimeTypes) > 0 && !mimeTypeAllowed(mimeType, config.AllowedMimeTypes) {
+		return fmt.Errorf("%w: %s", ErrFileTypeNotAllowed, mimeType)
+	}
+	return nil
+}
+
+// mimeTypeAllowed reports whether a MIME type is in a list of allowed types,
+// which may end with a wildcard such as "image/*"
+func mimeTypeAllowed(mimeType string, allowed []string) bool {
+	for _, pattern := range allowed {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(mimeType, prefix) || pattern == mimeType {
+			return true
+		}
+	}
+	return false
+}
 
-	// Create directory structure
-	yearMonth := time.Now().Format("2006/01")
-	targetDir := filepath.Join(s.uploadDir, string(fileType), yearMonth)
-	if err := os.MkdirAll(targetDir, 0755); err != nil {
+// createFile stores a received file and creates its record. Files are
+// stored by the hash of their content, so uploading the same content again
+// reuses the stored and processed objects.
+func (s *MediaService) createFile(ctx context.Context, received *receivedFile) (*model.MediaFile, error) {
+	if err := s.inspectFile(ctx, received); err != nil {
+		log.Printf("Rejected upload %q of tenant %s: %v", received.name, received.tenantID.Hex(), err)
 		return nil, err
 	}
 
-	// Save original file
-	originalPath := filepath.Join(targetDir, filename)
-	outFile, err := os.Create(originalPath)
-	if err != nil {
+	// Hash the content as it is stored, once sanitised
+	if _, err := received.file.Seek(0, io.SeekStart); err != nil {
 		return nil, err
 	}
-	defer outFile.Close()
-
-	size, err := io.Copy(outFile, file)
-	if err != nil {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, received.file); err != nil {
 		return nil, err
 	}
+	contentHash := hex.EncodeToString(hash.Sum(nil))
+
+	// The extension follows the detected type, not the client's name
+	ext := inspect.Extension(received.mimeType, received.name)
 
 	// Create media file record
 	mediaFile := &model.MediaFile{
-		TenantID:         tenantID,
-		FileName:         filename,
-		OriginalName:     fileHeader.Filename,
-		FilePath:         filepath.Join(string(fileType), yearMonth, filename),
-		FileType:         fileType,
-		MimeType:         fileHeader.Header.Get("Content-Type"),
-		FileSize:         size,
-		Folder:           folder,
-		UploadedBy:       userID,
-		URL:              fmt.Sprintf("%s/uploads/%s", s.baseURL, filepath.Join(string(fileType), yearMonth, filename)),
-		ProcessingStatus: "processing",
+		TenantID:         received.tenantID,
+		FileName:         contentHash + ext,
+		OriginalName:     received.name,
+		FilePath:         path.Join(s.contentKey(received.tenantID, received.fileType, contentHash), "original"+ext),
+		ContentHash:      contentHash,
+		FileType:         received.fileType,
+		MimeType:         received.mimeType,
+		FileSize:         received.size,
+		Folder:           received.folder,
+		UploadedBy:       received.userID,
+		ProcessingStatus: "completed",
+	}
+	if _, ok := jobTypeOf(received.fileType); ok {
+		mediaFile.ProcessingStatus = "pending"
 	}
 
+	// Store the content unless it was stored and processed before
+	reused := s.reuseProcessedFile(ctx, mediaFile)
+	if !reused {
+		if _, err := received.file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := s.store.Put(ctx, mediaFile.FilePath, received.file, received.size, received.mimeType); err != nil {
+			return nil, err
+		}
+	}
+	mediaFile.URL, mediaFile.CDNUrl = s.fileURLs(mediaFile.FilePath)
+
 	// Create record first
 	if err := s.repo.CreateFile(ctx, mediaFile); err != nil {
-		os.Remove(originalPath)
+		s.releaseObjects(context.Background(), mediaFile)
 		return nil, err
 	}
 
-	// Process file based on type
-	go s.processFile(context.Background(), mediaFile, originalPath)
+	// Queue the processing of the file
+	if !reused {
+		s.enqueueProcessing(ctx, mediaFile)
+	}
 
 	// Log upload
-	s.logUpload(ctx, tenantID, mediaFile.ID, userID, fileHeader.Filename, fileType, size, "upload", ipAddress, userAgent)
+	s.logUpload(ctx, received.tenantID, mediaFile.ID, received.userID, received.name, received.fileType, received.size, "upload", received.ipAddress, received.userAgent)
 
 	// Update tenant storage
-	s.repo.UpdateTenantStorage(ctx, tenantID, size, fileType, false)
+	s.repo.UpdateTenantStorage(ctx, received.tenantID, received.size, received.fileType, false)
 
 	return mediaFile, nil
 }
 
-// processFile processes uploaded file asynchronously
-func (s *MediaService) processFile(ctx context.Context, mediaFile *model.MediaFile, originalPath string) {
-	defer func() {
-		// Update processing status
-		s.repo.UpdateFile(ctx, mediaFile)
-	}()
-
-	switch mediaFile.FileType {
-	case model.FileTypeImage:
-		s.processImage(ctx, mediaFile, originalPath)
-	case model.FileTypeVideo:
-		s.processVideo(ctx, mediaFile, originalPath)
-	case model.FileTypeDocument, model.FileTypePDF:
-		s.processDocument(ctx, mediaFile, originalPath)
+// reuseProcessedFile copies the processing results of a file of the tenant
+// with the same content into mediaFile, if there is one whose objects are
+// still stored
+func (s *MediaService) reuseProcessedFile(ctx context.Context, mediaFile *model.MediaFile) bool {
+	existing, err := s.repo.FindProcessedFileByContentHash(ctx, mediaFile.TenantID, mediaFile.ContentHash)
+	if err != nil || existing.FileType != mediaFile.FileType {
+		return false
+	}
+	if _, err := s.store.Stat(ctx, existing.FilePath); err != nil {
+		return false
 	}
 
-	mediaFile.ProcessingStatus = "completed"
+	mediaFile.FilePath = existing.FilePath
+	mediaFile.FileSize = existing.FileSize
+	mediaFile.CompressedSize = existing.CompressedSize
+	mediaFile.Width = existing.Width
+	mediaFile.Height = existing.Height
+	mediaFile.Duration = existing.Duration
+	mediaFile.Thumbnail = existing.Thumbnail
+	mediaFile.Metadata = existing.Metadata
+	mediaFile.VideoFormats = existing.VideoFormats
+	mediaFile.M3U8Path = existing.M3U8Path
+	mediaFile.Derivatives = existing.Derivatives
+	mediaFile.ProcessingStatus = existing.ProcessingStatus
+	mediaFile.ProcessingError = existing.ProcessingError
+	return true
 }
 
-// processImage compresses image
-func (s *MediaService) processImage(ctx context.Context, mediaFile *model.MediaFile, originalPath string) {
-	// Compress image
-	compressedPath := strings.Replace(originalPath, filepath.Ext(originalPath), "_compressed"+filepath.Ext(originalPath), 1)
-
-	compressedSize, err := s.imageProcessor.CompressImage(originalPath, compressedPath)
-	if err != nil {
-		mediaFile.ProcessingError = err.Error()
-		return
+// processImage compresses image and generates its derivatives
+func (s *MediaService) processImage(ctx context.Context, workspace *processor.Workspace, mediaFile *model.MediaFile, originalPath string, report reportFunc) error {
+	// SVGs are vectors, and were sanitised on upload
+	if mediaFile.MimeType == "image/svg+xml" {
+		return nil
 	}
 
-	// Get dimensions
-	width, height, _ := s.imageProcessor.GetImageDimensions(compressedPath)
+	// Compress image, unless it was compressed when processed before
+	sourcePath := originalPath
+	if mediaFile.CompressedSize == 0 {
+		report(10, "compressing")
+		compressedName := "compressed" + filepath.Ext(originalPath)
+		compressedPath := workspace.Path(compressedName)
 
-	mediaFile.CompressedSize = compressedSize
-	mediaFile.Width = width
-	mediaFile.Height = height
+		compressedSize, err := s.imageProcessor.CompressImage(originalPath, compressedPath)
+		if err != nil {
+			return err
+		}
+
+		// Get dimensions
+		width, height, _ := s.imageProcessor.GetImageDimensions(compressedPath)
+
+		mediaFile.CompressedSize = compressedSize
+		mediaFile.Width = width
+		mediaFile.Height = height
 
-	// Replace original with compressed if smaller
-	if compressedSize < mediaFile.FileSize {
-		os.Rename(compressedPath, originalPath)
-		mediaFile.FileSize = compressedSize
-	} else {
-		os.Remove(compressedPath)
+		// Replace original with compressed if smaller
+		if compressedSize < mediaFile.FileSize {
+			report(20, "storing")
+			if _, err := workspace.Store(ctx, compressedName, mediaFile.FilePath); err != nil {
+				return err
+			}
+			mediaFile.FileSize = compressedSize
+			sourcePath = compressedPath
+		}
 	}
+
+	return s.generateDerivatives(ctx, workspace, mediaFile, sourcePath, report)
 }
 
 // processVideo converts video to HLS and extracts thumbnail
-func (s *MediaService) processVideo(ctx context.Context, mediaFile *model.MediaFile, originalPath string) {
+func (s *MediaService) processVideo(ctx context.Context, workspace *processor.Workspace, mediaFile *model.MediaFile, originalPath string, report reportFunc) error {
 	// Get video info
+	report(0, "probing")
 	width, height, duration, err := s.videoProcessor.GetVideoInfo(originalPath)
 	if err != nil {
-		mediaFile.ProcessingError = err.Error()
-		return
+		return err
 	}
 
 	mediaFile.Width = width
 	mediaFile.Height = height
 	mediaFile.Duration = duration
 
-	// Convert to HLS
-	baseName := strings.TrimSuffix(mediaFile.FileName, filepath.Ext(mediaFile.FileName))
-	m3u8Path, resolutions, err := s.videoProcessor.ConvertToHLS(originalPath, baseName)
+	// Convert to HLS, which takes most of the time
+	report(5, "transcoding")
+	_, resolutions, err := s.videoProcessor.ConvertToHLS(ctx, originalPath, workspace.Path("hls"), duration, func(fraction float64) {
+		report(5+fraction*85, "transcoding")
+	})
 	if err != nil {
-		mediaFile.ProcessingError = err.Error()
-		return
+		return err
 	}
 
-	mediaFile.M3U8Path = m3u8Path
+	report(90, "storing")
+	hlsPrefix := path.Join(path.Dir(mediaFile.FilePath), "hls")
+	if err := workspace.StoreDir(ctx, "hls", hlsPrefix); err != nil {
+		return err
+	}
+	mediaFile.M3U8Path = path.Join(hlsPrefix, "master.m3u8")
 
 	// Convert resolutions to model format
+	mediaFile.VideoFormats = nil
 	for _, res := range resolutions {
 		mediaFile.VideoFormats = append(mediaFile.VideoFormats, model.VideoFormat{
 			Resolution: res.Name,
-			Path:       filepath.Join(filepath.Dir(m3u8Path), fmt.Sprintf("%s.m3u8", res.Name)),
+			Path:       path.Join(hlsPrefix, fmt.Sprintf("%s.m3u8", res.Name)),
 			Bitrate:    0, // TODO: parse from res.Bitrate
 		})
 	}
 
 	// Extract thumbnail
-	thumbnailPath := strings.Replace(originalPath, filepath.Ext(originalPath), "_thumb.jpg", 1)
-	if err := s.videoProcessor.ExtractThumbnail(originalPath, thumbnailPath, 1); err == nil {
-		relPath := strings.TrimPrefix(thumbnailPath, s.uploadDir)
-		mediaFile.Thumbnail = fmt.Sprintf("%s/uploads%s", s.baseURL, relPath)
+	report(95, "thumbnail")
+	if err := s.videoProcessor.ExtractThumbnail(originalPath, workspace.Path("thumb.jpg"), 1); err == nil {
+		s.storeThumbnail(ctx, workspace, mediaFile)
 	}
+	return nil
 }
 
 // processDocument extracts thumbnail from document
-func (s *MediaService) processDocument(ctx context.Context, mediaFile *model.MediaFile, originalPath string) {
-	thumbnailPath := strings.Replace(originalPath, filepath.Ext(originalPath), "_thumb.jpg", 1)
-
-	if err := s.documentProcessor.ExtractThumbnailByType(originalPath, thumbnailPath); err == nil {
-		relPath := strings.TrimPrefix(thumbnailPath, s.uploadDir)
-		mediaFile.Thumbnail = fmt.Sprintf("%s/uploads%s", s.baseURL, relPath)
+func (s *MediaService) processDocument(ctx context.Context, workspace *processor.Workspace, mediaFile *model.MediaFile, originalPath string, report reportFunc) error {
+	report(10, "thumbnail")
+	if err := s.documentProcessor.ExtractThumbnailByType(originalPath, workspace.Path("thumb.jpg")); err == nil {
+		s.storeThumbnail(ctx, workspace, mediaFile)
 	}
 
 	// Get page count for PDFs
 	if mediaFile.FileType == model.FileTypePDF {
+		report(80, "counting pages")
 		if pageCount, err := s.documentProcessor.GetPDFPageCount(originalPath); err == nil {
 			if mediaFile.Metadata == nil {
 				mediaFile.Metadata = make(map[string]interface{})
@@ -231,13 +419,57 @@ func (s *MediaService) processDocument(ctx context.Context, mediaFile *model.Med
 			mediaFile.Metadata["pageCount"] = pageCount
 		}
 	}
+	return ctx.Err()
 }
 
-// generateFilename generates a unique filename
-func (s *MediaService) generateFilename(originalName string) string {
-	ext := filepath.Ext(originalName)
-	hash := md5.Sum([]byte(fmt.Sprintf("%s-%d", originalName, time.Now().UnixNano())))
-	return fmt.Sprintf("%x%s", hash, ext)
+// storeThumbnail stores the thumbnail extracted into the workspace next to
+// the file
+func (s *MediaService) storeThumbnail(ctx context.Context, workspace *processor.Workspace, mediaFile *model.MediaFile) {
+	key := path.Join(path.Dir(mediaFile.FilePath), "thumb.jpg")
+	if _, err := workspace.Store(ctx, "thumb.jpg", key); err != nil {
+		return
+	}
+	url, cdnURL := s.fileURLs(key)
+	if cdnURL != "" {
+		url = cdnURL
+	}
+	mediaFile.Thumbnail = url
+}
+
+// contentKey returns the storage key under which a tenant's content and its
+// processing outputs are stored
+func (s *MediaService) contentKey(tenantID primitive.ObjectID, fileType model.FileType, contentHash string) string {
+	return path.Join(tenantID.Hex(), string(fileType), contentHash[:2], contentHash)
+}
+
+// fileURLs returns the URL of a stored object served by this service, and
+// its CDN URL if a public base URL is configured
+func (s *MediaService) fileURLs(key string) (string, string) {
+	url := s.baseURL + "/uploads/" + key
+	if s.publicURL == "" {
+		return url, ""
+	}
+	return url, s.publicURL + "/" + key
+}
+
+// releaseObjects deletes the stored objects of a file once no other file of
+// the tenant has the same content
+func (s *MediaService) releaseObjects(ctx context.Context, file *model.MediaFile) {
+	// Files stored before content addressing own their object
+	if file.ContentHash == "" {
+		if err := s.store.Delete(ctx, file.FilePath); err != nil {
+			log.Printf("Failed to delete %s: %v", file.FilePath, err)
+		}
+		return
+	}
+
+	count, err := s.repo.CountFilesByContentHash(ctx, file.TenantID, file.ContentHash)
+	if err != nil || count > 0 {
+		return
+	}
+	if err := storage.DeletePrefix(ctx, s.store, path.Dir(file.FilePath)+"/"); err != nil {
+		log.Printf("Failed to delete the objects of %s: %v", file.FilePath, err)
+	}
 }
 
 // determineFileType determines file type from MIME type
@@ -305,19 +537,33 @@ func (s *MediaService) DeleteFile(ctx context.Context, id primitive.ObjectID, us
 		return err
 	}
 
+	// Stop processing it
+	if err := s.jobRepo.CancelByFile(ctx, id); err != nil {
+		log.Printf("Failed to cancel the processing jobs of %s: %v", id.Hex(), err)
+	}
+
 	// Update storage
 	s.repo.UpdateTenantStorage(ctx, file.TenantID, file.FileSize, file.FileType, true)
 
 	// Log deletion
 	s.logUpload(context.Background(), file.TenantID, file.ID, userID, file.FileName, file.FileType, file.FileSize, "delete", "", "")
 
-	// Delete physical file
-	fullPath := filepath.Join(s.uploadDir, file.FilePath)
-	os.Remove(fullPath)
+	// Delete stored objects
+	s.releaseObjects(context.Background(), file)
 
 	return nil
 }
 
+// GetDownloadURL returns a URL from which a file can be downloaded without
+// credentials until expiry has passed
+func (s *MediaService) GetDownloadURL(ctx context.Context, id primitive.ObjectID, expiry time.Duration) (string, error) {
+	file, err := s.repo.FindFileByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return s.store.PresignedURL(ctx, file.FilePath, expiry)
+}
+
 // GetStorageUsage gets tenant storage usage
 func (s *MediaService) GetStorageUsage(ctx context.Context, tenantID primitive.ObjectID) (*model.TenantStorageUsage, error) {
 	return s.repo.GetTenantStorage(ctx, tenantID)
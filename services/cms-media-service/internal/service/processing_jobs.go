@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/processor"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxJobRetryDelay caps the wait before the next attempt of a failed job
+const maxJobRetryDelay = time.Hour
+
+// reportFunc reports the progress of a job, in percent, and the stage it is
+// at
+type reportFunc func(progress float64, stage string)
+
+// jobTypeOf returns the type of the job that processes files of a type, if
+// they are processed at all
+func jobTypeOf(fileType model.FileType) (model.JobType, bool) {
+	switch fileType {
+	case model.FileTypeImage:
+		return model.JobTypeImage, true
+	case model.FileTypeVideo:
+		return model.JobTypeVideo, true
+	case model.FileTypeDocument, model.FileTypePDF:
+		return model.JobTypeDocument, true
+	default:
+		return "", false
+	}
+}
+
+// enqueueProcessing queues the job that processes a new file, if its type
+// is processed. The file is marked failed if the job cannot be queued.
+func (s *MediaService) enqueueProcessing(ctx context.Context, mediaFile *model.MediaFile) {
+	jobType, ok := jobTypeOf(mediaFile.FileType)
+	if !ok {
+		return
+	}
+
+	job := &model.ProcessingJob{
+		TenantID:    mediaFile.TenantID,
+		FileID:      mediaFile.ID,
+		Type:        jobType,
+		MaxAttempts: s.jobAttempts,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		log.Printf("Failed to queue the processing of %s: %v", mediaFile.ID.Hex(), err)
+		mediaFile.ProcessingStatus = "failed"
+		mediaFile.ProcessingError = "processing could not be queued"
+		s.repo.UpdateProcessingStatus(context.WithoutCancel(ctx), mediaFile.ID, mediaFile.ProcessingStatus, mediaFile.ProcessingError)
+	}
+}
+
+// ClaimJob leases the next due job of a type to owner for lease. It returns
+// nil if no job is due.
+func (s *MediaService) ClaimJob(ctx context.Context, jobType model.JobType, owner string, lease time.Duration) (*model.ProcessingJob, error) {
+	job, err := s.jobRepo.Claim(ctx, jobType, owner, lease)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// jobProgress is the progress of a running job, reported by the processing
+// and read by the heartbeat
+type jobProgress struct {
+	mu       sync.Mutex
+	progress float64
+	stage    string
+}
+
+func (p *jobProgress) report(progress float64, stage string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress, p.stage = progress, stage
+}
+
+func (p *jobProgress) get() (float64, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.progress, p.stage
+}
+
+// RunJob runs a job leased to owner and records its outcome. The lease is
+// renewed along with the job's progress every third of lease; the job is
+// abandoned if the lease is lost because the job was cancelled or another
+// worker took it over. If ctx is cancelled because the worker stops, the
+// job is queued again without counting the attempt.
+func (s *MediaService) RunJob(ctx context.Context, job *model.ProcessingJob, owner string, lease time.Duration) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	progress := &jobProgress{}
+	lost := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				percent, stage := progress.get()
+				err := s.jobRepo.Heartbeat(jobCtx, job.ID, owner, lease, percent, stage)
+				if errors.Is(err, repository.ErrConflict) {
+					close(lost)
+					cancel()
+					return
+				}
+				if err != nil && jobCtx.Err() == nil {
+					log.Printf("Failed to renew the lease of job %s: %v", job.ID.Hex(), err)
+				}
+			}
+		}
+	}()
+
+	err := s.processJob(jobCtx, job, progress.report)
+	close(done)
+
+	// Record the outcome even if the worker is stopping
+	ctx = context.WithoutCancel(ctx)
+	select {
+	case <-lost:
+		log.Printf("Abandoned job %s, which is no longer leased to this worker", job.ID.Hex())
+		return
+	default:
+	}
+
+	switch {
+	case err == nil || errors.Is(err, repository.ErrNotFound):
+		// A file deleted in the meantime needs no processing
+		err = s.jobRepo.Complete(ctx, job.ID, owner)
+	case jobCtx.Err() != nil:
+		err = s.jobRepo.Release(ctx, job.ID, owner)
+	default:
+		s.failJob(ctx, job, owner, err)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to record the outcome of job %s: %v", job.ID.Hex(), err)
+	}
+}
+
+// processJob processes the file of a job, reporting its progress
+func (s *MediaService) processJob(ctx context.Context, job *model.ProcessingJob, report reportFunc) error {
+	mediaFile, err := s.repo.FindFileByID(ctx, job.FileID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdateProcessingStatus(ctx, mediaFile.ID, "processing", mediaFile.ProcessingError); err != nil {
+		return err
+	}
+
+	// The stored file is fetched into a workspace, and the outputs are
+	// stored next to it
+	report(0, "fetching")
+	workspace, err := processor.NewWorkspace(s.store, s.workDir)
+	if err != nil {
+		return err
+	}
+	defer workspace.Close()
+
+	originalPath, err := workspace.Fetch(ctx, mediaFile.FilePath, path.Base(mediaFile.FilePath))
+	if err != nil {
+		return err
+	}
+
+	switch job.Type {
+	case model.JobTypeImage:
+		err = s.processImage(ctx, workspace, mediaFile, originalPath, report)
+	case model.JobTypeVideo:
+		err = s.processVideo(ctx, workspace, mediaFile, originalPath, report)
+	case model.JobTypeDocument:
+		err = s.processDocument(ctx, workspace, mediaFile, originalPath, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	mediaFile.ProcessingStatus = "completed"
+	mediaFile.ProcessingError = ""
+	return s.repo.UpdateProcessingResult(ctx, mediaFile)
+}
+
+// failJob records a failed attempt of a job. The job is retried after a
+// delay that doubles with each attempt, or is dead and its file failed once
+// it has no attempts left.
+func (s *MediaService) failJob(ctx context.Context, job *model.ProcessingJob, owner string, cause error) {
+	var runAt *time.Time
+	status := "failed"
+	if job.Attempts < job.MaxAttempts {
+		next := time.Now().Add(s.retryDelay(job.Attempts))
+		runAt = &next
+		status = "pending"
+	}
+
+	log.Printf("Attempt %d of job %s failed: %v", job.Attempts, job.ID.Hex(), cause)
+	if err := s.jobRepo.Fail(ctx, job.ID, owner, cause.Error(), runAt); err != nil {
+		log.Printf("Failed to record the outcome of job %s: %v", job.ID.Hex(), err)
+		return
+	}
+	s.repo.UpdateProcessingStatus(ctx, job.FileID, status, cause.Error())
+}
+
+// retryDelay returns the wait before the attempt after attempt
+func (s *MediaService) retryDelay(attempt int) time.Duration {
+	delay := s.jobRetryDelay
+	for i := 1; i < attempt && delay < maxJobRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxJobRetryDelay)
+}
+
+// RequeueExpiredJobs queues the jobs of workers that stopped renewing their
+// lease again, and fails the files of those without attempts left
+func (s *MediaService) RequeueExpiredJobs(ctx context.Context, now time.Time) (int64, error) {
+	requeued, dead, err := s.jobRepo.RequeueExpired(ctx, now)
+	for _, job := range dead {
+		s.repo.UpdateProcessingStatus(ctx, job.FileID, "failed", "worker stopped responding")
+	}
+	return requeued, err
+}
+
+// ListJobs lists a tenant's processing jobs
+func (s *MediaService) ListJobs(ctx context.Context, tenantID primitive.ObjectID, filter model.JobFilter, page, limit int) ([]*model.ProcessingJob, int64, error) {
+	return s.jobRepo.List(ctx, tenantID, filter, page, limit)
+}
+
+// GetJob gets a tenant's processing job
+func (s *MediaService) GetJob(ctx context.Context, tenantID, id primitive.ObjectID) (*model.ProcessingJob, error) {
+	return s.jobRepo.FindByID(ctx, tenantID, id)
+}
+
+// RetryJob queues a dead or cancelled job again with all its attempts
+func (s *MediaService) RetryJob(ctx context.Context, tenantID, id primitive.ObjectID) (*model.ProcessingJob, error) {
+	job, err := s.jobRepo.Retry(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.repo.UpdateProcessingStatus(ctx, job.FileID, "pending", "")
+	return job, nil
+}
+
+// CancelJob cancels a queued or running job. A running job stops at its
+// worker's next heartbeat.
+func (s *MediaService) CancelJob(ctx context.Context, tenantID, id primitive.ObjectID) (*model.ProcessingJob, error) {
+	job, err := s.jobRepo.Cancel(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.repo.UpdateProcessingStatus(ctx, job.FileID, "failed", "processing was cancelled")
+	return job, nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/repository"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrOffsetMismatch = errors.New("upload offset does not match")
+	ErrUploadBusy     = errors.New("upload is being assembled")
+)
+
+// CreateUpload starts a resumable upload. The announced length is checked
+// against the file type's maximum size and the tenant's quota before any
+// byte is received, and reserves quota until the upload completes or
+// expires.
+func (s *MediaService) CreateUpload(ctx context.Context, upload *model.ResumableUpload) error {
+	if upload.MimeType == "" {
+		upload.MimeType = storage.ContentType(upload.FileName)
+	}
+	if upload.Folder == "" {
+		upload.Folder = "/"
+	}
+	upload.FileType = s.determineFileType(upload.MimeType)
+
+	if err := s.checkUpload(ctx, upload.TenantID, upload.FileType, upload.Length); err != nil {
+		return err
+	}
+
+	upload.Offset = 0
+	upload.ExpiresAt = time.Now().Add(s.uploadExpiry)
+	return s.uploadRepo.Create(ctx, upload)
+}
+
+// GetUpload gets a tenant's resumable upload
+func (s *MediaService) GetUpload(ctx context.Context, tenantID, id primitive.ObjectID) (*model.ResumableUpload, error) {
+	return s.uploadRepo.FindByID(ctx, tenantID, id)
+}
+
+// WriteUploadChunk appends the bytes read from r to an upload at offset,
+// which must be the upload's current offset. The bytes received are kept
+// even if reading r fails, so the client can resume after them. Once every
+// byte is received the upload is assembled into a media file, which is
+// returned with the upload.
+func (s *MediaService) WriteUploadChunk(ctx context.Context, tenantID, id primitive.ObjectID, offset int64, r io.Reader) (*model.ResumableUpload, *model.MediaFile, error) {
+	upload, err := s.uploadRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if upload.FileID != nil {
+		file, err := s.repo.FindFileByID(ctx, *upload.FileID)
+		return upload, file, err
+	}
+	if upload.Completing {
+		return nil, nil, ErrUploadBusy
+	}
+	if offset != upload.Offset {
+		return nil, nil, fmt.Errorf("%w: upload is at %d, chunk starts at %d", ErrOffsetMismatch, upload.Offset, offset)
+	}
+
+	// Spool the chunk, reading at most one byte more than the upload lacks
+	tmp, err := os.CreateTemp(s.workDir, "chunk-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, readErr := io.Copy(tmp, io.LimitReader(r, upload.Length-offset+1))
+	if offset+size > upload.Length {
+		return nil, nil, fmt.Errorf("%w: chunk exceeds the upload length %d", ErrFileTooLarge, upload.Length)
+	}
+
+	if size > 0 {
+		// Keep what was received even if the client went away
+		ctx := context.WithoutCancel(ctx)
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return nil, nil, err
+		}
+		part := model.UploadPart{
+			Key:    path.Join(uploadPrefix(upload), fmt.Sprintf("%020d-%s", offset, primitive.NewObjectID().Hex())),
+			Offset: offset,
+			Size:   size,
+		}
+		if err := s.store.Put(ctx, part.Key, tmp, size, "application/octet-stream"); err != nil {
+			return nil, nil, err
+		}
+		upload, err = s.uploadRepo.AppendPart(ctx, upload.ID, part, time.Now().Add(s.uploadExpiry))
+		if err != nil {
+			s.store.Delete(ctx, part.Key)
+			if errors.Is(err, repository.ErrConflict) {
+				return nil, nil, fmt.Errorf("%w: another chunk was written at %d", ErrOffsetMismatch, offset)
+			}
+			return nil, nil, err
+		}
+	}
+	if readErr != nil {
+		return upload, nil, readErr
+	}
+
+	if !upload.Complete() {
+		return upload, nil, nil
+	}
+	file, err := s.completeUpload(context.WithoutCancel(ctx), upload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return upload, file, nil
+}
+
+// completeUpload assembles the parts of a fully received upload into a
+// media file, then deletes the parts
+func (s *MediaService) completeUpload(ctx context.Context, upload *model.ResumableUpload) (*model.MediaFile, error) {
+	if err := s.uploadRepo.ClaimCompletion(ctx, upload.ID); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, ErrUploadBusy
+		}
+		return nil, err
+	}
+
+	file, err := s.assembleUpload(ctx, upload)
+	if err != nil {
+		// A rejected file would be rejected again, so the upload is dropped
+		if rejected(err) {
+			if err := s.deleteUpload(ctx, upload); err != nil {
+				log.Printf("Failed to delete rejected upload %s: %v", upload.ID.Hex(), err)
+			}
+		} else {
+			s.uploadRepo.ReleaseCompletion(ctx, upload.ID)
+		}
+		return nil, err
+	}
+
+	if err := s.uploadRepo.Complete(ctx, upload.ID, file.ID); err != nil {
+		return nil, err
+	}
+	upload.FileID = &file.ID
+	upload.Completing = false
+
+	if err := storage.DeletePrefix(ctx, s.store, uploadPrefix(upload)+"/"); err != nil {
+		log.Printf("Failed to delete parts of upload %s: %v", upload.ID.Hex(), err)
+	}
+	return file, nil
+}
+
+// assembleUpload concatenates the parts of an upload into a temporary file
+// and creates a media file from it
+func (s *MediaService) assembleUpload(ctx context.Context, upload *model.ResumableUpload) (*model.MediaFile, error) {
+	tmp, err := os.CreateTemp(s.workDir, "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	var size int64
+	for _, part := range upload.Parts {
+		if part.Offset != size {
+			return nil, fmt.Errorf("upload %s has a gap at %d", upload.ID.Hex(), size)
+		}
+		reader, _, err := s.store.Get(ctx, part.Key)
+		if err != nil {
+			return nil, fmt.Errorf("read part %s: %w", part.Key, err)
+		}
+		n, err := io.Copy(tmp, reader)
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read part %s: %w", part.Key, err)
+		}
+		size += n
+	}
+	if size != upload.Length {
+		return nil, fmt.Errorf("upload %s assembled %d of %d bytes", upload.ID.Hex(), size, upload.Length)
+	}
+
+	return s.createFile(ctx, &receivedFile{
+		file:      tmp,
+		size:      size,
+		name:      upload.FileName,
+		mimeType:  upload.MimeType,
+		fileType:  upload.FileType,
+		tenantID:  upload.TenantID,
+		userID:    upload.UploadedBy,
+		folder:    upload.Folder,
+		ipAddress: upload.IPAddress,
+		userAgent: upload.UserAgent,
+	})
+}
+
+// TerminateUpload deletes an upload and the parts received so far. The
+// media file of a completed upload is kept.
+func (s *MediaService) TerminateUpload(ctx context.Context, tenantID, id primitive.ObjectID) error {
+	upload, err := s.uploadRepo.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if upload.Completing {
+		return ErrUploadBusy
+	}
+	return s.deleteUpload(ctx, upload)
+}
+
+// ExpireUploads deletes uploads that expired before now with their parts,
+// and returns how many were deleted
+func (s *MediaService) ExpireUploads(ctx context.Context, now time.Time) (int, error) {
+	uploads, err := s.uploadRepo.FindExpired(ctx, now, 100)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, upload := range uploads {
+		if err := s.deleteUpload(ctx, upload); err != nil {
+			log.Printf("Failed to expire upload %s: %v", upload.ID.Hex(), err)
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (s *MediaService) deleteUpload(ctx context.Context, upload *model.ResumableUpload) error {
+	if err := storage.DeletePrefix(ctx, s.store, uploadPrefix(upload)+"/"); err != nil {
+		return err
+	}
+	return s.uploadRepo.Delete(ctx, upload.ID)
+}
+
+// uploadPrefix returns the key under which the parts of an upload are stored
+func uploadPrefix(upload *model.ResumableUpload) string {
+	return path.Join("uploads", upload.TenantID.Hex(), upload.ID.Hex())
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalStorage stores objects as files under a directory
+type LocalStorage struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalStorage creates a store in root, whose files are served at
+// baseURL. The directory is created if it does not exist.
+func NewLocalStorage(root, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{
+		root:    filepath.Clean(root),
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// Put writes the object to a temporary file first, so readers never see a
+// partly written object
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("wrote %d bytes of %d", written, size)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// Get opens the object's file, which can seek
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil, ErrNotFound
+	}
+	return file, s.object(key, info), nil
+}
+
+// Stat describes the object's file
+func (s *LocalStorage) Stat(ctx context.Context, key string) (*Object, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if info.IsDir() {
+		return nil, ErrNotFound
+	}
+	return s.object(key, info), nil
+}
+
+// Delete removes the object's file and the directories it leaves empty
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for dir := filepath.Dir(filePath); dir != s.root && strings.HasPrefix(dir, s.root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// List walks the files under the directory that holds prefix
+func (s *LocalStorage) List(ctx context.Context, prefix string, fn func(*Object) error) error {
+	dir := s.root
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		if !ValidKey(prefix[:i]) {
+			return ErrInvalidKey
+		}
+		dir = filepath.Join(s.root, filepath.FromSlash(prefix[:i]))
+	}
+
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return fn(s.object(key, info))
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// PresignedURL returns the public URL of the file. Local files are served
+// to anyone, so the URL carries no signature and does not expire.
+func (s *LocalStorage) PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return s.baseURL + "/" + key, nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStorage) object(key string, info fs.FileInfo) *Object {
+	return &Object{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  ContentType(key),
+		ETag:         fmt.Sprintf("%q", fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())),
+		LastModified: info.ModTime(),
+	}
+}
+
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
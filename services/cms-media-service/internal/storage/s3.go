@@ -0,0 +1,376 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// emptyPayloadHash is the SHA-256 of an empty request body
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// unsignedPayload signs a request without hashing its body, so uploads
+	// can be streamed
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	signAlgorithm   = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+	// maxPresignExpiry is the longest a presigned URL may stay valid
+	maxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// S3Config configures an S3-compatible store
+type S3Config struct {
+	Endpoint        string // e.g. https://s3.eu-west-1.amazonaws.com or http://minio:9000
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool // Address the bucket in the path rather than the host, as MinIO expects
+}
+
+// S3Storage stores objects in a bucket of an S3-compatible service, signing
+// requests with AWS Signature Version 4. Objects are written with a single
+// PUT, so they are limited to 5 GB.
+type S3Storage struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Storage creates a store for a bucket
+func NewS3Storage(config S3Config) (*S3Storage, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, errors.New("S3 bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	return &S3Storage{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{},
+	}, nil
+}
+
+// Put uploads the object
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		return errors.New("S3 uploads need the object size")
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if size == 0 {
+		r = http.NoBody
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, nil, header, r, size)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get downloads the object
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, s3Object(key, resp), nil
+}
+
+// Stat reads the object's headers
+func (s *S3Storage) Stat(ctx context.Context, key string) (*Object, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return s3Object(key, resp), nil
+}
+
+// Delete deletes the object
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil, 0)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// List pages through the bucket listing for prefix
+func (s *S3Storage) List(ctx context.Context, prefix string, fn func(*Object) error) error {
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, nil, 0)
+		if err != nil {
+			return err
+		}
+		var result struct {
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+			Contents              []struct {
+				Key          string    `xml:"Key"`
+				LastModified time.Time `xml:"LastModified"`
+				ETag         string    `xml:"ETag"`
+				Size         int64     `xml:"Size"`
+			} `xml:"Contents"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("S3 list %s: %w", prefix, err)
+		}
+
+		for _, content := range result.Contents {
+			err := fn(&Object{
+				Key:          content.Key,
+				Size:         content.Size,
+				ContentType:  ContentType(content.Key),
+				ETag:         content.ETag,
+				LastModified: content.LastModified,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// PresignedURL signs a GET of the object into its query string
+func (s *S3Storage) PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return s.presign(key, expiry, time.Now()), nil
+}
+
+func (s *S3Storage) presign(key string, expiry time.Duration, now time.Time) string {
+	expiry = min(max(expiry, time.Second), maxPresignExpiry)
+	now = now.UTC()
+
+	u := s.objectURL(key)
+	query := url.Values{
+		"X-Amz-Algorithm":     {signAlgorithm},
+		"X-Amz-Credential":    {s.config.AccessKeyID + "/" + s.scope(now)},
+		"X-Amz-Date":          {now.Format(amzDateFormat)},
+		"X-Amz-Expires":       {strconv.Itoa(int(expiry.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(canonical, now))
+	u.RawQuery = canonicalQuery(query)
+	return u.String()
+}
+
+// do sends a signed request for key, or for the bucket if key is empty, and
+// turns error responses into errors
+func (s *S3Storage) do(ctx context.Context, method, key string, query url.Values, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	if key != "" && !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+	u := s.objectURL(key)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	payloadHash := emptyPayloadHash
+	if body != nil && body != http.NoBody {
+		req.ContentLength = size
+		payloadHash = unsignedPayload
+	}
+	s.sign(req, payloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var apiErr struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	xml.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&apiErr)
+	if resp.StatusCode == http.StatusNotFound && apiErr.Code != "NoSuchBucket" {
+		return nil, ErrNotFound
+	}
+	if apiErr.Code == "" {
+		apiErr.Code = resp.Status
+	}
+	return nil, fmt.Errorf("S3 %s %s: %s %s", method, key, apiErr.Code, apiErr.Message)
+}
+
+// sign adds the Authorization header of a request. The host, range and
+// content headers and every x-amz- header are signed.
+func (s *S3Storage) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "range" || name == "content-type" || name == "content-md5" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, s.config.AccessKeyID, s.scope(now), signedHeaders, s.signature(canonical, now)))
+}
+
+// signature signs a canonical request with the key derived for its day
+func (s *S3Storage) signature(canonicalRequest string, now time.Time) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		signAlgorithm,
+		now.Format(amzDateFormat),
+		s.scope(now),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), now.Format("20060102"))
+	for _, part := range []string{s.config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func (s *S3Storage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.config.Region + "/s3/aws4_request"
+}
+
+// objectURL returns the URL of an object, or of the bucket if key is empty
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	path := "/" + key
+	if s.config.PathStyle {
+		path = "/" + s.config.Bucket
+		if key != "" {
+			path += "/" + key
+		}
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+	}
+	u.Path = u.Path + path
+	u.RawPath = uriEncode(u.Path, false)
+	return &u
+}
+
+func s3Object(key string, resp *http.Response) *Object {
+	lastModified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = ContentType(key)
+	}
+	return &Object{
+		Key:          key,
+		Size:         resp.ContentLength,
+		ContentType:  contentType,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: lastModified,
+	}
+}
+
+// canonicalQuery encodes a query with sorted keys, as signatures need
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters and,
+// unless encodeSlash is set, slashes
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
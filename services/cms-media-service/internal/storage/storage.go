@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Object describes a stored object
+type Object struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+// Storage stores media objects by key. Keys are slash separated paths
+// relative to the root of the store, such as "tenant/image/ab/abcd.jpg".
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any object
+	// already there
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens an object; the caller closes the reader
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	// Stat describes an object without reading it
+	Stat(ctx context.Context, key string) (*Object, error)
+	// Delete removes an object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// List calls fn for every object whose key starts with prefix
+	List(ctx context.Context, prefix string, fn func(*Object) error) error
+	// PresignedURL returns a URL that reads an object without credentials
+	// until it expires
+	PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// ValidKey reports whether a key is a clean relative path that stays inside
+// the store
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	return path.Clean(key) == key && key != "." && !strings.HasPrefix(key, "../") && key != ".."
+}
+
+// ContentType guesses the content type of a key from its extension
+func ContentType(key string) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// PutFile stores a local file under key
+func PutFile(ctx context.Context, store Storage, key, filePath, contentType string) (int64, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if contentType == "" {
+		contentType = ContentType(key)
+	}
+	return info.Size(), store.Put(ctx, key, file, info.Size(), contentType)
+}
+
+// PutDir stores every file under a local directory, keyed by prefix followed
+// by the file's path relative to dir
+func PutDir(ctx context.Context, store Storage, prefix, dir string) error {
+	return filepath.WalkDir(dir, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		_, err = PutFile(ctx, store, path.Join(prefix, filepath.ToSlash(rel)), filePath, "")
+		return err
+	})
+}
+
+// FetchFile copies an object to a local file, so that tools that only read
+// files can process it
+func FetchFile(ctx context.Context, store Storage, key, filePath string) error {
+	reader, _, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// DeletePrefix deletes every object whose key starts with prefix
+func DeletePrefix(ctx context.Context, store Storage, prefix string) error {
+	var keys []string
+	err := store.List(ctx, prefix, func(object *Object) error {
+		keys = append(keys, object.Key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			errs = append(errs, fmt.Errorf("delete %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FileServer serves the objects of a store by the request path, which is
+// taken as the key. Objects that can seek, such as local files, are served
+// with range support.
+func FileServer(store Storage) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if !ValidKey(key) {
+			http.NotFound(w, r)
+			return
+		}
+
+		reader, object, err := store.Get(r.Context(), key)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				http.NotFound(w, r)
+				return
+			}
+			http.Error(w, "Failed to read file", http.StatusInternalServerError)
+			return
+		}
+		defer reader.Close()
+
+		// Stored files are never run as pages of this origin, whatever
+		// their type
+		w.Header().Set("Content-Type", object.ContentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src data:; style-src 'unsafe-inline'; sandbox")
+		if object.ETag != "" {
+			w.Header().Set("ETag", object.ETag)
+		}
+		if seeker, ok := reader.(io.ReadSeeker); ok {
+			http.ServeContent(w, r, key, object.LastModified, seeker)
+			return
+		}
+
+		w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+		if !object.LastModified.IsZero() {
+			w.Header().Set("Last-Modified", object.LastModified.UTC().Format(http.TimeFormat))
+		}
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			io.Copy(w, reader)
+		}
+	})
+}
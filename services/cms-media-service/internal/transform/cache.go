@@ -0,0 +1,184 @@
+package transform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache is a disk cache of generated images. Once it holds more than its
+// maximum size, the images used least recently are evicted until it is
+// back under nine tenths of it.
+type Cache struct {
+	dir     string
+	maxSize int64
+
+	mu   sync.Mutex
+	size int64
+}
+
+// NewCache creates a cache in dir holding up to maxSize bytes, or any
+// amount if maxSize is 0. Images cached by earlier runs are kept.
+func NewCache(dir string, maxSize int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	c := &Cache{dir: dir, maxSize: maxSize}
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		c.size += entry.size
+	}
+	return c, nil
+}
+
+// Key returns the name under which an image generated from parts is
+// cached, with the extension of its format
+func Key(ext string, parts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(hash[:]) + ext
+}
+
+// path returns the local path of a cached image
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// Open opens a cached image and marks it as used. The error wraps
+// fs.ErrNotExist if the image is not cached.
+func (c *Cache) Open(key string) (*os.File, error) {
+	localPath := c.path(key)
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	os.Chtimes(localPath, now, now)
+	return file, nil
+}
+
+// Add moves a generated image into the cache under key
+func (c *Cache) Add(key, srcPath string) error {
+	localPath := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	// The source may be on another file system, so it is copied unless it
+	// can be renamed; the image appears in the cache at once either way
+	if err := os.Rename(srcPath, localPath); err != nil {
+		if err := copyInto(srcPath, localPath); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	c.size += info.Size()
+	evict := c.maxSize > 0 && c.size > c.maxSize
+	c.mu.Unlock()
+	if evict {
+		c.evict()
+	}
+	return nil
+}
+
+// copyInto copies a file to a temporary file next to dst, then renames it
+// to dst
+func copyInto(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// cacheEntry is a cached image
+type cacheEntry struct {
+	path   string
+	size   int64
+	usedAt time.Time
+}
+
+// entries lists the cached images
+func (c *Cache) entries() ([]cacheEntry, error) {
+	var entries []cacheEntry
+	err := filepath.WalkDir(c.dir, func(localPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Images evicted concurrently are skipped
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, cacheEntry{path: localPath, size: info.Size(), usedAt: info.ModTime()})
+		return nil
+	})
+	return entries, err
+}
+
+// evict deletes the images used least recently until the cache is under
+// nine tenths of its maximum size
+func (c *Cache) evict() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.entries()
+	if err != nil {
+		log.Printf("Failed to list the image cache: %v", err)
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].usedAt.Before(entries[j].usedAt)
+	})
+
+	c.size = 0
+	for _, entry := range entries {
+		c.size += entry.size
+	}
+	target := c.maxSize / 10 * 9
+	for _, entry := range entries {
+		if c.size <= target {
+			break
+		}
+		if err := os.Remove(entry.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		c.size -= entry.size
+	}
+}
@@ -0,0 +1,137 @@
+// Package transform handles on-demand image transforms: the options given
+// in their URLs, the signatures that limit them to the URLs this service
+// handed out, and the disk cache of the images they generate.
+package transform
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxDimension is the largest width or height a transform may ask for
+const MaxDimension = 4096
+
+// ErrInvalidOptions is returned for transform options out of range
+var ErrInvalidOptions = errors.New("invalid image transform options")
+
+// Formats lists the formats a transform may ask for; auto picks the best
+// one the client accepts
+var Formats = []string{"auto", "jpeg", "png", "webp", "avif"}
+
+// Options are the options of a transform, given in its URL as w, h, fit,
+// fmt and q
+type Options struct {
+	Width   int    // 0 scales with Height
+	Height  int    // 0 scales with Width
+	Fit     string // contain (default) or cover
+	Format  string // one of Formats; empty keeps the image's format
+	Quality int    // 1-100; 0 uses the default
+}
+
+// ParseOptions parses and checks the options of a transform URL
+func ParseOptions(query url.Values) (Options, error) {
+	var o Options
+	var err error
+	if o.Width, err = parseInt(query, "w"); err != nil {
+		return o, err
+	}
+	if o.Height, err = parseInt(query, "h"); err != nil {
+		return o, err
+	}
+	if o.Quality, err = parseInt(query, "q"); err != nil {
+		return o, err
+	}
+	o.Fit = query.Get("fit")
+	o.Format = query.Get("fmt")
+	return o, o.Validate()
+}
+
+func parseInt(query url.Values, key string) (int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s is not a number", ErrInvalidOptions, key)
+	}
+	return n, nil
+}
+
+// Validate checks that the options are in range
+func (o Options) Validate() error {
+	switch {
+	case o.Width < 0 || o.Width > MaxDimension, o.Height < 0 || o.Height > MaxDimension:
+		return fmt.Errorf("%w: width and height must be at most %d", ErrInvalidOptions, MaxDimension)
+	case o.Width == 0 && o.Height == 0:
+		return fmt.Errorf("%w: width or height is required", ErrInvalidOptions)
+	case o.Fit != "" && o.Fit != "contain" && o.Fit != "cover":
+		return fmt.Errorf("%w: fit must be contain or cover", ErrInvalidOptions)
+	case o.Format != "" && !slices.Contains(Formats, o.Format):
+		return fmt.Errorf("%w: fmt must be one of %s", ErrInvalidOptions, strings.Join(Formats, ", "))
+	case o.Quality < 0 || o.Quality > 100:
+		return fmt.Errorf("%w: q must be between 1 and 100", ErrInvalidOptions)
+	}
+	return nil
+}
+
+// Query encodes the options as URL parameters, leaving out defaults, in a
+// canonical order
+func (o Options) Query() url.Values {
+	query := url.Values{}
+	if o.Width > 0 {
+		query.Set("w", strconv.Itoa(o.Width))
+	}
+	if o.Height > 0 {
+		query.Set("h", strconv.Itoa(o.Height))
+	}
+	if o.Fit != "" && o.Fit != "contain" {
+		query.Set("fit", o.Fit)
+	}
+	if o.Format != "" {
+		query.Set("fmt", o.Format)
+	}
+	if o.Quality > 0 {
+		query.Set("q", strconv.Itoa(o.Quality))
+	}
+	return query
+}
+
+// Signer signs transform URLs, so that only the transforms this service
+// handed out are generated
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner creates a signer with a secret shared by every instance of the
+// service
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: []byte(secret)}
+}
+
+// Sign returns the signature of a transform of an image
+func (s *Signer) Sign(id string, o Options) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id + "?" + o.Query().Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18])
+}
+
+// Verify reports whether a signature is the one of a transform of an image
+func (s *Signer) Verify(id string, o Options, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(s.Sign(id, o)))
+}
+
+// URL returns the signed URL of a transform of an image served under
+// baseURL
+func (s *Signer) URL(baseURL, id string, o Options) string {
+	query := o.Query()
+	query.Set("s", s.Sign(id, o))
+	return strings.TrimSuffix(baseURL, "/") + "/img/" + id + "?" + query.Encode()
+}
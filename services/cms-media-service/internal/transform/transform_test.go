@@ -0,0 +1,145 @@
+package transform
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    Options
+		wantErr bool
+	}{
+		{query: "w=640", want: Options{Width: 640}},
+		{query: "w=640&h=360&fit=cover&fmt=webp&q=75", want: Options{Width: 640, Height: 360, Fit: "cover", Format: "webp", Quality: 75}},
+		{query: "h=200&fmt=auto", want: Options{Height: 200, Format: "auto"}},
+		{query: "", wantErr: true},
+		{query: "w=abc", wantErr: true},
+		{query: "w=5000", wantErr: true},
+		{query: "w=-1", wantErr: true},
+		{query: "w=100&fit=fill", wantErr: true},
+		{query: "w=100&fmt=bmp", wantErr: true},
+		{query: "w=100&q=101", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		got, err := ParseOptions(query)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("ParseOptions(%q) error = %v, want ErrInvalidOptions", tt.query, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseOptions(%q) = %+v, %v, want %+v", tt.query, got, err, tt.want)
+		}
+	}
+}
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner("secret")
+	options := Options{Width: 640, Height: 360, Fit: "cover", Format: "webp"}
+
+	signed := signer.URL("https://media.example.com/", "65abc123def4567890123456", options)
+	parsed, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("URL() = %q: %v", signed, err)
+	}
+	if parsed.Path != "/img/65abc123def4567890123456" {
+		t.Errorf("URL() path = %q", parsed.Path)
+	}
+
+	query := parsed.Query()
+	got, err := ParseOptions(query)
+	if err != nil || got != options {
+		t.Fatalf("ParseOptions(URL()) = %+v, %v, want %+v", got, err, options)
+	}
+	if !signer.Verify("65abc123def4567890123456", got, query.Get("s")) {
+		t.Error("Verify() rejected the URL's own signature")
+	}
+
+	// The default fit signs the same whether it is given or not
+	contain := Options{Width: 640, Fit: "contain"}
+	if !signer.Verify("65abc123def4567890123456", Options{Width: 640}, signer.Sign("65abc123def4567890123456", contain)) {
+		t.Error("Verify() rejected an explicit default fit")
+	}
+
+	tampered := got
+	tampered.Width = 4096
+	if signer.Verify("65abc123def4567890123456", tampered, query.Get("s")) {
+		t.Error("Verify() accepted changed options")
+	}
+	if signer.Verify("65abc123def4567890123457", got, query.Get("s")) {
+		t.Error("Verify() accepted another image")
+	}
+	if NewSigner("other").Verify("65abc123def4567890123456", got, query.Get("s")) {
+		t.Error("Verify() accepted another secret")
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewCache(filepath.Join(dir, "cache"), 250)
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+
+	add := func(key string, size int, usedAt time.Time) {
+		t.Helper()
+		src := filepath.Join(dir, "generated")
+		if err := os.WriteFile(src, []byte(strings.Repeat("x", size)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.Add(key, src); err != nil {
+			t.Fatalf("Add(%s) error = %v", key, err)
+		}
+		os.Chtimes(cache.path(key), usedAt, usedAt)
+	}
+
+	now := time.Now()
+	first, second, third := Key(".webp", "a"), Key(".webp", "b"), Key(".avif", "c")
+	add(first, 100, now.Add(-3*time.Hour))
+	add(second, 100, now.Add(-2*time.Hour))
+
+	// Using the first image makes the second the least recently used
+	file, err := cache.Open(first)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	content, _ := io.ReadAll(file)
+	file.Close()
+	if len(content) != 100 {
+		t.Errorf("Open() read %d bytes, want 100", len(content))
+	}
+
+	add(third, 100, now)
+	if _, err := cache.Open(second); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(evicted) error = %v, want fs.ErrNotExist", err)
+	}
+	for _, key := range []string{first, third} {
+		file, err := cache.Open(key)
+		if err != nil {
+			t.Errorf("Open(%s) error = %v", key, err)
+			continue
+		}
+		file.Close()
+	}
+
+	// A new cache on the same directory counts what is cached
+	reopened, err := NewCache(filepath.Join(dir, "cache"), 250)
+	if err != nil {
+		t.Fatalf("NewCache() error = %v", err)
+	}
+	if reopened.size != 200 {
+		t.Errorf("reopened cache size = %d, want 200", reopened.size)
+	}
+}
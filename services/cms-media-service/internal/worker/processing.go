@@ -0,0 +1,127 @@
+package worker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/model"
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/service"
+)
+
+// ProcessingWorker runs the queued processing jobs with a pool of workers
+// per job type, and queues the jobs of crashed workers again once their
+// lease expires
+type ProcessingWorker struct {
+	mediaService *service.MediaService
+	concurrency  map[model.JobType]int
+	pollInterval time.Duration
+	lease        time.Duration
+	owner        string
+	stopChan     chan bool
+	stopped      chan struct{}
+}
+
+// NewProcessingWorker creates a new processing worker that runs
+// concurrency[type] jobs of each type at a time, looks for due jobs every
+// pollInterval while idle and leases the jobs it runs for lease
+func NewProcessingWorker(mediaService *service.MediaService, concurrency map[model.JobType]int, pollInterval, lease time.Duration) *ProcessingWorker {
+	return &ProcessingWorker{
+		mediaService: mediaService,
+		concurrency:  concurrency,
+		pollInterval: pollInterval,
+		lease:        lease,
+		owner:        workerOwner(),
+		stopChan:     make(chan bool),
+		stopped:      make(chan struct{}),
+	}
+}
+
+// workerOwner identifies this process as the owner of the jobs it leases
+func workerOwner() string {
+	hostname, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// Start runs jobs until stopped. Running jobs are queued again when the
+// worker stops.
+func (w *ProcessingWorker) Start(ctx context.Context) {
+	defer close(w.stopped)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, jobType := range model.JobTypes {
+		for i := 0; i < w.concurrency[jobType]; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w.run(ctx, jobType)
+			}()
+		}
+	}
+
+	ticker := time.NewTicker(w.lease)
+	defer ticker.Stop()
+
+	log.Printf("Processing worker %s started", w.owner)
+	w.requeueExpired(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			w.requeueExpired(ctx)
+		case <-w.stopChan:
+			cancel()
+			wg.Wait()
+			log.Println("Processing worker stopped")
+			return
+		case <-ctx.Done():
+			wg.Wait()
+			log.Println("Processing worker stopped due to context cancellation")
+			return
+		}
+	}
+}
+
+// Stop stops the worker and waits until its running jobs are queued again
+func (w *ProcessingWorker) Stop() {
+	close(w.stopChan)
+	<-w.stopped
+}
+
+// run claims and runs the jobs of a type one at a time
+func (w *ProcessingWorker) run(ctx context.Context, jobType model.JobType) {
+	for ctx.Err() == nil {
+		job, err := w.mediaService.ClaimJob(ctx, jobType, w.owner, w.lease)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Error claiming %s job: %v", jobType, err)
+		}
+		if job != nil {
+			w.mediaService.RunJob(ctx, job, w.owner, w.lease)
+			continue
+		}
+
+		select {
+		case <-time.After(w.pollInterval):
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (w *ProcessingWorker) requeueExpired(ctx context.Context) {
+	requeued, err := w.mediaService.RequeueExpiredJobs(ctx, time.Now())
+	if err != nil {
+		log.Printf("Error requeuing expired jobs: %v", err)
+	}
+	if requeued > 0 {
+		log.Printf("Queued %d jobs of unresponsive workers again", requeued)
+	}
+}
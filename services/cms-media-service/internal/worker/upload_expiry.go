@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/vhvplatform/go-cms-service/services/cms-media-service/internal/service"
+)
+
+// UploadExpiryWorker periodically deletes resumable uploads that were
+// abandoned before they completed, with the chunks received so far
+type UploadExpiryWorker struct {
+	mediaService *service.MediaService
+	interval     time.Duration
+	stopChan     chan bool
+}
+
+// NewUploadExpiryWorker creates a new upload expiry worker
+func NewUploadExpiryWorker(mediaService *service.MediaService, interval time.Duration) *UploadExpiryWorker {
+	return &UploadExpiryWorker{
+		mediaService: mediaService,
+		interval:     interval,
+		stopChan:     make(chan bool),
+	}
+}
+
+// Start deletes the expired uploads once, then every interval until stopped
+func (w *UploadExpiryWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Println("Upload expiry worker started")
+	w.expireUploads(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			w.expireUploads(ctx)
+		case <-w.stopChan:
+			log.Println("Upload expiry worker stopped")
+			return
+		case <-ctx.Done():
+			log.Println("Upload expiry worker stopped due to context cancellation")
+			return
+		}
+	}
+}
+
+// Stop stops the worker
+func (w *UploadExpiryWorker) Stop() {
+	close(w.stopChan)
+}
+
+func (w *UploadExpiryWorker) expireUploads(ctx context.Context) {
+	expired, err := w.mediaService.ExpireUploads(ctx, time.Now())
+	if err != nil {
+		log.Printf("Error expiring uploads: %v", err)
+	}
+	if expired > 0 {
+		log.Printf("Deleted %d expired uploads", expired)
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/vhvplatform/go-cms-service/pkg/auth"
+	"github.com/vhvplatform/go-cms-service/pkg/config"
+	"github.com/vhvplatform/go-cms-service/services/cms-stats-service/internal/client"
 	"github.com/vhvplatform/go-cms-service/services/cms-stats-service/internal/handler"
 	"github.com/vhvplatform/go-cms-service/services/cms-stats-service/internal/repository"
 	"github.com/vhvplatform/go-cms-service/services/cms-stats-service/internal/service"
@@ -21,37 +24,53 @@ func main() {
 	mongoURI := getEnv("MONGODB_URI", "mongodb://localhost:27017")
 	dbName := getEnv("MONGODB_DATABASE", "cms_comments")
 	serverPort := getEnv("SERVER_PORT", "8081")
+	cmsServiceURL := getEnv("CMS_SERVICE_URL", "http://localhost:8080")
 
 	log.Println("Starting Comment & Statistics Service...")
 	log.Printf("MongoDB URI: %s", mongoURI)
 	log.Printf("Database: %s", dbName)
 	log.Printf("Server Port: %s", serverPort)
+	log.Printf("CMS Service URL: %s", cmsServiceURL)
 
 	// Connect to MongoDB
 	ctx := context.Background()
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
 	if err != nil {
 		log.Fatalf("Failed to connect to MongoDB: %v", err)
 	}
-	defer client.Disconnect(ctx)
+	defer mongoClient.Disconnect(ctx)
 
 	// Ping MongoDB
-	if err := client.Ping(ctx, nil); err != nil {
+	if err := mongoClient.Ping(ctx, nil); err != nil {
 		log.Fatalf("Failed to ping MongoDB: %v", err)
 	}
 	log.Println("✓ Connected to MongoDB")
 
-	db := client.Database(dbName)
+	db := mongoClient.Database(dbName)
 
 	// Initialize repositories
 	commentRepo := repository.NewCommentRepository(db)
 
+	// Initialize clients; the CMS screens comments before they are stored
+	cmsClient := client.NewCMSClient(cmsServiceURL)
+
 	// Initialize services
-	commentService := service.NewCommentService(commentRepo)
+	commentService := service.NewCommentService(commentRepo, cmsClient)
 
 	// Initialize handlers
 	commentHandler := handler.NewCommentHandler(commentService)
 
+	// Initialize token validator; reading comments stays public while
+	// writes and user-specific routes require a valid bearer token
+	tokenValidator, err := auth.NewValidator(config.NewJWTConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize token validator: %v", err)
+	}
+	authenticate := auth.Middleware(tokenValidator)
+	protected := func(h http.HandlerFunc) http.HandlerFunc {
+		return authenticate(h).ServeHTTP
+	}
+
 	// Setup router
 	mux := http.NewServeMux()
 
@@ -65,7 +84,7 @@ func main() {
 	mux.HandleFunc("/api/v1/articles/", func(w http.ResponseWriter, r *http.Request) {
 		if containsSegment(r.URL.Path, "comments") {
 			if r.Method == http.MethodPost {
-				commentHandler.CreateComment(w, r)
+				protected(commentHandler.CreateComment)(w, r)
 			} else if r.Method == http.MethodGet {
 				commentHandler.GetArticleComments(w, r)
 			} else {
@@ -76,9 +95,9 @@ func main() {
 
 		if containsSegment(r.URL.Path, "favorite") {
 			if r.Method == http.MethodPost {
-				commentHandler.AddFavorite(w, r)
+				protected(commentHandler.AddFavorite)(w, r)
 			} else if r.Method == http.MethodDelete {
-				commentHandler.RemoveFavorite(w, r)
+				protected(commentHandler.RemoveFavorite)(w, r)
 			} else {
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			}
@@ -91,22 +110,22 @@ func main() {
 	// Comment-specific routes
 	mux.HandleFunc("/api/v1/comments/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/api/v1/comments/pending" && r.Method == http.MethodGet {
-			commentHandler.GetPendingComments(w, r)
+			protected(commentHandler.GetPendingComments)(w, r)
 			return
 		}
 
 		if containsSegment(r.URL.Path, "replies") && r.Method == http.MethodGet {
 			commentHandler.GetCommentReplies(w, r)
 		} else if containsSegment(r.URL.Path, "moderate") && r.Method == http.MethodPost {
-			commentHandler.ModerateComment(w, r)
+			protected(commentHandler.ModerateComment)(w, r)
 		} else if containsSegment(r.URL.Path, "like") {
 			if r.Method == http.MethodPost {
-				commentHandler.LikeComment(w, r)
+				protected(commentHandler.LikeComment)(w, r)
 			} else if r.Method == http.MethodDelete {
-				commentHandler.UnlikeComment(w, r)
+				protected(commentHandler.UnlikeComment)(w, r)
 			}
 		} else if containsSegment(r.URL.Path, "report") && r.Method == http.MethodPost {
-			commentHandler.ReportComment(w, r)
+			protected(commentHandler.ReportComment)(w, r)
 		} else {
 			http.Error(w, "Not found", http.StatusNotFound)
 		}
@@ -115,7 +134,7 @@ func main() {
 	// User favorites route
 	mux.HandleFunc("/api/v1/users/favorites", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
-			commentHandler.GetUserFavorites(w, r)
+			protected(commentHandler.GetUserFavorites)(w, r)
 		} else {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
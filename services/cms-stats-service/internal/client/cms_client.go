@@ -0,0 +1,128 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrArticleNotFound is returned when the article does not exist in the tenant
+// the request was made for
+var ErrArticleNotFound = errors.New("article not found")
+
+// ErrForbidden is returned when the CMS does not let the user comment in the
+// tenant the request was made for
+var ErrForbidden = errors.New("not allowed to comment on this site")
+
+// ErrContentBlocked is returned when a comment matches a blocked keyword
+var ErrContentBlocked = errors.New("comment contains blocked keywords")
+
+// Origin describes the request a call to the CMS is made for, so the CMS
+// resolves the same tenant and user
+type Origin struct {
+	Host          string // Host the request was made to, which the CMS maps to a tenant
+	TenantID      string // Tenant selected with the X-Tenant-ID header, if any
+	Authorization string // Authorization header of the user
+}
+
+// apply sets the headers that pass the origin on to the CMS
+func (o Origin) apply(req *http.Request) {
+	if o.Host != "" {
+		req.Host = o.Host
+	}
+	if o.TenantID != "" {
+		req.Header.Set("X-Tenant-ID", o.TenantID)
+	}
+	if o.Authorization != "" {
+		req.Header.Set("Authorization", o.Authorization)
+	}
+}
+
+// CommentScreening is the CMS's verdict on a comment that was not blocked
+type CommentScreening struct {
+	HasViolations     bool   `json:"hasViolations"`
+	RecommendedAction string `json:"recommendedAction"` // Strictest action matched: warn or review
+	Results           []struct {
+		Keyword string `json:"keyword"`
+	} `json:"results"`
+}
+
+// NeedsReview reports whether the comment matched keywords that require review
+func (s *CommentScreening) NeedsReview() bool {
+	return s.RecommendedAction == "review"
+}
+
+// Keywords returns the matched keywords
+func (s *CommentScreening) Keywords() []string {
+	keywords := make([]string, 0, len(s.Results))
+	for _, r := range s.Results {
+		keywords = append(keywords, r.Keyword)
+	}
+	return keywords
+}
+
+// CMSClient handles communication with the CMS admin service
+type CMSClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewCMSClient creates a new CMS client
+func NewCMSClient(baseURL string) *CMSClient {
+	return &CMSClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// ScreenComment has the CMS check a comment before it is stored: the article
+// has to belong to the origin's tenant and the content is scanned with the
+// tenant's sensitive keywords
+func (c *CMSClient) ScreenComment(ctx context.Context, origin Origin, articleID, content string) (*CommentScreening, error) {
+	url := fmt.Sprintf("%s/api/v1/articles/%s/comments/screen", c.baseURL, articleID)
+
+	body, err := json.Marshal(map[string]string{"content": content})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	origin.apply(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrForbidden
+	case http.StatusNotFound:
+		return nil, ErrArticleNotFound
+	case http.StatusUnprocessableEntity:
+		return nil, ErrContentBlocked
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to screen comment: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var screening CommentScreening
+	if err := json.NewDecoder(resp.Body).Decode(&screening); err != nil {
+		return nil, err
+	}
+	return &screening, nil
+}
@@ -2,9 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/vhvplatform/go-cms-service/services/cms-stats-service/internal/client"
 	"github.com/vhvplatform/go-cms-service/services/cms-stats-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-stats-service/internal/service"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -24,7 +26,7 @@ func NewCommentHandler(service *service.CommentService) *CommentHandler {
 
 // CreateComment handles POST /api/v1/articles/{id}/comments
 func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
-	articleID, err := getIDFromPath(r, "id")
+	articleID, err := getIDFromPath(r, "comments")
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid article ID")
 		return
@@ -56,8 +58,22 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 		comment.ParentID = &parentObjID
 	}
 
-	if err := h.service.CreateComment(r.Context(), comment, userID, userName); err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+	origin := client.Origin{
+		Host:          r.Host,
+		TenantID:      r.Header.Get("X-Tenant-ID"),
+		Authorization: r.Header.Get("Authorization"),
+	}
+	if err := h.service.CreateComment(r.Context(), origin, comment, userID, userName); err != nil {
+		switch {
+		case errors.Is(err, client.ErrArticleNotFound):
+			respondError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, client.ErrForbidden):
+			respondError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, client.ErrContentBlocked):
+			respondError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			respondError(w, http.StatusBadRequest, err.Error())
+		}
 		return
 	}
 
@@ -66,7 +82,7 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 
 // GetArticleComments handles GET /api/v1/articles/{id}/comments
 func (h *CommentHandler) GetArticleComments(w http.ResponseWriter, r *http.Request) {
-	articleID, err := getIDFromPath(r, "id")
+	articleID, err := getIDFromPath(r, "comments")
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid article ID")
 		return
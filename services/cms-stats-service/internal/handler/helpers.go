@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/vhvplatform/go-cms-service/pkg/auth"
 	"github.com/vhvplatform/go-cms-service/services/cms-stats-service/internal/model"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -44,31 +45,25 @@ func getIDFromPath(r *http.Request, param string) (primitive.ObjectID, error) {
 }
 
 func getUserID(r *http.Request) string {
-	// Get user ID from context (set by auth middleware)
-	if userID := r.Context().Value("userID"); userID != nil {
-		if id, ok := userID.(string); ok {
-			return id
-		}
+	// Get user ID from the principal set by the auth middleware
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		return principal.UserID
 	}
-	return "system" // Default for testing
+	return ""
 }
 
 func getUserRole(r *http.Request) model.Role {
-	// Get user role from context (set by auth middleware)
-	if userRole := r.Context().Value("userRole"); userRole != nil {
-		if role, ok := userRole.(string); ok {
-			return model.Role(role)
-		}
+	// Get user role from the principal set by the auth middleware
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		return model.Role(principal.Role)
 	}
-	return model.RoleWriter // Default for testing
+	return ""
 }
 
 func getUserName(r *http.Request) string {
-	// Get user name from context (set by auth middleware)
-	if userName := r.Context().Value("userName"); userName != nil {
-		if name, ok := userName.(string); ok {
-			return name
-		}
+	// Get user name from the principal set by the auth middleware
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		return principal.UserName
 	}
-	return "System User" // Default for testing
+	return ""
 }
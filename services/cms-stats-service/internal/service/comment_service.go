@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/vhvplatform/go-cms-service/services/cms-stats-service/internal/client"
 	"github.com/vhvplatform/go-cms-service/services/cms-stats-service/internal/model"
 	"github.com/vhvplatform/go-cms-service/services/cms-stats-service/internal/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -18,18 +19,23 @@ const (
 
 // CommentService handles comment business logic
 type CommentService struct {
-	repo *repository.CommentRepository
+	repo      *repository.CommentRepository
+	cmsClient *client.CMSClient
 }
 
 // NewCommentService creates a new comment service
-func NewCommentService(repo *repository.CommentRepository) *CommentService {
+func NewCommentService(repo *repository.CommentRepository, cmsClient *client.CMSClient) *CommentService {
 	return &CommentService{
-		repo: repo,
+		repo:      repo,
+		cmsClient: cmsClient,
 	}
 }
 
-// CreateComment creates a new comment
-func (s *CommentService) CreateComment(ctx context.Context, comment *model.Comment, userID string, userName string) error {
+// CreateComment creates a new comment. The CMS screens it first for the
+// tenant of the origin: comments on articles of other tenants and comments
+// matching blocked keywords are refused, and matches of review keywords are
+// noted for moderators.
+func (s *CommentService) CreateComment(ctx context.Context, origin client.Origin, comment *model.Comment, userID string, userName string) error {
 	// Validate content
 	comment.Content = strings.TrimSpace(comment.Content)
 	if comment.Content == "" {
@@ -39,6 +45,16 @@ func (s *CommentService) CreateComment(ctx context.Context, comment *model.Comme
 		return fmt.Errorf("comment exceeds maximum length of %d characters", MaxCommentLength)
 	}
 
+	screening, err := s.cmsClient.ScreenComment(ctx, origin, comment.ArticleID.Hex(), comment.Content)
+	if err != nil {
+		return err
+	}
+	comment.ModerationNote = ""
+	if screening.NeedsReview() {
+		// Comments always start pending; flag the matches for moderators
+		comment.ModerationNote = "Flagged by sensitive keywords: " + strings.Join(screening.Keywords(), ", ")
+	}
+
 	// Check rate limit
 	allowed, err := s.repo.CheckRateLimit(ctx, userID, MaxCommentsPerHour)
 	if err != nil {
@@ -58,6 +74,9 @@ func (s *CommentService) CreateComment(ctx context.Context, comment *model.Comme
 		if err != nil {
 			return fmt.Errorf("parent comment not found: %w", err)
 		}
+		if parent.ArticleID != comment.ArticleID {
+			return fmt.Errorf("parent comment belongs to another article")
+		}
 
 		// Check nesting level
 		comment.Level = parent.Level + 1